@@ -0,0 +1,143 @@
+package tea
+
+import "strings"
+
+// textAttrs is a set of text attributes which not all terminals support.
+type textAttrs byte
+
+// Available text attributes.
+const (
+	attrItalic textAttrs = 1 << iota
+	attrStrikethrough
+	attrUndercurl
+	attrUnderlineColor
+
+	// attrsBasic are the attributes supported by almost any terminal
+	// emulator, though not by the Linux console.
+	attrsBasic = attrItalic | attrStrikethrough
+
+	// attrsAll are all attributes we know about.
+	attrsAll = attrsBasic | attrUndercurl | attrUnderlineColor
+)
+
+func (a textAttrs) has(attr textAttrs) bool {
+	return a&attr != 0
+}
+
+// filterAttrs drops the given unsupported text attributes from the SGR
+// sequences in s. Where there's a reasonable substitute it's used instead:
+// undercurls and other styled underlines become plain underlines. Terminals
+// which don't understand an attribute might otherwise render something else
+// entirely, or print parts of the sequence as text.
+func filterAttrs(s string, unsupported textAttrs) string {
+	if unsupported == 0 {
+		return s
+	}
+	return mapSGR(s, func(params string) string {
+		return filterSGRParams(params, unsupported)
+	})
+}
+
+// mapSGR replaces the parameters of the SGR sequences in s with what fn
+// returns for them. Sequences for which fn returns no parameters are dropped,
+// unless they had none to begin with.
+func mapSGR(s string, fn func(params string) string) string {
+	if !strings.Contains(s, "\x1b[") {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for len(s) > 0 {
+		i := strings.IndexByte(s, '\x1b')
+		if i < 0 {
+			b.WriteString(s)
+			break
+		}
+		b.WriteString(s[:i])
+		s = s[i:]
+
+		n := escapeSequenceLen(s)
+		if n == 0 {
+			// A lone escape character at the end of the string.
+			b.WriteString(s)
+			break
+		}
+		seq := s[:n]
+		s = s[n:]
+
+		if !isSGR(seq) {
+			b.WriteString(seq)
+			continue
+		}
+		if params := fn(seq[2 : n-1]); params != "" || n == 3 {
+			b.WriteString("\x1b[" + params + "m")
+		}
+	}
+	return b.String()
+}
+
+// isSGR reports whether seq is a complete SGR (Select Graphic Rendition)
+// sequence.
+func isSGR(seq string) bool {
+	if len(seq) < 3 || seq[1] != '[' || seq[len(seq)-1] != 'm' { //nolint:gomnd
+		return false
+	}
+	for i := 2; i < len(seq)-1; i++ {
+		if c := seq[i]; (c < '0' || c > '9') && c != ';' && c != ':' {
+			return false
+		}
+	}
+	return true
+}
+
+// filterSGRParams removes unsupported attributes from the parameters of an
+// SGR sequence. If nothing remains, the empty string is returned, in which
+// case the sequence should be dropped altogether: an SGR sequence without
+// parameters resets all attributes.
+func filterSGRParams(params string, unsupported textAttrs) string {
+	in := strings.Split(params, ";")
+	out := make([]string, 0, len(in))
+
+	for i := 0; i < len(in); i++ {
+		p := in[i]
+		switch {
+		case p == "3" && unsupported.has(attrItalic):
+		case p == "9" && unsupported.has(attrStrikethrough):
+
+		case strings.HasPrefix(p, "4:") && unsupported.has(attrUndercurl):
+			if p == "4:0" {
+				out = append(out, "24")
+			} else {
+				out = append(out, "4")
+			}
+
+		case p == "38" || p == "48" || p == "58":
+			// Extended colors in their semicolon form: 5;n for palette
+			// colors and 2;r;g;b for direct colors.
+			end := i + 1
+			if end < len(in) {
+				switch in[end] {
+				case "5":
+					end += 2
+				case "2":
+					end += 4
+				}
+			}
+			if end > len(in) {
+				end = len(in)
+			}
+			if p != "58" || !unsupported.has(attrUnderlineColor) {
+				out = append(out, in[i:end]...)
+			}
+			i = end - 1
+
+		case (p == "59" || strings.HasPrefix(p, "58:")) && unsupported.has(attrUnderlineColor):
+
+		default:
+			out = append(out, p)
+		}
+	}
+
+	return strings.Join(out, ";")
+}
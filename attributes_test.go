@@ -0,0 +1,32 @@
+package tea
+
+import "testing"
+
+func TestFilterAttrs(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		unsupported textAttrs
+		expected    string
+	}{
+		{"all supported", "\x1b[3;9mhi\x1b[0m", 0, "\x1b[3;9mhi\x1b[0m"},
+		{"no italic", "\x1b[1;3mhi\x1b[0m", attrItalic, "\x1b[1mhi\x1b[0m"},
+		{"dropped sequence", "\x1b[3mhi\x1b[m", attrItalic, "hi\x1b[m"},
+		{"no strikethrough", "\x1b[9;31mhi", attrStrikethrough, "\x1b[31mhi"},
+		{"undercurl", "\x1b[4:3mhi\x1b[4:0m", attrUndercurl, "\x1b[4mhi\x1b[24m"},
+		{"underline color", "\x1b[4;58;5;196mhi\x1b[59m", attrUnderlineColor, "\x1b[4mhi"},
+		{"underline color direct", "\x1b[58;2;1;2;3;1mhi", attrUnderlineColor, "\x1b[1mhi"},
+		{"underline color colon", "\x1b[58:2::1:2:3mhi", attrUnderlineColor, "hi"},
+		{"palette colors", "\x1b[38;5;3;48;5;9mhi", attrsAll, "\x1b[38;5;3;48;5;9mhi"},
+		{"direct colors", "\x1b[38;2;3;9;3mhi", attrsAll, "\x1b[38;2;3;9;3mhi"},
+		{"other sequences", "\x1b[3Ahi\x1b]2;3\a", attrsAll, "\x1b[3Ahi\x1b]2;3\a"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := filterAttrs(test.input, test.unsupported); got != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,361 @@
+package tea
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea/input"
+	isatty "github.com/mattn/go-isatty"
+	"github.com/muesli/termenv"
+)
+
+// queryTimeout is how long we wait for the terminal to answer our queries
+// before giving up on it. Terminals that don't understand a query usually
+// ignore it rather than respond with an error.
+const queryTimeout = 2 * time.Second
+
+// Capabilities describes the features supported by the terminal. It's
+// determined from the environment and, where input and output are both
+// terminals, by querying the terminal itself.
+type Capabilities struct {
+	// Terminal is the name of the terminal emulator (or multiplexer) as
+	// detected from the environment, such as "kitty" or "tmux". It's empty
+	// if the terminal couldn't be identified.
+	Terminal string
+
+	// TrueColor reports whether the terminal supports 24-bit color.
+	TrueColor bool
+
+	// TrueColorQueried reports whether the TrueColor verdict was reached by
+	// querying the terminal, rather than guessed from the environment.
+	TrueColorQueried bool
+
+	// Italic, Strikethrough, Undercurl and UnderlineColor report whether the
+	// terminal supports the respective text attributes. Undercurl includes
+	// the other styled underlines, such as dotted and dashed ones. They're
+	// determined from what's known about the terminal, its terminfo entry and
+	// by querying it. Unsupported attributes are dropped from the program's
+	// output, or substituted where possible.
+	Italic         bool
+	Strikethrough  bool
+	Undercurl      bool
+	UnderlineColor bool
+
+	// Win32InputMode reports whether the terminal supports win32-input-mode,
+	// as Windows Terminal does. It's only queried, and enabled, for
+	// programs which ask for keyboard enhancements.
+	Win32InputMode bool
+
+	// SynchronizedOutput reports whether frames are written as synchronized
+	// updates, which the terminal shows all at once. It's queried unless
+	// set with WithSynchronizedOutput.
+	SynchronizedOutput bool
+
+	// ColorProfile is the color profile the program's output is rendered
+	// with. Colors beyond it are converted to the closest ones it supports.
+	// It's detected from the environment, the terminal's terminfo entry and
+	// by querying the terminal, unless set with WithColorProfile.
+	ColorProfile termenv.Profile
+
+	// ImageProtocol is the protocol images placed with PlaceImage are drawn
+	// with. It's chosen based on the terminal and, for sixel graphics, by
+	// querying the terminal. It's ImageProtocolNone where images can't be
+	// drawn, including on terminals whose tier doesn't support graphics.
+	ImageProtocol ImageProtocol
+
+	// Notifications reports whether Notify shows desktop notifications, as
+	// opposed to ringing the bell. It's based on the terminal and its tier.
+	Notifications bool
+
+	// Tier is the terminal's tier, which determines the features Bubble Tea
+	// uses. See Tier and Supports.
+	Tier Tier
+}
+
+// textAttrs returns the set of supported text attributes.
+func (c Capabilities) textAttrs() textAttrs {
+	var a textAttrs
+	if c.Italic {
+		a |= attrItalic
+	}
+	if c.Strikethrough {
+		a |= attrStrikethrough
+	}
+	if c.Undercurl {
+		a |= attrUndercurl
+	}
+	if c.UnderlineColor {
+		a |= attrUnderlineColor
+	}
+	return a
+}
+
+// CapabilitiesMsg is sent once when the program starts, after the terminal's
+// capabilities have been detected.
+type CapabilitiesMsg Capabilities
+
+// queryCapabilitiesTimeoutMsg is sent when the terminal hasn't answered our
+// capability queries in time.
+type queryCapabilitiesTimeoutMsg struct{}
+
+// capabilityQuery tracks the progress of capability detection.
+type capabilityQuery struct {
+	caps Capabilities
+
+	// results of the individual truecolor queries
+	rgbTermcap  bool
+	sgrReported bool
+	sgrDirect   bool
+
+	// whether the terminal reported supporting ANSI colors in its device
+	// attributes
+	ansiColor bool
+
+	// whether the terminal reported supporting rectangular area operations in
+	// its device attributes
+	rectOps bool
+
+	done bool
+}
+
+// truecolorSGR is the foreground color we set before querying the SGR state
+// with DECRQSS. Terminals that support truecolor report it back unchanged;
+// others report the palette color they've reduced it to, or nothing at all.
+const truecolorSGR = "\x1b[38;2;1;2;3m"
+
+// envCapabilities determines the terminal's capabilities from the
+// environment alone.
+func envCapabilities(term string, getenv func(string) string) Capabilities {
+	colorterm := strings.ToLower(getenv("COLORTERM"))
+	attrs := quirksFor(term).textAttrs
+	return Capabilities{
+		Terminal:       term,
+		TrueColor:      colorterm == "truecolor" || colorterm == "24bit",
+		Italic:         attrs.has(attrItalic),
+		Strikethrough:  attrs.has(attrStrikethrough),
+		Undercurl:      attrs.has(attrUndercurl),
+		UnderlineColor: attrs.has(attrUnderlineColor),
+		Notifications:  quirksFor(term).notifications != notifyBell,
+		ImageProtocol:  quirksFor(term).imageProtocol,
+		Tier:           detectTier(term, getenv),
+	}
+}
+
+// applyTerminfo refines the text attributes with those listed in the
+// terminal's terminfo entry. For terminals we know it only ever adds
+// attributes, as entries commonly trail behind what terminals support. For
+// others it's the best we have to go on, so attributes the entry doesn't list
+// are dropped, rather than risk them being printed as garbage.
+func (c *Capabilities) applyTerminfo(attrs input.TextAttrs, known bool) {
+	if known {
+		c.Italic = c.Italic || attrs.Italic
+		c.Strikethrough = c.Strikethrough || attrs.Strikethrough
+		c.Undercurl = c.Undercurl || attrs.Undercurl
+		c.UnderlineColor = c.UnderlineColor || attrs.UnderlineColor
+		return
+	}
+	c.Italic = attrs.Italic
+	c.Strikethrough = attrs.Strikethrough
+	c.Undercurl = attrs.Undercurl
+	c.UnderlineColor = attrs.UnderlineColor
+}
+
+// canQuery reports whether we can query the terminal, which requires both
+// input and output to be terminals.
+func (p *Program) canQuery() bool {
+	in, ok := p.input.(*os.File)
+	if !ok || !isatty.IsTerminal(in.Fd()) {
+		return false
+	}
+	out, ok := p.output.TTY().(*os.File)
+	return ok && isatty.IsTerminal(out.Fd())
+}
+
+// queryCapabilities starts capability detection. If the terminal can be
+// queried the queries are sent, and the result is reported once the answers
+// are in. Otherwise the capabilities are reported based on the environment
+// right away.
+func (p *Program) queryCapabilities() {
+	p.capQuery = &capabilityQuery{caps: envCapabilities(p.terminal, os.Getenv)}
+	p.capQuery.caps.Tier = p.tier
+	if attrs, err := input.TerminfoTextAttrs(os.Getenv("TERM")); err == nil {
+		_, known := quirks[p.terminal]
+		p.capQuery.caps.applyTerminfo(attrs, known)
+	}
+	if p.output.Profile == termenv.TrueColor {
+		p.capQuery.caps.TrueColor = true
+	}
+
+	if !p.canQuery() {
+		p.finishCapabilityQuery()
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(queryTermcap("RGB", "Tc", "sitm", "smxx", "Smulx", "Setulc"))
+	b.WriteString(truecolorSGR)
+	b.WriteString(querySGR)
+	b.WriteString("\x1b[m")
+	if p.keyboardRequested != 0 {
+		b.WriteString(queryMode(win32InputMode))
+	}
+	if !p.syncOutputForced {
+		b.WriteString(queryMode(synchronizedOutputMode))
+	}
+	// The terminal's colors are answered for by the same DA1 response.
+	colors := p.startColorQuery()
+	if colors {
+		b.WriteString(queryForegroundColor + queryBackgroundColor)
+	}
+	b.WriteString(queryPrimaryDeviceAttributes)
+	p.expectDCS(true)
+	if err := p.renderer.execute(b.String()); err != nil {
+		p.finishCapabilityQuery()
+		if colors {
+			p.finishColorQuery()
+		}
+		return
+	}
+
+	go func() {
+		select {
+		case <-p.ctx.Done():
+		case <-time.After(queryTimeout):
+			p.Send(queryCapabilitiesTimeoutMsg{})
+		}
+	}()
+}
+
+// handleCapabilityResponse records the terminal's answers to our capability
+// queries.
+func (p *Program) handleCapabilityResponse(msg Msg) {
+	q := p.capQuery
+	if q == nil || q.done {
+		return
+	}
+
+	switch msg := msg.(type) {
+	case termcapMsg:
+		if !msg.ok {
+			break
+		}
+		// Only positive answers count: terminals which do answer XTGETTCAP
+		// don't necessarily list every capability they support.
+		switch msg.name {
+		case "RGB", "Tc":
+			q.rgbTermcap = true
+		case "sitm":
+			q.caps.Italic = true
+		case "smxx":
+			q.caps.Strikethrough = true
+		case "Smulx":
+			q.caps.Undercurl = true
+		case "Setulc":
+			q.caps.UnderlineColor = true
+		}
+
+	case statusStringMsg:
+		if msg.ok {
+			q.sgrReported = true
+			q.sgrDirect = strings.Contains(msg.value, "38:2") || strings.Contains(msg.value, "38;2")
+		}
+
+	case modeReportMsg:
+		switch {
+		case !msg.supported():
+		case msg.mode == win32InputMode:
+			q.caps.Win32InputMode = true
+		case msg.mode == synchronizedOutputMode:
+			q.caps.SynchronizedOutput = true
+		}
+
+	case primaryDeviceAttributesMsg:
+		for _, attr := range msg {
+			if attr == deviceAttrSixel && q.caps.ImageProtocol == ImageProtocolNone {
+				q.caps.ImageProtocol = ImageProtocolSixel
+			}
+			if attr == deviceAttrANSIColor {
+				q.ansiColor = true
+			}
+			if attr == deviceAttrRectangularEditing {
+				q.rectOps = true
+			}
+		}
+		p.finishCapabilityQuery()
+
+	case queryCapabilitiesTimeoutMsg:
+		p.finishCapabilityQuery()
+	}
+}
+
+// finishCapabilityQuery reaches a verdict, applies it, and reports the
+// terminal's capabilities to the program.
+func (p *Program) finishCapabilityQuery() {
+	q := p.capQuery
+	q.done = true
+	p.expectDCS(false)
+
+	switch {
+	case q.rgbTermcap || q.sgrDirect:
+		q.caps.TrueColor = true
+		q.caps.TrueColorQueried = true
+	case q.sgrReported:
+		// The terminal reported back the SGR state, but not the color we
+		// set, so it must have reduced it to the palette.
+		q.caps.TrueColor = false
+		q.caps.TrueColorQueried = true
+	}
+
+	// Make sure the output's color profile agrees with our verdict.
+	if q.ansiColor && p.output.Profile == termenv.Ascii && !p.colorProfileForced && p.colorsAllowed() {
+		p.output.Profile = termenv.ANSI
+	}
+	if q.caps.TrueColorQueried && !p.colorProfileForced {
+		if q.caps.TrueColor && (p.output.Profile == termenv.ANSI256 || p.output.Profile == termenv.ANSI) {
+			p.output.Profile = termenv.TrueColor
+		} else if !q.caps.TrueColor && p.output.Profile == termenv.TrueColor {
+			p.output.Profile = termenv.ANSI256
+		}
+	}
+
+	// Dumb terminals don't get any attributes, whatever they claim.
+	if q.caps.Tier == TierDumb {
+		q.caps.Italic = false
+		q.caps.Strikethrough = false
+		q.caps.Undercurl = false
+		q.caps.UnderlineColor = false
+		q.caps.SynchronizedOutput = false
+	}
+	if !q.caps.Supports(FeatureGraphics) {
+		q.caps.ImageProtocol = ImageProtocolNone
+	}
+	if !q.caps.Supports(FeatureNotifications) {
+		q.caps.Notifications = false
+	}
+	if p.syncOutputForced {
+		q.caps.SynchronizedOutput = p.syncOutput
+	}
+
+	q.caps.ColorProfile = p.output.Profile
+
+	// Have the renderer drop the attributes and colors the terminal doesn't
+	// support, and synchronize frames and move blocks of lines where it can.
+	if r, ok := p.renderer.(*standardRenderer); ok {
+		r.setColorProfile(q.caps.ColorProfile)
+		r.setUnsupportedAttrs(attrsAll &^ q.caps.textAttrs())
+		r.setSynchronizedOutput(q.caps.SynchronizedOutput)
+		r.setImageProtocol(q.caps.ImageProtocol)
+		r.setRectangularOps(q.rectOps && q.caps.Tier != TierDumb)
+	}
+
+	// Windows Terminal reports keys in detail with win32-input-mode rather
+	// than keyboard enhancements, so enable it where it's supported.
+	if q.caps.Win32InputMode && p.keyboardRequested != 0 {
+		_ = p.renderer.execute(enableWin32InputMode)
+		p.win32Input = true
+	}
+
+	caps := q.caps
+	go p.Send(CapabilitiesMsg(caps))
+}
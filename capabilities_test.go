@@ -0,0 +1,317 @@
+package tea
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbletea/input"
+	"github.com/muesli/termenv"
+)
+
+func TestEnvCapabilities(t *testing.T) {
+	env := map[string]string{"COLORTERM": "truecolor"}
+	caps := envCapabilities(termKitty, func(k string) string { return env[k] })
+	if caps.Terminal != termKitty || !caps.TrueColor || caps.TrueColorQueried {
+		t.Errorf("unexpected capabilities %+v", caps)
+	}
+}
+
+func TestApplyTerminfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		attrs    input.TextAttrs
+		known    bool
+		expected textAttrs
+	}{
+		{"known adds", input.TextAttrs{Undercurl: true}, true, attrsBasic | attrUndercurl},
+		{"known keeps", input.TextAttrs{}, true, attrsBasic},
+		{"unknown adds", input.TextAttrs{Italic: true, UnderlineColor: true}, false, attrItalic | attrUnderlineColor},
+		{"unknown drops", input.TextAttrs{}, false, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			caps := envCapabilities(termUnknown, func(string) string { return "" })
+			caps.applyTerminfo(test.attrs, test.known)
+			if a := caps.textAttrs(); a != test.expected {
+				t.Errorf("expected attributes %b, got %b", test.expected, a)
+			}
+		})
+	}
+}
+
+func TestCapabilityQueryVerdict(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []Msg
+		profile   termenv.Profile
+		trueColor bool
+		queried   bool
+		expected  termenv.Profile
+	}{
+		{
+			name:      "no answers",
+			responses: []Msg{primaryDeviceAttributesMsg{62}},
+			profile:   termenv.ANSI256,
+			expected:  termenv.ANSI256,
+		},
+		{
+			name:      "timeout",
+			responses: []Msg{queryCapabilitiesTimeoutMsg{}},
+			profile:   termenv.ANSI256,
+			expected:  termenv.ANSI256,
+		},
+		{
+			name:      "termcap",
+			responses: []Msg{termcapMsg{name: "RGB", ok: true}, primaryDeviceAttributesMsg{62}},
+			profile:   termenv.ANSI256,
+			trueColor: true,
+			queried:   true,
+			expected:  termenv.TrueColor,
+		},
+		{
+			name:      "sgr direct",
+			responses: []Msg{statusStringMsg{value: "0;38:2::1:2:3m", ok: true}, primaryDeviceAttributesMsg{62}},
+			profile:   termenv.ANSI,
+			trueColor: true,
+			queried:   true,
+			expected:  termenv.TrueColor,
+		},
+		{
+			name:      "sgr reduced",
+			responses: []Msg{statusStringMsg{value: "0;38;5;16m", ok: true}, primaryDeviceAttributesMsg{62}},
+			profile:   termenv.TrueColor,
+			queried:   true,
+			expected:  termenv.ANSI256,
+		},
+		{
+			name:      "no color",
+			responses: []Msg{termcapMsg{name: "RGB", ok: true}, primaryDeviceAttributesMsg{62}},
+			profile:   termenv.Ascii,
+			trueColor: true,
+			queried:   true,
+			expected:  termenv.Ascii,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			p := NewProgram(nil, WithOutput(&buf))
+			p.output.Profile = test.profile
+			p.capQuery = &capabilityQuery{}
+
+			for _, msg := range test.responses {
+				p.handleCapabilityResponse(msg)
+			}
+
+			q := p.capQuery
+			if !q.done {
+				t.Fatal("expected the query to be done")
+			}
+			if q.caps.TrueColor != test.trueColor || q.caps.TrueColorQueried != test.queried {
+				t.Errorf("expected truecolor %t (queried %t), got %t (queried %t)",
+					test.trueColor, test.queried, q.caps.TrueColor, q.caps.TrueColorQueried)
+			}
+			if p.output.Profile != test.expected {
+				t.Errorf("expected profile %v, got %v", test.expected, p.output.Profile)
+			}
+		})
+	}
+}
+
+type capabilitiesModel struct {
+	caps *Capabilities
+}
+
+func (m capabilitiesModel) Init() Cmd {
+	return nil
+}
+
+func (m capabilitiesModel) Update(msg Msg) (Model, Cmd) {
+	if msg, ok := msg.(CapabilitiesMsg); ok {
+		*m.caps = Capabilities(msg)
+		return m, Quit
+	}
+	return m, nil
+}
+
+func (m capabilitiesModel) View() string {
+	return ""
+}
+
+func TestCapabilitiesMsg(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+
+	m := capabilitiesModel{caps: &Capabilities{}}
+	p := NewProgram(m, WithInput(&in), WithOutput(&buf))
+	p.terminal = termXterm
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if m.caps.Terminal != termXterm {
+		t.Errorf("expected terminal %q, got %q", termXterm, m.caps.Terminal)
+	}
+	if m.caps.TrueColorQueried {
+		t.Errorf("expected no queries to be sent to a non-terminal")
+	}
+}
+
+func TestCapabilityQueryTextAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewProgram(nil, WithOutput(&buf))
+	r := newRenderer(p.output, false).(*standardRenderer)
+	p.renderer = r
+	p.capQuery = &capabilityQuery{caps: envCapabilities(termXterm, func(string) string { return "" })}
+
+	for _, msg := range []Msg{
+		termcapMsg{name: "Smulx", ok: true},
+		termcapMsg{name: "Setulc"},
+		primaryDeviceAttributesMsg{62},
+	} {
+		p.handleCapabilityResponse(msg)
+	}
+
+	caps := p.capQuery.caps
+	if !caps.Italic || !caps.Strikethrough || !caps.Undercurl || caps.UnderlineColor {
+		t.Errorf("unexpected capabilities %+v", caps)
+	}
+	if r.unsupportedAttrs != attrUnderlineColor {
+		t.Errorf("expected only underline colors to be filtered, got %b", r.unsupportedAttrs)
+	}
+}
+
+func TestCapabilityQueryWin32InputMode(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested KeyboardEnhancements
+		report    modeReportMsg
+		enabled   bool
+	}{
+		{"supported", KeyboardReportEvents, modeReportMsg{mode: win32InputMode, value: 2}, true},
+		{"unsupported", KeyboardReportEvents, modeReportMsg{mode: win32InputMode}, false},
+		{"not requested", 0, modeReportMsg{mode: win32InputMode, value: 2}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			p := NewProgram(nil, WithOutput(&buf))
+			p.renderer = newRenderer(p.output, false)
+			p.keyboardRequested = test.requested
+			p.capQuery = &capabilityQuery{}
+
+			p.handleCapabilityResponse(test.report)
+			p.handleCapabilityResponse(primaryDeviceAttributesMsg{62})
+
+			if p.win32Input != test.enabled {
+				t.Errorf("expected win32-input-mode enabled %t, got %t", test.enabled, p.win32Input)
+			}
+			if enabled := strings.Contains(buf.String(), enableWin32InputMode); enabled != test.enabled {
+				t.Errorf("expected the mode to be enabled %t, got output %q", test.enabled, buf.String())
+			}
+		})
+	}
+}
+
+func TestCapabilityQuerySynchronizedOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []ProgramOption
+		tier    Tier
+		report  modeReportMsg
+		enabled bool
+	}{
+		{"supported", nil, TierXterm, modeReportMsg{mode: synchronizedOutputMode, value: 2}, true},
+		{"unsupported", nil, TierXterm, modeReportMsg{mode: synchronizedOutputMode}, false},
+		{"dumb", nil, TierDumb, modeReportMsg{mode: synchronizedOutputMode, value: 2}, false},
+		{"forced on", []ProgramOption{WithSynchronizedOutput(true)}, TierXterm, modeReportMsg{mode: synchronizedOutputMode}, true},
+		{"forced off", []ProgramOption{WithSynchronizedOutput(false)}, TierXterm, modeReportMsg{mode: synchronizedOutputMode, value: 2}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			p := NewProgram(nil, append(test.opts, WithOutput(&buf))...)
+			r := newRenderer(p.output, false).(*standardRenderer)
+			p.renderer = r
+			p.capQuery = &capabilityQuery{caps: Capabilities{Tier: test.tier}}
+
+			p.handleCapabilityResponse(test.report)
+			p.handleCapabilityResponse(primaryDeviceAttributesMsg{62})
+
+			if p.capQuery.caps.SynchronizedOutput != test.enabled {
+				t.Errorf("expected synchronized output %t, got %t", test.enabled, p.capQuery.caps.SynchronizedOutput)
+			}
+			if r.syncOutput != test.enabled {
+				t.Errorf("expected the renderer to synchronize output %t, got %t", test.enabled, r.syncOutput)
+			}
+		})
+	}
+}
+
+func TestCapabilityQueryImageProtocol(t *testing.T) {
+	tests := []struct {
+		name     string
+		terminal string
+		tier     Tier
+		attrs    primaryDeviceAttributesMsg
+		expected ImageProtocol
+	}{
+		{"kitty", termKitty, TierModern, primaryDeviceAttributesMsg{62}, ImageProtocolKitty},
+		{"iterm2", termITerm2, TierModern, primaryDeviceAttributesMsg{62, 4}, ImageProtocolITerm2},
+		{"sixel queried", termXterm, TierModern, primaryDeviceAttributesMsg{62, 4}, ImageProtocolSixel},
+		{"none", termXterm, TierModern, primaryDeviceAttributesMsg{62}, ImageProtocolNone},
+		{"tier", termFoot, TierXterm, primaryDeviceAttributesMsg{62, 4}, ImageProtocolNone},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			p := NewProgram(nil, WithOutput(&buf))
+			r := newRenderer(p.output, false).(*standardRenderer)
+			p.renderer = r
+			caps := envCapabilities(test.terminal, func(string) string { return "" })
+			caps.Tier = test.tier
+			p.capQuery = &capabilityQuery{caps: caps}
+
+			p.handleCapabilityResponse(test.attrs)
+
+			if p.capQuery.caps.ImageProtocol != test.expected {
+				t.Errorf("expected image protocol %s, got %s", test.expected, p.capQuery.caps.ImageProtocol)
+			}
+			if r.imageProtocol != test.expected {
+				t.Errorf("expected the renderer to use %s, got %s", test.expected, r.imageProtocol)
+			}
+		})
+	}
+}
+
+func TestCapabilityQueryRectangularOps(t *testing.T) {
+	tests := []struct {
+		name     string
+		tier     Tier
+		response Msg
+		expected bool
+	}{
+		{"reported", TierXterm, primaryDeviceAttributesMsg{64, 28}, true},
+		{"not reported", TierXterm, primaryDeviceAttributesMsg{64, 22}, false},
+		{"timeout", TierXterm, queryCapabilitiesTimeoutMsg{}, false},
+		{"dumb", TierDumb, primaryDeviceAttributesMsg{64, 28}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			p := NewProgram(nil, WithOutput(&buf))
+			r := newRenderer(p.output, false).(*standardRenderer)
+			p.renderer = r
+			p.capQuery = &capabilityQuery{caps: Capabilities{Tier: test.tier}}
+
+			p.handleCapabilityResponse(test.response)
+
+			if r.rectOps != test.expected {
+				t.Errorf("expected rectangular operations %t, got %t", test.expected, r.rectOps)
+			}
+		})
+	}
+}
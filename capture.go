@@ -0,0 +1,249 @@
+package tea
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"unicode"
+)
+
+// CaptureCallback is used by CaptureProcess to return a message with the
+// captured output of a command.
+type CaptureCallback func(*OutputView) Msg
+
+// CaptureProcess runs the given *exec.Cmd and captures its output, rather
+// than releasing the terminal to it like ExecProcess does. The program keeps
+// running in the meantime. Once the command has exited, the output is passed
+// to fn as an OutputView, which can be shown on top of the program's view as
+// a scrollable popup. This is handy for quickly showing the output of
+// non-interactive commands, like git diff.
+//
+//	type DiffMsg struct { view *tea.OutputView }
+//
+//	c := exec.Command("git", "diff", "--color=always")
+//
+//	cmd := tea.CaptureProcess(c, func(v *tea.OutputView) tea.Msg {
+//	    return DiffMsg{view: v}
+//	})
+//
+// Standard output and standard error are both captured. Commands usually
+// don't produce colored output when it's captured, so you may have to ask
+// for it, as in the example above. Styles are kept, but other escape
+// sequences, such as cursor movements, are removed. The command doesn't get
+// any input.
+func CaptureProcess(c *exec.Cmd, fn CaptureCallback) Cmd {
+	return func() Msg {
+		var buf bytes.Buffer
+		c.Stdout = &buf
+		c.Stderr = &buf
+		err := c.Run()
+
+		v := &OutputView{
+			Title: strings.Join(c.Args, " "),
+			Err:   err,
+			lines: captureLines(buf.Bytes()),
+		}
+		if fn == nil {
+			return nil
+		}
+		return fn(v)
+	}
+}
+
+// OutputView is a scrollable view of the output captured by CaptureProcess,
+// drawn with a border. Set its size with SetSize, pass it messages from your
+// Update function so it can handle scrolling, and place it on top of your
+// view with Layer and Compose:
+//
+//	func (m model) View() string {
+//	    view := m.mainView()
+//	    if m.output == nil {
+//	        return view
+//	    }
+//	    return tea.Compose(m.width, m.height,
+//	        tea.Layer{Rect: tea.Rect{Width: m.width, Height: m.height}, Content: view},
+//	        m.output.Layer(2, 1),
+//	    )
+//	}
+type OutputView struct {
+	// Title is shown in the top border. It's the command line by default.
+	Title string
+
+	// Err is the error returned by the command, if any.
+	Err error
+
+	lines  []string
+	offset int
+
+	width, height int
+}
+
+// SetSize sets the size of the view, including its border.
+func (v *OutputView) SetSize(width, height int) {
+	v.width, v.height = width, height
+	v.scroll(0)
+}
+
+// LineCount returns the number of lines of output.
+func (v *OutputView) LineCount() int {
+	return len(v.lines)
+}
+
+// ScrollUp scrolls the output up by n lines.
+func (v *OutputView) ScrollUp(n int) {
+	v.scroll(-n)
+}
+
+// ScrollDown scrolls the output down by n lines.
+func (v *OutputView) ScrollDown(n int) {
+	v.scroll(n)
+}
+
+// Update scrolls the output in response to the arrow, page up/down, home and
+// end keys, as well as the mouse wheel. Other messages are ignored.
+func (v *OutputView) Update(msg Msg) {
+	page := v.innerHeight()
+
+	switch msg := msg.(type) {
+	case KeyMsg:
+		switch msg.Type {
+		case KeyUp:
+			v.scroll(-1)
+		case KeyDown:
+			v.scroll(1)
+		case KeyPgUp:
+			v.scroll(-page)
+		case KeyPgDown, KeySpace:
+			v.scroll(page)
+		case KeyHome:
+			v.scroll(-len(v.lines))
+		case KeyEnd:
+			v.scroll(len(v.lines))
+		}
+
+	case MouseMsg:
+		switch msg.Type {
+		case MouseWheelUp:
+			v.scroll(-3) //nolint:gomnd
+		case MouseWheelDown:
+			v.scroll(3) //nolint:gomnd
+		}
+	}
+}
+
+// View renders the visible part of the output with a border.
+func (v *OutputView) View() string {
+	if v.width < 2 || v.height < 2 { //nolint:gomnd
+		return ""
+	}
+	iw, ih := v.width-2, v.innerHeight() //nolint:gomnd
+
+	var b strings.Builder
+
+	// Top border, with the title.
+	title := ""
+	if v.Title != "" && iw > 2 { //nolint:gomnd
+		title = " " + Truncate(v.Title, iw-2, "…") + " "
+	}
+	b.WriteString("┌" + title + strings.Repeat("─", iw-StringWidth(title)) + "┐\n")
+
+	for i := 0; i < ih; i++ {
+		var line string
+		if n := v.offset + i; n < len(v.lines) {
+			line = v.lines[n]
+		}
+		line = PadRight(Truncate(line, iw, ""), iw)
+		if strings.Contains(line, "\x1b") {
+			line += "\x1b[m"
+		}
+		b.WriteString("│" + line + "│\n")
+	}
+
+	// Bottom border, with the scroll position.
+	pos := ""
+	if len(v.lines) > 0 {
+		last := v.offset + ih
+		if last > len(v.lines) {
+			last = len(v.lines)
+		}
+		pos = fmt.Sprintf(" %d-%d/%d ", v.offset+1, last, len(v.lines))
+	}
+	if StringWidth(pos) > iw {
+		pos = ""
+	}
+	b.WriteString("└" + strings.Repeat("─", iw-StringWidth(pos)) + pos + "┘")
+
+	return b.String()
+}
+
+// Layer returns the view as a layer at the given position, for use with
+// Compose.
+func (v *OutputView) Layer(x, y int) Layer {
+	return Layer{
+		Rect:    Rect{X: x, Y: y, Width: v.width, Height: v.height},
+		Content: v.View(),
+	}
+}
+
+func (v *OutputView) innerHeight() int {
+	if v.height < 2 { //nolint:gomnd
+		return 0
+	}
+	return v.height - 2 //nolint:gomnd
+}
+
+// scroll moves the offset by n lines, keeping it within bounds.
+func (v *OutputView) scroll(n int) {
+	v.offset += n
+	if limit := len(v.lines) - v.innerHeight(); v.offset > limit {
+		v.offset = limit
+	}
+	if v.offset < 0 {
+		v.offset = 0
+	}
+}
+
+// tabWidth is the width of tab stops in captured output.
+const tabWidth = 8
+
+// captureLines splits captured output into lines suitable for display. Tabs
+// are expanded, carriage returns overwrite the line so far, which is what
+// progress bars expect, and escape sequences other than styles are removed,
+// along with any other control characters.
+func captureLines(b []byte) []string {
+	s := strings.ReplaceAll(string(b), "\r\n", "\n")
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if j := strings.LastIndexByte(l, '\r'); j >= 0 {
+			l = l[j+1:]
+		}
+
+		var sb strings.Builder
+		var col int
+		forEachCluster(l, func(seq string, w int) bool {
+			switch {
+			case seq[0] == '\x1b':
+				if isSGR(seq) {
+					sb.WriteString(seq)
+				}
+			case seq == "\t":
+				n := tabWidth - col%tabWidth
+				sb.WriteString(strings.Repeat(" ", n))
+				col += n
+			case unicode.IsControl([]rune(seq)[0]):
+			default:
+				sb.WriteString(seq)
+				col += w
+			}
+			return true
+		})
+		lines[i] = sb.String()
+	}
+	return lines
+}
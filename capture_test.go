@@ -0,0 +1,78 @@
+package tea
+
+import (
+	"os/exec"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestCaptureLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"empty", "", nil},
+		{"lines", "a\nb\r\nc\n", []string{"a", "b", "c"}},
+		{"tabs", "a\tb\n\tc", []string{"a       b", "        c"}},
+		{"carriage return", "10%\r50%\r100%", []string{"100%"}},
+		{"styles", "\x1b[31mred\x1b[m", []string{"\x1b[31mred\x1b[m"}},
+		{"other sequences", "\x1b[2Ka\x1b[1;1Hb\x1b]2;x\a", []string{"ab"}},
+		{"control characters", "a\x07b\x00c", []string{"abc"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := captureLines([]byte(test.input)); !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("expected %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestOutputView(t *testing.T) {
+	v := &OutputView{Title: "cmd", lines: []string{"one", "two", "three", "four"}}
+	v.SetSize(9, 4)
+
+	expected := "┌ cmd ──┐\n│one    │\n│two    │\n└ 1-2/4 ┘"
+	if got := v.View(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	v.Update(KeyMsg{Type: KeyPgDown})
+	v.Update(KeyMsg{Type: KeyDown})
+	expected = "┌ cmd ──┐\n│three  │\n│four   │\n└ 3-4/4 ┘"
+	if got := v.View(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	v.Update(MouseMsg{Type: MouseWheelUp})
+	if v.offset != 0 {
+		t.Errorf("expected to scroll to the top, got offset %d", v.offset)
+	}
+
+	if l := v.Layer(1, 2); l.Rect != (Rect{X: 1, Y: 2, Width: 9, Height: 4}) {
+		t.Errorf("unexpected layer %+v", l.Rect)
+	}
+}
+
+func TestCaptureProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	cmd := CaptureProcess(exec.Command("sh", "-c", "echo out; echo err >&2; exit 3"), func(v *OutputView) Msg {
+		return v
+	})
+	v, ok := cmd().(*OutputView)
+	if !ok {
+		t.Fatal("expected an OutputView")
+	}
+	if v.Err == nil {
+		t.Error("expected the exit status to be reported")
+	}
+	if !reflect.DeepEqual(v.lines, []string{"out", "err"}) {
+		t.Errorf("unexpected output %q", v.lines)
+	}
+}
@@ -0,0 +1,163 @@
+package tea
+
+import "time"
+
+// CellSizeMsg reports the size of the terminal's cells in pixels, in response
+// to QueryCellSize. Programs that draw images need it to scale sixel and
+// kitty graphics to whole cells. Width and Height are zero if the size is
+// unknown.
+type CellSizeMsg struct {
+	Width  int
+	Height int
+}
+
+// queryCellSizeMsg is an internal message used to query the size of the
+// terminal's cells. You can send a queryCellSizeMsg with QueryCellSize.
+type queryCellSizeMsg struct{}
+
+// QueryCellSize is a command that asks the terminal for the size of its cells
+// in pixels, which is then reported with a CellSizeMsg. Where the terminal
+// doesn't answer, the size is worked out from the size of the window in
+// pixels, if that's known.
+func QueryCellSize() Msg {
+	return queryCellSizeMsg{}
+}
+
+// Query sequences for sizes in pixels (XTWINOPS).
+const (
+	queryWindowPixels = "\x1b[14t"
+	queryCellPixels   = "\x1b[16t"
+)
+
+// windowPixelsMsg is the terminal's response to a query for the size of its
+// text area in pixels.
+type windowPixelsMsg struct {
+	width, height int
+}
+
+// cellPixelsMsg is the terminal's response to a query for the size of its
+// cells in pixels.
+type cellPixelsMsg struct {
+	width, height int
+}
+
+// cellSizeQuery tracks the progress of a query for the size of the terminal's
+// cells.
+type cellSizeQuery struct {
+	done bool
+}
+
+// queryCellSizeTimeoutMsg is sent when the terminal hasn't answered a query
+// for the size of its cells in time.
+type queryCellSizeTimeoutMsg struct {
+	query *cellSizeQuery
+}
+
+// queryCellSize asks the terminal for the size of its cells. If it can't be
+// queried, the size is reported right away, as far as it's known. A query
+// that's already in progress is left to answer for both.
+func (p *Program) queryCellSize() {
+	if !p.canQuery() {
+		p.reportCellSize()
+		return
+	}
+	if p.cellQuery != nil && !p.cellQuery.done {
+		return
+	}
+	q := &cellSizeQuery{}
+	p.cellQuery = q
+
+	go func() {
+		select {
+		case <-p.ctx.Done():
+		case <-time.After(queryTimeout):
+			p.Send(queryCellSizeTimeoutMsg{query: q})
+		}
+	}()
+	if err := p.renderer.execute(queryCellPixels + queryPrimaryDeviceAttributes); err != nil {
+		p.finishCellSizeQuery()
+	}
+}
+
+// queryWindowPixels asks the terminal for the size of its window in pixels,
+// once, if the system doesn't report it along with the window's size. The
+// size of the terminal's cells is worked out from the answer, and the
+// window's size is sent again, this time in pixels, too.
+func (p *Program) queryWindowPixels(size WindowSizeMsg) {
+	if size.WidthPixels > 0 || p.windowPixelsQueried || !p.canQuery() {
+		return
+	}
+	p.windowPixelsQueried = true
+	_ = p.renderer.execute(queryWindowPixels)
+}
+
+// handleCellSizeResponse records the terminal's answers to queries for sizes
+// in pixels.
+func (p *Program) handleCellSizeResponse(msg Msg) {
+	pending := p.cellQuery != nil && !p.cellQuery.done
+
+	switch msg := msg.(type) {
+	case windowPixelsMsg:
+		if msg.width <= 0 || msg.height <= 0 || p.size.Width <= 0 || p.size.Height <= 0 {
+			return
+		}
+		p.setCellSize(msg.width/p.size.Width, msg.height/p.size.Height)
+		go p.sendSize(WindowSizeMsg{
+			Width:        p.size.Width,
+			Height:       p.size.Height,
+			WidthPixels:  msg.width,
+			HeightPixels: msg.height,
+		})
+	case cellPixelsMsg:
+		if msg.width > 0 && msg.height > 0 {
+			p.setCellSize(msg.width, msg.height)
+		}
+		if pending {
+			p.finishCellSizeQuery()
+		}
+	case primaryDeviceAttributesMsg:
+		if pending {
+			p.finishCellSizeQuery()
+		}
+	case queryCellSizeTimeoutMsg:
+		if pending && msg.query == p.cellQuery {
+			p.finishCellSizeQuery()
+		}
+	}
+}
+
+// finishCellSizeQuery ends the query for the size of the terminal's cells, and
+// reports the size, as far as it's known.
+func (p *Program) finishCellSizeQuery() {
+	p.cellQuery.done = true
+	p.reportCellSize()
+}
+
+// reportCellSize sends the program the size of the terminal's cells as
+// reported by the terminal or, failing that, as worked out from the size of
+// the window in pixels. It's zero if neither is known.
+func (p *Program) reportCellSize() {
+	w, h := p.cellSize()
+	if w == 0 && p.size.Width > 0 && p.size.Height > 0 {
+		w, h = p.size.WidthPixels/p.size.Width, p.size.HeightPixels/p.size.Height
+	}
+	go p.Send(CellSizeMsg{Width: w, Height: h})
+}
+
+// cellSize returns the size of the terminal's cells in pixels, as reported by
+// the terminal, or zeros if it hasn't been.
+func (p *Program) cellSize() (int, int) {
+	p.sizeMtx.Lock()
+	defer p.sizeMtx.Unlock()
+
+	return p.cellWidth, p.cellHeight
+}
+
+// setCellSize records the size of the terminal's cells in pixels, as reported
+// by the terminal.
+func (p *Program) setCellSize(w, h int) {
+	p.sizeMtx.Lock()
+	defer p.sizeMtx.Unlock()
+
+	p.cellWidth, p.cellHeight = w, h
+}
@@ -0,0 +1,68 @@
+package tea
+
+import (
+	"testing"
+	"time"
+)
+
+// nextMsg returns the next message sent to the program.
+func nextMsg(t *testing.T, p *Program) Msg {
+	t.Helper()
+	select {
+	case msg := <-p.msgs:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("expected a message")
+		return nil
+	}
+}
+
+func TestCellSize(t *testing.T) {
+	t.Run("window pixels", func(t *testing.T) {
+		p := NewProgram(nil)
+		p.size = WindowSizeMsg{Width: 80, Height: 24}
+
+		// The terminal's answer is sent as the window's size in pixels, and
+		// the size of its cells is worked out from it.
+		p.handleCellSizeResponse(windowPixelsMsg{width: 800, height: 480})
+		expected := WindowSizeMsg{Width: 80, Height: 24, WidthPixels: 800, HeightPixels: 480}
+		if msg := nextMsg(t, p); msg != expected {
+			t.Errorf("expected %#v, got %#v", expected, msg)
+		}
+		if w, h := p.cellSize(); w != 10 || h != 20 {
+			t.Errorf("expected a cell size of 10x20, got %dx%d", w, h)
+		}
+	})
+
+	t.Run("reported", func(t *testing.T) {
+		p := NewProgram(nil)
+		p.cellQuery = &cellSizeQuery{}
+		p.handleCellSizeResponse(cellPixelsMsg{width: 9, height: 18})
+		if msg := nextMsg(t, p); msg != (CellSizeMsg{Width: 9, Height: 18}) {
+			t.Errorf("expected the reported cell size, got %#v", msg)
+		}
+		if !p.cellQuery.done {
+			t.Errorf("expected the query to be done")
+		}
+	})
+
+	t.Run("unanswered", func(t *testing.T) {
+		p := NewProgram(nil)
+		p.size = WindowSizeMsg{Width: 80, Height: 24, WidthPixels: 640, HeightPixels: 384}
+		p.cellQuery = &cellSizeQuery{}
+		p.handleCellSizeResponse(primaryDeviceAttributesMsg{62})
+		if msg := nextMsg(t, p); msg != (CellSizeMsg{Width: 8, Height: 16}) {
+			t.Errorf("expected the cell size to be worked out from the window, got %#v", msg)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		// Where the terminal can't be queried, the size is reported right
+		// away.
+		p := NewProgram(nil)
+		p.queryCellSize()
+		if msg := nextMsg(t, p); msg != (CellSizeMsg{}) {
+			t.Errorf("expected an unknown cell size, got %#v", msg)
+		}
+	})
+}
@@ -0,0 +1,284 @@
+package tea
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CellModel is a model which draws its view into a grid of cells rather than
+// returning it as a string. For large full-window views, such as those of
+// editors and spreadsheets, this saves building a string for the whole view
+// on every frame: the grid is kept from one frame to the next, and only the
+// rows with cells that changed are turned into text again.
+//
+// Once the size of the window is known, CellView is called instead of View,
+// with a grid as large as the window. Until then, as when the program's
+// output isn't a terminal, View is called as usual. Layers, see
+// LayeredModel, are drawn over the grid's contents.
+type CellModel interface {
+	Model
+
+	// CellView draws the view into the grid. The grid holds what was drawn
+	// in the last frame, so only what changed needs to be drawn again;
+	// clear it first to start over. It's called wherever View would be,
+	// which with WithPipelinedRendering is a goroutine of its own.
+	CellView(grid *CellGrid)
+}
+
+// CellStyle is the style of a cell of a CellGrid. Colors are hex colors such
+// as "#f38ba8", or the indices of colors of the terminal's palette, such as
+// "1" for red; colors which can't be parsed, and empty ones, are the
+// terminal's defaults. Colors the terminal doesn't support are downgraded.
+type CellStyle struct {
+	Fg, Bg string
+
+	Bold          bool
+	Faint         bool
+	Italic        bool
+	Underline     bool
+	Blink         bool
+	Reverse       bool
+	Strikethrough bool
+}
+
+// Cell is a cell of a CellGrid: a character and the style it's shown in. A
+// zero Rune is shown as a space.
+type Cell struct {
+	Rune  rune
+	Style CellStyle
+}
+
+// CellGrid is a grid of cells which a CellModel draws its view into.
+// Coordinates are zero-based, from the top left of the grid; cells outside
+// of it are ignored.
+//
+// A wide character, such as 世, covers the cell to its right, too, whose
+// contents aren't shown.
+type CellGrid struct {
+	width, height int
+	cells         []Cell
+
+	// the rows of the grid as text, the cells they were made from, and
+	// whether the cells of each row may have changed since
+	lines []string
+	drawn []Cell
+	dirty []bool
+}
+
+// Width returns the width of the grid.
+func (g *CellGrid) Width() int {
+	return g.width
+}
+
+// Height returns the height of the grid.
+func (g *CellGrid) Height() int {
+	return g.height
+}
+
+// Cell returns the cell at x, y. Cells outside of the grid are empty.
+func (g *CellGrid) Cell(x, y int) Cell {
+	if !g.contains(x, y) {
+		return Cell{}
+	}
+	return g.cells[y*g.width+x]
+}
+
+// SetCell sets the cell at x, y.
+func (g *CellGrid) SetCell(x, y int, c Cell) {
+	if !g.contains(x, y) {
+		return
+	}
+	i := y*g.width + x
+	if g.cells[i] != c {
+		g.cells[i] = c
+		g.dirty[y] = true
+	}
+}
+
+// SetString sets the cells from x, y on to the characters of s, in the given
+// style, and returns the number of cells it covers. The string is cut off at
+// the right edge of the grid; it doesn't wrap.
+func (g *CellGrid) SetString(x, y int, s string, style CellStyle) int {
+	cond := widthCondition()
+	start := x
+	for _, r := range s {
+		w := cond.RuneWidth(r)
+		if w == 0 {
+			continue
+		}
+		if x+w > g.width {
+			break
+		}
+		g.SetCell(x, y, Cell{Rune: r, Style: style})
+		for i := 1; i < w; i++ {
+			g.SetCell(x+i, y, Cell{Style: style})
+		}
+		x += w
+	}
+	return x - start
+}
+
+// Fill sets the cells within the rectangle to c.
+func (g *CellGrid) Fill(r Rect, c Cell) {
+	for y := r.Y; y < r.Y+r.Height; y++ {
+		for x := r.X; x < r.X+r.Width; x++ {
+			g.SetCell(x, y, c)
+		}
+	}
+}
+
+// Clear empties the grid.
+func (g *CellGrid) Clear() {
+	g.Fill(Rect{Width: g.width, Height: g.height}, Cell{})
+}
+
+// contains reports whether the cell at x, y is within the grid.
+func (g *CellGrid) contains(x, y int) bool {
+	return x >= 0 && x < g.width && y >= 0 && y < g.height
+}
+
+// resize changes the size of the grid, keeping the cells which are within
+// both the old and the new size.
+func (g *CellGrid) resize(width, height int) {
+	if width == g.width && height == g.height {
+		return
+	}
+	cells := make([]Cell, width*height)
+	for y := 0; y < height && y < g.height; y++ {
+		for x := 0; x < width && x < g.width; x++ {
+			cells[y*width+x] = g.cells[y*g.width+x]
+		}
+	}
+	g.width, g.height, g.cells = width, height, cells
+
+	// Every row is made into text again.
+	g.lines = make([]string, height)
+	g.drawn = make([]Cell, width*height)
+	g.dirty = make([]bool, height)
+	for y := range g.dirty {
+		g.dirty[y] = true
+	}
+}
+
+// String returns the grid as text, as a view would be. Only rows whose cells
+// differ from when they were last made into text are made into text again.
+func (g *CellGrid) String() string {
+	for y := 0; y < g.height; y++ {
+		if !g.dirty[y] {
+			continue
+		}
+		g.dirty[y] = false
+
+		row := g.cells[y*g.width : (y+1)*g.width]
+		drawn := g.drawn[y*g.width : (y+1)*g.width]
+		if g.lines[y] != "" && cellsEqual(row, drawn) {
+			continue
+		}
+		copy(drawn, row)
+		g.lines[y] = cellsLine(row)
+	}
+	return strings.Join(g.lines, "\n")
+}
+
+// cellsEqual reports whether two rows of cells are the same.
+func cellsEqual(a, b []Cell) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// cellsLine returns a row of cells as a line of text, with SGR sequences
+// setting the style wherever it changes.
+func cellsLine(row []Cell) string {
+	var b strings.Builder
+	b.Grow(len(row))
+
+	cond := widthCondition()
+	var style CellStyle
+	for x := 0; x < len(row); x++ {
+		c := row[x]
+		if c.Style != style {
+			b.WriteString(cellStyleSeq(c.Style))
+			style = c.Style
+		}
+		w := cond.RuneWidth(c.Rune)
+		if c.Rune == 0 || w == 0 || x+w > len(row) {
+			// Wide characters which don't fit are left out, too.
+			b.WriteByte(' ')
+			continue
+		}
+		b.WriteRune(c.Rune)
+
+		// Skip the cells a wide character covers.
+		x += w - 1
+	}
+	if style != (CellStyle{}) {
+		b.WriteString("\x1b[m")
+	}
+	return b.String()
+}
+
+// cellStyleSeq returns the SGR sequence that sets a style, starting from the
+// default one.
+func cellStyleSeq(s CellStyle) string {
+	params := []string{"0"}
+	for _, a := range []struct {
+		on    bool
+		param string
+	}{
+		{s.Bold, "1"},
+		{s.Faint, "2"},
+		{s.Italic, "3"},
+		{s.Underline, "4"},
+		{s.Blink, "5"},
+		{s.Reverse, "7"},
+		{s.Strikethrough, "9"},
+	} {
+		if a.on {
+			params = append(params, a.param)
+		}
+	}
+	if fg := cellColorParams(s.Fg); fg != "" {
+		params = append(params, "38;"+fg)
+	}
+	if bg := cellColorParams(s.Bg); bg != "" {
+		params = append(params, "48;"+bg)
+	}
+	return "\x1b[" + strings.Join(params, ";") + "m"
+}
+
+// cellColorParams returns the parameters of an extended SGR color for a hex
+// color or the index of a palette color, without the leading 38 or 48. It
+// returns an empty string if the color can't be parsed.
+func cellColorParams(color string) string {
+	if strings.HasPrefix(color, "#") {
+		if len(color) != 7 { //nolint:gomnd
+			return ""
+		}
+		n, err := strconv.ParseUint(color[1:], 16, 32) //nolint:gomnd
+		if err != nil {
+			return ""
+		}
+		return "2;" + strconv.Itoa(int(n>>16&0xff)) + ";" + strconv.Itoa(int(n>>8&0xff)) + ";" + strconv.Itoa(int(n&0xff)) //nolint:gomnd
+	}
+	if index, err := strconv.Atoi(color); err == nil && inPalette(index) {
+		return "5;" + strconv.Itoa(index)
+	}
+	return ""
+}
+
+// cellView returns the view of a CellModel drawn into the grid, resized to
+// the given size first. It reports false if the model isn't a CellModel, or
+// the size isn't known.
+func cellView(model Model, grid *CellGrid, width, height int) (string, bool) {
+	m, ok := optionalModel(model).(interface{ CellView(*CellGrid) })
+	if !ok || grid == nil || width <= 0 || height <= 0 {
+		return "", false
+	}
+	grid.resize(width, height)
+	m.CellView(grid)
+	return grid.String(), true
+}
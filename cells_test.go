@@ -0,0 +1,123 @@
+package tea
+
+import "testing"
+
+func TestCellGrid(t *testing.T) {
+	tests := []struct {
+		name     string
+		draw     func(g *CellGrid)
+		expected string
+	}{
+		{
+			name:     "empty",
+			draw:     func(g *CellGrid) {},
+			expected: "    \n    ",
+		},
+		{
+			name: "string",
+			draw: func(g *CellGrid) {
+				g.SetString(1, 1, "hello", CellStyle{})
+			},
+			expected: "    \n hel",
+		},
+		{
+			name: "wide characters",
+			draw: func(g *CellGrid) {
+				g.SetString(0, 0, "世界", CellStyle{})
+				g.SetString(1, 1, "a世界", CellStyle{})
+			},
+			expected: "世界\n a世",
+		},
+		{
+			name: "styles",
+			draw: func(g *CellGrid) {
+				g.SetString(0, 0, "ab", CellStyle{Bold: true, Fg: "#ff0000"})
+				g.SetCell(2, 0, Cell{Rune: 'c', Style: CellStyle{Bg: "4", Italic: true}})
+			},
+			expected: "\x1b[0;1;38;2;255;0;0mab\x1b[0;3;48;5;4mc\x1b[0m \n    ",
+		},
+		{
+			name: "invalid colors",
+			draw: func(g *CellGrid) {
+				g.SetString(0, 0, "a", CellStyle{Fg: "red", Bg: "256"})
+			},
+			expected: "\x1b[0ma\x1b[0m   \n    ",
+		},
+		{
+			name: "outside",
+			draw: func(g *CellGrid) {
+				g.SetCell(-1, 0, Cell{Rune: 'x'})
+				g.SetCell(4, 0, Cell{Rune: 'x'})
+				g.Fill(Rect{X: 3, Y: 1, Width: 5, Height: 5}, Cell{Rune: 'y'})
+			},
+			expected: "    \n   y",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var g CellGrid
+			g.resize(4, 2)
+			test.draw(&g)
+			if got := g.String(); got != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestCellGridRows(t *testing.T) {
+	var g CellGrid
+	g.resize(3, 2)
+	g.SetString(0, 0, "abc", CellStyle{})
+	g.SetString(0, 1, "def", CellStyle{})
+	_ = g.String()
+
+	// Rows whose cells end up as they were aren't made into text again.
+	g.lines[1] = "cached"
+	g.Clear()
+	g.SetString(0, 0, "xyz", CellStyle{})
+	g.SetString(0, 1, "def", CellStyle{})
+	if got := g.String(); got != "xyz\ncached" {
+		t.Errorf("expected only the first row to be made into text again, got %q", got)
+	}
+
+	// Resizing keeps the cells within both sizes.
+	g.resize(2, 3)
+	if got := g.String(); got != "xy\nde\n  " {
+		t.Errorf("expected the grid to be resized, got %q", got)
+	}
+}
+
+type cellModel struct {
+	layeredModel
+	text string
+}
+
+func (m cellModel) CellView(g *CellGrid) {
+	g.Clear()
+	g.SetString(0, 0, m.text, CellStyle{})
+}
+
+func TestCellView(t *testing.T) {
+	m := cellModel{
+		layeredModel: layeredModel{
+			view:   "view",
+			layers: []Layer{{Rect: Rect{X: 1, Y: 1, Width: 1, Height: 1}, Content: "X"}},
+		},
+		text: "cells",
+	}
+
+	// Until the size of the window is known, View is called.
+	var s layerState
+	var g CellGrid
+	if got := renderView(m, &s, &g, nil, true); got != "view\n X  " {
+		t.Errorf("expected the view, got %q", got)
+	}
+
+	// Then the grid is drawn into instead, and layers are drawn over it.
+	s.setSize(4, 2)
+	if got := renderView(m, &s, &g, nil, true); got != "cell\n X  " {
+		t.Errorf("expected the grid, got %q", got)
+	}
+}
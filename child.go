@@ -0,0 +1,71 @@
+package tea
+
+// ChildFinishedMsg is sent to the model when a child program run with
+// RunChild finishes, once the terminal has been handed back to the program.
+type ChildFinishedMsg struct {
+	// Model is the child's final model, as returned by Program.Run.
+	Model Model
+
+	// Err is the error the child program exited with, if any, such as
+	// ErrProgramKilled, or the error restoring the terminal afterwards.
+	Err error
+}
+
+// runChildMsg is an internal message used to run a child program. You can
+// send a runChildMsg with RunChild.
+type runChildMsg struct {
+	model Model
+	opts  []ProgramOption
+}
+
+// RunChild is a command that runs a child program with the given model on the
+// same terminal, such as a wizard or a full-screen picker, pausing the
+// program until it finishes. The program's event loop and renderer are
+// stopped and the terminal is handed over to the child, as with
+// ReleaseTerminal; once the child exits, the terminal is set up again, the
+// view repainted, and the child's final model is sent to the model in a
+// ChildFinishedMsg:
+//
+//	case tea.ChildFinishedMsg:
+//	    if picker, ok := msg.Model.(pickerModel); ok {
+//	        m.choice = picker.choice
+//	    }
+//
+// The child reads and writes the program's input and output, and is killed
+// along with the program. Other options, such as WithAltScreen, can be given
+// for the child. Messages sent to the program with Program.Send while the
+// child runs wait until it has finished.
+func RunChild(model Model, opts ...ProgramOption) Cmd {
+	return func() Msg {
+		return runChildMsg{model: model, opts: opts}
+	}
+}
+
+// runChild runs a child program, and delivers its final model to the program
+// as a ChildFinishedMsg.
+func (p *Program) runChild(msg runChildMsg) {
+	// The timers keep running, as the child's Tick and Every use them, too.
+	if err := p.releaseTerminal(false); err != nil {
+		go p.Send(ChildFinishedMsg{Model: msg.model, Err: err})
+		return
+	}
+
+	opts := []ProgramOption{
+		WithContext(p.ctx),
+		WithInput(p.input),
+		WithOutput(p.output),
+	}
+	if p.outputTee != nil {
+		opts = append(opts, WithOutputTee(p.outputTee))
+	}
+	child := NewProgram(msg.model, append(opts, msg.opts...)...)
+	model, err := child.Run()
+	if model == nil {
+		model = msg.model
+	}
+
+	if rerr := p.RestoreTerminal(); err == nil {
+		err = rerr
+	}
+	go p.Send(ChildFinishedMsg{Model: model, Err: err})
+}
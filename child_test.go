@@ -0,0 +1,129 @@
+package tea
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+type childModel struct {
+	choice string
+}
+
+func (m childModel) Init() Cmd {
+	return func() Msg { return "pick" }
+}
+
+func (m childModel) Update(msg Msg) (Model, Cmd) {
+	if msg == "pick" {
+		m.choice = "picked"
+		return m, Quit
+	}
+	return m, nil
+}
+
+func (m childModel) View() string {
+	return "child"
+}
+
+type parentModel struct {
+	finished *ChildFinishedMsg
+}
+
+func (m *parentModel) Init() Cmd {
+	return RunChild(childModel{})
+}
+
+func (m *parentModel) Update(msg Msg) (Model, Cmd) {
+	if msg, ok := msg.(ChildFinishedMsg); ok {
+		m.finished = &msg
+		return m, Quit
+	}
+	return m, nil
+}
+
+func (m *parentModel) View() string {
+	return "parent"
+}
+
+func TestRunChild(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+
+	m := &parentModel{}
+	p := NewProgram(m, WithInput(&in), WithOutput(&buf))
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if m.finished == nil {
+		t.Fatal("expected the child's final model to be delivered")
+	}
+	if m.finished.Err != nil {
+		t.Errorf("expected no error, got %v", m.finished.Err)
+	}
+	if child, ok := m.finished.Model.(childModel); !ok || child.choice != "picked" {
+		t.Errorf("expected the child's final model, got %#v", m.finished.Model)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("child")) {
+		t.Errorf("expected the child to be rendered, got %q", buf.String())
+	}
+}
+
+type tickingChildModel struct{}
+
+func (m tickingChildModel) Init() Cmd {
+	return Tick(time.Millisecond, func(time.Time) Msg { return "tick" })
+}
+
+func (m tickingChildModel) Update(msg Msg) (Model, Cmd) {
+	if msg == "tick" {
+		return m, Quit
+	}
+	return m, nil
+}
+
+func (m tickingChildModel) View() string {
+	return "child"
+}
+
+type tickingParentModel struct {
+	finished bool
+}
+
+func (m *tickingParentModel) Init() Cmd {
+	return RunChild(tickingChildModel{})
+}
+
+func (m *tickingParentModel) Update(msg Msg) (Model, Cmd) {
+	if _, ok := msg.(ChildFinishedMsg); ok {
+		m.finished = true
+		return m, Quit
+	}
+	return m, nil
+}
+
+func (m *tickingParentModel) View() string {
+	return "parent"
+}
+
+func TestRunChildTimers(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	m := &tickingParentModel{}
+	p := NewProgram(m, WithContext(ctx), WithInput(&in), WithOutput(&buf))
+	if _, err := p.Run(); err != nil {
+		t.Fatalf("expected the child's timer to fire, got %v", err)
+	}
+	if !m.finished {
+		t.Error("expected the child to finish")
+	}
+	if paused, _ := timers.state(); paused {
+		t.Error("expected timers to be running after the child finished")
+	}
+}
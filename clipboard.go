@@ -0,0 +1,208 @@
+package tea
+
+import (
+	"encoding/base64"
+	"errors"
+	"time"
+)
+
+// ErrClipboardUnsupported is reported when the terminal is known not to
+// support the clipboard.
+var ErrClipboardUnsupported = errors.New("terminal does not support the clipboard")
+
+// ErrClipboardTimeout is reported when the terminal hasn't answered a query for
+// the content of the clipboard in time, as terminals which don't allow
+// programs to read the clipboard don't.
+var ErrClipboardTimeout = errors.New("terminal did not report the clipboard's content")
+
+// ErrClipboardTooLarge is reported when the clipboard content exceeds what
+// the terminal accepts and can't be split into smaller writes.
+var ErrClipboardTooLarge = errors.New("clipboard content exceeds the terminal's limit")
+
+// ClipboardResultMsg is sent after the clipboard has been set with
+// SetClipboard. Written is the number of bytes of the content that were sent
+// to the terminal; if Err is non-nil and Written is greater than zero the
+// clipboard may contain partial content.
+//
+// Note that terminals don't acknowledge clipboard writes, so a nil error
+// means the content was sent, not necessarily that it was accepted.
+type ClipboardResultMsg struct {
+	Written int
+	Err     error
+}
+
+// setClipboardMsg is an internal message used to set the clipboard. You can
+// send a setClipboardMsg with SetClipboard.
+type setClipboardMsg string
+
+// SetClipboard is a command that sets the system clipboard to the given
+// string via the OSC 52 escape sequence. Because the sequence is interpreted
+// by the terminal it works over SSH, too.
+//
+// Many terminals limit the size of OSC 52 payloads. Where a terminal is known
+// to append consecutive writes, large content is split into several writes.
+// Otherwise content that exceeds the limit is not sent. Either way, the
+// outcome is reported with a ClipboardResultMsg.
+func SetClipboard(s string) Cmd {
+	return func() Msg {
+		return setClipboardMsg(s)
+	}
+}
+
+// osc52 returns the sequence that sets the clipboard to the given
+// base64-encoded data.
+func osc52(data string) string {
+	return "\x1b]52;c;" + data + "\a"
+}
+
+// clipboardChunks splits content into OSC 52 sequences according to the
+// given quirks. The returned sizes are the number of bytes of the original
+// content carried by each sequence.
+func clipboardChunks(content string, q terminalQuirks) ([]string, []int, error) {
+	if q.clipboardLimit < 0 {
+		return nil, nil, ErrClipboardUnsupported
+	}
+
+	encodedLen := base64.StdEncoding.EncodedLen(len(content))
+	if q.clipboardLimit == 0 || encodedLen <= q.clipboardLimit {
+		return []string{osc52(base64.StdEncoding.EncodeToString([]byte(content)))},
+			[]int{len(content)}, nil
+	}
+	if !q.clipboardChunks {
+		return nil, nil, ErrClipboardTooLarge
+	}
+
+	// Every 3 bytes of input encode to 4 bytes of base64. Chunk on that
+	// boundary so that each chunk is valid, unpadded base64 on its own.
+	chunkSize := q.clipboardLimit / 4 * 3 //nolint:gomnd
+	if chunkSize == 0 {
+		return nil, nil, ErrClipboardTooLarge
+	}
+
+	var (
+		seqs  []string
+		sizes []int
+	)
+	for i := 0; i < len(content); i += chunkSize {
+		end := i + chunkSize
+		if end > len(content) {
+			end = len(content)
+		}
+		chunk := content[i:end]
+		seqs = append(seqs, osc52(base64.StdEncoding.EncodeToString([]byte(chunk))))
+		sizes = append(sizes, len(chunk))
+	}
+	return seqs, sizes, nil
+}
+
+// setClipboard writes the given content to the clipboard and reports the
+// result.
+func (p *Program) setClipboard(content string) ClipboardResultMsg {
+	if !p.supports(FeatureClipboard) {
+		return ClipboardResultMsg{Err: ErrClipboardUnsupported}
+	}
+
+	seqs, sizes, err := clipboardChunks(content, quirksFor(p.terminal))
+	if err != nil {
+		return ClipboardResultMsg{Err: err}
+	}
+
+	var written int
+	for i, seq := range seqs {
+		if err := p.renderer.execute(seq); err != nil {
+			return ClipboardResultMsg{Written: written, Err: err}
+		}
+		written += sizes[i]
+	}
+	return ClipboardResultMsg{Written: written}
+}
+
+// clipboardReadTimeout is how long we wait for the terminal to report the
+// content of the clipboard. It's longer than for other queries, as some
+// terminals ask the user for permission first.
+const clipboardReadTimeout = 10 * time.Second
+
+// queryClipboard is the OSC 52 query for the content of the clipboard.
+const queryClipboard = "\x1b]52;c;?\a"
+
+// ClipboardMsg reports the content of the clipboard, in response to
+// ReadClipboard. If it couldn't be read, Err is non-nil.
+type ClipboardMsg struct {
+	Content string
+	Err     error
+}
+
+// readClipboardMsg is an internal message used to read the clipboard. You can
+// send a readClipboardMsg with ReadClipboard.
+type readClipboardMsg struct{}
+
+// ReadClipboard is a command that reads the system clipboard via the OSC 52
+// escape sequence. Like SetClipboard it works over SSH, too. The content is
+// reported with a ClipboardMsg.
+//
+// Many terminals don't allow programs to read the clipboard, or only after
+// asking the user for permission. If the terminal doesn't report the content
+// within 10 seconds, the ClipboardMsg carries ErrClipboardTimeout. Where the
+// terminal is known not to support OSC 52 at all, or the program's input and
+// output aren't terminals, it carries ErrClipboardUnsupported right away.
+func ReadClipboard() Msg {
+	return readClipboardMsg{}
+}
+
+// clipboardContentMsg is the terminal's response to a query for the content
+// of the clipboard.
+type clipboardContentMsg string
+
+// clipboardReadTimeoutMsg is sent when the terminal hasn't reported the
+// content of the clipboard in time. read tells the reads apart, so that a
+// timeout doesn't end a later read.
+type clipboardReadTimeoutMsg struct {
+	read int
+}
+
+// readClipboard asks the terminal for the content of the clipboard. A read
+// that's already waiting on the terminal answers for both.
+func (p *Program) readClipboard() {
+	if p.clipboardWaiting {
+		return
+	}
+	if !p.supports(FeatureClipboard) || quirksFor(p.terminal).clipboardLimit < 0 || !p.canQuery() {
+		go p.Send(ClipboardMsg{Err: ErrClipboardUnsupported})
+		return
+	}
+	if err := p.renderer.execute(queryClipboard); err != nil {
+		go p.Send(ClipboardMsg{Err: err})
+		return
+	}
+
+	p.clipboardReads++
+	p.clipboardWaiting = true
+	read := p.clipboardReads
+	go func() {
+		select {
+		case <-p.ctx.Done():
+		case <-time.After(clipboardReadTimeout):
+			p.Send(clipboardReadTimeoutMsg{read: read})
+		}
+	}()
+}
+
+// handleClipboardResponse reports the content of the clipboard, or that the
+// terminal didn't report it, to the program. Responses nothing is waiting
+// for are dropped.
+func (p *Program) handleClipboardResponse(msg Msg) {
+	if !p.clipboardWaiting {
+		return
+	}
+
+	switch msg := msg.(type) {
+	case clipboardContentMsg:
+		p.clipboardWaiting = false
+		go p.Send(ClipboardMsg{Content: string(msg)})
+	case clipboardReadTimeoutMsg:
+		if msg.read == p.clipboardReads {
+			p.clipboardWaiting = false
+			go p.Send(ClipboardMsg{Err: ErrClipboardTimeout})
+		}
+	}
+}
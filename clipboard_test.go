@@ -0,0 +1,187 @@
+package tea
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClipboardChunks(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		quirks terminalQuirks
+		seqs   []string
+		sizes  []int
+		err    error
+	}{
+		{
+			name:   "unlimited",
+			input:  "hello",
+			quirks: terminalQuirks{},
+			seqs:   []string{"\x1b]52;c;aGVsbG8=\a"},
+			sizes:  []int{5},
+		},
+		{
+			name:   "within limit",
+			input:  "hello",
+			quirks: terminalQuirks{clipboardLimit: 8},
+			seqs:   []string{"\x1b]52;c;aGVsbG8=\a"},
+			sizes:  []int{5},
+		},
+		{
+			name:   "unsupported",
+			input:  "hello",
+			quirks: terminalQuirks{clipboardLimit: -1},
+			err:    ErrClipboardUnsupported,
+		},
+		{
+			name:   "too large",
+			input:  "hello",
+			quirks: terminalQuirks{clipboardLimit: 4},
+			err:    ErrClipboardTooLarge,
+		},
+		{
+			name:   "chunked",
+			input:  "hello",
+			quirks: terminalQuirks{clipboardLimit: 4, clipboardChunks: true},
+			seqs:   []string{"\x1b]52;c;aGVs\a", "\x1b]52;c;bG8=\a"},
+			sizes:  []int{3, 2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			seqs, sizes, err := clipboardChunks(test.input, test.quirks)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected error %v, got %v", test.err, err)
+			}
+			if strings.Join(seqs, "|") != strings.Join(test.seqs, "|") {
+				t.Errorf("expected sequences %q, got %q", test.seqs, seqs)
+			}
+			if len(sizes) != len(test.sizes) {
+				t.Fatalf("expected sizes %v, got %v", test.sizes, sizes)
+			}
+			for i := range sizes {
+				if sizes[i] != test.sizes[i] {
+					t.Errorf("expected sizes %v, got %v", test.sizes, sizes)
+				}
+			}
+		})
+	}
+}
+
+type clipboardModel struct {
+	result ClipboardResultMsg
+}
+
+func (m *clipboardModel) Init() Cmd {
+	return SetClipboard("hello")
+}
+
+func (m *clipboardModel) Update(msg Msg) (Model, Cmd) {
+	if msg, ok := msg.(ClipboardResultMsg); ok {
+		m.result = msg
+		return m, Quit
+	}
+	return m, nil
+}
+
+func (m *clipboardModel) View() string {
+	return ""
+}
+
+func TestSetClipboard(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+
+	m := &clipboardModel{}
+	p := NewProgram(m, WithInput(&in), WithOutput(&buf))
+	p.terminal = termXterm
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if m.result.Err != nil || m.result.Written != 5 {
+		t.Errorf("expected 5 bytes to be written, got %+v", m.result)
+	}
+	if !strings.Contains(buf.String(), "\x1b]52;c;aGVsbG8=\a") {
+		t.Errorf("expected output to contain clipboard sequence, got %q", buf.String())
+	}
+}
+
+func TestClipboardResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		waiting  bool
+		response Msg
+		expected Msg
+	}{
+		{"content", true, clipboardContentMsg("hello"), ClipboardMsg{Content: "hello"}},
+		{"timeout", true, clipboardReadTimeoutMsg{read: 1}, ClipboardMsg{Err: ErrClipboardTimeout}},
+		{"timeout of an earlier read", true, clipboardReadTimeoutMsg{read: 0}, nil},
+		{"content nothing waits for", false, clipboardContentMsg("hello"), nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := NewProgram(nil, WithOutput(&bytes.Buffer{}))
+			p.clipboardReads, p.clipboardWaiting = 1, test.waiting
+			p.handleClipboardResponse(test.response)
+
+			var msg Msg
+			select {
+			case msg = <-p.msgs:
+			case <-time.After(10 * time.Millisecond):
+			}
+			if msg != test.expected {
+				t.Errorf("expected %#v, got %#v", test.expected, msg)
+			}
+			if test.expected != nil && p.clipboardWaiting {
+				t.Error("expected the read to be over")
+			}
+		})
+	}
+}
+
+type readClipboardModel struct {
+	result *ClipboardMsg
+}
+
+func (m readClipboardModel) Init() Cmd {
+	return ReadClipboard
+}
+
+func (m readClipboardModel) Update(msg Msg) (Model, Cmd) {
+	if msg, ok := msg.(ClipboardMsg); ok {
+		*m.result = msg
+		return m, Quit
+	}
+	return m, nil
+}
+
+func (m readClipboardModel) View() string {
+	return ""
+}
+
+func TestReadClipboardUnsupported(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+
+	m := readClipboardModel{result: &ClipboardMsg{}}
+	p := NewProgram(m, WithInput(&in), WithOutput(&buf))
+	p.terminal = termXterm
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Non-terminals can't be queried.
+	if !errors.Is(m.result.Err, ErrClipboardUnsupported) {
+		t.Errorf("expected ErrClipboardUnsupported, got %v", m.result.Err)
+	}
+	if strings.Contains(buf.String(), queryClipboard) {
+		t.Errorf("expected no query to be sent, got %q", buf.String())
+	}
+}
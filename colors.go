@@ -0,0 +1,155 @@
+package tea
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea/input"
+	isatty "github.com/mattn/go-isatty"
+	"github.com/muesli/termenv"
+)
+
+// deviceAttrANSIColor is the device attribute of terminals which support
+// ANSI colors.
+const deviceAttrANSIColor = 22
+
+// colorsAllowed reports whether the color profile detected from the
+// environment may be refined with more colors: the output must be a terminal,
+// and the user mustn't have asked for no colors.
+func (p *Program) colorsAllowed() bool {
+	out, ok := p.output.TTY().(*os.File)
+	if !ok || !isatty.IsTerminal(out.Fd()) {
+		return false
+	}
+	return os.Getenv("NO_COLOR") == "" && os.Getenv("CLICOLOR") != "0"
+}
+
+// terminfoProfile refines a color profile detected from the environment with
+// the number of colors listed in the terminal's terminfo entry. It only ever
+// adds colors: terminfo entries commonly understate what terminals support.
+func terminfoProfile(profile termenv.Profile, term string) termenv.Profile {
+	if profile <= termenv.ANSI256 || term == "" {
+		return profile
+	}
+	colors, err := input.TerminfoColors(term)
+	switch {
+	case err != nil:
+	case colors >= 256: //nolint:gomnd
+		return termenv.ANSI256
+	case colors >= 8: //nolint:gomnd
+		return termenv.ANSI
+	}
+	return profile
+}
+
+// downgradeColors converts the colors of the SGR sequences in s to those the
+// given profile supports: direct colors become palette colors, palette colors
+// become one of the 16 ANSI colors, and without colors, colors are dropped.
+// Other attributes are left alone.
+func downgradeColors(s string, profile termenv.Profile) string {
+	if profile == termenv.TrueColor {
+		return s
+	}
+	return mapSGR(s, func(params string) string {
+		return downgradeSGRParams(params, profile)
+	})
+}
+
+// downgradeSGRParams converts the colors among the parameters of an SGR
+// sequence to those the given profile supports.
+func downgradeSGRParams(params string, profile termenv.Profile) string {
+	in := strings.Split(params, ";")
+	out := make([]string, 0, len(in))
+
+	for i := 0; i < len(in); i++ {
+		p := in[i]
+		code, _ := strconv.Atoi(p)
+		switch {
+		case p == "38" || p == "48" || p == "58" ||
+			strings.HasPrefix(p, "38:") || strings.HasPrefix(p, "48:") || strings.HasPrefix(p, "58:"):
+			var args []string
+			if strings.Contains(p, ":") {
+				args = strings.Split(p, ":")
+				if len(args) == 6 && args[1] == "2" { //nolint:gomnd
+					// The colon form of direct colors has a color space
+					// id before the components.
+					args = append(args[:2], args[3:]...)
+				}
+			} else {
+				end := i + 1
+				if end < len(in) {
+					switch in[end] {
+					case "5":
+						end += 2
+					case "2":
+						end += 4
+					}
+				}
+				if end > len(in) {
+					end = len(in)
+				}
+				args = in[i:end]
+				i = end - 1
+			}
+			if c := downgradeColor(args, profile); c != "" {
+				out = append(out, c)
+			}
+
+		case profile == termenv.Ascii && (code >= 30 && code <= 37 || code >= 40 && code <= 47 ||
+			code >= 90 && code <= 97 || code >= 100 && code <= 107):
+
+		default:
+			out = append(out, p)
+		}
+	}
+
+	return strings.Join(out, ";")
+}
+
+// downgradeColor converts an extended color, given as its SGR parameters
+// such as 38;2;r;g;b, to the given profile. The result is empty if the color
+// is to be dropped.
+func downgradeColor(args []string, profile termenv.Profile) string {
+	if profile == termenv.Ascii || len(args) < 3 { //nolint:gomnd
+		return ""
+	}
+
+	var c termenv.Color
+	switch {
+	case args[1] == "5":
+		n, err := strconv.Atoi(args[2])
+		if err != nil {
+			return ""
+		}
+		c = termenv.ANSI256Color(n)
+	case args[1] == "2" && len(args) >= 5: //nolint:gomnd
+		var rgb [3]int
+		for j := range rgb {
+			n, err := strconv.Atoi(args[2+j])
+			if err != nil {
+				return ""
+			}
+			rgb[j] = n
+		}
+		c = termenv.RGBColor(fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2]))
+	default:
+		return ""
+	}
+
+	bg := args[0] == "48"
+	switch c := profile.Convert(c).(type) {
+	case termenv.ANSIColor:
+		if args[0] == "58" {
+			// There's no short form for underline colors.
+			return fmt.Sprintf("58;5;%d", int(c))
+		}
+		return c.Sequence(bg)
+	case termenv.ANSI256Color:
+		return fmt.Sprintf("%s;5;%d", args[0], int(c))
+	case termenv.RGBColor:
+		return strings.Join(args, ";")
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+package tea
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/muesli/termenv"
+)
+
+func TestDowngradeColors(t *testing.T) {
+	tests := []struct {
+		name     string
+		profile  termenv.Profile
+		in       string
+		expected string
+	}{
+		{"truecolor", termenv.TrueColor, "\x1b[38;2;255;0;0mred", "\x1b[38;2;255;0;0mred"},
+		{"direct to 256", termenv.ANSI256, "\x1b[1;38;2;255;0;0mred", "\x1b[1;38;5;196mred"},
+		{"direct colon form", termenv.ANSI256, "\x1b[48:2::0:0:255mblue", "\x1b[48;5;21mblue"},
+		{"palette kept", termenv.ANSI256, "\x1b[38;5;100mx", "\x1b[38;5;100mx"},
+		{"direct to 16", termenv.ANSI, "\x1b[38;2;255;0;0;48;2;0;0;0mx", "\x1b[91;40mx"},
+		{"palette to 16", termenv.ANSI, "\x1b[38;5;2mx", "\x1b[32mx"},
+		{"underline color", termenv.ANSI, "\x1b[4;58;2;255;0;0mx", "\x1b[4;58;5;9mx"},
+		{"ascii", termenv.Ascii, "\x1b[1;31;48;5;100mx\x1b[0m", "\x1b[1mx\x1b[0m"},
+		{"ascii colors only", termenv.Ascii, "\x1b[38;2;1;2;3mx", "x"},
+		{"no sequences", termenv.Ascii, "plain", "plain"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := downgradeColors(test.in, test.profile); got != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestRendererColorProfile(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRenderer(termenv.NewOutput(&buf), false).(*standardRenderer)
+	r.colorProfile = termenv.ANSI
+
+	r.write("\x1b[38;2;255;0;0mred\x1b[0m")
+	r.flush()
+	if out := buf.String(); !strings.Contains(out, "\x1b[91mred") {
+		t.Errorf("expected the color to be downgraded, got %q", out)
+	}
+
+	// Changing the profile repaints the view with the new colors.
+	buf.Reset()
+	r.setColorProfile(termenv.TrueColor)
+	r.write("\x1b[38;2;255;0;0mred\x1b[0m")
+	r.flush()
+	if out := buf.String(); !strings.Contains(out, "\x1b[38;2;255;0;0mred") {
+		t.Errorf("expected the view to be repainted in truecolor, got %q", out)
+	}
+}
+
+func TestCapabilityQueryColorProfile(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewProgram(nil, WithOutput(&buf), WithColorProfile(termenv.ANSI))
+	if p.output.Profile != termenv.ANSI {
+		t.Fatalf("expected the forced profile, got %v", p.output.Profile)
+	}
+	r := newRenderer(p.output, false).(*standardRenderer)
+	p.renderer = r
+	p.capQuery = &capabilityQuery{}
+
+	// A forced profile isn't upgraded, whatever the terminal reports.
+	p.handleCapabilityResponse(termcapMsg{name: "RGB", ok: true})
+	p.handleCapabilityResponse(primaryDeviceAttributesMsg{62, deviceAttrANSIColor})
+
+	if p.capQuery.caps.ColorProfile != termenv.ANSI || r.colorProfile != termenv.ANSI {
+		t.Errorf("expected the ANSI profile, got %v (renderer %v)", p.capQuery.caps.ColorProfile, r.colorProfile)
+	}
+}
@@ -88,13 +88,18 @@ type sequenceMsg []Cmd
 //	    return m, nil
 //	}
 //
+// The timer pauses while the program has released the terminal, for example
+// to run another process with ExecProcess. Once the program is back, the
+// timer waits for the next tick of the system clock again.
+//
 // Every is analogous to Tick in the Elm Architecture.
 func Every(duration time.Duration, fn func(time.Time) Msg) Cmd {
-	return func() Msg {
+	next := func() time.Duration {
 		n := time.Now()
-		d := n.Truncate(duration).Add(duration).Sub(n)
-		t := time.NewTimer(d)
-		return fn(<-t.C)
+		return n.Truncate(duration).Add(duration).Sub(n)
+	}
+	return func() Msg {
+		return fn(timers.sleep(next(), next))
 	}
 }
 
@@ -136,10 +141,14 @@ func Every(duration time.Duration, fn func(time.Time) Msg) Cmd {
 //	    }
 //	    return m, nil
 //	}
+//
+// The timer pauses while the program has released the terminal, for example
+// to run another process with ExecProcess, and picks up where it left off
+// once the program is back. Animations driven by Tick therefore resume where
+// they were rather than jumping ahead.
 func Tick(d time.Duration, fn func(time.Time) Msg) Cmd {
 	return func() Msg {
-		t := time.NewTimer(d)
-		return fn(<-t.C)
+		return fn(timers.sleep(d, nil))
 	}
 }
 
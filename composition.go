@@ -0,0 +1,31 @@
+package tea
+
+// CompositionMsg reports the text an input method editor (IME) is composing,
+// known as preedit text, before it's committed. Text inputs can draw it at
+// the cursor, underlined, in place of the text they'd otherwise show there,
+// until the composition ends:
+//
+//	case tea.CompositionMsg:
+//	    m.preedit = msg
+//	case tea.KeyMsg:
+//	    // The committed text arrives as a KeyMsg.
+//
+// Terminals compose input method text themselves, drawing the text being
+// composed on their own, and only send the text once it's committed. None of
+// the protocols terminals speak, including the kitty keyboard protocol,
+// report the composition in progress, so CompositionMsg isn't read from the
+// terminal. Programs which run their own input method, such as ones hosting a
+// program in a GUI or web terminal, can send it with Program.Send.
+type CompositionMsg struct {
+	// Text is the text being composed. It's empty once the composition has
+	// ended, whether the text was committed or cancelled.
+	Text string
+
+	// Cursor is the position of the cursor in Text, in runes.
+	Cursor int
+}
+
+// Composing reports whether text is being composed.
+func (m CompositionMsg) Composing() bool {
+	return m.Text != ""
+}
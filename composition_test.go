@@ -0,0 +1,50 @@
+package tea
+
+import (
+	"bytes"
+	"testing"
+)
+
+type compositionModel struct {
+	preedit []CompositionMsg
+}
+
+func (m *compositionModel) Init() Cmd { return nil }
+
+func (m *compositionModel) Update(msg Msg) (Model, Cmd) {
+	if msg, ok := msg.(CompositionMsg); ok {
+		m.preedit = append(m.preedit, msg)
+		if !msg.Composing() {
+			return m, Quit
+		}
+	}
+	return m, nil
+}
+
+func (m *compositionModel) View() string { return "" }
+
+func TestCompositionMsg(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+
+	m := &compositionModel{}
+	p := NewProgram(m, WithInput(&in), WithOutput(&buf))
+	go func() {
+		p.Send(CompositionMsg{Text: "ni", Cursor: 2})
+		p.Send(CompositionMsg{Text: "你", Cursor: 1})
+		p.Send(CompositionMsg{})
+	}()
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []CompositionMsg{{Text: "ni", Cursor: 2}, {Text: "你", Cursor: 1}, {}}
+	if len(m.preedit) != len(expected) {
+		t.Fatalf("expected %d compositions, got %v", len(expected), m.preedit)
+	}
+	for i, msg := range m.preedit {
+		if msg != expected[i] {
+			t.Errorf("expected composition %d to be %+v, got %+v", i, expected[i], msg)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package tea
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// Rect is a rectangular region of the screen. X and Y are zero-based
+// coordinates of its top left corner.
+type Rect struct {
+	X, Y          int
+	Width, Height int
+}
+
+// Contains reports whether the cell at x, y lies within the rectangle.
+func (r Rect) Contains(x, y int) bool {
+	return x >= r.X && x < r.X+r.Width && y >= r.Y && y < r.Y+r.Height
+}
+
+// Layer is a piece of content placed in a region of the screen by Compose.
+type Layer struct {
+	Rect    Rect
+	Content string
+
+	// Name identifies the layer. Layers drawn over a model's view, see
+	// LayeredModel, are hit-tested by name.
+	Name string
+
+	// Z orders overlapping layers: layers with a higher Z cover those with a
+	// lower one.
+	Z int
+}
+
+// Compose combines layers into a single view of the given size. Layers are
+// painted in order of Z and, where Z is equal, in the order they're given,
+// so later layers cover earlier ones where they overlap. Each layer's
+// content is truncated and padded to its region, and regions are clipped to
+// the view. Styles don't bleed from one layer into another.
+func Compose(width, height int, layers ...Layer) string {
+	if width <= 0 || height <= 0 {
+		return ""
+	}
+
+	layers = sortLayers(layers)
+
+	canvas := make([]string, height)
+	for i := range canvas {
+		canvas[i] = strings.Repeat(" ", width)
+	}
+
+	for _, l := range layers {
+		x0, x1 := l.Rect.X, l.Rect.X+l.Rect.Width
+		if x0 < 0 {
+			x0 = 0
+		}
+		if x1 > width {
+			x1 = width
+		}
+		if x0 >= x1 {
+			continue
+		}
+
+		lines := strings.Split(l.Content, "\n")
+		for row := 0; row < l.Rect.Height; row++ {
+			y := l.Rect.Y + row
+			if y < 0 || y >= height {
+				continue
+			}
+			var line string
+			if row < len(lines) {
+				line = lines[row]
+			}
+			line = PadRight(Truncate(line, l.Rect.Width, ""), l.Rect.Width)
+			line = cutCells(line, x0-l.Rect.X, x1-l.Rect.X)
+			canvas[y] = splice(canvas[y], x0, x1, line)
+		}
+	}
+
+	return strings.Join(canvas, "\n")
+}
+
+// sortLayers returns a copy of layers sorted by Z, keeping the order of
+// layers with equal Z.
+func sortLayers(layers []Layer) []Layer {
+	sorted := make([]Layer, len(layers))
+	copy(sorted, layers)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Z < sorted[j].Z
+	})
+	return sorted
+}
+
+// splice replaces the cells between x0 and x1 of line with seg, which must be
+// exactly x1-x0 cells wide.
+func splice(line string, x0, x1 int, seg string) string {
+	left := cutCells(line, 0, x0)
+	right := cutCells(line, x1, math.MaxInt)
+
+	// Reset styles and close hyperlinks at the boundaries so they don't
+	// bleed across. The right part replays the original line's escape
+	// sequences, which restores its style and hyperlink.
+	return endSegment(left) + endSegment(seg) + right
+}
+
+// endSegment resets the style at the end of a segment of a line, and closes
+// the hyperlink left open, if any.
+func endSegment(seg string) string {
+	if !strings.Contains(seg, "\x1b") {
+		return seg
+	}
+	if openHyperlink(seg) != "" {
+		seg += hyperlinkEnd
+	}
+	return seg + "\x1b[m"
+}
+
+// cutCells returns the cells of s between the columns start (inclusive) and
+// end (exclusive). Escape sequences before end are kept, including those
+// preceding start, so that the result has the same style as the original.
+// Wide characters which straddle either boundary are replaced with spaces.
+func cutCells(s string, start, end int) string {
+	var b strings.Builder
+	var col int
+	forEachCluster(s, func(seq string, w int) bool {
+		if col >= end {
+			return false
+		}
+		switch {
+		case seq[0] == '\x1b':
+			b.WriteString(seq)
+		case col >= start && col+w <= end:
+			b.WriteString(seq)
+		case col+w > start:
+			// A wide character cut in half.
+			from, to := col, col+w
+			if from < start {
+				from = start
+			}
+			if to > end {
+				to = end
+			}
+			b.WriteString(strings.Repeat(" ", to-from))
+		}
+		col += w
+		return true
+	})
+	return b.String()
+}
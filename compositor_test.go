@@ -0,0 +1,111 @@
+package tea
+
+import "testing"
+
+func TestCompose(t *testing.T) {
+	tests := []struct {
+		name     string
+		width    int
+		height   int
+		layers   []Layer
+		expected string
+	}{
+		{
+			name:     "empty",
+			width:    3,
+			height:   2,
+			expected: "   \n   ",
+		},
+		{
+			name:   "tiled",
+			width:  6,
+			height: 2,
+			layers: []Layer{
+				{Rect: Rect{X: 0, Y: 0, Width: 3, Height: 2}, Content: "ab\ncdef"},
+				{Rect: Rect{X: 3, Y: 0, Width: 3, Height: 2}, Content: "xyz"},
+			},
+			expected: "ab xyz\ncde   ",
+		},
+		{
+			name:   "overlay",
+			width:  5,
+			height: 3,
+			layers: []Layer{
+				{Rect: Rect{Width: 5, Height: 3}, Content: "aaaaa\nbbbbb\nccccc"},
+				{Rect: Rect{X: 1, Y: 1, Width: 2, Height: 1}, Content: "XY"},
+			},
+			expected: "aaaaa\nbXYbb\nccccc",
+		},
+		{
+			name:   "z order",
+			width:  3,
+			height: 1,
+			layers: []Layer{
+				{Rect: Rect{Width: 2, Height: 1}, Content: "ab", Z: 1},
+				{Rect: Rect{X: 1, Width: 2, Height: 1}, Content: "XY"},
+			},
+			expected: "abY",
+		},
+		{
+			name:   "clipped",
+			width:  3,
+			height: 2,
+			layers: []Layer{
+				{Rect: Rect{X: -1, Y: 1, Width: 5, Height: 2}, Content: "abcde\nfghij"},
+			},
+			expected: "   \nbcd",
+		},
+		{
+			name:   "wide character cut",
+			width:  4,
+			height: 1,
+			layers: []Layer{
+				{Rect: Rect{Width: 4, Height: 1}, Content: "你好"},
+				{Rect: Rect{X: 1, Width: 2, Height: 1}, Content: "ab"},
+			},
+			expected: " ab ",
+		},
+		{
+			name:   "styles",
+			width:  4,
+			height: 1,
+			layers: []Layer{
+				{Rect: Rect{Width: 4, Height: 1}, Content: "\x1b[31mabcd\x1b[m"},
+				{Rect: Rect{X: 1, Width: 2, Height: 1}, Content: "XY"},
+			},
+			expected: "\x1b[31ma\x1b[mXY\x1b[31md\x1b[m",
+		},
+		{
+			name:   "hyperlinks",
+			width:  4,
+			height: 1,
+			layers: []Layer{
+				{Rect: Rect{Width: 4, Height: 1}, Content: Hyperlink("x", "abcd")},
+				{Rect: Rect{X: 1, Width: 2, Height: 1}, Content: "XY"},
+			},
+			expected: "\x1b]8;;x\x1b\\a\x1b]8;;\x1b\\\x1b[mXY\x1b]8;;x\x1b\\d\x1b]8;;\x1b\\\x1b[m",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Compose(test.width, test.height, test.layers...); got != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestRectContains(t *testing.T) {
+	r := Rect{X: 2, Y: 1, Width: 3, Height: 2}
+	for _, c := range []struct {
+		x, y int
+		in   bool
+	}{
+		{2, 1, true}, {4, 2, true}, {1, 1, false}, {5, 1, false}, {2, 3, false},
+	} {
+		if got := r.Contains(c.x, c.y); got != c.in {
+			t.Errorf("Contains(%d, %d): expected %t, got %t", c.x, c.y, c.in, got)
+		}
+	}
+}
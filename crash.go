@@ -0,0 +1,160 @@
+package tea
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// ErrProgramPanic is returned by [Program.Run] when the program recovered from
+// a panic, in Update, View or a command.
+var ErrProgramPanic = errors.New("program experienced a panic")
+
+// maxCrashMsgLen is the length beyond which messages are truncated in crash
+// reports.
+const maxCrashMsgLen = 256
+
+// recordedMsg is a message recorded for crash reports.
+type recordedMsg struct {
+	time time.Time
+	msg  Msg
+}
+
+// crashRecorder keeps track of the most recent messages processed by the
+// program, for use in crash reports. It's only accessed from the event loop's
+// goroutine.
+type crashRecorder struct {
+	size int
+	msgs []recordedMsg
+}
+
+// record records a message, dropping the oldest one if necessary.
+func (c *crashRecorder) record(msg Msg) {
+	if c.size <= 0 {
+		return
+	}
+	if len(c.msgs) >= c.size {
+		copy(c.msgs, c.msgs[1:])
+		c.msgs = c.msgs[:len(c.msgs)-1]
+	}
+	c.msgs = append(c.msgs, recordedMsg{time: time.Now(), msg: msg})
+}
+
+// goPanic is a panic recovered in a goroutine running commands. It's handed to
+// the event loop, which stops the program as if it had panicked itself.
+type goPanic struct {
+	value interface{}
+	stack []byte
+}
+
+func (g goPanic) Error() string {
+	return fmt.Sprintf("panic: %v", g.value)
+}
+
+// recoverGoPanic recovers a panic in a goroutine running commands, unless
+// catching panics is disabled, and hands it to the event loop. It must be
+// deferred.
+func (p *Program) recoverGoPanic() {
+	if p.startupOptions.has(withoutCatchPanics) {
+		return
+	}
+	r := recover()
+	if r == nil {
+		return
+	}
+	select {
+	case p.errs <- goPanic{value: r, stack: debug.Stack()}:
+	case <-p.ctx.Done():
+	}
+}
+
+// printPanic prints a recovered panic, along with what's to be printed about
+// the crash report, if any. The terminal must have been restored.
+func printPanic(r interface{}, stack []byte, report string) {
+	fmt.Printf("Caught panic:\n\n%s\n\nRestoring terminal...\n\n", r)
+	_, _ = os.Stderr.Write(stack)
+	if report != "" {
+		fmt.Printf("\n%s", report)
+	}
+}
+
+// saveCrashReport writes a crash report and returns what's to be printed about
+// it once the terminal has been restored: its path or, if it couldn't be
+// written, the report itself.
+func (p *Program) saveCrashReport(reason string) string {
+	path, err := p.writeCrashReport(reason)
+	if err == nil {
+		return fmt.Sprintf("Crash report written to %s\n", path)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Couldn't write crash report: %v\n\n", err)
+	p.crashReport(&b, reason)
+	return b.String()
+}
+
+// writeCrashReport writes a crash report to a temporary file and returns its
+// path. The report contains the reason for the crash, the terminal's
+// capabilities, the last frame rendered, the most recent messages, and the
+// stacks of all goroutines.
+func (p *Program) writeCrashReport(reason string) (string, error) {
+	f, err := os.CreateTemp("", "bubbletea-crash-*.log")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close() //nolint:errcheck
+
+	p.crashReport(f, reason)
+	return f.Name(), nil
+}
+
+// crashReport writes a crash report to w.
+func (p *Program) crashReport(w io.Writer, reason string) {
+	fmt.Fprintf(w, "Bubble Tea crash report\n\n")
+	fmt.Fprintf(w, "Time:   %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(w, "Reason: %s\n", reason)
+	fmt.Fprintf(w, "Go:     %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+
+	caps := envCapabilities(p.terminal, os.Getenv)
+	caps.Tier = p.tier
+	if p.capQuery != nil {
+		caps = p.capQuery.caps
+	}
+	fmt.Fprintf(w, "\n== Capabilities ==\n\n%+v\n", caps)
+
+	if r, ok := p.renderer.(*standardRenderer); ok {
+		r.mtx.Lock()
+		frame := r.lastRender
+		r.mtx.Unlock()
+		fmt.Fprintf(w, "\n== Last frame ==\n\n%s\n", frame)
+	}
+
+	if p.crashRecorder != nil {
+		fmt.Fprintf(w, "\n== Last %d messages, oldest first ==\n\n", len(p.crashRecorder.msgs))
+		for _, m := range p.crashRecorder.msgs {
+			s := fmt.Sprintf("%#v", m.msg)
+			if len(s) > maxCrashMsgLen {
+				s = s[:maxCrashMsgLen] + "…"
+			}
+			fmt.Fprintf(w, "%s %T %s\n", m.time.Format("15:04:05.000"), m.msg, s)
+		}
+	}
+
+	fmt.Fprintf(w, "\n== Goroutines ==\n\n%s\n", allStacks())
+}
+
+// allStacks returns the stacks of all goroutines.
+func allStacks() string {
+	buf := make([]byte, 1<<16) //nolint:gomnd
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return strings.TrimSpace(string(buf[:n]))
+		}
+		buf = make([]byte, 2*len(buf)) //nolint:gomnd
+	}
+}
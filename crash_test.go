@@ -0,0 +1,134 @@
+package tea
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/muesli/termenv"
+)
+
+func TestCrashRecorder(t *testing.T) {
+	c := &crashRecorder{size: 2}
+	c.record(KeyMsg{Type: KeyEnter})
+	c.record("second")
+	c.record("third")
+
+	if len(c.msgs) != 2 || c.msgs[0].msg != "second" || c.msgs[1].msg != "third" {
+		t.Errorf("expected the two most recent messages, got %+v", c.msgs)
+	}
+
+	// A zero size disables recording.
+	c = &crashRecorder{}
+	c.record("first")
+	if len(c.msgs) != 0 {
+		t.Errorf("expected no messages to be recorded, got %+v", c.msgs)
+	}
+}
+
+func TestCrashReport(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewProgram(nil, WithOutput(&buf), WithCrashReports(10))
+	r := newRenderer(termenv.NewOutput(&buf), false).(*standardRenderer)
+	r.lastRender = "last frame"
+	p.renderer = r
+	p.terminal = termKitty
+	p.crashRecorder.record(KeyMsg{Type: KeyEnter})
+
+	path, err := p.writeCrashReport("panic: oops")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(path) //nolint:errcheck
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := string(b)
+
+	for _, s := range []string{
+		"Reason: panic: oops",
+		"Terminal:kitty",
+		"== Last frame ==\n\nlast frame\n",
+		"== Last 1 messages, oldest first ==",
+		"tea.KeyMsg",
+		"== Goroutines ==",
+		"TestCrashReport",
+	} {
+		if !strings.Contains(report, s) {
+			t.Errorf("expected the report to contain %q:\n%s", s, report)
+		}
+	}
+}
+
+type panicModel struct {
+	cmd Cmd
+}
+
+func (m panicModel) Init() Cmd {
+	return m.cmd
+}
+
+func (m panicModel) Update(msg Msg) (Model, Cmd) {
+	if msg == "update" {
+		panic("oops")
+	}
+	return m, nil
+}
+
+func (m panicModel) View() string {
+	return "view"
+}
+
+func TestTeaPanic(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  Cmd
+	}{
+		{
+			name: "update",
+			cmd:  func() Msg { return "update" },
+		},
+		{
+			name: "command",
+			cmd:  func() Msg { panic("oops") },
+		},
+		{
+			name: "batched command",
+			cmd:  Batch(func() Msg { panic("oops") }, nil),
+		},
+		{
+			name: "sequenced command",
+			cmd:  Sequence(func() Msg { panic("oops") }, nil),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			var in bytes.Buffer
+
+			p := NewProgram(panicModel{cmd: test.cmd}, WithInput(&in), WithOutput(&buf))
+			if _, err := p.Run(); err != ErrProgramPanic {
+				t.Fatalf("expected ErrProgramPanic, got %v", err)
+			}
+			if out := buf.String(); !strings.Contains(out, "\x1b[?25h") {
+				t.Errorf("expected the terminal to be restored, got %q", out)
+			}
+		})
+	}
+}
+
+func TestSaveCrashReport(t *testing.T) {
+	p := NewProgram(nil, WithCrashReports(10))
+	p.crashRecorder.record(KeyMsg{Type: KeyEnter})
+
+	// The report is printed when it can't be written.
+	t.Setenv("TMPDIR", "/nonexistent")
+	out := p.saveCrashReport("panic: oops")
+	if !strings.HasPrefix(out, "Couldn't write crash report") || !strings.Contains(out, "tea.KeyMsg") {
+		t.Errorf("expected the report to be printed, got %q", out)
+	}
+}
@@ -0,0 +1,139 @@
+package tea
+
+import (
+	"strconv"
+
+	"github.com/muesli/termenv"
+)
+
+// CursorShape is the shape of the terminal cursor, as set with SetCursorShape.
+type CursorShape int
+
+// Available cursor shapes. The values correspond to the parameters of the
+// DECSCUSR sequence.
+const (
+	// CursorDefault is the shape configured by the user in their terminal.
+	CursorDefault CursorShape = iota
+	CursorBlinkingBlock
+	CursorBlock
+	CursorBlinkingUnderline
+	CursorUnderline
+	CursorBlinkingBar
+	CursorBar
+)
+
+// setCursorShapeMsg is an internal message used to set the cursor shape. You
+// can send a setCursorShapeMsg with SetCursorShape.
+type setCursorShapeMsg CursorShape
+
+// SetCursorShape produces a command that sets the shape of the cursor, for
+// example to a bar while editing text. The shape is preserved when the
+// terminal is released, for instance with ExecProcess, and restored to the
+// user's default when the program exits.
+func SetCursorShape(shape CursorShape) Cmd {
+	return func() Msg {
+		return setCursorShapeMsg(shape)
+	}
+}
+
+// cursorShapeSeq returns the DECSCUSR sequence for the given shape.
+func cursorShapeSeq(shape CursorShape) string {
+	return "\x1b[" + strconv.Itoa(int(shape)) + " q"
+}
+
+// cursorState is the state of the cursor that we save when releasing the
+// terminal and restore when taking it back.
+type cursorState struct {
+	hidden bool
+	shape  CursorShape
+
+	// where the model had the cursor placed in the last frame, see
+	// CursorModel
+	placement cursorPlacement
+}
+
+// CursorModel is a model that has the terminal's real cursor shown in its
+// view, such as at the caret of a text input, instead of drawing a fake one.
+// Screen readers follow the real cursor, and terminals draw it the way the
+// user has configured it, blinking and all. Set its shape with
+// SetCursorShape.
+//
+// The cursor is only placed by the standard renderer. It's shown on top of
+// the hidden cursor, see HideCursor, and hidden again when the model no
+// longer wants it shown.
+type CursorModel interface {
+	Model
+
+	// Cursor returns the cell the cursor is to be shown at, relative to
+	// the top left of the view, and whether it's to be shown at all. It's
+	// called after each call to View. Cells outside the part of the view
+	// that's on the screen don't show the cursor.
+	Cursor() (x, y int, visible bool)
+}
+
+// cursorPlacement is where a CursorModel wants the cursor.
+type cursorPlacement struct {
+	x, y    int
+	visible bool
+}
+
+// cursorOf returns where the model wants the cursor.
+func cursorOf(model Model) cursorPlacement {
+	if m, ok := optionalModel(model).(interface{ Cursor() (int, int, bool) }); ok {
+		x, y, visible := m.Cursor()
+		return cursorPlacement{x: x, y: y, visible: visible}
+	}
+	return cursorPlacement{}
+}
+
+// placeCursor moves the cursor from the start of the last line rendered to
+// where the model wants it, and shows it, if that's within the frame.
+func (r *standardRenderer) placeCursor(out *termenv.Output) {
+	c := r.cursorWanted
+	row := c.y - r.cursorOffset
+	if !c.visible || c.x < 0 || row < 0 || row >= r.linesRendered {
+		return
+	}
+	if r.width > 0 && c.x >= r.width {
+		c.x = r.width - 1
+	}
+
+	up := r.linesRendered - 1 - row
+	if up > 0 {
+		out.CursorUp(up)
+	}
+	if c.x > 0 {
+		out.CursorForward(c.x)
+	}
+	if r.cursorHidden {
+		out.ShowCursor()
+	}
+	r.cursorPlaced = true
+	r.cursorUp = up
+}
+
+// unplaceCursor moves a placed cursor back to the start of the last line
+// rendered, where the rest of the renderer expects it, hiding it again.
+func (r *standardRenderer) unplaceCursor(out *termenv.Output) {
+	if !r.cursorPlaced {
+		return
+	}
+	if r.cursorHidden {
+		out.HideCursor()
+	}
+	if r.cursorUp > 0 {
+		out.CursorDown(r.cursorUp)
+	}
+	_, _ = out.WriteString("\r")
+	r.cursorPlaced = false
+}
+
+// parkCursor moves a placed cursor back to the start of the last line
+// rendered for writes other than frames, and has the next frame place it
+// again.
+func (r *standardRenderer) parkCursor(out *termenv.Output) {
+	if r.cursorPlaced {
+		r.unplaceCursor(out)
+		r.cursorMoved = true
+	}
+}
@@ -0,0 +1,105 @@
+package tea
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/muesli/termenv"
+)
+
+func TestRendererCursor(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRenderer(termenv.NewOutput(&buf), false).(*standardRenderer)
+	r.width, r.height = 10, 10
+	r.cursorHidden = true
+
+	// The cursor is placed after the frame, counting from the last line.
+	r.writeWithCursor("a\nbcd\ne", cursorPlacement{x: 2, y: 1, visible: true})
+	r.flush()
+	if out := buf.String(); !strings.HasSuffix(out, "\x1b[1A\x1b[2C\x1b[?25h") {
+		t.Errorf("expected the cursor to be placed and shown, got %q", out)
+	}
+
+	// Moving the cursor alone doesn't repaint the view.
+	buf.Reset()
+	r.writeWithCursor("a\nbcd\ne", cursorPlacement{x: 3, y: 0, visible: true})
+	r.flush()
+	expected := "\x1b[?25l\x1b[1B\r\x1b[2A\x1b[3C\x1b[?25h"
+	if out := buf.String(); out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+
+	// Once the cursor isn't wanted, it's hidden and left where the renderer
+	// expects it.
+	buf.Reset()
+	r.writeWithCursor("a\nbcd\nf", cursorPlacement{})
+	r.flush()
+	out := buf.String()
+	if !strings.HasPrefix(out, "\x1b[?25l\x1b[2B\r") || strings.Contains(out, "\x1b[?25h") {
+		t.Errorf("expected the cursor to be hidden and returned, got %q", out)
+	}
+	if r.cursorPlaced {
+		t.Error("expected the cursor not to be placed")
+	}
+
+	// Cursors outside the frame aren't shown.
+	buf.Reset()
+	r.writeWithCursor("a\nbcd\nf", cursorPlacement{y: 3, visible: true})
+	r.flush()
+	if out := buf.String(); out != "" {
+		t.Errorf("expected no output, got %q", out)
+	}
+}
+
+type cursorModel struct {
+	x, y int
+}
+
+func (m cursorModel) Init() Cmd                        { return nil }
+func (m cursorModel) Update(msg Msg) (Model, Cmd)      { return m, nil }
+func (m cursorModel) View() string                     { return "> input" }
+func (m cursorModel) Cursor() (x, y int, visible bool) { return m.x, m.y, true }
+
+func TestCursorOf(t *testing.T) {
+	if c := cursorOf(cursorModel{x: 7}); c != (cursorPlacement{x: 7, visible: true}) {
+		t.Errorf("unexpected placement %+v", c)
+	}
+	if c := cursorOf(&testModel{}); c.visible {
+		t.Errorf("expected no cursor for models without one, got %+v", c)
+	}
+}
+
+func TestRestoreTerminalCursor(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewProgram(nil, WithInput(&bytes.Buffer{}), WithOutput(&buf))
+	r := newRenderer(p.output, false).(*standardRenderer)
+	p.renderer = r
+	r.width, r.height = 10, 10
+	r.start()
+	if err := p.initCancelReader(); err != nil {
+		t.Fatal(err)
+	}
+	r.hideCursor()
+	r.setCursorShape(CursorBar)
+	r.writeWithCursor("a\nbcd\ne", cursorPlacement{x: 2, y: 1, visible: true})
+	r.flush()
+
+	if err := p.ReleaseTerminal(); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := p.RestoreTerminal(); err != nil {
+		t.Fatal(err)
+	}
+	r.stop()
+
+	// The last frame is drawn again with the cursor placed in it, without
+	// waiting for the model's view.
+	out := buf.String()
+	for _, seq := range []string{"\x1b[6 q", "a\r\nbcd\r\ne", "\x1b[1A\x1b[2C\x1b[?25h"} {
+		if !strings.Contains(out, seq) {
+			t.Errorf("expected %q to be written, got %q", seq, out)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package tea
+
+import (
+	"sync"
+
+	"github.com/muesli/termenv"
+)
+
+// Renderer renders a program's views. Bubble Tea's own renderer writes them
+// to the terminal at a fixed framerate, repainting only the lines that have
+// changed. A custom renderer, set with WithRenderer, could instead record
+// views for tests, send them to a remote client, or draw them onto a grid of
+// cells.
+//
+// Methods are called from the program's event loop, one at a time, except
+// for Execute, which may also be called while the program starts up and
+// shuts down. They shouldn't block for long, as the program doesn't process
+// messages in the meantime.
+type Renderer interface {
+	// Start is called when the program starts, before the first view is
+	// written, and again when the program takes back the terminal after
+	// releasing it.
+	Start()
+
+	// Stop is called when the program exits or releases the terminal. The
+	// last view written should be rendered, if it hasn't been already.
+	Stop()
+
+	// Kill is called when the program is killed. Nothing more should be
+	// rendered.
+	Kill()
+
+	// Write is called with the program's view after every message. The
+	// renderer decides when to render it, and may skip views which are
+	// replaced before it gets to them.
+	Write(view string)
+
+	// Repaint asks for the next view to be rendered in full, rather than
+	// only where it changed, as something else has drawn over it. It may be
+	// called several times before the next render.
+	Repaint()
+
+	// ClearScreen clears the screen and repaints the view.
+	ClearScreen()
+
+	// AltScreen reports whether the alternate screen buffer is active. In
+	// the altscreen views take up the whole window; otherwise they're drawn
+	// inline, below what's already on the screen.
+	AltScreen() bool
+
+	// EnterAltScreen switches to the alternate screen buffer, clears it,
+	// and repaints the view. It's only called when the altscreen isn't
+	// active.
+	EnterAltScreen()
+
+	// ExitAltScreen switches back to the main screen buffer and repaints the
+	// view. It's only called when the altscreen is active.
+	ExitAltScreen()
+
+	// Execute writes an escape sequence to the terminal immediately,
+	// bypassing the view. Bubble Tea uses it for everything which doesn't
+	// change the layout of the screen: the cursor, mouse tracking,
+	// bracketed paste, keyboard modes, titles and the clipboard, as well as
+	// querying the terminal's capabilities. Renderers which don't draw to a
+	// terminal can ignore the sequences.
+	Execute(seq string) error
+
+	// HandleMessage is called with every message before it's passed to the
+	// model's Update function, such as WindowSizeMsg, so that the renderer
+	// can keep track of the window.
+	HandleMessage(msg Msg)
+}
+
+// customRenderer adapts a Renderer to the interface the program uses
+// internally. Terminal modes are written with Execute, and their state is
+// kept here.
+type customRenderer struct {
+	r Renderer
+
+	mtx          sync.Mutex
+	cursorHidden bool
+	cursorShape  CursorShape
+	cellMotion   bool
+	allMotion    bool
+	bpActive     bool
+	altScroll    bool
+}
+
+func (c *customRenderer) start()            { c.r.Start() }
+func (c *customRenderer) stop()             { c.r.Stop() }
+func (c *customRenderer) kill()             { c.r.Kill() }
+func (c *customRenderer) write(view string) { c.r.Write(view) }
+func (c *customRenderer) repaint()          { c.r.Repaint() }
+func (c *customRenderer) clearScreen()      { c.r.ClearScreen() }
+func (c *customRenderer) altScreen() bool   { return c.r.AltScreen() }
+func (c *customRenderer) execute(seq string) error {
+	return c.r.Execute(seq)
+}
+
+// resetTerminal resets the terminal and re-applies the modes that have been
+// set.
+func (c *customRenderer) resetTerminal() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	_ = c.r.Execute("\x1b[!p\x1bc")
+	if c.r.AltScreen() {
+		_ = c.r.Execute(termenv.CSI + termenv.AltScreenSeq)
+		if c.altScroll {
+			_ = c.r.Execute(enableAltScroll)
+		}
+	}
+	if c.cursorHidden {
+		_ = c.r.Execute(termenv.CSI + termenv.HideCursorSeq)
+	}
+	if c.cursorShape != CursorDefault {
+		_ = c.r.Execute(cursorShapeSeq(c.cursorShape))
+	}
+	if c.cellMotion {
+		_ = c.r.Execute(termenv.CSI + termenv.EnableMouseCellMotionSeq)
+	}
+	if c.allMotion {
+		_ = c.r.Execute(termenv.CSI + termenv.EnableMouseAllMotionSeq)
+	}
+	if c.bpActive {
+		_ = c.r.Execute(termenv.CSI + termenv.EnableBracketedPasteSeq)
+	}
+	c.r.ClearScreen()
+}
+
+func (c *customRenderer) enterAltScreen() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.r.AltScreen() {
+		return
+	}
+	c.r.EnterAltScreen()
+	if c.altScroll {
+		_ = c.r.Execute(enableAltScroll)
+	}
+}
+
+func (c *customRenderer) exitAltScreen() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if !c.r.AltScreen() {
+		return
+	}
+	if c.altScroll {
+		_ = c.r.Execute(disableAltScroll)
+	}
+	c.r.ExitAltScreen()
+}
+
+func (c *customRenderer) showCursor() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.cursorHidden = false
+	_ = c.r.Execute(termenv.CSI + termenv.ShowCursorSeq)
+}
+
+func (c *customRenderer) hideCursor() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.cursorHidden = true
+	_ = c.r.Execute(termenv.CSI + termenv.HideCursorSeq)
+}
+
+func (c *customRenderer) setCursorShape(shape CursorShape) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.cursorShape = shape
+	_ = c.r.Execute(cursorShapeSeq(shape))
+}
+
+func (c *customRenderer) cursor() cursorState {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	return cursorState{hidden: c.cursorHidden, shape: c.cursorShape}
+}
+
+func (c *customRenderer) enableMouseCellMotion() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.cellMotion = true
+	_ = c.r.Execute(termenv.CSI + termenv.EnableMouseCellMotionSeq)
+}
+
+func (c *customRenderer) disableMouseCellMotion() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.cellMotion = false
+	_ = c.r.Execute(termenv.CSI + termenv.DisableMouseCellMotionSeq)
+}
+
+func (c *customRenderer) enableMouseAllMotion() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.allMotion = true
+	_ = c.r.Execute(termenv.CSI + termenv.EnableMouseAllMotionSeq)
+}
+
+func (c *customRenderer) disableMouseAllMotion() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.allMotion = false
+	_ = c.r.Execute(termenv.CSI + termenv.DisableMouseAllMotionSeq)
+}
+
+func (c *customRenderer) enableBracketedPaste() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.bpActive = true
+	_ = c.r.Execute(termenv.CSI + termenv.EnableBracketedPasteSeq)
+}
+
+func (c *customRenderer) disableBracketedPaste() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.bpActive = false
+	_ = c.r.Execute(termenv.CSI + termenv.DisableBracketedPasteSeq)
+}
+
+func (c *customRenderer) setAlternateScroll(on bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.altScroll == on {
+		return
+	}
+	c.altScroll = on
+	if !c.r.AltScreen() {
+		return
+	}
+	if on {
+		_ = c.r.Execute(enableAltScroll)
+	} else {
+		_ = c.r.Execute(disableAltScroll)
+	}
+}
+
+func (c *customRenderer) bracketedPasteActive() bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	return c.bpActive
+}
@@ -0,0 +1,131 @@
+package tea
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingRenderer records what it's asked to do.
+type recordingRenderer struct {
+	mtx       sync.Mutex
+	calls     []string
+	views     []string
+	seqs      strings.Builder
+	msgs      []Msg
+	altScreen bool
+}
+
+func (r *recordingRenderer) record(call string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+func (r *recordingRenderer) Start()       { r.record("start") }
+func (r *recordingRenderer) Stop()        { r.record("stop") }
+func (r *recordingRenderer) Kill()        { r.record("kill") }
+func (r *recordingRenderer) Repaint()     { r.record("repaint") }
+func (r *recordingRenderer) ClearScreen() { r.record("clear") }
+
+func (r *recordingRenderer) Write(view string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.views = append(r.views, view)
+}
+
+func (r *recordingRenderer) AltScreen() bool {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.altScreen
+}
+
+func (r *recordingRenderer) EnterAltScreen() {
+	r.record("enter")
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.altScreen = true
+}
+
+func (r *recordingRenderer) ExitAltScreen() {
+	r.record("exit")
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.altScreen = false
+}
+
+func (r *recordingRenderer) Execute(seq string) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.seqs.WriteString(seq)
+	return nil
+}
+
+func (r *recordingRenderer) HandleMessage(msg Msg) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.msgs = append(r.msgs, msg)
+}
+
+func TestCustomRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	in := bytes.NewBufferString("q")
+	r := &recordingRenderer{}
+
+	p := NewProgram(&testModel{}, WithInput(in), WithOutput(&buf), WithRenderer(r))
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r.calls) < 2 || r.calls[0] != "start" || r.calls[len(r.calls)-1] != "stop" {
+		t.Errorf("expected the renderer to be started and stopped, got %v", r.calls)
+	}
+	if len(r.views) == 0 || r.views[len(r.views)-1] != "success\n" {
+		t.Errorf("expected the view to be written, got %q", r.views)
+	}
+	var sawKey bool
+	for _, msg := range r.msgs {
+		if _, ok := msg.(KeyMsg); ok {
+			sawKey = true
+		}
+	}
+	if !sawKey {
+		t.Errorf("expected the key to be handed to the renderer, got %v", r.msgs)
+	}
+	if strings.Contains(buf.String(), "success") {
+		t.Errorf("expected nothing to be rendered to the output, got %q", buf.String())
+	}
+}
+
+func TestCustomRendererModes(t *testing.T) {
+	r := &recordingRenderer{}
+	c := &customRenderer{r: r}
+
+	c.setAlternateScroll(true)
+	c.enterAltScreen()
+	c.enterAltScreen()
+	c.hideCursor()
+	c.enableMouseCellMotion()
+	c.enableBracketedPaste()
+	if !c.altScreen() || !c.bracketedPasteActive() || c.cursor() != (cursorState{hidden: true}) {
+		t.Errorf("expected the modes to be tracked, got altscreen %t, bracketed paste %t and cursor %+v",
+			c.altScreen(), c.bracketedPasteActive(), c.cursor())
+	}
+	if got := strings.Join(r.calls, ","); got != "enter" {
+		t.Errorf("expected the altscreen to be entered once, got %v", r.calls)
+	}
+
+	r.seqs.Reset()
+	c.resetTerminal()
+	expected := "\x1b[!p\x1bc\x1b[?1049h" + enableAltScroll + "\x1b[?25l\x1b[?1002h\x1b[?2004h"
+	if got := r.seqs.String(); got != expected {
+		t.Errorf("expected the modes to be re-applied with %q, got %q", expected, got)
+	}
+
+	r.seqs.Reset()
+	c.exitAltScreen()
+	if got := r.seqs.String(); got != disableAltScroll || !strings.HasSuffix(strings.Join(r.calls, ","), "clear,exit") {
+		t.Errorf("expected alternate scroll to be disabled before exiting the altscreen, got %q and %v", got, r.calls)
+	}
+}
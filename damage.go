@@ -0,0 +1,120 @@
+package tea
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// damagedLine is a line that's repainted in part: the cells from col on are
+// rewritten with span, and the rest are left as they are on the screen.
+type damagedLine struct {
+	col  int
+	span string
+}
+
+// damageCell is a cell of a line as far as damage tracking is concerned: a
+// grapheme cluster along with the escape sequences right before it.
+type damageCell struct {
+	text  string
+	width int
+
+	// the length of the line's escape sequences before this cell's, which
+	// together make up the state the cell is painted in
+	state int
+}
+
+// damageCells splits a line into cells, and returns them along with all of
+// the line's escape sequences. Escape sequences at the end of the line make
+// up a cell of their own, with no width.
+func damageCells(s string) ([]damageCell, string) {
+	var cells []damageCell
+	var escapes, pending strings.Builder
+	forEachCluster(s, func(seq string, w int) bool {
+		if seq[0] == '\x1b' {
+			pending.WriteString(seq)
+			return true
+		}
+		cells = append(cells, damageCell{text: pending.String() + seq, width: w, state: escapes.Len()})
+		escapes.WriteString(pending.String())
+		pending.Reset()
+		return true
+	})
+	if pending.Len() > 0 {
+		cells = append(cells, damageCell{text: pending.String(), state: escapes.Len()})
+		escapes.WriteString(pending.String())
+	}
+	return cells, escapes.String()
+}
+
+// lineDamage compares the line on the screen with the line replacing it, both
+// as painted, and works out how to repaint only the cells that differ, which
+// for the likes of a clock in a status bar is a fraction of the line. It
+// reports false if the lines have nothing in common, or if repainting part of
+// the line wouldn't save anything over repainting all of it.
+func lineDamage(old, line string) (damagedLine, bool) {
+	oldCells, oldEscapes := damageCells(old)
+	newCells, newEscapes := damageCells(line)
+
+	n := len(oldCells)
+	if len(newCells) < n {
+		n = len(newCells)
+	}
+
+	// Cells at the start of the lines which are the same, escape sequences
+	// included, are painted the same.
+	var prefix, col int
+	for prefix < n && oldCells[prefix].text == newCells[prefix].text {
+		col += oldCells[prefix].width
+		prefix++
+	}
+
+	// Cells at the end of the lines are painted the same if, on top of that,
+	// they're painted in the same state, and in the same place.
+	var suffix, suffixWidth int
+	for suffix < n-prefix && oldCells[len(oldCells)-1-suffix].text == newCells[len(newCells)-1-suffix].text {
+		suffixWidth += oldCells[len(oldCells)-1-suffix].width
+		suffix++
+	}
+	oldWidth, newWidth := cellsWidth(oldCells), cellsWidth(newCells)
+	if suffix > 0 {
+		o, c := oldCells[len(oldCells)-suffix], newCells[len(newCells)-suffix]
+		if oldWidth != newWidth || oldEscapes[:o.state] != newEscapes[:c.state] {
+			suffix, suffixWidth = 0, 0
+		}
+	}
+
+	if prefix == 0 && suffix == 0 {
+		return damagedLine{}, false
+	}
+
+	// The span carries all the escape sequences before it, so that it's
+	// painted in the right state.
+	end := math.MaxInt
+	if suffix > 0 {
+		end = newWidth - suffixWidth
+	}
+	span := endSegment(cutCells(line, col, end))
+	if suffix == 0 && newWidth < oldWidth {
+		// Erase what's left of the old line.
+		span += "\x1b[K"
+	}
+	// Compare against clearing the line and painting it whole.
+	cost := len(span)
+	if col > 0 {
+		cost += len(fmt.Sprintf("\x1b[%dC", col))
+	}
+	if cost >= len(line)+len("\x1b[2K") {
+		return damagedLine{}, false
+	}
+	return damagedLine{col: col, span: span}, true
+}
+
+// cellsWidth returns the width of a line's cells.
+func cellsWidth(cells []damageCell) int {
+	var w int
+	for _, c := range cells {
+		w += c.width
+	}
+	return w
+}
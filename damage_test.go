@@ -0,0 +1,103 @@
+package tea
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/muesli/termenv"
+)
+
+func TestLineDamage(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      string
+		line     string
+		expected damagedLine
+		ok       bool
+	}{
+		{
+			name:     "middle",
+			old:      "status: 12:00:01 ok",
+			line:     "status: 12:00:02 ok",
+			expected: damagedLine{col: 15, span: "2"},
+			ok:       true,
+		},
+		{
+			name:     "end",
+			old:      "count: 9",
+			line:     "count: 10",
+			expected: damagedLine{col: 7, span: "10"},
+			ok:       true,
+		},
+		{
+			name:     "shrunk",
+			old:      "loading...",
+			line:     "loading",
+			expected: damagedLine{col: 7, span: "\x1b[K"},
+			ok:       true,
+		},
+		{
+			name:     "styled",
+			old:      "\x1b[1mtime\x1b[0m 10:59",
+			line:     "\x1b[1mtime\x1b[0m 11:00",
+			expected: damagedLine{col: 6, span: "\x1b[1m\x1b[0m1:00\x1b[m"},
+			ok:       true,
+		},
+		{
+			name: "restyled suffix",
+			old:  "abcdef\x1b[1mgh",
+			line: "abcdef\x1b[2mgh",
+			// The last cell is the same, but it's painted bold before and
+			// faint after.
+			expected: damagedLine{col: 6, span: "\x1b[2mgh\x1b[m"},
+			ok:       true,
+		},
+		{
+			name: "wide characters",
+			old:  "日本語です",
+			line: "日本人です",
+			// Cells are counted by width.
+			expected: damagedLine{col: 4, span: "人"},
+			ok:       true,
+		},
+		{
+			name: "nothing in common",
+			old:  "abc",
+			line: "xyz",
+		},
+		{
+			name: "no savings",
+			old:  "\x1b[1ma\x1b[0mb",
+			line: "\x1b[1ma\x1b[0mc",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := lineDamage(test.old, test.line)
+			if ok != test.ok || got != test.expected {
+				t.Errorf("expected %+v (%t), got %+v (%t)", test.expected, test.ok, got, ok)
+			}
+		})
+	}
+}
+
+func TestRendererDamage(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRenderer(termenv.NewOutput(&buf), false).(*standardRenderer)
+	r.width, r.height = 40, 10
+
+	r.write("header\nstatus: 12:00:01 ok\nfooter")
+	r.flush()
+
+	buf.Reset()
+	r.write("header\nstatus: 12:00:02 ok\nfooter")
+	r.flush()
+	out := buf.String()
+	if strings.Contains(out, "status") || !strings.Contains(out, "\x1b[15C2") {
+		t.Errorf("expected only the changed cell to be painted, got %q", out)
+	}
+	if n := strings.Count(out, "\x1b[2K"); n != 1 {
+		t.Errorf("expected only the first line to be cleared, got %q", out)
+	}
+}
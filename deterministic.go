@@ -0,0 +1,58 @@
+package tea
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// commandScheduler decides the order in which the results of batched
+// commands are delivered when deterministic command ordering is enabled with
+// WithDeterministicCommands.
+type commandScheduler struct {
+	mtx  sync.Mutex
+	rand *rand.Rand
+}
+
+func newCommandScheduler(seed int64) *commandScheduler {
+	return &commandScheduler{rand: rand.New(rand.NewSource(seed))} //nolint:gosec
+}
+
+// order returns the order in which to deliver the results of n commands.
+func (s *commandScheduler) order(n int) []int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return s.rand.Perm(n)
+}
+
+// deterministicBatch returns a command which runs the given commands
+// concurrently, waits for all of them to finish, and then sends their results
+// to the program in an order determined by the scheduler's seed.
+func (p *Program) deterministicBatch(batch BatchMsg) Cmd {
+	order := p.scheduler.order(len(batch))
+
+	return func() Msg {
+		results := make([]Msg, len(batch))
+
+		var wg sync.WaitGroup
+		for i, cmd := range batch {
+			if cmd == nil {
+				continue
+			}
+			wg.Add(1)
+			go func(i int, cmd Cmd) {
+				defer wg.Done()
+				defer p.recoverGoPanic()
+				results[i] = cmd()
+			}(i, cmd)
+		}
+		wg.Wait()
+
+		for _, i := range order {
+			if results[i] != nil {
+				p.Send(results[i])
+			}
+		}
+		return nil
+	}
+}
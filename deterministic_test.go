@@ -0,0 +1,69 @@
+package tea
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type batchOrderModel struct {
+	n       int
+	results *[]int
+}
+
+func (m batchOrderModel) Init() Cmd {
+	cmds := make([]Cmd, m.n)
+	for i := range cmds {
+		i := i
+		cmds[i] = func() Msg {
+			// Finish in reverse order.
+			time.Sleep(time.Duration(m.n-i) * time.Millisecond)
+			return i
+		}
+	}
+	return Batch(cmds...)
+}
+
+func (m batchOrderModel) Update(msg Msg) (Model, Cmd) {
+	if i, ok := msg.(int); ok {
+		*m.results = append(*m.results, i)
+		if len(*m.results) == m.n {
+			return m, Quit
+		}
+	}
+	return m, nil
+}
+
+func (m batchOrderModel) View() string {
+	return ""
+}
+
+func runBatchOrder(t *testing.T, seed int64) []int {
+	t.Helper()
+
+	var buf bytes.Buffer
+	var in bytes.Buffer
+	var results []int
+
+	m := batchOrderModel{n: 8, results: &results}
+	p := NewProgram(m, WithInput(&in), WithOutput(&buf), WithDeterministicCommands(seed))
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+	return results
+}
+
+func TestDeterministicCommands(t *testing.T) {
+	first := runBatchOrder(t, 42)
+	second := runBatchOrder(t, 42)
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("expected the same order for the same seed, got %v and %v", first, second)
+	}
+
+	// The Init batch is the first one to be scheduled.
+	expected := newCommandScheduler(42).order(8)
+	if !reflect.DeepEqual(first, expected) {
+		t.Errorf("expected order %v, got %v", expected, first)
+	}
+}
@@ -0,0 +1,36 @@
+package tea
+
+// ErrorModel is a model whose updates can fail. The program calls UpdateErr
+// in place of Update, and when it returns an error, the program stops: the
+// final view is rendered, the terminal is restored, and Program.Run returns
+// the model along with the error. That spares programs from keeping fatal
+// errors in the model and quitting, only to look for them once Run returns.
+//
+//	func (m model) UpdateErr(msg tea.Msg) (tea.Model, tea.Cmd, error) {
+//	    switch msg := msg.(type) {
+//	    case configLoadedMsg:
+//	        if msg.err != nil {
+//	            return m, nil, fmt.Errorf("loading config: %w", msg.err)
+//	        }
+//	    }
+//	    return m, nil, nil
+//	}
+//
+// The command returned along with an error isn't run. Update still has to be
+// implemented to satisfy Model, but it's not called by the program.
+type ErrorModel interface {
+	Model
+
+	// UpdateErr is called in place of Update.
+	UpdateErr(Msg) (Model, Cmd, error)
+}
+
+// update has the model handle a message, with UpdateErr if it's an
+// ErrorModel.
+func update(model Model, msg Msg) (Model, Cmd, error) {
+	if m, ok := model.(ErrorModel); ok {
+		return m.UpdateErr(msg)
+	}
+	model, cmd := model.Update(msg)
+	return model, cmd, nil
+}
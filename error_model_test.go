@@ -0,0 +1,51 @@
+package tea
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var errUpdate = errors.New("update failed")
+
+type errorModel struct {
+	updates int
+}
+
+func (m errorModel) Init() Cmd {
+	return func() Msg { return "fail" }
+}
+
+func (m errorModel) Update(msg Msg) (Model, Cmd) {
+	panic("Update is not to be called on an ErrorModel")
+}
+
+func (m errorModel) UpdateErr(msg Msg) (Model, Cmd, error) {
+	m.updates++
+	if msg == "fail" {
+		return m, Quit, errUpdate
+	}
+	return m, nil, nil
+}
+
+func (m errorModel) View() string {
+	return "view"
+}
+
+func TestErrorModel(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+
+	p := NewProgram(errorModel{}, WithInput(&in), WithOutput(&buf))
+	m, err := p.Run()
+	if !errors.Is(err, errUpdate) {
+		t.Fatalf("expected the update's error, got %v", err)
+	}
+	if m, ok := m.(errorModel); !ok || m.updates == 0 {
+		t.Errorf("expected the model as updated, got %#v", m)
+	}
+	if out := buf.String(); !strings.Contains(out, "\x1b[?25h") {
+		t.Errorf("expected the terminal to be restored, got %q", out)
+	}
+}
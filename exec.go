@@ -1,6 +1,7 @@
 package tea
 
 import (
+	"errors"
 	"io"
 	"os"
 	"os/exec"
@@ -46,6 +47,13 @@ func Exec(c ExecCommand, fn ExecCallback) Cmd {
 //
 //	cmd := ExecProcess(exec.Command("vim", "file.txt"), nil)
 //
+// The process's exit status can be had from the error with ExitCode.
+//
+// While the command runs, the terminal is handed over to it, as with
+// Program.ReleaseTerminal: the altscreen, mouse reporting and the other modes
+// the program set are turned off, and the view is erased when running
+// inline. Once it exits, they're set up again and the view is repainted.
+//
 // For non-interactive i/o you should use a Cmd (that is, a tea.Cmd).
 func ExecProcess(c *exec.Cmd, fn ExecCallback) Cmd {
 	return Exec(wrapExecCommand(c), fn)
@@ -55,6 +63,44 @@ func ExecProcess(c *exec.Cmd, fn ExecCallback) Cmd {
 // with an error, which may or may not be nil.
 type ExecCallback func(error) Msg
 
+// ExitCode returns the exit code of a process run with ExecProcess, from the
+// error its callback is called with, or an error wrapping it. It's zero if
+// the process exited successfully, even if the terminal couldn't be restored
+// afterwards.
+//
+// It's -1 if the process didn't exit with a status of its own: if it was
+// killed by a signal, if it couldn't be started, or if the terminal couldn't
+// be released to run it. It's also -1 for errors which don't come from
+// running a process, such as those of other ExecCommands.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var restoreErr restoreTerminalError
+	if errors.As(err, &restoreErr) {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// restoreTerminalError is the error the callback of Exec is called with when
+// the command succeeded, but the terminal couldn't be restored afterwards.
+type restoreTerminalError struct {
+	err error
+}
+
+func (e restoreTerminalError) Error() string {
+	return e.err.Error()
+}
+
+func (e restoreTerminalError) Unwrap() error {
+	return e.err
+}
+
 // ExecCommand can be implemented to execute things in a blocking fashion in
 // the current terminal.
 type ExecCommand interface {
@@ -122,7 +168,10 @@ func (p *Program) exec(c ExecCommand, fn ExecCallback) {
 	}
 
 	// Have the program re-capture input.
-	err := p.RestoreTerminal()
+	var err error
+	if restoreErr := p.RestoreTerminal(); restoreErr != nil {
+		err = restoreTerminalError{err: restoreErr}
+	}
 	if fn != nil {
 		go p.Send(fn(err))
 	}
@@ -2,7 +2,10 @@ package tea
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"os/exec"
+	"strings"
 	"testing"
 )
 
@@ -79,3 +82,86 @@ func TestTeaExec(t *testing.T) {
 		})
 	}
 }
+
+type cursorExecModel struct{}
+
+func (m cursorExecModel) Init() Cmd {
+	c := exec.Command("true") //nolint:gosec
+	return Sequence(ShowCursor, SetCursorShape(CursorBar), ExecProcess(c, func(err error) Msg {
+		return execFinishedMsg{err}
+	}))
+}
+
+func (m cursorExecModel) Update(msg Msg) (Model, Cmd) {
+	if _, ok := msg.(execFinishedMsg); ok {
+		return m, Quit
+	}
+	return m, nil
+}
+
+func (m cursorExecModel) View() string {
+	return "\n"
+}
+
+func TestTeaExecRestoresCursor(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+
+	p := NewProgram(cursorExecModel{}, WithInput(&in), WithOutput(&buf))
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	release := strings.Index(out, "\x1b[?25h\x1b[0 q")
+	if release < 0 {
+		t.Fatalf("expected the cursor to be reset when releasing the terminal: %q", out)
+	}
+	if !strings.Contains(out[release:], "\x1b[?25h\x1b[6 q") {
+		t.Errorf("expected the cursor to be restored after the process exited: %q", out)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      func() error
+		expected int
+	}{
+		{
+			name:     "success",
+			err:      func() error { return exec.Command("true").Run() }, //nolint:gosec
+			expected: 0,
+		},
+		{
+			name:     "failure",
+			err:      func() error { return exec.Command("sh", "-c", "exit 3").Run() }, //nolint:gosec
+			expected: 3,
+		},
+		{
+			name: "wrapped failure",
+			err: func() error {
+				return fmt.Errorf("running editor: %w", exec.Command("sh", "-c", "exit 3").Run()) //nolint:gosec
+			},
+			expected: 3,
+		},
+		{
+			name:     "not started",
+			err:      func() error { return errors.New("not found") },
+			expected: -1,
+		},
+		{
+			name:     "terminal not restored",
+			err:      func() error { return restoreTerminalError{err: errors.New("restoring terminal")} },
+			expected: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ExitCode(test.err()); got != test.expected {
+				t.Errorf("expected exit code %d, got %d", test.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package tea
+
+// Msgs is a list of messages. A filter set with WithFilter can return Msgs to
+// have the program handle each of the messages in place of the one filtered,
+// in order, and ahead of any other messages. That's how a filter translates
+// raw events into higher-level ones, say a paste into a command per line,
+// or fans a message out to several:
+//
+//	func filter(m tea.Model, msg tea.Msg) tea.Msg {
+//		if msg, ok := msg.(tea.PasteMsg); ok {
+//			var msgs tea.Msgs
+//			for _, line := range strings.Split(msg.Content, "\n") {
+//				msgs = append(msgs, commandMsg(line))
+//			}
+//			return msgs
+//		}
+//		return msg
+//	}
+//
+// An empty list drops the message, like nil. The messages aren't filtered
+// again. Msgs is only expanded when returned by a filter.
+type Msgs []Msg
+
+// filteredMsg is a message a filter returned as part of Msgs, which isn't
+// filtered again. It's unwrapped by the event loop.
+type filteredMsg struct {
+	msg Msg
+}
+
+// expandMsgs has the event loop handle the given messages, returned by a
+// filter, before any others.
+func (p *Program) expandMsgs(msgs Msgs) {
+	ch := make(chan Msg, len(msgs))
+	for _, msg := range msgs {
+		if msg != nil {
+			ch <- filteredMsg{msg: msg}
+		}
+	}
+	p.expanded = ch
+}
@@ -0,0 +1,61 @@
+package tea
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+type filterModel struct {
+	received *[]Msg
+}
+
+func (m filterModel) Init() Cmd {
+	return Sequence(
+		func() Msg { return 2 },
+		func() Msg { return "a b" },
+	)
+}
+
+func (m filterModel) Update(msg Msg) (Model, Cmd) {
+	switch msg.(type) {
+	case string, int:
+		*m.received = append(*m.received, msg)
+		if msg == "done" {
+			return m, Quit
+		}
+	}
+	return m, nil
+}
+
+func (m filterModel) View() string {
+	return ""
+}
+
+func TestFilterMsgs(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+	var received []Msg
+
+	// The filter expands a message into several, which aren't filtered
+	// again, and drops others.
+	filter := func(_ Model, msg Msg) Msg {
+		switch msg {
+		case "a b":
+			return Msgs{"a", nil, "b", 1, "a b", "done"}
+		case 2:
+			return Msgs{}
+		}
+		return msg
+	}
+
+	p := NewProgram(filterModel{received: &received}, WithInput(&in), WithOutput(&buf), WithFilter(filter))
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Msg{"a", "b", 1, "a b", "done"}
+	if !reflect.DeepEqual(received, expected) {
+		t.Errorf("expected %v, got %v", expected, received)
+	}
+}
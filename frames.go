@@ -0,0 +1,240 @@
+package tea
+
+import "time"
+
+// FrameDiff describes a single frame written to the terminal by the renderer.
+// It's passed to the hook set with WithFrameHook.
+type FrameDiff struct {
+	// Time is when the frame was written.
+	Time time.Time
+
+	// Changed are the regions of the screen that were repainted, merged
+	// into runs of consecutive lines. Coordinates are relative to the top of
+	// the program's output, which in the altscreen is the top of the screen.
+	Changed []Rect
+
+	// Lines is the number of lines in the frame.
+	Lines int
+
+	// Output holds the bytes written to the terminal for this frame, escape
+	// sequences included.
+	Output []byte
+}
+
+// changedRegions merges the painted lines of a frame into rectangles. Each
+// rectangle spans the given width or, if the width is unknown, the widest of
+// its lines.
+func changedRegions(painted []bool, lines []string, width int) []Rect {
+	var regions []Rect
+	for i := 0; i < len(painted); i++ {
+		if !painted[i] {
+			continue
+		}
+
+		start := i
+		w := width
+		for ; i < len(painted) && painted[i]; i++ {
+			if width <= 0 {
+				if lw := StringWidth(lines[i]); lw > w {
+					w = lw
+				}
+			}
+		}
+		regions = append(regions, Rect{X: 0, Y: start, Width: w, Height: i - start})
+	}
+	return regions
+}
+
+// FramePerfMsg reports on the renderer's performance over the past period.
+// It's sent periodically when enabled with WithFramePerf, so that models can
+// adapt to slow terminals or links, for example by reducing the detail of
+// animations.
+type FramePerfMsg struct {
+	// Period is the time span covered by the report.
+	Period time.Duration
+
+	// Frames is the number of frames written to the terminal.
+	Frames int
+
+	// Dropped is the number of views which were replaced by newer ones
+	// before they could be rendered. A high number means the program
+	// produces views faster than the renderer's framerate.
+	Dropped int
+
+	// AvgRenderTime and MaxRenderTime are the average and maximum time it
+	// took to render a frame, including writing it to the terminal. Writes
+	// block when the terminal or the link to it can't keep up.
+	AvgRenderTime time.Duration
+	MaxRenderTime time.Duration
+
+	// Bytes is the number of bytes written to the terminal for the frames.
+	Bytes int64
+}
+
+// RenderStats are statistics on a program's rendering since it started, for
+// diagnosing slow views and terminal bandwidth issues. See Program.Stats.
+type RenderStats struct {
+	// Frames is the number of frames written to the terminal.
+	Frames int
+
+	// Skipped is the number of views which were replaced by newer ones
+	// before they could be rendered.
+	Skipped int
+
+	// Bytes is the number of bytes written to the terminal for the frames.
+	Bytes int64
+
+	// LastRenderTime, AvgRenderTime and MaxRenderTime are the time it took
+	// to render the last frame, and the average and maximum time it took to
+	// render a frame, including writing it to the terminal.
+	LastRenderTime time.Duration
+	AvgRenderTime  time.Duration
+	MaxRenderTime  time.Duration
+
+	// QueueDepth is the number of messages waiting to be processed. It's
+	// only known for programs with queue limits, see WithQueueLimits, and
+	// zero otherwise.
+	QueueDepth int
+}
+
+// Stats returns statistics on the program's rendering so far. The frame
+// statistics are only gathered by the standard renderer, and are zero with
+// other renderers.
+func (p *Program) Stats() RenderStats {
+	var stats RenderStats
+	if r, ok := p.renderer.(*standardRenderer); ok {
+		r.mtx.Lock()
+		t := r.totals
+		r.mtx.Unlock()
+
+		stats = RenderStats{
+			Frames:         t.frames,
+			Skipped:        t.dropped,
+			Bytes:          t.bytes,
+			LastRenderTime: t.last,
+			AvgRenderTime:  t.avg(),
+			MaxRenderTime:  t.max,
+		}
+	}
+	if p.queue != nil {
+		stats.QueueDepth = p.queue.len()
+	}
+	return stats
+}
+
+// frameStats are the renderer's performance statistics.
+type frameStats struct {
+	frames  int
+	dropped int
+	bytes   int64
+	total   time.Duration
+	last    time.Duration
+	max     time.Duration
+}
+
+// add records the render time and size of a frame.
+func (s *frameStats) add(d time.Duration, n int) {
+	s.frames++
+	s.bytes += int64(n)
+	s.total += d
+	s.last = d
+	if d > s.max {
+		s.max = d
+	}
+}
+
+// avg returns the average render time of a frame.
+func (s frameStats) avg() time.Duration {
+	if s.frames == 0 {
+		return 0
+	}
+	return s.total / time.Duration(s.frames)
+}
+
+// msg turns the statistics into a FramePerfMsg.
+func (s frameStats) msg(period time.Duration) FramePerfMsg {
+	return FramePerfMsg{
+		Period:        period,
+		Frames:        s.frames,
+		Dropped:       s.dropped,
+		AvgRenderTime: s.avg(),
+		MaxRenderTime: s.max,
+		Bytes:         s.bytes,
+	}
+}
+
+// handleFramePerf periodically reports the renderer's performance to the
+// program. Periods without any rendering activity aren't reported.
+func (p *Program) handleFramePerf(r *standardRenderer) chan struct{} {
+	ch := make(chan struct{})
+
+	go func() {
+		defer close(ch)
+
+		ticker := time.NewTicker(p.framePerfInterval)
+		defer ticker.Stop()
+
+		last := time.Now()
+		for {
+			select {
+			case <-p.ctx.Done():
+				return
+
+			case now := <-ticker.C:
+				s := r.takeFrameStats()
+				period := now.Sub(last)
+				last = now
+				if s.frames == 0 && s.dropped == 0 {
+					continue
+				}
+				p.Send(s.msg(period))
+			}
+		}
+	}()
+
+	return ch
+}
+
+// Bounds of the renderer's frame rate.
+const (
+	minFPS = 1
+	maxFPS = 120
+)
+
+// clampFPS limits a frame rate to what the renderer supports.
+func clampFPS(fps int) int {
+	if fps < minFPS {
+		return minFPS
+	}
+	if fps > maxFPS {
+		return maxFPS
+	}
+	return fps
+}
+
+// FPSMsg confirms the frame rate set with SetFPS. FPS is the rate applied,
+// which is limited to between 1 and 120 frames per second, or zero if the
+// program's renderer doesn't draw at a frame rate.
+type FPSMsg struct {
+	FPS int
+}
+
+// setFPSMsg is an internal message that sets the renderer's frame rate. You
+// can send a setFPSMsg with SetFPS.
+type setFPSMsg int
+
+// SetFPS is a command that sets the most frames per second the renderer
+// draws, overriding WithFPS. Raise it for smooth animations, and lower it
+// once the program is idle to save work. The program receives an FPSMsg with
+// the rate applied.
+func SetFPS(fps int) Cmd {
+	return func() Msg {
+		return setFPSMsg(fps)
+	}
+}
+
+// SetFPS sets the most frames per second the renderer draws, like the SetFPS
+// command.
+func (p *Program) SetFPS(fps int) {
+	p.Send(setFPSMsg(fps))
+}
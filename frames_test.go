@@ -0,0 +1,49 @@
+package tea
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+type fpsModel struct {
+	fps []int
+}
+
+func (m *fpsModel) Init() Cmd {
+	return SetFPS(500)
+}
+
+func (m *fpsModel) Update(msg Msg) (Model, Cmd) {
+	if msg, ok := msg.(FPSMsg); ok {
+		m.fps = append(m.fps, msg.FPS)
+		if len(m.fps) == 1 {
+			return m, SetFPS(10)
+		}
+		return m, Quit
+	}
+	return m, nil
+}
+
+func (m *fpsModel) View() string {
+	return ""
+}
+
+func TestSetFPS(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+
+	m := &fpsModel{}
+	p := NewProgram(m, WithInput(&in), WithOutput(&buf), WithFPS(30))
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The rate is limited to what the renderer supports.
+	if len(m.fps) != 2 || m.fps[0] != maxFPS || m.fps[1] != 10 {
+		t.Errorf("expected the rates %d and 10 to be applied, got %v", maxFPS, m.fps)
+	}
+	if r := p.renderer.(*standardRenderer); r.framerate != time.Second/10 {
+		t.Errorf("expected a framerate of %v, got %v", time.Second/10, r.framerate)
+	}
+}
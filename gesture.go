@@ -0,0 +1,165 @@
+package tea
+
+import (
+	"math"
+	"time"
+)
+
+// Thresholds of gesture recognition, see WithGestures.
+const (
+	// how far, in cells, the mouse must be dragged to swipe
+	swipeDistance = 3
+
+	// how fast, in cells per second, the mouse must still be moving when
+	// released to fling
+	flingSpeed = 40
+
+	// how far back the speed of the mouse is measured when it's released
+	flingWindow = 100 * time.Millisecond
+)
+
+// GestureDirection is the direction of a swipe or a fling.
+type GestureDirection int
+
+// Gesture directions.
+const (
+	GestureUp GestureDirection = iota
+	GestureDown
+	GestureLeft
+	GestureRight
+)
+
+var gestureDirections = map[GestureDirection]string{
+	GestureUp:    "up",
+	GestureDown:  "down",
+	GestureLeft:  "left",
+	GestureRight: "right",
+}
+
+// String returns the name of the direction.
+func (d GestureDirection) String() string {
+	return gestureDirections[d]
+}
+
+// SwipeMsg is sent when the mouse is dragged across a few cells and released,
+// if gestures are enabled with WithGestures. It follows the MouseMsg of the
+// release.
+type SwipeMsg struct {
+	// Direction is the direction the mouse was mostly dragged in.
+	Direction GestureDirection
+
+	// Button is the button which was held.
+	Button MouseButton
+
+	// StartX and StartY are where the button was pressed, X and Y where it
+	// was released.
+	StartX, StartY int
+	X, Y           int
+
+	// VelocityX and VelocityY are how fast the mouse was moving when the
+	// button was released, in cells per second.
+	VelocityX, VelocityY float64
+}
+
+// FlingMsg is sent instead of a SwipeMsg when the mouse was still moving
+// fast when the button was released, like when flicking a list to scroll it
+// on a touch screen.
+type FlingMsg SwipeMsg
+
+// gestureRecognizer recognizes swipes and flings in the drags of mouse input,
+// see WithGestures. Terminals which translate touches into mouse events
+// report them as drags.
+type gestureRecognizer struct {
+	// the press and the motion of the current drag
+	samples []gestureSample
+	button  MouseEventType
+}
+
+// gestureSample is the position of the mouse during a drag.
+type gestureSample struct {
+	x, y int
+	time time.Time
+}
+
+// track tracks a message read from the input, and returns the gesture it
+// completes, if any.
+func (g *gestureRecognizer) track(msg Msg, now time.Time) Msg {
+	m, ok := msg.(MouseMsg)
+	if !ok {
+		return nil
+	}
+
+	switch {
+	case m.Dragging && len(g.samples) > 0 && m.Type == g.button:
+		g.samples = append(g.samples, gestureSample{x: m.X, y: m.Y, time: now})
+
+	case !m.Dragging && (m.Type == MouseLeft || m.Type == MouseMiddle || m.Type == MouseRight):
+		g.samples = append(g.samples[:0], gestureSample{x: m.X, y: m.Y, time: now})
+		g.button = m.Type
+
+	case m.Type == MouseRelease && len(g.samples) > 1:
+		gesture := g.recognize(gestureSample{x: m.X, y: m.Y, time: now})
+		g.samples = g.samples[:0]
+		return gesture
+
+	default:
+		g.samples = g.samples[:0]
+	}
+	return nil
+}
+
+// recognize returns the gesture of the current drag, released at the given
+// position, if any.
+func (g *gestureRecognizer) recognize(release gestureSample) Msg {
+	start := g.samples[0]
+	dx, dy := release.x-start.x, release.y-start.y
+	if abs(dx) < swipeDistance && abs(dy) < swipeDistance {
+		return nil
+	}
+
+	swipe := SwipeMsg{
+		Button: MouseEvent{Type: g.button}.Button(),
+		StartX: start.x,
+		StartY: start.y,
+		X:      release.x,
+		Y:      release.y,
+	}
+	switch {
+	case abs(dx) >= abs(dy) && dx < 0:
+		swipe.Direction = GestureLeft
+	case abs(dx) >= abs(dy):
+		swipe.Direction = GestureRight
+	case dy < 0:
+		swipe.Direction = GestureUp
+	default:
+		swipe.Direction = GestureDown
+	}
+
+	// Measure the speed since the earliest sample within the window before
+	// the release. If there's none, the mouse was held still. Input read at
+	// once has the same time, so samples read along with the release are
+	// skipped.
+	from := release
+	for _, s := range g.samples {
+		if release.time.Sub(s.time) <= flingWindow && s.time.Before(release.time) {
+			from = s
+			break
+		}
+	}
+	if dt := release.time.Sub(from.time).Seconds(); dt > 0 {
+		swipe.VelocityX = float64(release.x-from.x) / dt
+		swipe.VelocityY = float64(release.y-from.y) / dt
+	}
+
+	if math.Hypot(swipe.VelocityX, swipe.VelocityY) >= flingSpeed {
+		return FlingMsg(swipe)
+	}
+	return swipe
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,75 @@
+package tea
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGestureRecognizer(t *testing.T) {
+	start := time.Now()
+	at := func(ms int) time.Time {
+		return start.Add(time.Duration(ms) * time.Millisecond)
+	}
+	press := func(x, y int) MouseMsg {
+		return MouseMsg{X: x, Y: y, Type: MouseLeft}
+	}
+	drag := func(x, y int) MouseMsg {
+		return MouseMsg{X: x, Y: y, Type: MouseLeft, Dragging: true, Drag: Drag{}}
+	}
+	release := func(x, y int) MouseMsg {
+		return MouseMsg{X: x, Y: y, Type: MouseRelease}
+	}
+
+	type input struct {
+		msg Msg
+		ms  int
+	}
+	tests := []struct {
+		name     string
+		input    []input
+		expected Msg
+	}{
+		{
+			"click",
+			[]input{{press(5, 5), 0}, {release(5, 5), 100}},
+			nil,
+		},
+		{
+			"short drag",
+			[]input{{press(5, 5), 0}, {drag(6, 6), 50}, {release(6, 6), 100}},
+			nil,
+		},
+		{
+			"swipe left",
+			[]input{{press(10, 5), 0}, {drag(7, 5), 500}, {drag(4, 6), 1000}, {release(4, 6), 1500}},
+			SwipeMsg{Direction: GestureLeft, Button: MouseButtonLeft, StartX: 10, StartY: 5, X: 4, Y: 6},
+		},
+		{
+			"fling up",
+			[]input{{press(5, 20), 0}, {drag(5, 15), 50}, {drag(5, 10), 100}, {release(5, 8), 150}},
+			FlingMsg{
+				Direction: GestureUp, Button: MouseButtonLeft, StartX: 5, StartY: 20, X: 5, Y: 8,
+				VelocityY: -70,
+			},
+		},
+		{
+			"missed release",
+			[]input{{press(5, 5), 0}, {drag(15, 5), 50}, {MouseMsg{X: 15, Y: 5, Type: MouseMotion}, 60}, {release(15, 5), 100}},
+			nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var g gestureRecognizer
+			var got Msg
+			for _, in := range test.input {
+				if msg := g.track(in.msg, at(in.ms)); msg != nil {
+					got = msg
+				}
+			}
+			if got != test.expected {
+				t.Errorf("expected %#v, got %#v", test.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package tea
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbletea/input"
+)
+
+// MouseHighlightMsg is sent when the user releases the mouse button after
+// highlighting text while mouse highlight tracking is enabled. Coordinates
+// are zero-based, like those of MouseMsg. If the user clicked without
+// dragging, the start and end of the highlight are the same.
+type MouseHighlightMsg input.MouseHighlight
+
+// enableMouseHighlightTrackingMsg is an internal message that signals to
+// enable mouse highlight tracking. You can send an
+// enableMouseHighlightTrackingMsg with EnableMouseHighlightTracking.
+type enableMouseHighlightTrackingMsg struct {
+	firstRow, lastRow int
+}
+
+// EnableMouseHighlightTracking is a special command that enables xterm's
+// mouse highlight tracking. Clicking the left mouse button sends a MouseMsg
+// as usual, after which the terminal highlights text as the user drags the
+// mouse, like a native selection. When the button is released a
+// MouseHighlightMsg reports the highlighted region.
+//
+// The highlight can only start and extend between firstRow and lastRow
+// (inclusive, zero-based); clicks outside of these rows are reported as
+// regular clicks. Send the command again to change the rows.
+//
+// Highlight tracking replaces other mouse modes, such as cell motion, and is
+// disabled again by them or DisableMouse. Few terminals besides xterm
+// support it.
+func EnableMouseHighlightTracking(firstRow, lastRow int) Cmd {
+	return func() Msg {
+		return enableMouseHighlightTrackingMsg{firstRow: firstRow, lastRow: lastRow}
+	}
+}
+
+// Highlight tracking sequences.
+const (
+	enableHighlightTracking  = "\x1b[?1001h"
+	disableHighlightTracking = "\x1b[?1001l"
+)
+
+// highlightTracking is the state of mouse highlight tracking.
+type highlightTracking struct {
+	enabled           bool
+	firstRow, lastRow int
+}
+
+// response returns the answer to a button press the terminal expects while
+// highlight tracking is enabled. The terminal won't process any further
+// output until it has received the answer, so it must be sent for every
+// left button press. Presses outside of the highlight rows abort tracking for
+// that press.
+func (h highlightTracking) response(m MouseEvent) string {
+	if m.Y < h.firstRow || m.Y > h.lastRow {
+		return "\x1b[0;0;0;0;0T"
+	}
+	// Screen coordinates are 1-based, and the last row is exclusive.
+	return fmt.Sprintf("\x1b[1;%d;%d;%d;%dT", m.X+1, m.Y+1, h.firstRow+1, h.lastRow+2) //nolint:gomnd
+}
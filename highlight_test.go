@@ -0,0 +1,69 @@
+package tea
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestHighlightResponse(t *testing.T) {
+	h := highlightTracking{enabled: true, firstRow: 2, lastRow: 5}
+
+	tests := []struct {
+		name     string
+		event    MouseEvent
+		expected string
+	}{
+		{"inside", MouseEvent{X: 3, Y: 4, Type: MouseLeft}, "\x1b[1;4;5;3;7T"},
+		{"first row", MouseEvent{X: 0, Y: 2, Type: MouseLeft}, "\x1b[1;1;3;3;7T"},
+		{"above", MouseEvent{X: 3, Y: 1, Type: MouseLeft}, "\x1b[0;0;0;0;0T"},
+		{"below", MouseEvent{X: 3, Y: 6, Type: MouseLeft}, "\x1b[0;0;0;0;0T"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := h.response(test.event); got != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseHighlightResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		expected []Msg
+	}{
+		{
+			name:     "click",
+			input:    []byte("\x1b[t\x24\x26"),
+			expected: []Msg{MouseHighlightMsg{StartX: 3, StartY: 5, EndX: 3, EndY: 5, X: 3, Y: 5}},
+		},
+		{
+			name:     "highlight",
+			input:    []byte("\x1b[T\x21\x22\x2a\x23\x2b\x24"),
+			expected: []Msg{MouseHighlightMsg{StartX: 0, StartY: 1, EndX: 9, EndY: 2, X: 10, Y: 3}},
+		},
+		{
+			name:  "followed by a key",
+			input: []byte("\x1b[t\x24\x26a"),
+			expected: []Msg{
+				MouseHighlightMsg{StartX: 3, StartY: 5, EndX: 3, EndY: 5, X: 3, Y: 5},
+				KeyMsg{Type: KeyRunes, Runes: []rune{'a'}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			msgs, err := readInputs(bytes.NewReader(test.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(msgs, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, msgs)
+			}
+		})
+	}
+}
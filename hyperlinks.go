@@ -0,0 +1,83 @@
+package tea
+
+import "strings"
+
+// hyperlinkEnd closes an OSC 8 hyperlink.
+const hyperlinkEnd = "\x1b]8;;\x1b\\"
+
+// Hyperlink wraps text in an OSC 8 hyperlink to url, which terminals that
+// support hyperlinks (see FeatureHyperlinks) let users open, usually with a
+// modified click. Others show just the text.
+func Hyperlink(url, text string) string {
+	return "\x1b]8;;" + url + "\x1b\\" + text + hyperlinkEnd
+}
+
+// HyperlinkWithID wraps text in a hyperlink like Hyperlink, with an id.
+// Terminals treat the pieces of text linked with the same id and url as one
+// hyperlink, for example highlighting all of them when one is hovered, which
+// keeps links that are split across lines, or interrupted by other text,
+// together.
+func HyperlinkWithID(url, id, text string) string {
+	return "\x1b]8;id=" + id + ";" + url + "\x1b\\" + text + hyperlinkEnd
+}
+
+// parseHyperlink reports whether seq is an OSC 8 hyperlink sequence and, if
+// so, whether it opens a hyperlink rather than closing it.
+func parseHyperlink(seq string) (isLink, opens bool) {
+	if !strings.HasPrefix(seq, "\x1b]8;") {
+		return false, false
+	}
+	body := strings.TrimSuffix(strings.TrimSuffix(seq[4:], "\a"), "\x1b\\")
+	i := strings.IndexByte(body, ';')
+	if i < 0 {
+		return false, false
+	}
+	return true, body[i+1:] != ""
+}
+
+// openHyperlink returns the sequence which opened the hyperlink still open at
+// the end of s, if any.
+func openHyperlink(s string) string {
+	if !strings.Contains(s, "\x1b]8;") {
+		return ""
+	}
+	var open string
+	forEachCluster(s, func(seq string, w int) bool {
+		if isLink, opens := parseHyperlink(seq); isLink {
+			open = ""
+			if opens {
+				open = seq
+			}
+		}
+		return true
+	})
+	return open
+}
+
+// closeHyperlinks closes the hyperlink left open at the end of each line and
+// opens it again at the start of the next, so that every line can be painted,
+// cut or cleared on its own without leaving a hyperlink open behind it.
+func closeHyperlinks(lines []string) []string {
+	var open string
+	var closed []string
+	for i, line := range lines {
+		if open == "" && !strings.Contains(line, "\x1b]8;") {
+			continue
+		}
+		if closed == nil {
+			closed = make([]string, len(lines))
+			copy(closed, lines)
+		}
+
+		line = open + line
+		open = openHyperlink(line)
+		if open != "" {
+			line += hyperlinkEnd
+		}
+		closed[i] = line
+	}
+	if closed == nil {
+		return lines
+	}
+	return closed
+}
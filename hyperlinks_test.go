@@ -0,0 +1,75 @@
+package tea
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/muesli/termenv"
+)
+
+func TestHyperlink(t *testing.T) {
+	if got, expected := Hyperlink("https://charm.sh", "charm"), "\x1b]8;;https://charm.sh\x1b\\charm\x1b]8;;\x1b\\"; got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+	if got, expected := HyperlinkWithID("https://charm.sh", "1", "charm"), "\x1b]8;id=1;https://charm.sh\x1b\\charm\x1b]8;;\x1b\\"; got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestCloseHyperlinks(t *testing.T) {
+	open := "\x1b]8;id=1;https://charm.sh\a"
+
+	tests := []struct {
+		name     string
+		lines    []string
+		expected []string
+	}{
+		{
+			name:     "no hyperlinks",
+			lines:    []string{"a", "b"},
+			expected: []string{"a", "b"},
+		},
+		{
+			name:     "closed",
+			lines:    []string{Hyperlink("https://charm.sh", "a"), "b"},
+			expected: []string{Hyperlink("https://charm.sh", "a"), "b"},
+		},
+		{
+			name:     "across lines",
+			lines:    []string{"a" + open + "b", "c", "d" + hyperlinkEnd + "e", "f"},
+			expected: []string{"a" + open + "b" + hyperlinkEnd, open + "c" + hyperlinkEnd, open + "d" + hyperlinkEnd + "e", "f"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := closeHyperlinks(test.lines); !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("expected %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestRendererHyperlinks(t *testing.T) {
+	open := "\x1b]8;;https://charm.sh\x1b\\"
+
+	var buf bytes.Buffer
+	r := newRenderer(termenv.NewOutput(&buf), false).(*standardRenderer)
+	r.width = 10
+
+	r.write(open + "abc\ndef\nghi" + hyperlinkEnd)
+	r.flush()
+	buf.Reset()
+
+	// Only the changed line is painted, within the hyperlink it's part of.
+	r.write(open + "abc\nxyz\nghi" + hyperlinkEnd)
+	r.flush()
+	out := buf.String()
+	if !strings.Contains(out, open+"xyz"+hyperlinkEnd) {
+		t.Errorf("expected the line to be painted as a hyperlink, got %q", out)
+	}
+	if strings.Contains(out, "ghi") {
+		t.Errorf("expected unchanged lines not to be painted, got %q", out)
+	}
+}
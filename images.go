@@ -0,0 +1,361 @@
+package tea
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// ImageProtocol is a protocol for drawing images in the terminal.
+type ImageProtocol int
+
+// Available image protocols.
+const (
+	// ImageProtocolNone means images can't be drawn.
+	ImageProtocolNone ImageProtocol = iota
+
+	// ImageProtocolSixel draws images with DEC sixel graphics, as foot,
+	// Windows Terminal and xterm (when built with it) do.
+	ImageProtocolSixel
+
+	// ImageProtocolKitty draws images with the kitty graphics protocol, as
+	// kitty and Ghostty do.
+	ImageProtocolKitty
+
+	// ImageProtocolITerm2 draws images with iTerm2's inline images protocol,
+	// OSC 1337, as iTerm2, WezTerm and VS Code do.
+	ImageProtocolITerm2
+)
+
+// String returns the name of the image protocol.
+func (p ImageProtocol) String() string {
+	switch p {
+	case ImageProtocolNone:
+		return "none"
+	case ImageProtocolSixel:
+		return "sixel"
+	case ImageProtocolKitty:
+		return "kitty"
+	case ImageProtocolITerm2:
+		return "iterm2"
+	}
+	return "unknown"
+}
+
+// kittyChunkSize is the largest payload of a single kitty graphics command.
+const kittyChunkSize = 4096
+
+// Image is an image drawn over a region of the program's output, such as a
+// picture in a file browser or a chart. Images are drawn by the renderer, on
+// top of the view, with the protocol the terminal supports (see
+// Capabilities.ImageProtocol), and only redrawn when something has disturbed
+// them.
+//
+// The renderer treats an image's region as opaque: whatever the view has in
+// those cells is neither drawn nor compared between frames, so the view can
+// leave them blank. Images are only drawn on terminals of the modern tier,
+// see FeatureGraphics. Where an image can't be drawn, because the terminal
+// doesn't support any protocol the image has data for, the view is drawn in
+// its region instead.
+type Image struct {
+	// ID identifies the image. Placing an image with an id that's already in
+	// use replaces the image.
+	ID string
+
+	// Rect is the region of cells the image covers. Coordinates are
+	// relative to the top of the program's output, which in the altscreen
+	// is the top of the screen. The image should fit its region; sixel
+	// images are drawn at their own size in pixels, whatever the region.
+	Rect Rect
+
+	// Sixel is the image encoded as a sixel sequence, from the DCS
+	// introducer up to and including the string terminator, for the sixel
+	// protocol.
+	Sixel string
+
+	// PNG is the image encoded as PNG, for the kitty and iTerm2 protocols.
+	// Unlike sixel images, these are scaled to fit the image's region.
+	PNG []byte
+}
+
+// Sequence returns the escape sequence which draws the image at the cursor
+// with the given protocol, for programs which draw images themselves. It's
+// empty if the image has no data for the protocol.
+func (img Image) Sequence(protocol ImageProtocol) string {
+	return img.sequence(protocol, 0)
+}
+
+// sequence returns the escape sequence which draws the image, using the given
+// id for the kitty protocol, if it isn't zero.
+func (img Image) sequence(protocol ImageProtocol, id int) string {
+	switch protocol {
+	case ImageProtocolSixel:
+		return img.Sixel
+
+	case ImageProtocolKitty:
+		if len(img.PNG) == 0 {
+			return ""
+		}
+		// Transmit and display the PNG, scaled to the region, without
+		// moving the cursor or having the terminal respond.
+		var b strings.Builder
+		data := base64.StdEncoding.EncodeToString(img.PNG)
+		for first := true; first || len(data) > 0; first = false {
+			chunk := data
+			if len(chunk) > kittyChunkSize {
+				chunk = chunk[:kittyChunkSize]
+			}
+			data = data[len(chunk):]
+			more := 0
+			if len(data) > 0 {
+				more = 1
+			}
+
+			b.WriteString("\x1b_G")
+			if first {
+				fmt.Fprintf(&b, "a=T,f=100,c=%d,r=%d,C=1,q=2,", img.Rect.Width, img.Rect.Height)
+				if id != 0 {
+					fmt.Fprintf(&b, "i=%d,", id)
+				}
+			}
+			fmt.Fprintf(&b, "m=%d;%s\x1b\\", more, chunk)
+		}
+		return b.String()
+
+	case ImageProtocolITerm2:
+		if len(img.PNG) == 0 {
+			return ""
+		}
+		return fmt.Sprintf("\x1b]1337;File=inline=1;size=%d;width=%d;height=%d;preserveAspectRatio=1:%s\a",
+			len(img.PNG), img.Rect.Width, img.Rect.Height, base64.StdEncoding.EncodeToString(img.PNG))
+	}
+	return ""
+}
+
+// deleteKittyImage returns the kitty graphics command which deletes the image
+// with the given id.
+func deleteKittyImage(id int) string {
+	return fmt.Sprintf("\x1b_Ga=d,d=I,i=%d,q=2\x1b\\", id)
+}
+
+// placeImageMsg is an internal message that places an image. You can send a
+// placeImageMsg with PlaceImage.
+type placeImageMsg Image
+
+// removeImageMsg is an internal message that removes an image. You can send a
+// removeImageMsg with RemoveImage.
+type removeImageMsg string
+
+// clearImagesMsg is an internal message that removes all images. You can send
+// a clearImagesMsg with ClearImages.
+type clearImagesMsg struct{}
+
+// PlaceImage is a command that draws an image over the view, or moves or
+// replaces the image with the same id.
+func PlaceImage(img Image) Cmd {
+	return func() Msg {
+		return placeImageMsg(img)
+	}
+}
+
+// RemoveImage is a command that removes the image with the given id. The
+// view is drawn in its region again.
+func RemoveImage(id string) Cmd {
+	return func() Msg {
+		return removeImageMsg(id)
+	}
+}
+
+// ClearImages is a special command that removes all images.
+func ClearImages() Msg {
+	return clearImagesMsg{}
+}
+
+// placedImage is an image the renderer knows about.
+type placedImage struct {
+	Image
+
+	// the image's id in the kitty graphics protocol
+	kittyID int
+
+	// whether the image is on the screen
+	drawn bool
+}
+
+// drawable reports whether the image can be drawn with the renderer's
+// protocol. Images which can't be drawn are ignored.
+func (r *standardRenderer) drawable(p *placedImage) bool {
+	return p.sequence(r.imageProtocol, 0) != ""
+}
+
+// setImageProtocol sets the protocol images are drawn with.
+func (r *standardRenderer) setImageProtocol(protocol ImageProtocol) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if protocol == r.imageProtocol {
+		return
+	}
+	r.imageProtocol = protocol
+	for _, p := range r.images {
+		r.invalidateRect(p.Rect)
+		p.drawn = false
+	}
+}
+
+// placeImage adds an image, replacing any image with the same id, and
+// invalidates the lines it covered and covers.
+func (r *standardRenderer) placeImage(img Image) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	for i, p := range r.images {
+		if p.ID == img.ID {
+			r.forgetImage(p)
+			r.images = append(r.images[:i], r.images[i+1:]...)
+			break
+		}
+	}
+	r.nextKittyID++
+	r.images = append(r.images, &placedImage{Image: img, kittyID: r.nextKittyID})
+	r.invalidateRect(img.Rect)
+}
+
+// removeImages removes the images for which remove returns true.
+func (r *standardRenderer) removeImages(remove func(*placedImage) bool) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	images := r.images[:0]
+	for _, p := range r.images {
+		if remove(p) {
+			r.forgetImage(p)
+			continue
+		}
+		images = append(images, p)
+	}
+	r.images = images
+}
+
+// forgetImage invalidates the lines an image covered, so that the view is
+// painted there, and has kitty images deleted on the next frame, as text
+// doesn't paint over them.
+func (r *standardRenderer) forgetImage(p *placedImage) {
+	r.invalidateRect(p.Rect)
+	if p.drawn && r.imageProtocol == ImageProtocolKitty {
+		r.kittyDeletes = append(r.kittyDeletes, p.kittyID)
+	}
+}
+
+// invalidateRect marks the lines of a region to be repainted.
+func (r *standardRenderer) invalidateRect(rect Rect) {
+	if r.invalidLines == nil {
+		r.invalidLines = make(map[int]struct{})
+	}
+	for y := rect.Y; y < rect.Y+rect.Height; y++ {
+		r.invalidLines[y] = struct{}{}
+	}
+}
+
+// imageSpans returns the columns covered by images on the given line, as
+// sorted and merged [start, end) pairs.
+func (r *standardRenderer) imageSpans(y int) [][2]int {
+	var spans [][2]int
+	for _, p := range r.images {
+		if y < p.Rect.Y || y >= p.Rect.Y+p.Rect.Height || p.Rect.Width <= 0 || !r.drawable(p) {
+			continue
+		}
+		span := [2]int{p.Rect.X, p.Rect.X + p.Rect.Width}
+		if span[0] < 0 {
+			span[0] = 0
+		}
+
+		// Insert in order, merging with overlapping spans.
+		i := 0
+		for i < len(spans) && spans[i][1] < span[0] {
+			i++
+		}
+		j := i
+		for j < len(spans) && spans[j][0] <= span[1] {
+			if spans[j][0] < span[0] {
+				span[0] = spans[j][0]
+			}
+			if spans[j][1] > span[1] {
+				span[1] = spans[j][1]
+			}
+			j++
+		}
+		spans = append(spans[:i], append([][2]int{span}, spans[j:]...)...)
+	}
+	return spans
+}
+
+// maskImages returns a copy of lines in which the cells covered by images
+// are skipped over with cursor movements rather than painted, along with the
+// set of lines that have images on them. The masked lines are written over
+// what's on the screen rather than after clearing it, so they erase the rest
+// of the line themselves.
+func (r *standardRenderer) maskImages(lines []string) ([]string, map[int]struct{}) {
+	if len(r.images) == 0 || r.imageProtocol == ImageProtocolNone {
+		return lines, nil
+	}
+
+	masked := make([]string, len(lines))
+	copy(masked, lines)
+	rows := make(map[int]struct{})
+	for y, line := range lines {
+		spans := r.imageSpans(y)
+		if len(spans) == 0 {
+			continue
+		}
+		rows[y] = struct{}{}
+		if r.width > 0 {
+			line = Truncate(line, r.width, "")
+		}
+
+		var b strings.Builder
+		col := 0
+		for _, span := range spans {
+			b.WriteString(endSegment(PadRight(cutCells(line, col, span[0]), span[0]-col)))
+			fmt.Fprintf(&b, "\x1b[%dC", span[1]-span[0])
+			col = span[1]
+		}
+		rest := cutCells(line, col, math.MaxInt)
+		b.WriteString(rest)
+		if StringWidth(rest) > 0 || r.width <= 0 || col < r.width {
+			// Erase whatever's left of the previous frame.
+			b.WriteString("\x1b[K")
+		}
+		masked[y] = b.String()
+	}
+	return masked, rows
+}
+
+// drawImages deletes the kitty images which have been removed, and draws the
+// images which aren't on the screen, as long as they're within the lines
+// rendered. The cursor must be at the start of the last line rendered, and is
+// returned there.
+func (r *standardRenderer) drawImages(w io.Writer) {
+	for _, id := range r.kittyDeletes {
+		_, _ = io.WriteString(w, deleteKittyImage(id))
+	}
+	r.kittyDeletes = nil
+
+	for _, p := range r.images {
+		if p.drawn || !r.drawable(p) || p.Rect.Y < 0 || p.Rect.X < 0 || p.Rect.Y+p.Rect.Height > r.linesRendered {
+			continue
+		}
+		_, _ = io.WriteString(w, "\x1b7") // save the cursor
+		if up := r.linesRendered - 1 - p.Rect.Y; up > 0 {
+			fmt.Fprintf(w, "\x1b[%dA", up)
+		}
+		if p.Rect.X > 0 {
+			fmt.Fprintf(w, "\x1b[%dC", p.Rect.X)
+		}
+		_, _ = io.WriteString(w, p.sequence(r.imageProtocol, p.kittyID))
+		_, _ = io.WriteString(w, "\x1b8") // restore the cursor
+		p.drawn = true
+	}
+}
@@ -0,0 +1,136 @@
+package tea
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/muesli/termenv"
+)
+
+func TestRendererImages(t *testing.T) {
+	const sixel = "\x1bPq#0~\x1b\\"
+
+	var buf bytes.Buffer
+	r := newRenderer(termenv.NewOutput(&buf), false).(*standardRenderer)
+	r.altScreenActive = true
+	r.width, r.height = 10, 5
+	r.imageProtocol = ImageProtocolSixel
+
+	r.handleMessages(placeImageMsg{ID: "img", Rect: Rect{X: 2, Y: 1, Width: 3, Height: 1}, Sixel: sixel})
+	r.write("aaaaaaaaaa\nbbbbbbbbbb\ncccccccccc")
+	r.flush()
+	out := buf.String()
+	if strings.Count(out, sixel) != 1 {
+		t.Errorf("expected the image to be drawn once, got %q", out)
+	}
+	if !strings.Contains(out, "bb\x1b[3Cbbbbb\x1b[K") {
+		t.Errorf("expected the image's region to be skipped, got %q", out)
+	}
+
+	// Changes within the image's region aren't painted.
+	buf.Reset()
+	r.write("aaaaaaaaaa\nbbXXXbbbbb\ncccccccccc")
+	r.flush()
+	if out := buf.String(); strings.Contains(out, "XXX") || strings.Contains(out, sixel) {
+		t.Errorf("expected neither the region nor the image to be painted, got %q", out)
+	}
+
+	// The image is drawn again once invalidated.
+	buf.Reset()
+	r.handleMessages(invalidateLinesMsg{from: 1, to: 2})
+	r.write("aaaaaaaaaa\nbbXXXbbbbb\ncccccccccc")
+	r.flush()
+	if out := buf.String(); strings.Count(out, sixel) != 1 {
+		t.Errorf("expected the image to be redrawn, got %q", out)
+	}
+
+	// Once removed, the view is painted in its place.
+	buf.Reset()
+	r.handleMessages(RemoveImage("img")())
+	r.write("aaaaaaaaaa\nbbXXXbbbbb\ncccccccccc")
+	r.flush()
+	if out := buf.String(); strings.Contains(out, sixel) || !strings.Contains(out, "bbXXXbbbbb") {
+		t.Errorf("expected the view to be painted in place of the image, got %q", out)
+	}
+}
+
+func TestImageSpans(t *testing.T) {
+	r := &standardRenderer{imageProtocol: ImageProtocolSixel, images: []*placedImage{
+		{Image: Image{Rect: Rect{X: 5, Y: 0, Width: 2, Height: 2}, Sixel: "a"}},
+		{Image: Image{Rect: Rect{X: 1, Y: 1, Width: 2, Height: 1}, Sixel: "b"}},
+		{Image: Image{Rect: Rect{X: 6, Y: 1, Width: 3, Height: 1}, Sixel: "c"}},
+		{Image: Image{Rect: Rect{X: 0, Y: 1, Width: 9, Height: 1}, PNG: []byte("d")}},
+	}}
+
+	expected := [][2]int{{1, 3}, {5, 9}}
+	if got := r.imageSpans(1); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected spans %v, got %v", expected, got)
+	}
+	if got := r.imageSpans(2); got != nil {
+		t.Errorf("expected no spans, got %v", got)
+	}
+}
+
+func TestImageSequence(t *testing.T) {
+	img := Image{Rect: Rect{Width: 4, Height: 2}, Sixel: "\x1bPq#0~\x1b\\", PNG: []byte("png")}
+
+	tests := []struct {
+		protocol ImageProtocol
+		id       int
+		expected string
+	}{
+		{ImageProtocolNone, 0, ""},
+		{ImageProtocolSixel, 0, "\x1bPq#0~\x1b\\"},
+		{ImageProtocolKitty, 0, "\x1b_Ga=T,f=100,c=4,r=2,C=1,q=2,m=0;cG5n\x1b\\"},
+		{ImageProtocolKitty, 7, "\x1b_Ga=T,f=100,c=4,r=2,C=1,q=2,i=7,m=0;cG5n\x1b\\"},
+		{ImageProtocolITerm2, 0, "\x1b]1337;File=inline=1;size=3;width=4;height=2;preserveAspectRatio=1:cG5n\a"},
+	}
+	for _, test := range tests {
+		t.Run(test.protocol.String(), func(t *testing.T) {
+			if got := img.sequence(test.protocol, test.id); got != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, got)
+			}
+		})
+	}
+
+	if got := (Image{Sixel: "x"}).Sequence(ImageProtocolKitty); got != "" {
+		t.Errorf("expected no sequence without PNG data, got %q", got)
+	}
+}
+
+func TestKittyImageChunks(t *testing.T) {
+	img := Image{Rect: Rect{Width: 1, Height: 1}, PNG: bytes.Repeat([]byte{0}, kittyChunkSize)}
+	seq := img.Sequence(ImageProtocolKitty)
+	if n := strings.Count(seq, "\x1b_G"); n != 2 {
+		t.Fatalf("expected the image to be sent in 2 chunks, got %d in %q", n, seq)
+	}
+	if !strings.Contains(seq, "m=1;") || !strings.Contains(seq, "\x1b_Gm=0;") {
+		t.Errorf("expected the first chunk to announce more, got %q", seq)
+	}
+}
+
+func TestRendererKittyImages(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRenderer(termenv.NewOutput(&buf), false).(*standardRenderer)
+	r.altScreenActive = true
+	r.width, r.height = 10, 5
+	r.imageProtocol = ImageProtocolKitty
+
+	r.handleMessages(placeImageMsg{ID: "img", Rect: Rect{Width: 2, Height: 1}, PNG: []byte("png")})
+	r.write("aaaaaaaaaa")
+	r.flush()
+	if out := buf.String(); !strings.Contains(out, "i=1,m=0;cG5n") {
+		t.Errorf("expected the image to be transmitted, got %q", out)
+	}
+
+	// Text doesn't paint over kitty images, so they're deleted.
+	buf.Reset()
+	r.handleMessages(ClearImages())
+	r.write("aaaaaaaaaa")
+	r.flush()
+	if out := buf.String(); !strings.Contains(out, deleteKittyImage(1)) {
+		t.Errorf("expected the image to be deleted, got %q", out)
+	}
+}
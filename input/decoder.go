@@ -0,0 +1,452 @@
+package input
+
+import (
+	"bytes"
+	"io"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mattn/go-localereader"
+)
+
+// DefaultBufferSize is the size of reads, unless set with
+// Decoder.BufferSize.
+const DefaultBufferSize = 256
+
+// maxReads is the most reads combined in throughput mode, so that a
+// continuous flood of input is still processed in batches.
+const maxReads = 16
+
+// Decoder reads events from a stream of terminal input.
+type Decoder struct {
+	// BufferSize is the size of reads. Larger reads handle floods of input,
+	// such as large pastes, more efficiently. It defaults to
+	// DefaultBufferSize.
+	BufferSize int
+
+	// Throughput keeps reading while the input fills the read buffer, or
+	// ends in an incomplete escape sequence, and parses it all at once. This
+	// handles floods of input, such as large pastes or fast mouse motion,
+	// more efficiently and splits escape sequences less often, at the cost
+	// of a little latency.
+	Throughput bool
+
+	// ClickInterval is the longest time between presses of a mouse button
+	// which count as successive clicks, see MouseEvent.ClickCount. It
+	// defaults to DefaultClickInterval.
+	ClickInterval time.Duration
+
+	// PasteChunkSize streams bracketed pastes as they're read, as a
+	// PasteStart event, PasteChunk events of at most this many bytes and a
+	// PasteEnd event, instead of reading them whole into a Paste event. Large
+	// pastes then neither hold up the decoder until they end, nor need to
+	// fit in memory at once. Pastes aren't streamed if it's zero.
+	PasteChunkSize int
+
+	// MaxPasteSize is the most bytes of a bracketed paste which are
+	// reported; the rest is dropped. There's no limit if it's zero.
+	MaxPasteSize int
+
+	// Keys are escape sequences, and the keys they're translated into, in
+	// addition to the ones the decoder knows. They take precedence over the
+	// built-in sequences, so they can also correct those of unusual
+	// terminals. See TerminfoKeys for loading them from terminfo.
+	Keys map[string]Key
+
+	// ReportUnknown reports escape sequences the decoder doesn't recognize
+	// as UnknownSequence events, rather than dropping them.
+	ReportUnknown bool
+
+	// Timestamps records when keys and mouse events are read, see Key.Time
+	// and MouseEvent.Time. They're left out by default, so that events can
+	// be compared as they are.
+	Timestamps bool
+
+	// ReportRaw reports the bytes of all input as it's read, as a RawInput
+	// event ahead of the events parsed from it.
+	ReportRaw bool
+
+	// UTF8Mouse is to be set when xterm's UTF-8 extended mouse encoding is
+	// enabled, with mode 1005, so that mouse events whose values are split
+	// across reads are held back until they're complete. Otherwise, the
+	// values are taken to be single bytes, as in X10 mouse events, which are
+	// never held back, even if they look like the beginning of a rune.
+	UTF8Mouse bool
+
+	r     io.Reader
+	mouse mouseTracker
+
+	// the bracketed paste being streamed, if any
+	paste *pasteReader
+
+	// the beginning of a rune split across reads
+	pending []byte
+
+	// the input read since the last events were returned, if ReportRaw is
+	// set
+	raw []byte
+
+	// whether responses to DECRQSS and XTGETTCAP queries are expected, see
+	// ExpectDCS; accessed atomically
+	dcsExpected int32
+}
+
+// UnknownSequence is an escape sequence the decoder doesn't recognize. It's
+// only reported when enabled with Decoder.ReportUnknown.
+type UnknownSequence []byte
+
+// RawInput is the input as it's read, before it's parsed into events. It's
+// only reported when enabled with Decoder.ReportRaw.
+type RawInput []byte
+
+// rawReader reads the decoder's input, recording it if ReportRaw is set.
+type rawReader struct {
+	d *Decoder
+}
+
+func (r rawReader) Read(p []byte) (int, error) {
+	n, err := r.d.r.Read(p)
+	if r.d.ReportRaw && n > 0 {
+		r.d.raw = append(r.d.raw, p[:n]...)
+	}
+	return n, err
+}
+
+// NewDecoder returns a decoder which reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// ExpectDCS sets whether responses to DECRQSS and XTGETTCAP queries are
+// expected. They're device control strings, which start just like alt+P
+// followed by a digit, so the decoder only waits for the rest of one split
+// across reads while they're expected; otherwise the input is taken for keys.
+// Set it when sending such queries, and clear it once they're answered, for
+// instance when the response to a DA1 query sent after them arrives. It may be
+// called while Decode runs.
+func (d *Decoder) ExpectDCS(expect bool) {
+	var v int32
+	if expect {
+		v = 1
+	}
+	atomic.StoreInt32(&d.dcsExpected, v)
+}
+
+// Decode reads the next input and returns the events it contains, blocking
+// until there is some input. The events may be empty, for example if the
+// input only contained sequences the decoder doesn't know.
+//
+// Input is parsed as it's read, so escape sequences split across reads may
+// not be recognized. Bracketed pastes, unless streamed, and responses to
+// terminal queries are read until they're complete, as are UTF-8 encoded
+// runes.
+func (d *Decoder) Decode() ([]Event, error) {
+	for {
+		b, err := d.readBytes()
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		if len(d.pending) > 0 {
+			b = append(d.pending, b...)
+			d.pending = nil
+		}
+
+		n := incompleteRuneLen(b)
+		if i := bytes.LastIndex(b, []byte("\x1b[M")); n > 0 && i >= 0 {
+			switch {
+			case d.UTF8Mouse && utf8.RuneCount(b[i+3:len(b)-n]) < 3: //nolint:gomnd
+				// Mouse events in the UTF-8 extended encoding are held
+				// back whole until their last value is complete.
+				n = len(b) - i
+			case !d.UTF8Mouse && len(b)-n < i+6: //nolint:gomnd
+				// The values of X10 mouse events are single bytes,
+				// which may look like the beginning of a rune.
+				n = 0
+			}
+		}
+		if n == len(b) {
+			// Nothing but the beginning of a rune so far.
+			d.pending = b
+			continue
+		}
+		if n > 0 {
+			d.pending = append([]byte{}, b[len(b)-n:]...)
+			b = b[:len(b)-n]
+		}
+		events, err := d.parse(b)
+		if err != nil {
+			return nil, err
+		}
+		if !d.ReportUnknown {
+			events = dropUnknown(events)
+		}
+		d.trackMouse(events, now)
+		if d.Timestamps {
+			stamp(events, now)
+		}
+		if len(d.raw) > 0 {
+			events = append([]Event{RawInput(d.raw)}, events...)
+			d.raw = nil
+		}
+		return events, nil
+	}
+}
+
+// stamp sets the read time of the keys and mouse events among the events.
+func stamp(events []Event, now time.Time) {
+	for i, e := range events {
+		switch e := e.(type) {
+		case Key:
+			e.time = now
+			events[i] = e
+		case KeyRelease:
+			e.time = now
+			events[i] = e
+		case MouseEvent:
+			e.time = now
+			events[i] = e
+		}
+	}
+}
+
+// trackMouse sets the click counts and drags of the mouse events among the
+// events.
+func (d *Decoder) trackMouse(events []Event, now time.Time) {
+	interval := d.ClickInterval
+	if interval <= 0 {
+		interval = DefaultClickInterval
+	}
+	for i, e := range events {
+		if m, ok := e.(MouseEvent); ok {
+			events[i] = d.mouse.track(m, now, interval)
+		}
+	}
+}
+
+// Parse translates input which has been read in one go into events, like
+// Decoder.Decode does for each read. It fails if the input ends in the middle
+// of a bracketed paste or a response to a terminal query.
+func Parse(b []byte) ([]Event, error) {
+	d := NewDecoder(bytes.NewReader(nil))
+	events, err := d.parse(b)
+	if err != nil {
+		return nil, err
+	}
+	return dropUnknown(events), nil
+}
+
+// dropUnknown removes the UnknownSequence events from events.
+func dropUnknown(events []Event) []Event {
+	kept := events[:0]
+	for _, e := range events {
+		if _, ok := e.(UnknownSequence); !ok {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// incompleteRuneLen returns the length of the incomplete UTF-8 encoded rune
+// at the end of b, if any.
+func incompleteRuneLen(b []byte) int {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax+1; i-- {
+		c := b[i]
+		switch {
+		case c < utf8.RuneSelf:
+			return 0
+		case c&0xc0 == 0x80:
+			// continuation byte, keep looking for the leading byte
+			continue
+		}
+
+		var size int
+		switch {
+		case c&0xe0 == 0xc0:
+			size = 2
+		case c&0xf0 == 0xe0:
+			size = 3
+		case c&0xf8 == 0xf0:
+			size = 4
+		}
+		if n := len(b) - i; n < size {
+			return n
+		}
+		return 0
+	}
+	return 0
+}
+
+// readBytes reads the next input, blocking until there is some.
+func (d *Decoder) readBytes() ([]byte, error) {
+	size := d.BufferSize
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+	buf := make([]byte, size)
+
+	// Read and block
+	numBytes, err := rawReader{d}.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	b := buf[:numBytes]
+
+	if d.Throughput {
+		more := make([]byte, size)
+		for reads := 1; reads < maxReads && (numBytes == size || incompleteEscape(b)); reads++ {
+			numBytes, err = rawReader{d}.Read(more)
+			if err != nil {
+				// Process what we have; the error will come up again with
+				// the next read.
+				break
+			}
+			b = append(b, more[:numBytes]...)
+		}
+	}
+
+	return b, nil
+}
+
+// incompleteEscape reports whether the input ends in an incomplete CSI or SS3
+// escape sequence. A lone escape at the end is taken to be the escape key.
+func incompleteEscape(b []byte) bool {
+	i := bytes.LastIndexByte(b, '\x1b')
+	if i < 0 || i == len(b)-1 {
+		return false
+	}
+	seq := b[i:]
+
+	switch seq[1] {
+	case '[':
+		// X10 mouse events carry three bytes after the final byte.
+		if len(seq) >= 3 && seq[2] == 'M' {
+			return len(seq) < 6 //nolint:gomnd
+		}
+		for _, c := range seq[2:] {
+			if c >= 0x40 && c <= 0x7e {
+				return false
+			}
+		}
+		return true
+	case 'O':
+		return len(seq) < 3 //nolint:gomnd
+	}
+	return false
+}
+
+// parse translates the given input into events. If the input contains the
+// start of a bracketed paste, or of a response to a terminal query, more input
+// is read until it's complete.
+func (d *Decoder) parse(b []byte) ([]Event, error) {
+	var events []Event
+
+	if d.paste != nil {
+		e, rest, done, err := d.streamPaste(b)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e...)
+		if !done {
+			return events, nil
+		}
+		b = rest
+	}
+
+	// flush translates regular input preceding a paste or a response.
+	flush := func(b []byte) error {
+		if len(b) == 0 {
+			return nil
+		}
+		e, err := d.detectEvents(b)
+		if err != nil {
+			return err
+		}
+		events = append(events, e...)
+		return nil
+	}
+
+	start := 0
+	for i := 0; i < len(b); i++ {
+		if b[i] != '\x1b' {
+			continue
+		}
+
+		if bytes.HasPrefix(b[i:], bracketedPasteStart) {
+			if err := flush(b[start:i]); err != nil {
+				return nil, err
+			}
+
+			if d.PasteChunkSize > 0 {
+				d.paste = &pasteReader{limit: d.MaxPasteSize}
+				events = append(events, PasteStart{})
+				e, rest, done, err := d.streamPaste(b[i+len(bracketedPasteStart):])
+				if err != nil {
+					return nil, err
+				}
+				events = append(events, e...)
+				if !done {
+					return events, nil
+				}
+				b, start, i = rest, 0, -1
+				continue
+			}
+
+			content, rest, err := readBracketedPaste(rawReader{d}, b[i+len(bracketedPasteStart):], d.MaxPasteSize)
+			if err != nil {
+				return nil, err
+			}
+			if len(content) > 0 {
+				content, err = localereader.UTF8(content)
+				if err != nil {
+					return nil, err
+				}
+				events = append(events, Paste(content))
+			}
+
+			// Carry on with whatever followed the paste.
+			b, start, i = rest, 0, -1
+			continue
+		}
+
+		if isResponseStart(b[i:], atomic.LoadInt32(&d.dcsExpected) != 0) && !isResponseComplete(b[i:]) {
+			// The response has been split across reads.
+			rest, err := readUntil(rawReader{d}, b[i:], isResponseComplete)
+			if err != nil {
+				return nil, err
+			}
+			b = append(append([]byte{}, b[:i]...), rest...)
+		}
+
+		if e, n := parseTerminalResponse(b[i:]); n > 0 {
+			if err := flush(b[start:i]); err != nil {
+				return nil, err
+			}
+			events = append(events, e...)
+			start = i + n
+			i = start - 1
+		}
+	}
+
+	if err := flush(b[start:]); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// readUntil reads from input until what's been read, starting with b, is
+// complete, returning all of the input read, including b.
+func readUntil(input io.Reader, b []byte, complete func([]byte) bool) ([]byte, error) {
+	res := append([]byte{}, b...)
+	var buf [256]byte
+
+	for !complete(res) {
+		n, err := input.Read(buf[:])
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, buf[:n]...)
+	}
+	return res, nil
+}
@@ -0,0 +1,370 @@
+package input
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// chunkedReader returns the given chunks of input, one per call to Read.
+type chunkedReader struct {
+	chunks [][]byte
+}
+
+func (r *chunkedReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.chunks[0])
+	r.chunks = r.chunks[1:]
+	return n, nil
+}
+
+func TestDecoderSplitRunes(t *testing.T) {
+	tests := []struct {
+		name     string
+		chunks   []string
+		expected [][]string
+	}{
+		{
+			name:     "whole runes",
+			chunks:   []string{"日本"},
+			expected: [][]string{{"日", "本"}},
+		},
+		{
+			name:     "split rune",
+			chunks:   []string{"a\xe6\x97", "\xa5b"},
+			expected: [][]string{{"a"}, {"日", "b"}},
+		},
+		{
+			name:     "rune split byte by byte",
+			chunks:   []string{"\xf0", "\x9f", "\x98", "\x80"},
+			expected: [][]string{{"😀"}},
+		},
+		{
+			name:     "split after a sequence",
+			chunks:   []string{"\x1b[A\xe6", "\x97\xa5"},
+			expected: [][]string{{"up"}, {"日"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &chunkedReader{}
+			for _, c := range test.chunks {
+				r.chunks = append(r.chunks, []byte(c))
+			}
+
+			d := NewDecoder(r)
+			for _, expected := range test.expected {
+				events, err := d.Decode()
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				var keys []string
+				for _, e := range events {
+					keys = append(keys, e.(Key).String())
+				}
+				if !reflect.DeepEqual(keys, expected) {
+					t.Errorf("expected keys %q, got %q", expected, keys)
+				}
+			}
+			if len(d.pending) != 0 || len(r.chunks) != 0 {
+				t.Errorf("expected all input to be consumed, %q pending", d.pending)
+			}
+		})
+	}
+}
+
+func TestIncompleteRuneLen(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected int
+	}{
+		{"", 0},
+		{"a", 0},
+		{"日", 0},
+		{"\xe6", 1},
+		{"\xe6\x97", 2},
+		{"a\xf0\x9f\x98", 3},
+		{"\xf0\x9f\x98\x80", 0},
+		{"\x97\xa5", 0},
+	}
+
+	for _, test := range tests {
+		if got := incompleteRuneLen([]byte(test.in)); got != test.expected {
+			t.Errorf("expected %d for %q, got %d", test.expected, test.in, got)
+		}
+	}
+}
+
+func TestIncompleteEscape(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected bool
+	}{
+		{"a", false},
+		{"\x1b", false},
+		{"\x1b[", true},
+		{"\x1b[1;5", true},
+		{"\x1b[1;5A", false},
+		{"\x1b[M ", true},
+		{"\x1b[M !!", false},
+		{"\x1bO", true},
+		{"\x1bOA", false},
+		{"a\x1ba", false},
+	}
+
+	for _, test := range tests {
+		if got := incompleteEscape([]byte(test.in)); got != test.expected {
+			t.Errorf("expected %v for %q, got %v", test.expected, test.in, got)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	events, err := Parse([]byte("\x1b[A\x1b[200~hi\x1b[201~\x1b[M !!\x1bP1$r0m\x1b\\"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Event{
+		Key{Type: KeyUp},
+		Paste("hi"),
+		MouseEvent{Type: MouseLeft},
+		StatusString{Value: "0m", OK: true},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected events %#v, got %#v", expected, events)
+	}
+
+	if _, err := Parse([]byte("\x1b[200~unterminated")); err == nil {
+		t.Error("expected an error for an unterminated paste")
+	}
+}
+
+func TestParseSpecialKeys(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected Key
+	}{
+		{"\x1b[1;2P", Key{Type: KeyF13}},
+		{"\x1b[1;4S", Key{Type: KeyF16, Alt: true}},
+		{"\x1b[19;2~", Key{Type: KeyF20}},
+		{"\x1b[20;2~", Key{Type: KeyF21}},
+		{"\x1b[24;2~", Key{Type: KeyF24}},
+		{"\x1b[24;4~", Key{Type: KeyF24, Alt: true}},
+		{"\x1b[23$", Key{Type: KeyF21}},
+		{"\x1bOM", Key{Type: KeyKPEnter}},
+		{"\x1bOp", Key{Type: KeyKP0}},
+		{"\x1bOk", Key{Type: KeyKPPlus}},
+	}
+	for _, test := range tests {
+		t.Run(test.expected.String(), func(t *testing.T) {
+			events, err := Parse([]byte(test.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(events, []Event{test.expected}) {
+				t.Errorf("expected %#v, got %#v", test.expected, events)
+			}
+		})
+	}
+}
+
+func TestParseMixedMouseEncodings(t *testing.T) {
+	events, err := Parse([]byte("\x1b[32;250;100M\x1b[35;250;100Ma"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Event{
+		MouseEvent{X: 249, Y: 99, Type: MouseLeft},
+		MouseEvent{X: 249, Y: 99, Type: MouseRelease},
+		Key{Type: KeyRunes, Runes: []rune{'a'}},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected events %#v, got %#v", expected, events)
+	}
+}
+
+func TestDecoderSplitUTF8MouseEvent(t *testing.T) {
+	// A click at column 300 in the UTF-8 extended encoding, split in the
+	// middle of the encoded column.
+	r := &chunkedReader{chunks: [][]byte{[]byte("\x1b[M \xc5"), []byte("\x8d!")}}
+	d := NewDecoder(r)
+	d.UTF8Mouse = true
+
+	events, err := d.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected a single event, got %#v", events)
+	}
+	if m, ok := events[0].(MouseEvent); !ok || m.X != 300 || m.Y != 0 || m.Type != MouseLeft {
+		t.Errorf("expected a left click at 300,0, got %#v", events[0])
+	}
+}
+
+func TestDecoderX10MouseEventHighByte(t *testing.T) {
+	// A click at row 195 in the X10 encoding, whose last byte looks like the
+	// beginning of a rune, followed by a key in the next read.
+	r := &chunkedReader{chunks: [][]byte{[]byte("\x1b[M !\xe3"), []byte("a")}}
+	d := NewDecoder(r)
+
+	events, err := d.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Event{MouseEvent{
+		X: 0, Y: 194, Type: MouseLeft,
+		ClickCount: 1, HeldButtons: MouseButtons(0).with(MouseButtonLeft),
+	}}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected the event right away, got %#v", events)
+	}
+}
+
+func TestDecoderSplitColorResponse(t *testing.T) {
+	r := &chunkedReader{chunks: [][]byte{[]byte("\x1b]11;rgb:1e1e/"), []byte("1e1e/2e2e\x07a")}}
+	d := NewDecoder(r)
+
+	events, err := d.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Event{
+		BackgroundColor("#1e1e2e"),
+		Key{Type: KeyRunes, Runes: []rune{'a'}},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected events %#v, got %#v", expected, events)
+	}
+}
+
+func TestDecoderExpectDCS(t *testing.T) {
+	chunks := func() [][]byte {
+		return [][]byte{[]byte("\x1bP1$r0"), []byte("m\x1b\\")}
+	}
+
+	// Split responses are read in full while they're expected.
+	d := NewDecoder(&chunkedReader{chunks: chunks()})
+	d.ExpectDCS(true)
+	events, err := d.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []Event{StatusString{Value: "0m", OK: true}}; !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected events %#v, got %#v", expected, events)
+	}
+
+	// Otherwise, what looks like the start of one is taken for keys.
+	d = NewDecoder(&chunkedReader{chunks: chunks()})
+	events, err = d.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) == 0 {
+		t.Fatal("expected events")
+	}
+	if k, ok := events[0].(Key); !ok || k.String() != "alt+P" {
+		t.Errorf("expected alt+P, got %#v", events)
+	}
+}
+
+func TestDecoderThroughput(t *testing.T) {
+	r := &chunkedReader{chunks: [][]byte{[]byte("\x1b["), []byte("B")}}
+	d := NewDecoder(r)
+	d.Throughput = true
+
+	events, err := d.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []Event{Key{Type: KeyDown}}; !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected events %#v, got %#v", expected, events)
+	}
+	if _, err := d.Decode(); err != io.EOF {
+		t.Errorf("expected EOF, got %v", err)
+	}
+}
+
+func TestDecoderReportUnknown(t *testing.T) {
+	in := "\x1bPxyz\x1b\\\x1b[99;99z"
+	tests := []struct {
+		name     string
+		unknown  bool
+		expected []Event
+	}{
+		{
+			"dropped",
+			false,
+			[]Event{},
+		},
+		{
+			"reported",
+			true,
+			[]Event{UnknownSequence("\x1bPxyz\x1b\\"), UnknownSequence("\x1b[99;99z")},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := NewDecoder(strings.NewReader(in))
+			d.ReportUnknown = test.unknown
+			events, err := d.Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(events, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, events)
+			}
+		})
+	}
+}
+
+func TestDecoderReportRaw(t *testing.T) {
+	in := "\x1b[A\x1b[200~hi\x1b[201~"
+	d := NewDecoder(&chunkedReader{chunks: [][]byte{[]byte(in[:10]), []byte(in[10:])}})
+	d.ReportRaw = true
+	events, err := d.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Event{RawInput(in), Key{Type: KeyUp}, Paste("hi")}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected %#v, got %#v", expected, events)
+	}
+}
+
+func TestDecoderTimestamps(t *testing.T) {
+	before := time.Now()
+	d := NewDecoder(&chunkedReader{chunks: [][]byte{[]byte("a"), []byte("\x1b[M !!")}})
+	d.Timestamps = true
+	var times []time.Time
+	for i := 0; i < 2; i++ {
+		events, err := d.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch e := events[0].(type) {
+		case Key:
+			times = append(times, e.Time())
+		case MouseEvent:
+			times = append(times, e.Time())
+		}
+	}
+	if len(times) != 2 || times[0].Before(before) || times[1].Before(times[0]) {
+		t.Errorf("expected a key and a mouse event read in order after %v, got %v", before, times)
+	}
+
+	d = NewDecoder(strings.NewReader("a"))
+	events, err := d.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !events[0].(Key).Time().IsZero() {
+		t.Error("expected no time without timestamps")
+	}
+}
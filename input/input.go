@@ -0,0 +1,35 @@
+// Package input parses the input Bubble Tea programs receive from terminals:
+// keys, mouse events, bracketed pastes and responses to terminal queries. It
+// doesn't depend on the rest of Bubble Tea, so tools which need to make sense
+// of terminal input, such as terminal recorders and test harnesses, can use
+// it on their own.
+//
+// A Decoder reads events from a stream of input:
+//
+//	d := input.NewDecoder(os.Stdin)
+//	for {
+//	    events, err := d.Decode()
+//	    if err != nil {
+//	        return err
+//	    }
+//	    for _, e := range events {
+//	        switch e := e.(type) {
+//	        case input.Key:
+//	            fmt.Println("key:", e)
+//	        case input.MouseEvent:
+//	            fmt.Println("mouse:", e, e.X, e.Y)
+//	        }
+//	    }
+//	}
+//
+// The terminal must be in raw mode for keys to be sent as they're pressed.
+// Mouse events, bracketed pastes and so on are only sent once enabled with
+// the respective escape sequences. This package only parses input; it never
+// writes to the terminal.
+package input
+
+// Event is an input event: a Key, KeyRelease, Paste, MouseEvent or
+// MouseHighlight, or a response to a terminal query, such as
+// PrimaryDeviceAttributes, Termcap, StatusString or ModeReport. Decoders can
+// also report UnknownSequence and RawInput events.
+type Event interface{}
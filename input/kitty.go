@@ -0,0 +1,234 @@
+package input
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// KeyRelease is sent when a key is released. Terminals only report releases
+// when asked to with the kitty keyboard protocol.
+type KeyRelease Key
+
+// String returns a string representation of the released key, like
+// Key.String.
+func (k KeyRelease) String() string {
+	return Key(k).String()
+}
+
+// Modifier bits of the kitty keyboard protocol. The modifier parameter is
+// one plus these bits.
+const (
+	kittyShift = 1 << iota
+	kittyAlt
+	kittyCtrl
+)
+
+// Event types of the kitty keyboard protocol.
+const (
+	kittyPress   = 1
+	kittyRepeat  = 2
+	kittyRelease = 3
+)
+
+// parseKittyKey parses a key event in the format of the kitty keyboard
+// protocol, CSI code[:alternates];modifiers[:event] u, as well as legacy key
+// sequences which carry an event type. It returns the event and the length
+// of the sequence, or a length of zero if b doesn't start with such a
+// sequence.
+func parseKittyKey(b []byte) (Event, int) {
+	if !bytes.HasPrefix(b, []byte("\x1b[")) {
+		return nil, 0
+	}
+	i := 2
+	for i < len(b) && (b[i] >= '0' && b[i] <= '9' || b[i] == ';' || b[i] == ':') {
+		i++
+	}
+	if i == 2 || i >= len(b) {
+		return nil, 0
+	}
+	final := b[i]
+	params := strings.Split(string(b[2:i]), ";")
+
+	mods, event := 1, kittyPress
+	if len(params) > 1 {
+		m := strings.SplitN(params[1], ":", 2) //nolint:gomnd
+		if n, err := strconv.Atoi(m[0]); err == nil {
+			mods = n
+		}
+		if len(m) > 1 {
+			if n, err := strconv.Atoi(m[1]); err == nil {
+				event = n
+			}
+		}
+	}
+
+	var k Key
+	switch final {
+	case 'u':
+		var ok bool
+		k, ok = kittyKey(params[0], mods-1)
+		if !ok {
+			// Keys we don't have a representation for, such as modifier
+			// keys on their own, are dropped.
+			return nil, i + 1
+		}
+
+	case 'A', 'B', 'C', 'D', 'H', 'F', '~':
+		// Legacy sequences only need parsing here if they carry an event
+		// type. Strip it, along with modifiers if there are none, and look
+		// the sequence up.
+		if len(params) < 2 || !strings.Contains(params[1], ":") {
+			return nil, 0
+		}
+		seq := "\x1b[" + params[0] + ";" + strconv.Itoa(mods)
+		if mods == 1 {
+			seq = "\x1b["
+			if final == '~' {
+				seq += params[0]
+			}
+		}
+		var ok bool
+		k, ok = sequences[seq+string(final)]
+		if !ok {
+			return nil, i + 1
+		}
+
+	default:
+		return nil, 0
+	}
+
+	switch event {
+	case kittyRepeat:
+		k.Action = KeyActionRepeat
+	case kittyRelease:
+		k.Action = KeyActionRelease
+		return KeyRelease(k), i + 1
+	}
+	return k, i + 1
+}
+
+// kittyFunctionalKeys maps the codes of functional keys without a legacy
+// equivalent, which the kitty keyboard protocol encodes in the private use
+// area, to key types. Keys not listed, such as modifier keys on their own,
+// are dropped.
+var kittyFunctionalKeys = map[int]KeyType{
+	57361: KeyPrintScreen,
+	57363: KeyMenu,
+	57376: KeyF13,
+	57377: KeyF14,
+	57378: KeyF15,
+	57379: KeyF16,
+	57380: KeyF17,
+	57381: KeyF18,
+	57382: KeyF19,
+	57383: KeyF20,
+	57384: KeyF21,
+	57385: KeyF22,
+	57386: KeyF23,
+	57387: KeyF24,
+	57399: KeyKP0,
+	57400: KeyKP1,
+	57401: KeyKP2,
+	57402: KeyKP3,
+	57403: KeyKP4,
+	57404: KeyKP5,
+	57405: KeyKP6,
+	57406: KeyKP7,
+	57407: KeyKP8,
+	57408: KeyKP9,
+	57409: KeyKPDecimal,
+	57410: KeyKPDivide,
+	57411: KeyKPMultiply,
+	57412: KeyKPMinus,
+	57413: KeyKPPlus,
+	57414: KeyKPEnter,
+	57415: KeyKPEqual,
+	57416: KeyKPComma,
+	57417: KeyLeft,
+	57418: KeyRight,
+	57419: KeyUp,
+	57420: KeyDown,
+	57421: KeyPgUp,
+	57422: KeyPgDown,
+	57423: KeyHome,
+	57424: KeyEnd,
+	57425: KeyInsert,
+	57426: KeyDelete,
+	57427: KeyKPBegin,
+	57428: KeyMediaPlay,
+	57429: KeyMediaPause,
+	57430: KeyMediaPlayPause,
+	57432: KeyMediaStop,
+	57433: KeyMediaFastForward,
+	57434: KeyMediaRewind,
+	57435: KeyMediaNext,
+	57436: KeyMediaPrev,
+	57437: KeyMediaRecord,
+	57438: KeyVolumeDown,
+	57439: KeyVolumeUp,
+	57440: KeyVolumeMute,
+}
+
+// kittyKey translates a key code, with optional alternate codes, and
+// modifier bits into a Key.
+func kittyKey(codes string, mods int) (Key, bool) {
+	c := strings.Split(codes, ":")
+	code, err := strconv.Atoi(c[0])
+	if err != nil {
+		return Key{}, false
+	}
+	alt := mods&kittyAlt != 0
+
+	switch code {
+	case int(keyCR):
+		return Key{Type: KeyEnter, Alt: alt}, true
+	case int(keyHT):
+		if mods&kittyShift != 0 {
+			return Key{Type: KeyShiftTab, Alt: alt}, true
+		}
+		return Key{Type: KeyTab, Alt: alt}, true
+	case int(keyESC):
+		return Key{Type: KeyEsc, Alt: alt}, true
+	case int(keyDEL), int(keyBS):
+		return Key{Type: KeyBackspace, Alt: alt}, true
+	}
+
+	if t, ok := kittyFunctionalKeys[code]; ok {
+		return Key{Type: t, Alt: alt}, true
+	}
+
+	r := rune(code)
+	if !unicode.IsPrint(r) || (r >= 0xe000 && r <= 0xf8ff) {
+		// Functional keys without a legacy equivalent are encoded in the
+		// private use area.
+		return Key{}, false
+	}
+
+	if mods&kittyCtrl != 0 {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return Key{Type: KeyType(r-'a') + KeyCtrlA, Alt: alt}, true
+		case r == '@' || r == ' ':
+			return Key{Type: KeyCtrlAt, Alt: alt}, true
+		case r >= '[' && r <= '_':
+			return Key{Type: KeyType(r-'[') + KeyCtrlOpenBracket, Alt: alt}, true
+		}
+	}
+
+	if mods&kittyShift != 0 {
+		if len(c) > 1 && c[1] != "" {
+			if shifted, err := strconv.Atoi(c[1]); err == nil {
+				r = rune(shifted)
+			}
+		} else {
+			r = unicode.ToUpper(r)
+		}
+	}
+
+	if r == ' ' {
+		return Key{Type: KeySpace, Runes: []rune{r}, Alt: alt}, true
+	}
+	return Key{Type: KeyRunes, Runes: []rune{r}, Alt: alt}, true
+}
@@ -0,0 +1,52 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKittyKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		expected Event
+		n        int
+	}{
+		{"rune", "\x1b[97u", Key{Type: KeyRunes, Runes: []rune("a")}, 5},
+		{"shift", "\x1b[97;2u", Key{Type: KeyRunes, Runes: []rune("A")}, 7},
+		{"shifted alternate", "\x1b[49:33;2u", Key{Type: KeyRunes, Runes: []rune("!")}, 10},
+		{"alt", "\x1b[97;3u", Key{Type: KeyRunes, Runes: []rune("a"), Alt: true}, 7},
+		{"ctrl", "\x1b[105;5u", Key{Type: KeyCtrlI}, 8},
+		{"ctrl bracket", "\x1b[93;5u", Key{Type: KeyCtrlCloseBracket}, 7},
+		{"escape", "\x1b[27u", Key{Type: KeyEsc}, 5},
+		{"enter", "\x1b[13u", Key{Type: KeyEnter}, 5},
+		{"shift tab", "\x1b[9;2u", Key{Type: KeyShiftTab}, 6},
+		{"space", "\x1b[32u", Key{Type: KeySpace, Runes: []rune(" ")}, 5},
+		{"repeat", "\x1b[97;1:2u", Key{Type: KeyRunes, Runes: []rune("a"), Action: KeyActionRepeat}, 9},
+		{"release", "\x1b[97;1:3u", KeyRelease{Type: KeyRunes, Runes: []rune("a"), Action: KeyActionRelease}, 9},
+		{"legacy release", "\x1b[1;1:3A", KeyRelease{Type: KeyUp, Action: KeyActionRelease}, 8},
+		{"legacy modified", "\x1b[1;5:1C", Key{Type: KeyCtrlRight}, 8},
+		{"legacy tilde release", "\x1b[3;1:3~", KeyRelease{Type: KeyDelete, Action: KeyActionRelease}, 8},
+		{"f13", "\x1b[57376u", Key{Type: KeyF13}, 8},
+		{"media key", "\x1b[57430u", Key{Type: KeyMediaPlayPause}, 8},
+		{"alt menu", "\x1b[57363;3u", Key{Type: KeyMenu, Alt: true}, 10},
+		{"keypad enter", "\x1b[57414u", Key{Type: KeyKPEnter}, 8},
+		{"keypad left", "\x1b[57417u", Key{Type: KeyLeft}, 8},
+		{"modifier key", "\x1b[57441;2u", nil, 10},
+		{"legacy without event", "\x1b[1;5A", nil, 0},
+		{"not a key", "\x1b[12;5R", nil, 0},
+		{"incomplete", "\x1b[97", nil, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			msg, n := parseKittyKey([]byte(test.in))
+			if n != test.n {
+				t.Errorf("expected length %d, got %d", test.n, n)
+			}
+			if !reflect.DeepEqual(msg, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,523 @@
+package input
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MouseEvent represents a mouse event, which could be a click, a scroll wheel
+// movement, a cursor movement, or a combination.
+type MouseEvent struct {
+	X    int
+	Y    int
+	Type MouseEventType
+	Alt  bool
+	Ctrl bool
+
+	// ClickCount is the number of rapid successive presses of the same
+	// button at the same position, up to and including this one: 2 for a
+	// double click, 3 for a triple click, and so on. It's set for button
+	// presses read by a Decoder, and zero otherwise.
+	ClickCount int
+
+	// Dragging is set when the mouse moves while a button is held, and Drag
+	// then describes the motion. The event's type is the held button. Like
+	// ClickCount, they're only set by a Decoder, and only reported by
+	// terminals with mouse motion tracking enabled.
+	Dragging bool
+	Drag     Drag
+
+	// HeldButtons holds the buttons held after the event, so that chords of
+	// several buttons can be recognized. Terminals report a release without
+	// telling which button was released, so any release is taken to release
+	// all buttons. Like ClickCount, it's only set by a Decoder.
+	HeldButtons MouseButtons
+
+	// Repeat is set on the copies of a press Bubble Tea programs send while
+	// the button is held in a repeat zone, see tea.RegisterRepeatZone. The
+	// decoder doesn't set it.
+	Repeat bool
+
+	// when the event was read, see Time
+	time time.Time
+
+	// the ids of the zones the event hit, see Zones, each preceded by its
+	// length and a colon, so that events stay comparable
+	zones string
+}
+
+// Time returns when the event was read, if it was read by a Decoder with
+// Timestamps set, like Key.Time. It's zero otherwise.
+func (m MouseEvent) Time() time.Time {
+	return m.time
+}
+
+// Zones returns the ids of the zones the event hit. Bubble Tea programs set
+// them according to the layers of a tea.LayeredModel and the zones registered
+// with tea.RegisterZone; the decoder doesn't.
+func (m MouseEvent) Zones() []string {
+	var ids []string
+	for z := m.zones; z != ""; {
+		i := strings.IndexByte(z, ':')
+		n, _ := strconv.Atoi(z[:i])
+		ids = append(ids, z[i+1:i+1+n])
+		z = z[i+1+n:]
+	}
+	return ids
+}
+
+// WithZones returns a copy of the event listing the given zone ids, see
+// Zones.
+func (m MouseEvent) WithZones(ids ...string) MouseEvent {
+	var b strings.Builder
+	for _, id := range ids {
+		b.WriteString(strconv.Itoa(len(id)))
+		b.WriteByte(':')
+		b.WriteString(id)
+	}
+	m.zones = b.String()
+	return m
+}
+
+// isPress reports whether the event is a button press.
+func (m MouseEvent) isPress() bool {
+	return m.Type == MouseLeft || m.Type == MouseMiddle || m.Type == MouseRight
+}
+
+// DefaultClickInterval is the longest time between presses which count as
+// successive clicks, unless set with Decoder.ClickInterval.
+const DefaultClickInterval = 500 * time.Millisecond
+
+// Drag describes the motion of the mouse while a button is held.
+type Drag struct {
+	// StartX and StartY are where the button was pressed.
+	StartX int `json:"startX"`
+	StartY int `json:"startY"`
+
+	// DX and DY are how far the mouse has moved since the button was
+	// pressed.
+	DX int `json:"dx"`
+	DY int `json:"dy"`
+}
+
+// mouseTracker keeps track of mouse buttons across events, to count clicks,
+// recognize drags and report which buttons are held.
+type mouseTracker struct {
+	// the last press, and when it happened
+	last MouseEvent
+	time time.Time
+
+	clicks int
+
+	// the presses of the buttons which are still held, in the order they
+	// were pressed
+	held []MouseEvent
+}
+
+// track sets the click count of button presses, the drag of motion while a
+// button is held and the buttons held, and returns the event.
+func (t *mouseTracker) track(m MouseEvent, now time.Time, interval time.Duration) MouseEvent {
+	switch press := t.press(m.Type); {
+	case !m.isPress():
+		// Releases, and motion without a button, are only reported when no
+		// button is held. Releases don't tell which button was released,
+		// so they end chords.
+		if m.Type == MouseRelease || m.Type == MouseMotion {
+			t.held = nil
+		}
+
+	case press != nil:
+		// The terminal reports motion while a button is held as further
+		// presses of that button.
+		m.Dragging = true
+		m.Drag = Drag{
+			StartX: press.X,
+			StartY: press.Y,
+			DX:     m.X - press.X,
+			DY:     m.Y - press.Y,
+		}
+
+	default:
+		if t.clicks > 0 && m.Type == t.last.Type && m.X == t.last.X && m.Y == t.last.Y &&
+			now.Sub(t.time) <= interval {
+			t.clicks++
+		} else {
+			t.clicks = 1
+		}
+		m.ClickCount = t.clicks
+		t.last, t.time = m, now
+		t.held = append(t.held, m)
+	}
+
+	for _, press := range t.held {
+		m.HeldButtons = m.HeldButtons.with(press.Button())
+	}
+	return m
+}
+
+// press returns the press of the given button if it's held, or nil.
+func (t *mouseTracker) press(typ MouseEventType) *MouseEvent {
+	for i := range t.held {
+		if t.held[i].Type == typ {
+			return &t.held[i]
+		}
+	}
+	return nil
+}
+
+// String returns a string representation of a mouse event.
+func (m MouseEvent) String() (s string) {
+	if m.Ctrl {
+		s += "ctrl+"
+	}
+	if m.Alt {
+		s += "alt+"
+	}
+	s += mouseEventTypes[m.Type]
+	return s
+}
+
+// MouseEventType indicates the type of mouse event occurring.
+type MouseEventType int
+
+// Mouse event types.
+const (
+	MouseUnknown MouseEventType = iota
+	MouseLeft
+	MouseRight
+	MouseMiddle
+	MouseRelease
+	MouseWheelUp
+	MouseWheelDown
+	MouseMotion
+)
+
+var mouseEventTypes = map[MouseEventType]string{
+	MouseUnknown:   "unknown",
+	MouseLeft:      "left",
+	MouseRight:     "right",
+	MouseMiddle:    "middle",
+	MouseRelease:   "release",
+	MouseWheelUp:   "wheel up",
+	MouseWheelDown: "wheel down",
+	MouseMotion:    "motion",
+}
+
+// MouseButton is a mouse button, or a direction of the wheel.
+type MouseButton int
+
+// Mouse buttons.
+const (
+	MouseButtonNone MouseButton = iota
+	MouseButtonLeft
+	MouseButtonMiddle
+	MouseButtonRight
+	MouseButtonWheelUp
+	MouseButtonWheelDown
+)
+
+var mouseButtons = map[MouseButton]string{
+	MouseButtonNone:      "none",
+	MouseButtonLeft:      "left",
+	MouseButtonMiddle:    "middle",
+	MouseButtonRight:     "right",
+	MouseButtonWheelUp:   "wheel up",
+	MouseButtonWheelDown: "wheel down",
+}
+
+// String returns the name of the button.
+func (b MouseButton) String() string {
+	return mouseButtons[b]
+}
+
+// MouseButtons is a set of mouse buttons, such as the buttons held during a
+// mouse event.
+type MouseButtons uint8
+
+// Has reports whether the set holds the given button.
+func (b MouseButtons) Has(button MouseButton) bool {
+	return button != MouseButtonNone && b&(1<<button) != 0
+}
+
+// List returns the buttons in the set, in the order of the MouseButton
+// constants, or nil if it's empty.
+func (b MouseButtons) List() []MouseButton {
+	var buttons []MouseButton
+	for button := MouseButtonLeft; button <= MouseButtonWheelDown; button++ {
+		if b.Has(button) {
+			buttons = append(buttons, button)
+		}
+	}
+	return buttons
+}
+
+// with returns the set with the given button added.
+func (b MouseButtons) with(button MouseButton) MouseButtons {
+	if button == MouseButtonNone {
+		return b
+	}
+	return b | 1<<button
+}
+
+// MouseAction is what a mouse event reports the mouse did.
+type MouseAction int
+
+// Mouse actions. The zero value isn't an action events report.
+const (
+	MouseActionPress MouseAction = iota + 1
+	MouseActionRelease
+	MouseActionMotion
+)
+
+var mouseActions = map[MouseAction]string{
+	MouseActionPress:   "press",
+	MouseActionRelease: "release",
+	MouseActionMotion:  "motion",
+}
+
+// String returns the name of the action.
+func (a MouseAction) String() string {
+	return mouseActions[a]
+}
+
+// Button returns the button the event reports. Releases report no button, as
+// terminals don't tell which button was released.
+func (m MouseEvent) Button() MouseButton {
+	switch m.Type {
+	case MouseLeft:
+		return MouseButtonLeft
+	case MouseMiddle:
+		return MouseButtonMiddle
+	case MouseRight:
+		return MouseButtonRight
+	case MouseWheelUp:
+		return MouseButtonWheelUp
+	case MouseWheelDown:
+		return MouseButtonWheelDown
+	}
+	return MouseButtonNone
+}
+
+// Action returns the action the event reports. Turning the wheel is reported
+// as a press, and drags as motion.
+func (m MouseEvent) Action() MouseAction {
+	switch {
+	case m.Type == MouseRelease:
+		return MouseActionRelease
+	case m.Type == MouseMotion || m.Dragging:
+		return MouseActionMotion
+	}
+	return MouseActionPress
+}
+
+// Parse X10-encoded mouse events; the simplest kind. The last release of X10
+// was December 1986, by the way.
+//
+// X10 mouse events look like:
+//
+//	ESC [M Cb Cx Cy
+//
+// Each value is a single byte, offset by 32, so coordinates beyond 223 can't
+// be reported and overflow. Events in xterm's UTF-8 extended encoding, which
+// terminals use when enabled with mode 1005, look the same, except that the
+// values are UTF-8 encoded characters, so coordinates up to 2015 can be
+// reported. Events of three bytes are the same in both encodings; longer ones
+// are decoded as UTF-8.
+//
+// See: http://www.xfree86.org/current/ctlseqs.html#Mouse%20Tracking
+func parseX10MouseEvents(buf []byte) ([]MouseEvent, error) {
+	var r []MouseEvent
+
+	seq := []byte("\x1b[M")
+	if !bytes.Contains(buf, seq) {
+		return r, errors.New("not an X10 mouse event")
+	}
+
+	for _, v := range bytes.Split(buf, seq) {
+		if len(v) == 0 {
+			continue
+		}
+		values, ok := mouseValues(v)
+		if !ok {
+			return r, errors.New("not an X10 mouse event")
+		}
+
+		const byteOffset = 32
+		m := mouseButton(byte(values[0] - byteOffset))
+
+		// (1,1) is the upper left. We subtract 1 to normalize it to (0,0).
+		m.X = values[1] - byteOffset - 1
+		m.Y = values[2] - byteOffset - 1
+
+		r = append(r, m)
+	}
+
+	return r, nil
+}
+
+// mouseValues decodes the button and coordinates of an X10 mouse event, which
+// are single bytes, or of a UTF-8 extended one, which are UTF-8 encoded.
+func mouseValues(v []byte) ([3]int, bool) {
+	var values [3]int
+	if len(v) == len(values) {
+		for i, c := range v {
+			values[i] = int(c)
+		}
+		return values, true
+	}
+
+	for i := range values {
+		c, n := utf8.DecodeRune(v)
+		if c == utf8.RuneError {
+			return values, false
+		}
+		values[i] = int(c)
+		v = v[n:]
+	}
+	return values, len(v) == 0
+}
+
+// mouseButton decodes the button and modifiers of a mouse event, which are
+// encoded alike in X10 and urxvt mouse events.
+func mouseButton(e byte) MouseEvent {
+	var m MouseEvent
+
+	const (
+		bitShift  = 0b0000_0100
+		bitAlt    = 0b0000_1000
+		bitCtrl   = 0b0001_0000
+		bitMotion = 0b0010_0000
+		bitWheel  = 0b0100_0000
+
+		bitsMask = 0b0000_0011
+
+		bitsLeft    = 0b0000_0000
+		bitsMiddle  = 0b0000_0001
+		bitsRight   = 0b0000_0010
+		bitsRelease = 0b0000_0011
+
+		bitsWheelUp   = 0b0000_0000
+		bitsWheelDown = 0b0000_0001
+	)
+
+	if e&bitWheel != 0 {
+		// Check the low two bits.
+		switch e & bitsMask {
+		case bitsWheelUp:
+			m.Type = MouseWheelUp
+		case bitsWheelDown:
+			m.Type = MouseWheelDown
+		}
+	} else {
+		// Check the low two bits.
+		// We do not separate clicking and dragging.
+		switch e & bitsMask {
+		case bitsLeft:
+			m.Type = MouseLeft
+		case bitsMiddle:
+			m.Type = MouseMiddle
+		case bitsRight:
+			m.Type = MouseRight
+		case bitsRelease:
+			if e&bitMotion != 0 {
+				m.Type = MouseMotion
+			} else {
+				m.Type = MouseRelease
+			}
+		}
+	}
+
+	if e&bitAlt != 0 {
+		m.Alt = true
+	}
+	if e&bitCtrl != 0 {
+		m.Ctrl = true
+	}
+	return m
+}
+
+// parseURxvtMouseEvent parses a mouse event in urxvt's extended encoding,
+// which rxvt-unicode uses when enabled with mode 1015:
+//
+//	ESC [ Cb ; Cx ; Cy M
+//
+// Unlike in X10 events, the values are decimal numbers, so coordinates
+// beyond 223 can be reported. It returns the event and the length of the
+// sequence, or a length of zero if b doesn't start with such an event.
+func parseURxvtMouseEvent(b []byte) (MouseEvent, int) {
+	if !bytes.HasPrefix(b, []byte("\x1b[")) {
+		return MouseEvent{}, 0
+	}
+	i := 2
+	for i < len(b) && (b[i] >= '0' && b[i] <= '9' || b[i] == ';') {
+		i++
+	}
+	if i >= len(b) || b[i] != 'M' {
+		return MouseEvent{}, 0
+	}
+	const byteOffset = 32
+	params := parseParams(b[2:i])
+	if len(params) != 3 || params[0] < byteOffset { //nolint:gomnd
+		return MouseEvent{}, 0
+	}
+
+	m := mouseButton(byte(params[0] - byteOffset))
+
+	// (1,1) is the upper left. We subtract 1 to normalize it to (0,0).
+	m.X = params[1] - 1
+	m.Y = params[2] - 1
+	return m, i + 1
+}
+
+// MouseHighlight is sent when the user releases the mouse button after
+// highlighting text while xterm's mouse highlight tracking is enabled.
+// Coordinates are zero-based, like those of MouseEvent. If the user clicked
+// without dragging, the start and end of the highlight are the same.
+type MouseHighlight struct {
+	// The start and end of the highlighted text.
+	StartX, StartY int
+	EndX, EndY     int
+
+	// The position of the mouse when the button was released.
+	X, Y int
+}
+
+// parseHighlightResponse parses the report the terminal sends when the mouse
+// button is released during highlight tracking: CSI t Cx Cy if nothing has
+// been highlighted, or CSI T followed by the start, end and mouse positions
+// otherwise. Like X10 mouse events, each coordinate is a single byte. It
+// returns the number of bytes consumed, which is zero if b doesn't start with
+// a complete report.
+func parseHighlightResponse(b []byte) (MouseHighlight, int) {
+	const byteOffset = 32 + 1 // X10 offset, plus 1-based coordinates
+
+	coord := func(c byte) int {
+		return int(c) - byteOffset
+	}
+
+	if len(b) < 5 || b[0] != '\x1b' || b[1] != '[' { //nolint:gomnd
+		return MouseHighlight{}, 0
+	}
+
+	switch b[2] {
+	case 't':
+		x, y := coord(b[3]), coord(b[4])
+		return MouseHighlight{StartX: x, StartY: y, EndX: x, EndY: y, X: x, Y: y}, 5 //nolint:gomnd
+
+	case 'T':
+		if len(b) < 9 { //nolint:gomnd
+			return MouseHighlight{}, 0
+		}
+		return MouseHighlight{
+			StartX: coord(b[3]), StartY: coord(b[4]),
+			EndX: coord(b[5]), EndY: coord(b[6]),
+			X: coord(b[7]), Y: coord(b[8]),
+		}, 9 //nolint:gomnd
+	}
+
+	return MouseHighlight{}, 0
+}
@@ -0,0 +1,145 @@
+package input
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// mouseEventJSON is the JSON encoding of a MouseEvent.
+type mouseEventJSON struct {
+	X           int      `json:"x"`
+	Y           int      `json:"y"`
+	Type        string   `json:"type"`
+	Alt         bool     `json:"alt,omitempty"`
+	Ctrl        bool     `json:"ctrl,omitempty"`
+	ClickCount  int      `json:"clicks,omitempty"`
+	Drag        *Drag    `json:"drag,omitempty"`
+	HeldButtons []string `json:"held,omitempty"`
+	Zones       []string `json:"zones,omitempty"`
+	Repeat      bool     `json:"repeat,omitempty"`
+}
+
+// MarshalJSON encodes the event as a JSON object holding all of its fields,
+// with the type and buttons by name, so that events can be logged or
+// recorded and replayed:
+//
+//	{"x":10,"y":5,"type":"left","ctrl":true,"clicks":2,"held":["left"]}
+func (m MouseEvent) MarshalJSON() ([]byte, error) {
+	name, ok := mouseEventTypes[m.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown mouse event type %d", m.Type)
+	}
+	e := mouseEventJSON{
+		X:          m.X,
+		Y:          m.Y,
+		Type:       name,
+		Alt:        m.Alt,
+		Ctrl:       m.Ctrl,
+		ClickCount: m.ClickCount,
+		Zones:      m.Zones(),
+		Repeat:     m.Repeat,
+	}
+	if m.Dragging {
+		drag := m.Drag
+		e.Drag = &drag
+	}
+	for _, b := range m.HeldButtons.List() {
+		e.HeldButtons = append(e.HeldButtons, b.String())
+	}
+	return json.Marshal(e)
+}
+
+// UnmarshalJSON decodes an event encoded with MarshalJSON.
+func (m *MouseEvent) UnmarshalJSON(b []byte) error {
+	var e mouseEventJSON
+	if err := json.Unmarshal(b, &e); err != nil {
+		return err
+	}
+	typ, ok := mouseEventTypeNames()[e.Type]
+	if !ok {
+		return fmt.Errorf("unknown mouse event type %q", e.Type)
+	}
+	*m = MouseEvent{
+		X:          e.X,
+		Y:          e.Y,
+		Type:       typ,
+		Alt:        e.Alt,
+		Ctrl:       e.Ctrl,
+		ClickCount: e.ClickCount,
+		Repeat:     e.Repeat,
+	}
+	*m = m.WithZones(e.Zones...)
+	if e.Drag != nil {
+		m.Dragging, m.Drag = true, *e.Drag
+	}
+	for _, name := range e.HeldButtons {
+		button, ok := mouseButtonNames()[name]
+		if !ok {
+			return fmt.Errorf("unknown mouse button %q", name)
+		}
+		m.HeldButtons = m.HeldButtons.with(button)
+	}
+	return nil
+}
+
+// ParseMouseEvent parses a mouse event written as its String, followed by its
+// position, such as "ctrl+left 10,5" or "wheel up 0,3", which is handy for
+// writing events by hand in tests. The other fields are left zero; use the
+// JSON encoding to record events losslessly.
+func ParseMouseEvent(s string) (MouseEvent, error) {
+	var m MouseEvent
+
+	i := strings.LastIndexByte(s, ' ')
+	if i < 0 {
+		return m, fmt.Errorf("mouse event %q: missing position", s)
+	}
+	name, pos := s[:i], s[i+1:]
+
+	comma := strings.IndexByte(pos, ',')
+	if comma < 0 {
+		return m, fmt.Errorf("mouse event %q: invalid position %q", s, pos)
+	}
+	var errX, errY error
+	m.X, errX = strconv.Atoi(pos[:comma])
+	m.Y, errY = strconv.Atoi(pos[comma+1:])
+	if errX != nil || errY != nil {
+		return m, fmt.Errorf("mouse event %q: invalid position %q", s, pos)
+	}
+
+	// Modifiers are written in this order by String.
+	if strings.HasPrefix(name, "ctrl+") {
+		m.Ctrl = true
+		name = name[len("ctrl+"):]
+	}
+	if strings.HasPrefix(name, "alt+") {
+		m.Alt = true
+		name = name[len("alt+"):]
+	}
+
+	typ, ok := mouseEventTypeNames()[name]
+	if !ok {
+		return m, fmt.Errorf("mouse event %q: unknown type %q", s, name)
+	}
+	m.Type = typ
+	return m, nil
+}
+
+// mouseEventTypeNames maps the names of mouse event types to the types.
+func mouseEventTypeNames() map[string]MouseEventType {
+	names := make(map[string]MouseEventType, len(mouseEventTypes))
+	for typ, name := range mouseEventTypes {
+		names[name] = typ
+	}
+	return names
+}
+
+// mouseButtonNames maps the names of mouse buttons to the buttons.
+func mouseButtonNames() map[string]MouseButton {
+	names := make(map[string]MouseButton, len(mouseButtons))
+	for button, name := range mouseButtons {
+		names[name] = button
+	}
+	return names
+}
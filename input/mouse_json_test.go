@@ -0,0 +1,85 @@
+package input
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMouseEventJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		event MouseEvent
+		json  string
+	}{
+		{
+			"press",
+			MouseEvent{X: 10, Y: 5, Type: MouseLeft, Ctrl: true, ClickCount: 2, HeldButtons: MouseButtons(0).with(MouseButtonLeft)},
+			`{"x":10,"y":5,"type":"left","ctrl":true,"clicks":2,"held":["left"]}`,
+		},
+		{
+			"drag",
+			MouseEvent{X: 3, Y: 1, Type: MouseMiddle, Dragging: true, Drag: Drag{StartX: 1, StartY: 1, DX: 2}}.WithZones("list"),
+			`{"x":3,"y":1,"type":"middle","drag":{"startX":1,"startY":1,"dx":2,"dy":0},"zones":["list"]}`,
+		},
+		{
+			"wheel",
+			MouseEvent{Type: MouseWheelDown, Alt: true, Repeat: true},
+			`{"x":0,"y":0,"type":"wheel down","alt":true,"repeat":true}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b, err := json.Marshal(test.event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != test.json {
+				t.Errorf("expected %s, got %s", test.json, b)
+			}
+
+			var m MouseEvent
+			if err := json.Unmarshal(b, &m); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(m, test.event) {
+				t.Errorf("expected %+v, got %+v", test.event, m)
+			}
+		})
+	}
+
+	var m MouseEvent
+	for _, s := range []string{`{"type":"side"}`, `{"type":"left","held":["thumb"]}`} {
+		if err := json.Unmarshal([]byte(s), &m); err == nil {
+			t.Errorf("expected an error decoding %s", s)
+		}
+	}
+}
+
+func TestParseMouseEvent(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected MouseEvent
+	}{
+		{"left 0,0", MouseEvent{Type: MouseLeft}},
+		{"ctrl+alt+wheel up 12,3", MouseEvent{X: 12, Y: 3, Type: MouseWheelUp, Ctrl: true, Alt: true}},
+		{"alt+motion 300,40", MouseEvent{X: 300, Y: 40, Type: MouseMotion, Alt: true}},
+	}
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			m, err := ParseMouseEvent(test.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(m, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, m)
+			}
+		})
+	}
+
+	for _, in := range []string{"left", "left 1", "left a,1", "shift+left 1,1", "wheel 1,1"} {
+		if _, err := ParseMouseEvent(in); err == nil {
+			t.Errorf("expected an error parsing %q", in)
+		}
+	}
+}
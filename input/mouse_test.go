@@ -0,0 +1,663 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMouseEvent_String(t *testing.T) {
+	tt := []struct {
+		name     string
+		event    MouseEvent
+		expected string
+	}{
+		{
+			name:     "unknown",
+			event:    MouseEvent{Type: MouseUnknown},
+			expected: "unknown",
+		},
+		{
+			name:     "left",
+			event:    MouseEvent{Type: MouseLeft},
+			expected: "left",
+		},
+		{
+			name:     "right",
+			event:    MouseEvent{Type: MouseRight},
+			expected: "right",
+		},
+		{
+			name:     "middle",
+			event:    MouseEvent{Type: MouseMiddle},
+			expected: "middle",
+		},
+		{
+			name:     "release",
+			event:    MouseEvent{Type: MouseRelease},
+			expected: "release",
+		},
+		{
+			name:     "wheel up",
+			event:    MouseEvent{Type: MouseWheelUp},
+			expected: "wheel up",
+		},
+		{
+			name:     "wheel down",
+			event:    MouseEvent{Type: MouseWheelDown},
+			expected: "wheel down",
+		},
+		{
+			name:     "motion",
+			event:    MouseEvent{Type: MouseMotion},
+			expected: "motion",
+		},
+		{
+			name: "alt+left",
+			event: MouseEvent{
+				Type: MouseLeft,
+				Alt:  true,
+			},
+			expected: "alt+left",
+		},
+		{
+			name: "ctrl+left",
+			event: MouseEvent{
+				Type: MouseLeft,
+				Ctrl: true,
+			},
+			expected: "ctrl+left",
+		},
+		{
+			name: "ctrl+alt+left",
+			event: MouseEvent{
+				Type: MouseLeft,
+				Alt:  true,
+				Ctrl: true,
+			},
+			expected: "ctrl+alt+left",
+		},
+		{
+			name: "ignore coordinates",
+			event: MouseEvent{
+				X:    100,
+				Y:    200,
+				Type: MouseLeft,
+			},
+			expected: "left",
+		},
+		{
+			name: "broken type",
+			event: MouseEvent{
+				Type: MouseEventType(-1000),
+			},
+			expected: "",
+		},
+	}
+
+	for i := range tt {
+		tc := tt[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			actual := tc.event.String()
+
+			if tc.expected != actual {
+				t.Fatalf("expected %q but got %q",
+					tc.expected,
+					actual,
+				)
+			}
+		})
+	}
+}
+
+func TestParseX10MouseEvent(t *testing.T) {
+	encode := func(b byte, x, y int) []byte {
+		return []byte{
+			'\x1b',
+			'[',
+			'M',
+			byte(32) + b,
+			byte(x + 32 + 1),
+			byte(y + 32 + 1),
+		}
+	}
+
+	tt := []struct {
+		name     string
+		buf      []byte
+		expected []MouseEvent
+	}{
+		// Position.
+		{
+			name: "zero position",
+			buf:  encode(0b0010_0000, 0, 0),
+			expected: []MouseEvent{
+				{
+					X:    0,
+					Y:    0,
+					Type: MouseLeft,
+				},
+			},
+		},
+		{
+			name: "max position",
+			buf:  encode(0b0010_0000, 222, 222), // Because 255 (max int8) - 32 - 1.
+			expected: []MouseEvent{
+				{
+					X:    222,
+					Y:    222,
+					Type: MouseLeft,
+				},
+			},
+		},
+		// Simple.
+		{
+			name: "left",
+			buf:  encode(0b0000_0000, 32, 16),
+			expected: []MouseEvent{
+				{
+					X:    32,
+					Y:    16,
+					Type: MouseLeft,
+				},
+			},
+		},
+		{
+			name: "left in motion",
+			buf:  encode(0b0010_0000, 32, 16),
+			expected: []MouseEvent{
+				{
+					X:    32,
+					Y:    16,
+					Type: MouseLeft,
+				},
+			},
+		},
+		{
+			name: "middle",
+			buf:  encode(0b0000_0001, 32, 16),
+			expected: []MouseEvent{
+				{
+					X:    32,
+					Y:    16,
+					Type: MouseMiddle,
+				},
+			},
+		},
+		{
+			name: "middle in motion",
+			buf:  encode(0b0010_0001, 32, 16),
+			expected: []MouseEvent{
+				{
+					X:    32,
+					Y:    16,
+					Type: MouseMiddle,
+				},
+			},
+		},
+		{
+			name: "right",
+			buf:  encode(0b0000_0010, 32, 16),
+			expected: []MouseEvent{
+				{
+					X:    32,
+					Y:    16,
+					Type: MouseRight,
+				},
+			},
+		},
+		{
+			name: "right in motion",
+			buf:  encode(0b0010_0010, 32, 16),
+			expected: []MouseEvent{
+				{
+					X:    32,
+					Y:    16,
+					Type: MouseRight,
+				},
+			},
+		},
+		{
+			name: "motion",
+			buf:  encode(0b0010_0011, 32, 16),
+			expected: []MouseEvent{
+				{
+					X:    32,
+					Y:    16,
+					Type: MouseMotion,
+				},
+			},
+		},
+		{
+			name: "wheel up",
+			buf:  encode(0b0100_0000, 32, 16),
+			expected: []MouseEvent{
+				{
+					X:    32,
+					Y:    16,
+					Type: MouseWheelUp,
+				},
+			},
+		},
+		{
+			name: "wheel down",
+			buf:  encode(0b0100_0001, 32, 16),
+			expected: []MouseEvent{
+				{
+					X:    32,
+					Y:    16,
+					Type: MouseWheelDown,
+				},
+			},
+		},
+		{
+			name: "release",
+			buf:  encode(0b0000_0011, 32, 16),
+			expected: []MouseEvent{
+				{
+					X:    32,
+					Y:    16,
+					Type: MouseRelease,
+				},
+			},
+		},
+		// Combinations.
+		{
+			name: "alt+right",
+			buf:  encode(0b0010_1010, 32, 16),
+			expected: []MouseEvent{
+				{
+					X:    32,
+					Y:    16,
+					Type: MouseRight,
+					Alt:  true,
+				},
+			},
+		},
+		{
+			name: "ctrl+right",
+			buf:  encode(0b0011_0010, 32, 16),
+			expected: []MouseEvent{
+				{
+					X:    32,
+					Y:    16,
+					Type: MouseRight,
+					Ctrl: true,
+				},
+			},
+		},
+		{
+			name: "ctrl+alt+right",
+			buf:  encode(0b0011_1010, 32, 16),
+			expected: []MouseEvent{
+				{
+					X:    32,
+					Y:    16,
+					Type: MouseRight,
+					Alt:  true,
+					Ctrl: true,
+				},
+			},
+		},
+		{
+			name: "alt+wheel down",
+			buf:  encode(0b0100_1001, 32, 16),
+			expected: []MouseEvent{
+				{
+					X:    32,
+					Y:    16,
+					Type: MouseWheelDown,
+					Alt:  true,
+				},
+			},
+		},
+		{
+			name: "ctrl+wheel down",
+			buf:  encode(0b0101_0001, 32, 16),
+			expected: []MouseEvent{
+				{
+					X:    32,
+					Y:    16,
+					Type: MouseWheelDown,
+					Ctrl: true,
+				},
+			},
+		},
+		{
+			name: "ctrl+alt+wheel down",
+			buf:  encode(0b0101_1001, 32, 16),
+			expected: []MouseEvent{
+				{
+					X:    32,
+					Y:    16,
+					Type: MouseWheelDown,
+					Alt:  true,
+					Ctrl: true,
+				},
+			},
+		},
+		// Unknown.
+		{
+			name: "wheel with unknown bit",
+			buf:  encode(0b0100_0010, 32, 16),
+			expected: []MouseEvent{
+				{
+					X:    32,
+					Y:    16,
+					Type: MouseUnknown,
+				},
+			},
+		},
+		{
+			name: "unknown with modifier",
+			buf:  encode(0b0100_1010, 32, 16),
+			expected: []MouseEvent{
+				{
+					X:    32,
+					Y:    16,
+					Type: MouseUnknown,
+					Alt:  true,
+				},
+			},
+		},
+		// Overflow position.
+		{
+			name: "overflow position",
+			buf:  encode(0b0010_0000, 250, 223), // Because 255 (max int8) - 32 - 1.
+			expected: []MouseEvent{
+				{
+					X:    -6,
+					Y:    -33,
+					Type: MouseLeft,
+				},
+			},
+		},
+		// Batched events.
+		{
+			name: "batched events",
+			buf:  append(encode(0b0010_0000, 32, 16), encode(0b0000_0011, 64, 32)...),
+			expected: []MouseEvent{
+				{
+					X:    32,
+					Y:    16,
+					Type: MouseLeft,
+				},
+				{
+					X:    64,
+					Y:    32,
+					Type: MouseRelease,
+				},
+			},
+		},
+	}
+
+	for i := range tt {
+		tc := tt[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := parseX10MouseEvents(tc.buf)
+			if err != nil {
+				t.Fatalf("unexpected error for test: %v",
+					err,
+				)
+			}
+
+			for i := range tc.expected {
+				if tc.expected[i] != actual[i] {
+					t.Fatalf("expected %#v but got %#v",
+						tc.expected[i],
+						actual[i],
+					)
+				}
+			}
+		})
+	}
+}
+
+func TestParseX10MouseEvent_error(t *testing.T) {
+	tt := []struct {
+		name string
+		buf  []byte
+	}{
+		{
+			name: "empty buf",
+			buf:  nil,
+		},
+		{
+			name: "wrong high bit",
+			buf:  []byte("\x1a[M@A1"),
+		},
+		{
+			name: "short buf",
+			buf:  []byte("\x1b[M@A"),
+		},
+		{
+			name: "long buf",
+			buf:  []byte("\x1b[M@A11"),
+		},
+	}
+
+	for i := range tt {
+		tc := tt[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseX10MouseEvents(tc.buf)
+
+			if err == nil {
+				t.Fatalf("expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestParseUTF8MouseEvents(t *testing.T) {
+	// encode encodes a mouse event in xterm's UTF-8 extended encoding.
+	encode := func(b byte, x, y int) []byte {
+		return []byte(string([]rune{
+			'\x1b',
+			'[',
+			'M',
+			rune(b) + 32,
+			rune(x + 32 + 1),
+			rune(y + 32 + 1),
+		}))
+	}
+
+	tests := []struct {
+		name     string
+		buf      []byte
+		expected []MouseEvent
+	}{
+		{"ascii", encode(0b0000_0000, 10, 5), []MouseEvent{{X: 10, Y: 5, Type: MouseLeft}}},
+		{"wide", encode(0b0000_0000, 250, 5), []MouseEvent{{X: 250, Y: 5, Type: MouseLeft}}},
+		{"tall", encode(0b0000_0011, 3, 1000), []MouseEvent{{X: 3, Y: 1000, Type: MouseRelease}}},
+		{"max", encode(0b0010_0011, 2014, 2014), []MouseEvent{{X: 2014, Y: 2014, Type: MouseMotion}}},
+		{
+			"batched",
+			append(encode(0b0000_0010, 300, 2), encode(0b0000_0011, 300, 2)...),
+			[]MouseEvent{{X: 300, Y: 2, Type: MouseRight}, {X: 300, Y: 2, Type: MouseRelease}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, err := parseX10MouseEvents(test.buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(m, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, m)
+			}
+		})
+	}
+
+	if _, err := parseX10MouseEvents([]byte("\x1b[M \xff!!")); err == nil {
+		t.Error("expected an error for invalid UTF-8")
+	}
+}
+
+func TestClickCount(t *testing.T) {
+	left := MouseEvent{X: 1, Y: 2, Type: MouseLeft}
+	start := time.Now()
+
+	tests := []struct {
+		name     string
+		event    MouseEvent
+		after    time.Duration
+		expected int
+	}{
+		{"first", left, 0, 1},
+		{"double", left, 100 * time.Millisecond, 2},
+		{"triple", left, 200 * time.Millisecond, 3},
+		{"too slow", left, time.Second, 1},
+		{"elsewhere", MouseEvent{X: 2, Y: 2, Type: MouseLeft}, 1100 * time.Millisecond, 1},
+		{"other button", MouseEvent{X: 2, Y: 2, Type: MouseRight}, 1200 * time.Millisecond, 1},
+	}
+
+	var tr mouseTracker
+	for _, test := range tests {
+		now := start.Add(test.after)
+		if got := tr.track(test.event, now, DefaultClickInterval).ClickCount; got != test.expected {
+			t.Errorf("%s: expected a click count of %d, got %d", test.name, test.expected, got)
+		}
+		tr.track(MouseEvent{X: test.event.X, Y: test.event.Y, Type: MouseRelease}, now, DefaultClickInterval)
+	}
+}
+
+func TestDrag(t *testing.T) {
+	var tr mouseTracker
+	now := time.Now()
+	track := func(m MouseEvent) MouseEvent {
+		return tr.track(m, now, DefaultClickInterval)
+	}
+
+	if m := track(MouseEvent{X: 5, Y: 5, Type: MouseLeft}); m.Dragging || m.ClickCount != 1 {
+		t.Errorf("expected a press, got %+v", m)
+	}
+
+	m := track(MouseEvent{X: 7, Y: 4, Type: MouseLeft})
+	expected := Drag{StartX: 5, StartY: 5, DX: 2, DY: -1}
+	if !m.Dragging || m.Drag != expected || m.ClickCount != 0 {
+		t.Errorf("expected a drag of %+v, got %+v", expected, m)
+	}
+
+	m = track(MouseEvent{X: 9, Y: 6, Type: MouseLeft})
+	expected = Drag{StartX: 5, StartY: 5, DX: 4, DY: 1}
+	if !m.Dragging || m.Drag != expected {
+		t.Errorf("expected a drag of %+v, got %+v", expected, m)
+	}
+
+	if m := track(MouseEvent{X: 9, Y: 6, Type: MouseRelease}); m.Dragging {
+		t.Errorf("expected a release without a drag, got %+v", m)
+	}
+	if m := track(MouseEvent{X: 9, Y: 6, Type: MouseLeft}); m.Dragging {
+		t.Errorf("expected a press after the release, got %+v", m)
+	}
+	if m := track(MouseEvent{X: 3, Y: 3, Type: MouseRight}); m.Dragging {
+		t.Errorf("expected a press of another button, got %+v", m)
+	}
+}
+
+func TestHeldButtons(t *testing.T) {
+	var tr mouseTracker
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		event    MouseEvent
+		expected []MouseButton
+	}{
+		{"press", MouseEvent{X: 1, Y: 1, Type: MouseMiddle}, []MouseButton{MouseButtonMiddle}},
+		{"chord", MouseEvent{X: 1, Y: 1, Type: MouseLeft}, []MouseButton{MouseButtonLeft, MouseButtonMiddle}},
+		{"drag", MouseEvent{X: 2, Y: 1, Type: MouseLeft}, []MouseButton{MouseButtonLeft, MouseButtonMiddle}},
+		{"wheel", MouseEvent{X: 2, Y: 1, Type: MouseWheelUp}, []MouseButton{MouseButtonLeft, MouseButtonMiddle}},
+		{"release", MouseEvent{X: 2, Y: 1, Type: MouseRelease}, nil},
+		{"hover", MouseEvent{X: 3, Y: 1, Type: MouseMotion}, nil},
+	}
+	for _, test := range tests {
+		m := tr.track(test.event, now, DefaultClickInterval)
+		if held := m.HeldButtons.List(); !reflect.DeepEqual(held, test.expected) {
+			t.Errorf("%s: expected held buttons %v, got %v", test.name, test.expected, held)
+		}
+		for _, b := range test.expected {
+			if !m.HeldButtons.Has(b) {
+				t.Errorf("%s: expected %v to be held", test.name, b)
+			}
+		}
+		if m.HeldButtons.Has(MouseButtonRight) || m.HeldButtons.Has(MouseButtonNone) {
+			t.Errorf("%s: expected only %v to be held, got %v", test.name, test.expected, m.HeldButtons.List())
+		}
+	}
+}
+
+func TestDecoderClickCount(t *testing.T) {
+	press := "\x1b[M !!"
+	r := &chunkedReader{chunks: [][]byte{[]byte(press + "\x1b[M#!!"), []byte(press)}}
+	d := NewDecoder(r)
+
+	var counts []int
+	for i := 0; i < 2; i++ {
+		events, err := d.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range events {
+			counts = append(counts, e.(MouseEvent).ClickCount)
+		}
+	}
+
+	// Releases aren't counted.
+	if expected := []int{1, 0, 2}; !reflect.DeepEqual(counts, expected) {
+		t.Errorf("expected click counts %v, got %v", expected, counts)
+	}
+}
+
+func TestParseURxvtMouseEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		expected MouseEvent
+		n        int
+	}{
+		{"left", "\x1b[32;1;1M", MouseEvent{X: 0, Y: 0, Type: MouseLeft}, 9},
+		{"release", "\x1b[35;10;20M", MouseEvent{X: 9, Y: 19, Type: MouseRelease}, 11},
+		{"wheel down", "\x1b[97;3;4M", MouseEvent{X: 2, Y: 3, Type: MouseWheelDown}, 9},
+		{"ctrl+alt right", "\x1b[58;5;6M", MouseEvent{X: 4, Y: 5, Type: MouseRight, Alt: true, Ctrl: true}, 9},
+		{"motion", "\x1b[67;300;2M", MouseEvent{X: 299, Y: 1, Type: MouseMotion}, 11},
+		{"followed by a key", "\x1b[32;1;1Ma", MouseEvent{X: 0, Y: 0, Type: MouseLeft}, 9},
+		{"x10", "\x1b[M !!", MouseEvent{}, 0},
+		{"too few parameters", "\x1b[32;1M", MouseEvent{}, 0},
+		{"not a mouse event", "\x1b[1;5A", MouseEvent{}, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, n := parseURxvtMouseEvent([]byte(test.in))
+			if n != test.n {
+				t.Errorf("expected length %d, got %d", test.n, n)
+			}
+			if !reflect.DeepEqual(m, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, m)
+			}
+		})
+	}
+}
+
+func TestMouseEventZones(t *testing.T) {
+	ids := []string{"list", "a:b", "", "12:x"}
+	m := MouseEvent{X: 1, Type: MouseLeft}.WithZones(ids...)
+	if zones := m.Zones(); !reflect.DeepEqual(zones, ids) {
+		t.Errorf("expected zones %q, got %q", ids, zones)
+	}
+	if zones := (MouseEvent{}).Zones(); zones != nil {
+		t.Errorf("expected no zones, got %q", zones)
+	}
+
+	// Events with the same zones are equal.
+	if m != (MouseEvent{X: 1, Type: MouseLeft}).WithZones(ids...) {
+		t.Error("expected events with the same zones to be equal")
+	}
+	if m == (MouseEvent{X: 1, Type: MouseLeft}).WithZones("list") {
+		t.Error("expected events with different zones to differ")
+	}
+}
@@ -0,0 +1,150 @@
+package input
+
+import (
+	"bytes"
+	"io"
+	"unicode/utf8"
+
+	"github.com/mattn/go-localereader"
+)
+
+// Paste is text pasted into the terminal. Terminals only tell pasted text
+// from typed text when bracketed paste is enabled.
+type Paste string
+
+// PasteStart is sent when a paste starts, if pastes are streamed, see
+// Decoder.PasteChunkSize. It's followed by the PasteChunk events of the paste
+// and a PasteEnd event.
+type PasteStart struct{}
+
+// PasteChunk is a piece of text pasted into the terminal, if pastes are
+// streamed.
+type PasteChunk string
+
+// PasteEnd is sent when a paste ends, if pastes are streamed.
+type PasteEnd struct{}
+
+// Bracketed paste delimiters. When bracketed paste is enabled the terminal
+// wraps pasted text in these sequences.
+var (
+	bracketedPasteStart = []byte("\x1b[200~")
+	bracketedPasteEnd   = []byte("\x1b[201~")
+)
+
+// pasteReader reads the content of a bracketed paste as it arrives, up to a
+// limit.
+type pasteReader struct {
+	// the most bytes of content kept, or zero for no limit
+	limit int
+
+	// the bytes of content read so far, including dropped ones
+	size int
+
+	// the end of the input so far, which may be the beginning of the end of
+	// the paste
+	held []byte
+}
+
+// feed reads input of the paste. It returns the content the input holds
+// within the limit, and whether the paste ended, along with the input
+// following it if it did.
+func (p *pasteReader) feed(b []byte) (content, rest []byte, done bool) {
+	if len(p.held) > 0 {
+		b = append(p.held, b...)
+		p.held = nil
+	}
+
+	if i := bytes.Index(b, bracketedPasteEnd); i >= 0 {
+		content, rest, done = b[:i], b[i+len(bracketedPasteEnd):], true
+	} else {
+		n := partialSuffixLen(b, bracketedPasteEnd)
+		content = b[:len(b)-n]
+		p.held = append([]byte{}, b[len(b)-n:]...)
+	}
+
+	if p.limit > 0 {
+		room := p.limit - p.size
+		if room < 0 {
+			room = 0
+		}
+		if room < len(content) {
+			// Don't keep part of a rune.
+			for room > 0 && !utf8.RuneStart(content[room]) {
+				room--
+			}
+			p.size += len(content)
+			return content[:room], rest, done
+		}
+	}
+	p.size += len(content)
+	return content, rest, done
+}
+
+// partialSuffixLen returns the length of the longest beginning of seq which b
+// ends with, short of all of seq.
+func partialSuffixLen(b, seq []byte) int {
+	for n := len(seq) - 1; n > 0; n-- {
+		if bytes.HasSuffix(b, seq[:n]) {
+			return n
+		}
+	}
+	return 0
+}
+
+// readBracketedPaste reads from input until the end of a bracketed paste,
+// returning the pasted content, up to limit bytes if limit isn't zero, and
+// any input following the paste. b is the input following the start of the
+// paste which has already been read.
+func readBracketedPaste(input io.Reader, b []byte, limit int) (content, rest []byte, err error) {
+	p := &pasteReader{limit: limit}
+	var buf [256]byte
+
+	for {
+		c, r, done := p.feed(b)
+		content = append(content, c...)
+		if done {
+			return content, r, nil
+		}
+
+		n, err := input.Read(buf[:])
+		if err != nil {
+			return nil, nil, err
+		}
+		b = buf[:n]
+	}
+}
+
+// streamPaste reads input of the paste being streamed, returning the events
+// it holds and, if the paste ended, the input following it.
+func (d *Decoder) streamPaste(b []byte) (events []Event, rest []byte, done bool, err error) {
+	var content []byte
+	content, rest, done = d.paste.feed(b)
+	if len(content) > 0 {
+		content, err = localereader.UTF8(content)
+		if err != nil {
+			return nil, nil, false, err
+		}
+	}
+
+	for len(content) > 0 {
+		n := len(content)
+		if n > d.PasteChunkSize {
+			n = d.PasteChunkSize
+			// Don't split runes, unless they don't fit in a chunk.
+			for n > 0 && !utf8.RuneStart(content[n]) {
+				n--
+			}
+			if n == 0 {
+				n = d.PasteChunkSize
+			}
+		}
+		events = append(events, PasteChunk(content[:n]))
+		content = content[n:]
+	}
+
+	if done {
+		events = append(events, PasteEnd{})
+		d.paste = nil
+	}
+	return events, rest, done, nil
+}
@@ -0,0 +1,84 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecoderStreamPaste(t *testing.T) {
+	r := &chunkedReader{chunks: [][]byte{
+		[]byte("a\x1b[200~hello wo"),
+		[]byte("rld\x1b[20"),
+		[]byte("1~b"),
+	}}
+	d := NewDecoder(r)
+	d.PasteChunkSize = 4
+
+	var events []Event
+	for i := 0; i < 3; i++ {
+		e, err := d.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, e...)
+	}
+
+	expected := []Event{
+		Key{Type: KeyRunes, Runes: []rune("a")},
+		PasteStart{},
+		PasteChunk("hell"),
+		PasteChunk("o wo"),
+		PasteChunk("rld"),
+		PasteEnd{},
+		Key{Type: KeyRunes, Runes: []rune("b")},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected events %#v, got %#v", expected, events)
+	}
+}
+
+func TestDecoderStreamPasteRunes(t *testing.T) {
+	r := &chunkedReader{chunks: [][]byte{[]byte("\x1b[200~äöü\x1b[201~")}}
+	d := NewDecoder(r)
+	d.PasteChunkSize = 3
+
+	events, err := d.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Event{PasteStart{}, PasteChunk("ä"), PasteChunk("ö"), PasteChunk("ü"), PasteEnd{}}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected events %#v, got %#v", expected, events)
+	}
+}
+
+func TestMaxPasteSize(t *testing.T) {
+	tests := []struct {
+		name      string
+		chunkSize int
+		expected  []Event
+	}{
+		{"whole", 0, []Event{Paste("héll"), Key{Type: KeyRunes, Runes: []rune("x")}}},
+		{"streamed", 16, []Event{PasteStart{}, PasteChunk("hé"), PasteChunk("ll"), PasteEnd{}, Key{Type: KeyRunes, Runes: []rune("x")}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &chunkedReader{chunks: [][]byte{[]byte("\x1b[200~hé"), []byte("llö world\x1b[201~x")}}
+			d := NewDecoder(r)
+			d.PasteChunkSize = test.chunkSize
+			d.MaxPasteSize = 6
+
+			var events []Event
+			for len(r.chunks) > 0 {
+				e, err := d.Decode()
+				if err != nil {
+					t.Fatal(err)
+				}
+				events = append(events, e...)
+			}
+			if !reflect.DeepEqual(events, test.expected) {
+				t.Errorf("expected events %#v, got %#v", test.expected, events)
+			}
+		})
+	}
+}
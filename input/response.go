@@ -0,0 +1,311 @@
+package input
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Responses to terminal queries. Terminals send them when asked to with the
+// respective query sequences.
+
+// PrimaryDeviceAttributes is the terminal's response to a primary device
+// attributes (DA1) query, CSI c. As every terminal answers DA1, it's useful
+// to send after any other queries: once the response arrives, all other
+// responses have been received, too.
+type PrimaryDeviceAttributes []int
+
+// Termcap is the terminal's response to an XTGETTCAP query for a single
+// terminfo capability. OK is false if the terminal doesn't know the
+// capability.
+type Termcap struct {
+	Name  string
+	Value string
+	OK    bool
+}
+
+// StatusString is the terminal's response to a DECRQSS query. OK is false if
+// the terminal considered the query invalid.
+type StatusString struct {
+	Value string
+	OK    bool
+}
+
+// ModeReport is the terminal's response to a DECRQM query for a private
+// mode. Value is 1 or 3 if the mode is set, 2 or 4 if it's reset, and 0 if the
+// terminal doesn't know the mode.
+type ModeReport struct {
+	Mode  int
+	Value int
+}
+
+// ForegroundColor is the terminal's response to an OSC 10 query for its
+// default foreground color, as a hex color such as "#cdd6f4".
+type ForegroundColor string
+
+// BackgroundColor is the terminal's response to an OSC 11 query for its
+// default background color, as a hex color such as "#1e1e2e".
+type BackgroundColor string
+
+// PaletteColor is the terminal's response to an OSC 4 query for one of the
+// colors of its palette, as a hex color such as "#f38ba8".
+type PaletteColor struct {
+	Index int
+	Color string
+}
+
+// Clipboard is the terminal's response to an OSC 52 query for the content of
+// the clipboard.
+type Clipboard string
+
+// WindowPixelSize is the terminal's response to an XTWINOPS 14 query for the
+// size of its text area in pixels.
+type WindowPixelSize struct {
+	Width  int
+	Height int
+}
+
+// CellPixelSize is the terminal's response to an XTWINOPS 16 query for the
+// size of its cells in pixels.
+type CellPixelSize struct {
+	Width  int
+	Height int
+}
+
+// isOSCResponse reports whether b starts with an operating system command
+// sent in response to one of our queries: one reporting one of the
+// terminal's colors, or the content of the clipboard.
+func isOSCResponse(b []byte) bool {
+	return bytes.HasPrefix(b, []byte("\x1b]4;")) ||
+		bytes.HasPrefix(b, []byte("\x1b]10;")) || bytes.HasPrefix(b, []byte("\x1b]11;")) ||
+		bytes.HasPrefix(b, []byte("\x1b]52;"))
+}
+
+// isResponseStart reports whether b looks like the start of an operating
+// system command sent in response to one of our queries, or, if dcs is set,
+// of a device control string sent in response to a DECRQSS or XTGETTCAP
+// query. The latter look just like alt+P followed by a digit, so they're only
+// taken for responses while such queries are outstanding.
+func isResponseStart(b []byte, dcs bool) bool {
+	if isOSCResponse(b) {
+		return true
+	}
+	return dcs && len(b) > 2 && b[0] == '\x1b' && b[1] == 'P' && (b[2] == '0' || b[2] == '1')
+}
+
+// isResponseComplete reports whether the response b starts with has been
+// read in full. Device control strings end with ST; operating system commands
+// end with either ST or BEL.
+func isResponseComplete(b []byte) bool {
+	if bytes.Contains(b, []byte("\x1b\\")) {
+		return true
+	}
+	return len(b) > 1 && b[1] == ']' && bytes.IndexByte(b, '\a') >= 0
+}
+
+// parseTerminalResponse checks whether b starts with a response to a terminal
+// query. If so, it returns the resulting messages and the number of bytes
+// consumed. Otherwise the number of bytes consumed is zero.
+func parseTerminalResponse(b []byte) ([]Event, int) {
+	if e, n := parseHighlightResponse(b); n > 0 {
+		return []Event{e}, n
+	}
+	if e, n := parseURxvtMouseEvent(b); n > 0 {
+		return []Event{e}, n
+	}
+	if e, n := parseWin32Key(b); n > 0 {
+		if e == nil {
+			return nil, n
+		}
+		return []Event{e}, n
+	}
+	if e, n := parseKittyKey(b); n > 0 {
+		if e == nil {
+			return nil, n
+		}
+		return []Event{e}, n
+	}
+
+	switch {
+	case bytes.HasPrefix(b, []byte("\x1bP")):
+		end := bytes.Index(b, []byte("\x1b\\"))
+		if end < 0 {
+			return nil, 0
+		}
+		events := parseDeviceControlString(b[2:end])
+		if events == nil {
+			events = []Event{UnknownSequence(append([]byte{}, b[:end+2]...))}
+		}
+		return events, end + 2 //nolint:gomnd
+
+	case isOSCResponse(b):
+		// The response ends with whichever of ST and BEL comes first.
+		end, n := bytes.Index(b, []byte("\x1b\\")), 2 //nolint:gomnd
+		if bel := bytes.IndexByte(b, '\a'); bel >= 0 && (end < 0 || bel < end) {
+			end, n = bel, 1
+		}
+		if end < 0 {
+			return nil, 0
+		}
+		e := parseOSCResponse(string(b[2:end]))
+		if e == nil {
+			e = UnknownSequence(append([]byte{}, b[:end+n]...))
+		}
+		return []Event{e}, end + n
+
+	case bytes.HasPrefix(b, []byte("\x1b[?")):
+		// Find the final byte of the CSI sequence.
+		i := 3
+		for i < len(b) && (b[i] >= '0' && b[i] <= '9' || b[i] == ';') {
+			i++
+		}
+		if i+1 < len(b) && b[i] == '$' && b[i+1] == 'y' {
+			params := parseParams(b[3:i])
+			if len(params) != 2 { //nolint:gomnd
+				return nil, 0
+			}
+			return []Event{ModeReport{Mode: params[0], Value: params[1]}}, i + 2 //nolint:gomnd
+		}
+		if i >= len(b) || b[i] != 'c' {
+			return nil, 0
+		}
+		return []Event{PrimaryDeviceAttributes(parseParams(b[3:i]))}, i + 1
+
+	case bytes.HasPrefix(b, []byte("\x1b[4;")) || bytes.HasPrefix(b, []byte("\x1b[6;")):
+		// XTWINOPS reports sizes as CSI 4 ; height ; width t, and cell
+		// sizes as CSI 6 ; height ; width t.
+		i := 2
+		for i < len(b) && (b[i] >= '0' && b[i] <= '9' || b[i] == ';') {
+			i++
+		}
+		if i >= len(b) || b[i] != 't' {
+			return nil, 0
+		}
+		params := parseParams(b[2:i])
+		if len(params) != 3 { //nolint:gomnd
+			return nil, 0
+		}
+		if params[0] == 4 { //nolint:gomnd
+			return []Event{WindowPixelSize{Width: params[2], Height: params[1]}}, i + 1
+		}
+		return []Event{CellPixelSize{Width: params[2], Height: params[1]}}, i + 1
+	}
+
+	return nil, 0
+}
+
+// parseDeviceControlString parses the payload of a device control string
+// sent in response to XTGETTCAP and DECRQSS queries.
+func parseDeviceControlString(payload []byte) []Event {
+	s := string(payload)
+
+	switch {
+	case strings.HasPrefix(s, "1+r"):
+		var events []Event
+		for _, c := range strings.Split(s[3:], ";") {
+			parts := strings.SplitN(c, "=", 2) //nolint:gomnd
+			name, err := hex.DecodeString(parts[0])
+			if err != nil {
+				continue
+			}
+			e := Termcap{Name: string(name), OK: true}
+			if len(parts) > 1 {
+				value, err := hex.DecodeString(parts[1])
+				if err != nil {
+					continue
+				}
+				e.Value = string(value)
+			}
+			events = append(events, e)
+		}
+		return events
+
+	case strings.HasPrefix(s, "0+r"):
+		name, _ := hex.DecodeString(s[3:])
+		return []Event{Termcap{Name: string(name)}}
+
+	case strings.HasPrefix(s, "1$r"):
+		return []Event{StatusString{Value: s[3:], OK: true}}
+
+	case strings.HasPrefix(s, "0$r"):
+		return []Event{StatusString{}}
+	}
+
+	// An unknown device control string.
+	return nil
+}
+
+// parseOSCResponse parses the payload of an operating system command sent in
+// response to one of our queries. It returns nil if the payload is malformed.
+func parseOSCResponse(payload string) Event {
+	parts := strings.Split(payload, ";")
+	switch {
+	case parts[0] == "52" && len(parts) == 3: //nolint:gomnd
+		content, err := base64.StdEncoding.DecodeString(parts[2])
+		if err != nil {
+			return nil
+		}
+		return Clipboard(content)
+	case parts[0] == "4" && len(parts) == 3: //nolint:gomnd
+		index, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil
+		}
+		if color, ok := parseXColor(parts[2]); ok {
+			return PaletteColor{Index: index, Color: color}
+		}
+	case parts[0] == "10" && len(parts) == 2: //nolint:gomnd
+		if color, ok := parseXColor(parts[1]); ok {
+			return ForegroundColor(color)
+		}
+	case parts[0] == "11" && len(parts) == 2: //nolint:gomnd
+		if color, ok := parseXColor(parts[1]); ok {
+			return BackgroundColor(color)
+		}
+	}
+	return nil
+}
+
+// parseXColor parses a color as terminals report it, rgb:RRRR/GGGG/BBBB with
+// one to four hex digits per component, into a hex color such as "#1e1e2e".
+func parseXColor(s string) (string, bool) {
+	if !strings.HasPrefix(s, "rgb:") {
+		return "", false
+	}
+	parts := strings.Split(s[4:], "/")
+	if len(parts) != 3 { //nolint:gomnd
+		return "", false
+	}
+
+	var rgb [3]uint64
+	for i, p := range parts {
+		if len(p) == 0 || len(p) > 4 { //nolint:gomnd
+			return "", false
+		}
+		n, err := strconv.ParseUint(p, 16, 16) //nolint:gomnd
+		if err != nil {
+			return "", false
+		}
+		// Scale the component to 8 bits, rounding to the nearest value.
+		max := uint64(1)<<(4*len(p)) - 1 //nolint:gomnd
+		rgb[i] = (n*255 + max/2) / max   //nolint:gomnd
+	}
+	return fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2]), true
+}
+
+// parseParams parses semicolon-separated numeric parameters. Missing or
+// invalid parameters are reported as zero.
+func parseParams(b []byte) []int {
+	if len(b) == 0 {
+		return nil
+	}
+	parts := bytes.Split(b, []byte{';'})
+	params := make([]int, len(parts))
+	for i, p := range parts {
+		params[i], _ = strconv.Atoi(string(p))
+	}
+	return params
+}
@@ -0,0 +1,179 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTerminalResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		events []Event
+		n      int
+	}{
+		{
+			name:   "termcap",
+			in:     "\x1bP1+r524742=38\x1b\\",
+			events: []Event{Termcap{Name: "RGB", Value: "8", OK: true}},
+			n:      16,
+		},
+		{
+			name:   "termcap boolean",
+			in:     "\x1bP1+r5463\x1b\\",
+			events: []Event{Termcap{Name: "Tc", OK: true}},
+			n:      11,
+		},
+		{
+			name:   "termcap multiple",
+			in:     "\x1bP1+r524742;5463\x1b\\",
+			events: []Event{Termcap{Name: "RGB", OK: true}, Termcap{Name: "Tc", OK: true}},
+			n:      18,
+		},
+		{
+			name:   "termcap unknown",
+			in:     "\x1bP0+r524742\x1b\\",
+			events: []Event{Termcap{Name: "RGB"}},
+			n:      13,
+		},
+		{
+			name:   "status string",
+			in:     "\x1bP1$r0;38:2::1:2:3m\x1b\\abc",
+			events: []Event{StatusString{Value: "0;38:2::1:2:3m", OK: true}},
+			n:      21,
+		},
+		{
+			name:   "status string invalid",
+			in:     "\x1bP0$r\x1b\\",
+			events: []Event{StatusString{}},
+			n:      7,
+		},
+		{
+			name:   "primary device attributes",
+			in:     "\x1b[?62;22c",
+			events: []Event{PrimaryDeviceAttributes{62, 22}},
+			n:      9,
+		},
+		{
+			name:   "window pixel size",
+			in:     "\x1b[4;600;800t",
+			events: []Event{WindowPixelSize{Width: 800, Height: 600}},
+			n:      12,
+		},
+		{
+			name:   "cell pixel size",
+			in:     "\x1b[6;20;10tabc",
+			events: []Event{CellPixelSize{Width: 10, Height: 20}},
+			n:      10,
+		},
+		{
+			name: "key with modifiers",
+			in:   "\x1b[4;5~",
+			n:    0,
+		},
+		{
+			name:   "mode report",
+			in:     "\x1b[?9001;2$y",
+			events: []Event{ModeReport{Mode: 9001, Value: 2}},
+			n:      11,
+		},
+		{
+			name:   "foreground color",
+			in:     "\x1b]10;rgb:cdcd/d6d6/f4f4\x1b\\",
+			events: []Event{ForegroundColor("#cdd6f4")},
+			n:      25,
+		},
+		{
+			name:   "background color with bel",
+			in:     "\x1b]11;rgb:1e/1e/2e\aabc",
+			events: []Event{BackgroundColor("#1e1e2e")},
+			n:      18,
+		},
+		{
+			name:   "color in an unknown format",
+			in:     "\x1b]11;cmyk:0/0/0/0\a",
+			events: []Event{UnknownSequence("\x1b]11;cmyk:0/0/0/0\a")},
+			n:      18,
+		},
+		{
+			name:   "palette color",
+			in:     "\x1b]4;1;rgb:f3f3/8b8b/a8a8\x1b\\",
+			events: []Event{PaletteColor{Index: 1, Color: "#f38ba8"}},
+			n:      26,
+		},
+		{
+			name:   "palette color with a bad index",
+			in:     "\x1b]4;x;rgb:f3/8b/a8\a",
+			events: []Event{UnknownSequence("\x1b]4;x;rgb:f3/8b/a8\a")},
+			n:      19,
+		},
+		{
+			name:   "clipboard",
+			in:     "\x1b]52;c;aGVsbG8=\a",
+			events: []Event{Clipboard("hello")},
+			n:      16,
+		},
+		{
+			name:   "empty clipboard",
+			in:     "\x1b]52;c;\x1b\\",
+			events: []Event{Clipboard("")},
+			n:      9,
+		},
+		{
+			name:   "clipboard with bad base64",
+			in:     "\x1b]52;c;!!\a",
+			events: []Event{UnknownSequence("\x1b]52;c;!!\a")},
+			n:      10,
+		},
+		{
+			name: "unterminated color",
+			in:   "\x1b]11;rgb:1e/1e",
+		},
+		{
+			name: "unterminated",
+			in:   "\x1bP1$r0m",
+		},
+		{
+			name: "not a response",
+			in:   "\x1b[A",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			events, n := parseTerminalResponse([]byte(test.in))
+			if n != test.n {
+				t.Errorf("expected %d bytes to be consumed, got %d", test.n, n)
+			}
+			if !reflect.DeepEqual(events, test.events) {
+				t.Errorf("expected events %#v, got %#v", test.events, events)
+			}
+		})
+	}
+}
+
+func TestParseXColor(t *testing.T) {
+	tests := []struct {
+		in    string
+		color string
+		ok    bool
+	}{
+		{"rgb:ffff/0000/8080", "#ff0080", true},
+		{"rgb:ff/00/80", "#ff0080", true},
+		{"rgb:f/0/8", "#ff0088", true},
+		{"rgb:fff/000/800", "#ff0080", true},
+		{"rgb:ff/00", "", false},
+		{"rgb:fffff/0/0", "", false},
+		{"rgb:gg/00/00", "", false},
+		{"#ff0080", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			color, ok := parseXColor(test.in)
+			if color != test.color || ok != test.ok {
+				t.Errorf("expected %q, %v, got %q, %v", test.color, test.ok, color, ok)
+			}
+		})
+	}
+}
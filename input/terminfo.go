@@ -0,0 +1,361 @@
+package input
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Magic numbers of compiled terminfo entries, in the legacy format and in the
+// format with 32-bit numbers.
+const (
+	terminfoMagic   = 0o432
+	terminfoMagic32 = 0o1036
+)
+
+// terminfoKeys maps the indices of key capabilities among the string
+// capabilities of compiled terminfo entries to keys.
+var terminfoKeys = map[int]KeyType{
+	55:  KeyBackspace, // kbs
+	59:  KeyDelete,    // kdch1
+	61:  KeyDown,      // kcud1
+	66:  KeyF1,        // kf1
+	67:  KeyF10,       // kf10
+	68:  KeyF2,        // kf2
+	69:  KeyF3,        // kf3
+	70:  KeyF4,        // kf4
+	71:  KeyF5,        // kf5
+	72:  KeyF6,        // kf6
+	73:  KeyF7,        // kf7
+	74:  KeyF8,        // kf8
+	75:  KeyF9,        // kf9
+	76:  KeyHome,      // khome
+	77:  KeyInsert,    // kich1
+	79:  KeyLeft,      // kcub1
+	81:  KeyPgDown,    // knp
+	82:  KeyPgUp,      // kpp
+	83:  KeyRight,     // kcuf1
+	87:  KeyUp,        // kcuu1
+	148: KeyShiftTab,  // kcbt
+	164: KeyEnd,       // kend
+	216: KeyF11,       // kf11
+	217: KeyF12,       // kf12
+	218: KeyF13,       // kf13
+	219: KeyF14,       // kf14
+	220: KeyF15,       // kf15
+	221: KeyF16,       // kf16
+	222: KeyF17,       // kf17
+	223: KeyF18,       // kf18
+	224: KeyF19,       // kf19
+	225: KeyF20,       // kf20
+	226: KeyF21,       // kf21
+	227: KeyF22,       // kf22
+	228: KeyF23,       // kf23
+	229: KeyF24,       // kf24
+}
+
+// terminfoColors is the index of the colors capability among the numeric
+// capabilities of compiled terminfo entries.
+const terminfoColors = 13
+
+// terminfoItalic is the index of the sitm capability, which enters italics
+// mode, among the string capabilities of compiled terminfo entries.
+const terminfoItalic = 311
+
+// TextAttrs are text attributes which not all terminals support.
+type TextAttrs struct {
+	Italic         bool
+	Strikethrough  bool
+	Undercurl      bool
+	UnderlineColor bool
+}
+
+// TerminfoKeys returns the key sequences of the given terminal, such as
+// "xterm-256color", as described by its terminfo entry, for use as
+// Decoder.Keys. Entries are looked up where ncurses looks for them: in
+// $TERMINFO, ~/.terminfo, $TERMINFO_DIRS and the system's terminfo
+// directories.
+//
+// Only the sequences of the keys Key has a type for are returned. Terminals
+// which follow xterm send sequences the decoder already knows, so this is
+// mostly useful on unusual terminals.
+func TerminfoKeys(term string) (map[string]Key, error) {
+	b, err := readTerminfo(term)
+	if err != nil {
+		return nil, err
+	}
+	return parseTerminfoKeys(b)
+}
+
+// TerminfoColors returns the number of colors the given terminal supports,
+// as described by its terminfo entry, which is looked up like TerminfoKeys
+// does. It's zero if the entry doesn't list the number of colors.
+func TerminfoColors(term string) (int, error) {
+	b, err := readTerminfo(term)
+	if err != nil {
+		return 0, err
+	}
+	return parseTerminfoColors(b)
+}
+
+// TerminfoTextAttrs returns the text attributes the given terminal supports,
+// as described by its terminfo entry, which is looked up like TerminfoKeys
+// does. Italics are listed as sitm, and the others as the extended
+// capabilities smxx, Smulx and Setulc, which entries written before they
+// were in common use don't list.
+func TerminfoTextAttrs(term string) (TextAttrs, error) {
+	b, err := readTerminfo(term)
+	if err != nil {
+		return TextAttrs{}, err
+	}
+	return parseTerminfoTextAttrs(b)
+}
+
+// readTerminfo reads the compiled terminfo entry of the given terminal.
+func readTerminfo(term string) ([]byte, error) {
+	if term == "" || strings.ContainsAny(term, "/\\") {
+		return nil, fmt.Errorf("invalid terminal name %q", term)
+	}
+	for _, dir := range terminfoDirs() {
+		for _, sub := range []string{term[:1], fmt.Sprintf("%x", term[0])} {
+			b, err := os.ReadFile(filepath.Join(dir, sub, term))
+			if err == nil {
+				return b, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no terminfo entry for %q", term)
+}
+
+// terminfoDirs returns the directories in which terminfo entries are looked
+// up, in order.
+func terminfoDirs() []string {
+	var dirs []string
+	if dir := os.Getenv("TERMINFO"); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".terminfo"))
+	}
+	for _, dir := range filepath.SplitList(os.Getenv("TERMINFO_DIRS")) {
+		if dir == "" {
+			// An empty entry stands for the system's directory.
+			dir = "/usr/share/terminfo"
+		}
+		dirs = append(dirs, dir)
+	}
+	return append(dirs, "/etc/terminfo", "/lib/terminfo", "/usr/share/terminfo")
+}
+
+// terminfoEntry describes where the sections of a compiled terminfo entry
+// are.
+type terminfoEntry struct {
+	b []byte
+
+	numbers  int // offset of the numeric capabilities
+	numSize  int // size of a number in bytes
+	numCount int
+
+	offsets   int // offset of the string capabilities' offsets
+	strCount  int
+	table     int // offset of the string table
+	tableSize int
+}
+
+// parseTerminfo locates the sections of a compiled terminfo entry. Extended
+// capabilities, which follow the standard ones, aren't located.
+func parseTerminfo(b []byte) (terminfoEntry, error) {
+	errInvalid := errors.New("invalid terminfo entry")
+
+	const headerSize = 12
+	if len(b) < headerSize {
+		return terminfoEntry{}, errInvalid
+	}
+	header := make([]int, headerSize/2) //nolint:gomnd
+	for i := range header {
+		header[i] = int(int16(binary.LittleEndian.Uint16(b[i*2:])))
+	}
+	e := terminfoEntry{b: b, numSize: 2} //nolint:gomnd
+	switch header[0] {
+	case terminfoMagic:
+	case terminfoMagic32:
+		e.numSize = 4
+	default:
+		return terminfoEntry{}, errInvalid
+	}
+	namesSize, boolCount := header[1], header[2]
+	e.numCount, e.strCount, e.tableSize = header[3], header[4], header[5]
+
+	e.numbers = headerSize + namesSize + boolCount
+	if e.numbers%2 != 0 {
+		// Numbers are aligned to even bytes.
+		e.numbers++
+	}
+	e.offsets = e.numbers + e.numCount*e.numSize
+	e.table = e.offsets + e.strCount*2 //nolint:gomnd
+	if namesSize < 0 || boolCount < 0 || e.numCount < 0 || e.strCount < 0 || e.tableSize < 0 ||
+		len(b) < e.table+e.tableSize {
+		return terminfoEntry{}, errInvalid
+	}
+	return e, nil
+}
+
+// str returns the string capability with the given index. It reports false if
+// the capability is absent or cancelled.
+func (e terminfoEntry) str(i int) (string, bool) {
+	if i >= e.strCount {
+		return "", false
+	}
+	off := int(int16(binary.LittleEndian.Uint16(e.b[e.offsets+i*2:])))
+	if off < 0 || off >= e.tableSize {
+		return "", false
+	}
+	return cString(e.b[e.table+off : e.table+e.tableSize]), true
+}
+
+// extended returns the names of the extended capabilities listed by a
+// compiled terminfo entry, leaving out absent and cancelled ones. The
+// extended capabilities follow the standard ones, in sections laid out like
+// theirs, except that their names are listed, too, at the end of the string
+// table.
+func (e terminfoEntry) extended() map[string]bool {
+	b := e.b
+	start := e.table + e.tableSize
+	if start%2 != 0 {
+		start++
+	}
+
+	const headerSize = 10
+	if len(b) < start+headerSize {
+		// The entry has no extended capabilities.
+		return nil
+	}
+	header := make([]int, headerSize/2) //nolint:gomnd
+	for i := range header {
+		header[i] = int(int16(binary.LittleEndian.Uint16(b[start+i*2:])))
+	}
+	boolCount, numCount, strCount, tableSize := header[0], header[1], header[2], header[4]
+	if boolCount < 0 || numCount < 0 || strCount < 0 || tableSize < 0 {
+		return nil
+	}
+
+	bools := start + headerSize
+	numbers := bools + boolCount
+	if numbers%2 != 0 {
+		numbers++
+	}
+	offsets := numbers + numCount*e.numSize
+	names := offsets + strCount*2 //nolint:gomnd
+	count := boolCount + numCount + strCount
+	table := names + count*2 //nolint:gomnd
+	if len(b) < table+tableSize {
+		return nil
+	}
+	short := func(off int) int {
+		return int(int16(binary.LittleEndian.Uint16(b[off:])))
+	}
+
+	// The names follow the values of the string capabilities.
+	var namesStart int
+	for i := 0; i < strCount; i++ {
+		off := short(offsets + i*2)
+		if off < 0 || off >= tableSize {
+			continue
+		}
+		if end := off + len(cString(b[table+off:table+tableSize])) + 1; end > namesStart {
+			namesStart = end
+		}
+	}
+
+	present := make(map[string]bool)
+	for i := 0; i < count; i++ {
+		off := namesStart + short(names+i*2)
+		if off < 0 || off >= tableSize {
+			continue
+		}
+		name := cString(b[table+off : table+tableSize])
+
+		switch {
+		case i < boolCount:
+			present[name] = b[bools+i] == 1
+		case i < boolCount+numCount:
+			j := numbers + (i-boolCount)*e.numSize
+			if e.numSize == 4 { //nolint:gomnd
+				present[name] = int32(binary.LittleEndian.Uint32(b[j:])) >= 0
+			} else {
+				present[name] = short(j) >= 0
+			}
+		default:
+			v := short(offsets + (i-boolCount-numCount)*2)
+			present[name] = v >= 0 && v < tableSize
+		}
+	}
+	return present
+}
+
+// cString returns the NUL-terminated string b starts with.
+func cString(b []byte) string {
+	if end := strings.IndexByte(string(b), 0); end >= 0 {
+		return string(b[:end])
+	}
+	return string(b)
+}
+
+// parseTerminfoKeys returns the key sequences of a compiled terminfo entry.
+func parseTerminfoKeys(b []byte) (map[string]Key, error) {
+	e, err := parseTerminfo(b)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]Key)
+	for i, t := range terminfoKeys {
+		if s, ok := e.str(i); ok && s != "" {
+			keys[s] = Key{Type: t}
+		}
+	}
+	return keys, nil
+}
+
+// parseTerminfoColors returns the number of colors listed by a compiled
+// terminfo entry, or zero if there's none.
+func parseTerminfoColors(b []byte) (int, error) {
+	e, err := parseTerminfo(b)
+	if err != nil || terminfoColors >= e.numCount {
+		return 0, err
+	}
+
+	var n int
+	off := e.numbers + terminfoColors*e.numSize
+	if e.numSize == 4 { //nolint:gomnd
+		n = int(int32(binary.LittleEndian.Uint32(b[off:])))
+	} else {
+		n = int(int16(binary.LittleEndian.Uint16(b[off:])))
+	}
+	if n < 0 {
+		// The capability is absent or cancelled.
+		return 0, nil
+	}
+	return n, nil
+}
+
+// parseTerminfoTextAttrs returns the text attributes listed by a compiled
+// terminfo entry.
+func parseTerminfoTextAttrs(b []byte) (TextAttrs, error) {
+	e, err := parseTerminfo(b)
+	if err != nil {
+		return TextAttrs{}, err
+	}
+
+	_, italic := e.str(terminfoItalic)
+	ext := e.extended()
+	return TextAttrs{
+		Italic:         italic,
+		Strikethrough:  ext["smxx"],
+		Undercurl:      ext["Smulx"],
+		UnderlineColor: ext["Setulc"],
+	}, nil
+}
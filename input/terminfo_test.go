@@ -0,0 +1,265 @@
+package input
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// compileTerminfo returns a compiled terminfo entry with the given string
+// capabilities, by index.
+func compileTerminfo(magic int, nums []int, strs map[int]string) []byte {
+	names := "test|a test terminal\x00"
+	strCount := 0
+	for i := range strs {
+		if i >= strCount {
+			strCount = i + 1
+		}
+	}
+	var table []byte
+	offsets := make([]int, strCount)
+	for i := range offsets {
+		s, ok := strs[i]
+		if !ok {
+			offsets[i] = -1
+			continue
+		}
+		offsets[i] = len(table)
+		table = append(append(table, s...), 0)
+	}
+
+	var b []byte
+	put := func(v int) {
+		var buf [2]byte
+		binary.LittleEndian.PutUint16(buf[:], uint16(int16(v)))
+		b = append(b, buf[:]...)
+	}
+	put(magic)
+	put(len(names))
+	put(1) // booleans
+	put(len(nums))
+	put(strCount)
+	put(len(table))
+	b = append(b, names...)
+	b = append(b, 1)
+	if len(b)%2 != 0 {
+		b = append(b, 0)
+	}
+	numSize := 2
+	if magic == terminfoMagic32 {
+		numSize = 4
+	}
+	for _, n := range nums {
+		if numSize == 4 {
+			var buf [4]byte
+			binary.LittleEndian.PutUint32(buf[:], uint32(int32(n)))
+			b = append(b, buf[:]...)
+		} else {
+			put(n)
+		}
+	}
+	for _, off := range offsets {
+		put(off)
+	}
+	return append(b, table...)
+}
+
+func TestParseTerminfoKeys(t *testing.T) {
+	strs := map[int]string{
+		5:   "\x1b[H\x1b[2J", // clear, not a key
+		55:  "\x08",
+		76:  "\x1bOH",
+		87:  "\x1bOA",
+		164: "\x1bOF",
+	}
+	expected := map[string]Key{
+		"\x08":   {Type: KeyBackspace},
+		"\x1bOH": {Type: KeyHome},
+		"\x1bOA": {Type: KeyUp},
+		"\x1bOF": {Type: KeyEnd},
+	}
+	for _, magic := range []int{terminfoMagic, terminfoMagic32} {
+		keys, err := parseTerminfoKeys(compileTerminfo(magic, []int{0}, strs))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(keys, expected) {
+			t.Errorf("expected keys %q, got %q", expected, keys)
+		}
+	}
+
+	if _, err := parseTerminfoKeys([]byte("not terminfo")); err == nil {
+		t.Error("expected an error for an invalid entry")
+	}
+	if _, err := parseTerminfoKeys(compileTerminfo(terminfoMagic, []int{0}, strs)[:40]); err == nil {
+		t.Error("expected an error for a truncated entry")
+	}
+}
+
+func TestTerminfoKeys(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "t"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	entry := compileTerminfo(terminfoMagic, []int{0}, map[int]string{87: "\x1bOA"})
+	if err := os.WriteFile(filepath.Join(dir, "t", "test"), entry, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TERMINFO", dir)
+
+	keys, err := TerminfoKeys("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k := keys["\x1bOA"]; k.Type != KeyUp {
+		t.Errorf("expected up, got %v", k)
+	}
+
+	if _, err := TerminfoKeys("../test"); err == nil {
+		t.Error("expected an error for an invalid name")
+	}
+}
+
+func TestParseTerminfoColors(t *testing.T) {
+	nums := func(colors int) []int {
+		n := make([]int, terminfoColors+1)
+		n[terminfoColors] = colors
+		return n
+	}
+
+	tests := []struct {
+		name     string
+		entry    []byte
+		expected int
+	}{
+		{"legacy", compileTerminfo(terminfoMagic, nums(256), nil), 256},
+		{"32-bit", compileTerminfo(terminfoMagic32, nums(1<<24), nil), 1 << 24},
+		{"absent", compileTerminfo(terminfoMagic, nums(-1), nil), 0},
+		{"not listed", compileTerminfo(terminfoMagic, []int{80}, nil), 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			n, err := parseTerminfoColors(test.entry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != test.expected {
+				t.Errorf("expected %d colors, got %d", test.expected, n)
+			}
+		})
+	}
+}
+
+func TestDecoderKeys(t *testing.T) {
+	d := NewDecoder(bytes.NewReader(nil))
+	d.Keys = map[string]Key{
+		"\x1bOH": {Type: KeyHome},
+		"\x1b[A": {Type: KeyDown},
+	}
+	events, err := d.parse([]byte("\x1bOH\x1b[A\x1b[B"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Event{Key{Type: KeyHome}, Key{Type: KeyDown}, Key{Type: KeyDown}}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected %#v, got %#v", expected, events)
+	}
+}
+
+// appendExtended appends extended string capabilities, by name, to a
+// compiled terminfo entry, along with a boolean and a cancelled number.
+func appendExtended(b []byte, strs map[string]string) []byte {
+	if len(b)%2 != 0 {
+		b = append(b, 0)
+	}
+	put := func(v int) {
+		var buf [2]byte
+		binary.LittleEndian.PutUint16(buf[:], uint16(int16(v)))
+		b = append(b, buf[:]...)
+	}
+
+	var strNames []string
+	for name := range strs {
+		strNames = append(strNames, name)
+	}
+	sort.Strings(strNames)
+	names := append([]string{"AX", "Nx"}, strNames...)
+
+	var values, nameTable []byte
+	var valueOffsets, nameOffsets []int
+	for _, name := range strNames {
+		valueOffsets = append(valueOffsets, len(values))
+		values = append(append(values, strs[name]...), 0)
+	}
+	for _, name := range names {
+		nameOffsets = append(nameOffsets, len(nameTable))
+		nameTable = append(append(nameTable, name...), 0)
+	}
+
+	put(1) // booleans
+	put(1) // numbers
+	put(len(strNames))
+	put(len(strNames) + len(names))
+	put(len(values) + len(nameTable))
+	b = append(b, 1, 0) // AX, aligned
+	if binary.LittleEndian.Uint16(b) == terminfoMagic32 {
+		cancelled := int32(-2)
+		var buf [4]byte
+		binary.LittleEndian.PutUint32(buf[:], uint32(cancelled))
+		b = append(b, buf[:]...) // Nx
+	} else {
+		put(-2) // Nx, cancelled
+	}
+	for _, off := range valueOffsets {
+		put(off)
+	}
+	for _, off := range nameOffsets {
+		put(off)
+	}
+	return append(append(b, values...), nameTable...)
+}
+
+func TestParseTerminfoTextAttrs(t *testing.T) {
+	tests := []struct {
+		name     string
+		entry    []byte
+		expected TextAttrs
+	}{
+		{
+			name:  "none",
+			entry: compileTerminfo(terminfoMagic, nil, nil),
+		},
+		{
+			name:     "italic",
+			entry:    compileTerminfo(terminfoMagic, nil, map[int]string{terminfoItalic: "\x1b[3m"}),
+			expected: TextAttrs{Italic: true},
+		},
+		{
+			name: "extended",
+			entry: appendExtended(compileTerminfo(terminfoMagic, nil, map[int]string{terminfoItalic: "\x1b[3m"}),
+				map[string]string{"smxx": "\x1b[9m", "Smulx": "\x1b[4:%p1%dm", "Ss": "\x1b[%p1%d q"}),
+			expected: TextAttrs{Italic: true, Strikethrough: true, Undercurl: true},
+		},
+		{
+			name: "extended 32-bit",
+			entry: appendExtended(compileTerminfo(terminfoMagic32, []int{0}, nil),
+				map[string]string{"Setulc": "\x1b[58:2::%p1%{65536}%/%d:%p1%{256}%/%{255}%&%d:%p1%{255}%&%d%;m"}),
+			expected: TextAttrs{UnderlineColor: true},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attrs, err := parseTerminfoTextAttrs(test.entry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if attrs != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, attrs)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package input
+
+import (
+	"bytes"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Control key state bits of win32-input-mode key events, as in the Windows
+// console's KEY_EVENT_RECORD.
+const (
+	win32RightAlt  = 0x1
+	win32LeftAlt   = 0x2
+	win32RightCtrl = 0x4
+	win32LeftCtrl  = 0x8
+	win32Shift     = 0x10
+)
+
+// Virtual key codes of the keys which are reported without a character.
+const (
+	vkBack   = 0x08
+	vkTab    = 0x09
+	vkReturn = 0x0d
+	vkEscape = 0x1b
+	vkSpace  = 0x20
+	vkF1     = 0x70
+	vkF20    = 0x83
+)
+
+// win32Keys maps the virtual key codes of cursor and editing keys to the
+// parameter and final byte of their xterm sequences.
+var win32Keys = map[int]struct {
+	param string
+	final byte
+}{
+	0x21: {"5", '~'}, // page up
+	0x22: {"6", '~'}, // page down
+	0x23: {"1", 'F'}, // end
+	0x24: {"1", 'H'}, // home
+	0x25: {"1", 'D'}, // left
+	0x26: {"1", 'A'}, // up
+	0x27: {"1", 'C'}, // right
+	0x28: {"1", 'B'}, // down
+	0x2d: {"2", '~'}, // insert
+	0x2e: {"3", '~'}, // delete
+}
+
+// win32OtherKeys maps the virtual key codes of keys which don't have an xterm
+// sequence to key types.
+var win32OtherKeys = map[int]KeyType{
+	0x2c: KeyPrintScreen,
+	0x5d: KeyMenu,
+	0x84: KeyF21,
+	0x85: KeyF22,
+	0x86: KeyF23,
+	0x87: KeyF24,
+	0xad: KeyVolumeMute,
+	0xae: KeyVolumeDown,
+	0xaf: KeyVolumeUp,
+	0xb0: KeyMediaNext,
+	0xb1: KeyMediaPrev,
+	0xb2: KeyMediaStop,
+	0xb3: KeyMediaPlayPause,
+}
+
+// win32FunctionKeys are the parameters of the xterm sequences of F5 through
+// F20. F1 through F4 use SS3 sequences instead.
+var win32FunctionKeys = []string{
+	"15", "17", "18", "19", "20", "21", "23", "24",
+	"25", "26", "28", "29", "31", "32", "33", "34",
+}
+
+// parseWin32Key parses a key event in the format of win32-input-mode, which
+// Windows Terminal and ConPTY use once it's enabled:
+//
+//	CSI Vk ; Sc ; Uc ; Kd ; Cs ; Rc _
+//
+// That's the virtual key code, scan code, character, whether the key is down,
+// control key state and repeat count, any of which may be omitted. It returns
+// the event and the length of the sequence, or a length of zero if b doesn't
+// start with such a sequence.
+//
+// Characters are reported in UTF-16, so characters outside the basic
+// multilingual plane, such as most emoji, take two events, one for each half
+// of the surrogate pair. They're combined into a single key.
+func parseWin32Key(b []byte) (Event, int) {
+	params, n := win32Params(b)
+	if n == 0 {
+		return nil, 0
+	}
+	vk, char, down, state := params[0], rune(params[2]), params[3] != 0, params[4]
+
+	if utf16.IsSurrogate(char) {
+		next, m := win32Params(b[n:])
+		if m == 0 || next[3] != params[3] {
+			// Half a character is of no use. Drop it.
+			return nil, n
+		}
+		char = utf16.DecodeRune(char, rune(next[2]))
+		if char == utf8.RuneError {
+			return nil, n
+		}
+		n += m
+	}
+
+	k, ok := win32Key(vk, char, state)
+	if !ok {
+		// Keys we don't have a representation for, such as modifier keys
+		// on their own, are dropped.
+		return nil, n
+	}
+	if !down {
+		k.Action = KeyActionRelease
+		return KeyRelease(k), n
+	}
+	return k, n
+}
+
+// win32Params parses the parameters of a win32-input-mode key event, filling
+// in omitted ones. It returns the parameters and the length of the sequence,
+// or a length of zero if b doesn't start with such a sequence.
+func win32Params(b []byte) ([]int, int) {
+	if !bytes.HasPrefix(b, []byte("\x1b[")) {
+		return nil, 0
+	}
+	i := 2
+	for i < len(b) && (b[i] >= '0' && b[i] <= '9' || b[i] == ';') {
+		i++
+	}
+	if i == 2 || i >= len(b) || b[i] != '_' {
+		return nil, 0
+	}
+	params := parseParams(b[2:i])
+	for len(params) < 6 { //nolint:gomnd
+		params = append(params, 0)
+	}
+	return params, i + 1
+}
+
+// win32Key translates a virtual key code, the character it produced, if any,
+// and the control key state into a Key.
+func win32Key(vk int, char rune, state int) (Key, bool) {
+	alt := state&(win32LeftAlt|win32RightAlt) != 0
+	ctrl := state&(win32LeftCtrl|win32RightCtrl) != 0
+	shift := state&win32Shift != 0
+
+	switch vk {
+	case vkReturn:
+		return Key{Type: KeyEnter, Alt: alt}, true
+	case vkTab:
+		if shift {
+			return Key{Type: KeyShiftTab, Alt: alt}, true
+		}
+		return Key{Type: KeyTab, Alt: alt}, true
+	case vkEscape:
+		return Key{Type: KeyEsc, Alt: alt}, true
+	case vkBack:
+		return Key{Type: KeyBackspace, Alt: alt}, true
+	}
+
+	if char == 0 {
+		if vk == vkSpace && ctrl {
+			return Key{Type: KeyCtrlAt, Alt: alt}, true
+		}
+		return win32FunctionalKey(vk, alt, ctrl, shift)
+	}
+
+	switch {
+	case char == rune(keyDEL):
+		return Key{Type: KeyBackspace, Alt: alt}, true
+	case char < ' ':
+		// Control characters, such as ctrl+a.
+		return Key{Type: KeyType(char), Alt: alt}, true
+	case ctrl && alt:
+		// Windows reports AltGr as ctrl+alt. As the key produced a
+		// character, that's what the modifiers were for.
+		alt = false
+	}
+	if char == ' ' {
+		return Key{Type: KeySpace, Runes: []rune{char}, Alt: alt}, true
+	}
+	return Key{Type: KeyRunes, Runes: []rune{char}, Alt: alt}, true
+}
+
+// win32FunctionalKey translates the virtual key code of a key which doesn't
+// produce a character, such as an arrow or function key, by looking up its
+// xterm sequence.
+func win32FunctionalKey(vk int, alt, ctrl, shift bool) (Key, bool) {
+	var param, plain string
+	var final byte
+	switch {
+	case vk >= vkF1 && vk < vkF1+4:
+		final = "PQRS"[vk-vkF1]
+		param, plain = "1", "\x1bO"+string(final)
+	case vk > vkF1+3 && vk <= vkF20:
+		final = '~'
+		param = win32FunctionKeys[vk-vkF1-4]
+		plain = "\x1b[" + param + "~"
+	default:
+		if t, ok := win32OtherKeys[vk]; ok {
+			return Key{Type: t, Alt: alt}, true
+		}
+		seq, ok := win32Keys[vk]
+		if !ok {
+			return Key{}, false
+		}
+		param, final = seq.param, seq.final
+		plain = "\x1b["
+		if final == '~' {
+			plain += param
+		}
+		plain += string(final)
+	}
+
+	mods := 1
+	if shift {
+		mods += kittyShift
+	}
+	if alt {
+		mods += kittyAlt
+	}
+	if ctrl {
+		mods += kittyCtrl
+	}
+	if mods > 1 {
+		if k, ok := sequences["\x1b["+param+";"+strconv.Itoa(mods)+string(final)]; ok {
+			return k, true
+		}
+	}
+
+	// There's no key for the combination of modifiers, so keep alt, if
+	// anything.
+	k, ok := sequences[plain]
+	if !ok {
+		return Key{}, false
+	}
+	k.Alt = k.Alt || alt
+	return k, true
+}
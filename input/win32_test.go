@@ -0,0 +1,70 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseWin32Key(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		event Event
+		n     int
+	}{
+		{"letter", "\x1b[65;30;97;1;0;1_", Key{Type: KeyRunes, Runes: []rune("a")}, 17},
+		{"shifted letter", "\x1b[65;30;65;1;16;1_", Key{Type: KeyRunes, Runes: []rune("A")}, 18},
+		{"alt letter", "\x1b[65;30;97;1;2;1_", Key{Type: KeyRunes, Runes: []rune("a"), Alt: true}, 17},
+		{"altgr", "\x1b[81;16;64;1;9;1_", Key{Type: KeyRunes, Runes: []rune("@")}, 17},
+		{"ctrl letter", "\x1b[65;30;1;1;8;1_", Key{Type: KeyCtrlA}, 16},
+		{"ctrl space", "\x1b[32;57;0;1;8;1_", Key{Type: KeyCtrlAt}, 16},
+		{"space", "\x1b[32;57;32;1;0;1_", Key{Type: KeySpace, Runes: []rune(" ")}, 17},
+		{"enter", "\x1b[13;28;13;1;0;1_", Key{Type: KeyEnter}, 17},
+		{"shift tab", "\x1b[9;15;9;1;16;1_", Key{Type: KeyShiftTab}, 16},
+		{"backspace", "\x1b[8;14;8;1;0;1_", Key{Type: KeyBackspace}, 15},
+		{"escape", "\x1b[27;1;27;1;0;1_", Key{Type: KeyEsc}, 16},
+		{"up", "\x1b[38;72;0;1;0;1_", Key{Type: KeyUp}, 16},
+		{"ctrl shift up", "\x1b[38;72;0;1;24;1_", Key{Type: KeyCtrlShiftUp}, 17},
+		{"ctrl page up", "\x1b[33;73;0;1;4;1_", Key{Type: KeyCtrlPgUp}, 16},
+		{"f1", "\x1b[112;59;0;1;0;1_", Key{Type: KeyF1}, 17},
+		{"f12", "\x1b[123;88;0;1;0;1_", Key{Type: KeyF12}, 17},
+		{"shift f5", "\x1b[116;63;0;1;16;1_", Key{Type: KeyF17}, 18},
+		{"f24", "\x1b[135;0;0;1;0;1_", Key{Type: KeyF24}, 16},
+		{"print screen", "\x1b[44;55;0;1;0;1_", Key{Type: KeyPrintScreen}, 16},
+		{"volume up", "\x1b[175;0;0;1;0;1_", Key{Type: KeyVolumeUp}, 16},
+		{"alt home", "\x1b[36;71;0;1;1;1_", Key{Type: KeyHome, Alt: true}, 16},
+		{
+			"release",
+			"\x1b[65;30;97;0;0;1_",
+			KeyRelease{Type: KeyRunes, Runes: []rune("a"), Action: KeyActionRelease},
+			17,
+		},
+		{
+			"surrogate pair",
+			"\x1b[0;0;55357;1;0;1_\x1b[0;0;56832;1;0;1_",
+			Key{Type: KeyRunes, Runes: []rune("😀")},
+			36,
+		},
+		{
+			"surrogate pair release",
+			"\x1b[0;0;55357;0;0;1_\x1b[0;0;56832;0;0;1_",
+			KeyRelease{Type: KeyRunes, Runes: []rune("😀"), Action: KeyActionRelease},
+			36,
+		},
+		{"lone surrogate", "\x1b[0;0;55357;1;0;1_a", nil, 18},
+		{"omitted parameters", "\x1b[13;;13;1_", Key{Type: KeyEnter}, 11},
+		{"modifier key", "\x1b[16;42;0;1;16;1_", nil, 17},
+		{"not win32", "\x1b[1;5A", nil, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e, n := parseWin32Key([]byte(test.in))
+			if n != test.n {
+				t.Errorf("expected %d bytes to be consumed, got %d", test.n, n)
+			}
+			if !reflect.DeepEqual(e, test.event) {
+				t.Errorf("expected %#v, got %#v", test.event, e)
+			}
+		})
+	}
+}
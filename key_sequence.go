@@ -0,0 +1,299 @@
+package tea
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultKeySequenceTimeout is how long the next key of a sequence is waited
+// for, unless set with WithKeySequenceTimeout.
+const defaultKeySequenceTimeout = time.Second
+
+// KeySequenceMsg is sent when the keys of a sequence registered with
+// RegisterKeySequence are pressed one after another. The keys aren't sent on
+// their own.
+type KeySequenceMsg []KeyMsg
+
+// String returns the keys of the sequence, separated by spaces, such as
+// "ctrl+x ctrl+s", which is handy for comparing against sequences, like
+// KeyMsg.String.
+func (m KeySequenceMsg) String() string {
+	return strings.Join(keyStrings(m), " ")
+}
+
+// registerKeySequenceMsg is an internal message that registers a key
+// sequence. You can send a registerKeySequenceMsg with RegisterKeySequence.
+type registerKeySequenceMsg []string
+
+// unregisterKeySequenceMsg is an internal message that removes a key
+// sequence. You can send an unregisterKeySequenceMsg with
+// UnregisterKeySequence.
+type unregisterKeySequenceMsg []string
+
+// RegisterKeySequence is a command that registers a sequence of keys, such as
+// "g", "g" or "ctrl+x", "ctrl+s", given as the strings KeyMsg.String returns.
+// When the keys are pressed one after another, a single KeySequenceMsg is sent
+// instead of their KeyMsgs.
+//
+// Keys which may start a sequence are held back until the sequence is
+// complete, another key breaks it, or the next key doesn't come in time; see
+// WithKeySequenceTimeout. Keys which turn out not to form a sequence are then
+// sent on their own, in order. If a sequence is also the beginning of a longer
+// one, it's only sent once the time is up.
+//
+// Only keys read from the terminal are recognized as sequences.
+func RegisterKeySequence(keys ...string) Cmd {
+	return func() Msg {
+		return registerKeySequenceMsg(keys)
+	}
+}
+
+// UnregisterKeySequence is a command that removes a key sequence registered
+// with RegisterKeySequence.
+func UnregisterKeySequence(keys ...string) Cmd {
+	return func() Msg {
+		return unregisterKeySequenceMsg(keys)
+	}
+}
+
+// keySequencer recognizes registered key sequences in the input read from
+// the terminal.
+type keySequencer struct {
+	timeout time.Duration
+
+	// held while input is delivered, which may block, so that it's delivered
+	// in order. The program may change the sequences meanwhile.
+	delivering sync.Mutex
+
+	mtx       sync.Mutex
+	sequences [][]string
+
+	// the input held back while a sequence may be typed
+	pending []Msg
+
+	// delivers pending input when the next key doesn't come in time
+	timer   *time.Timer
+	deliver func(Msg)
+}
+
+// register adds a key sequence.
+func (s *keySequencer) register(keys []string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if len(keys) > 0 && s.index(keys) < 0 {
+		s.sequences = append(s.sequences, keys)
+	}
+}
+
+// unregister removes a key sequence.
+func (s *keySequencer) unregister(keys []string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if i := s.index(keys); i >= 0 {
+		s.sequences = append(s.sequences[:i], s.sequences[i+1:]...)
+	}
+}
+
+// reset removes all key sequences, and drops the keys held back while one may
+// be typed.
+func (s *keySequencer) reset() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.stopTimer()
+	s.sequences, s.pending = nil, nil
+}
+
+// index returns the index of the given sequence, or -1 if it isn't
+// registered. The mutex must be held.
+func (s *keySequencer) index(keys []string) int {
+	for i, seq := range s.sequences {
+		if equalKeys(seq, keys) {
+			return i
+		}
+	}
+	return -1
+}
+
+// track delivers input read from the terminal, holding back keys which may
+// be part of a sequence.
+func (s *keySequencer) track(msg Msg, deliver func(Msg)) {
+	s.delivering.Lock()
+	defer s.delivering.Unlock()
+
+	for _, msg := range s.sort(msg, deliver) {
+		deliver(msg)
+	}
+}
+
+// sort returns the input to deliver now, holding back keys which may be part
+// of a sequence.
+func (s *keySequencer) sort(msg Msg, deliver func(Msg)) []Msg {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.deliver = deliver
+	if len(s.sequences) == 0 && len(s.pending) == 0 {
+		return []Msg{msg}
+	}
+
+	switch msg := msg.(type) {
+	case KeyMsg:
+		s.stopTimer()
+		var out []Msg
+		if !s.isPrefix(append(s.pendingKeys(), msg.String())) {
+			// The key breaks the sequence, but may start another.
+			out = s.flush()
+			if !s.isPrefix([]string{msg.String()}) {
+				return append(out, msg)
+			}
+		}
+		return append(out, s.hold(msg)...)
+
+	case RawInputMsg:
+		// Raw input doesn't break sequences either, but is delivered right
+		// away, ahead of the keys parsed from it.
+		return []Msg{msg}
+
+	case KeyReleaseMsg:
+		// Releases don't break sequences, and are delivered after the keys
+		// they follow.
+		if len(s.pending) > 0 {
+			s.pending = append(s.pending, msg)
+			return nil
+		}
+		return []Msg{msg}
+
+	default:
+		s.stopTimer()
+		return append(s.flush(), msg)
+	}
+}
+
+// hold holds back a key of a sequence, until the sequence is complete or the
+// next key doesn't come in time. It returns the input to deliver now, if the
+// sequence is complete. The mutex must be held.
+func (s *keySequencer) hold(msg KeyMsg) []Msg {
+	s.pending = append(s.pending, msg)
+	if s.isLongest(s.pendingKeys()) {
+		return s.flush()
+	}
+
+	var t *time.Timer
+	t = time.AfterFunc(s.wait(), func() {
+		s.delivering.Lock()
+		defer s.delivering.Unlock()
+
+		s.mtx.Lock()
+		if s.timer != t {
+			// Stopped too late.
+			s.mtx.Unlock()
+			return
+		}
+		s.timer = nil
+		msgs, deliver := s.flush(), s.deliver
+		s.mtx.Unlock()
+
+		for _, msg := range msgs {
+			deliver(msg)
+		}
+	})
+	s.timer = t
+	return nil
+}
+
+// flush returns the pending input to deliver: as a KeySequenceMsg if its keys
+// form a sequence, or as it is otherwise. The mutex must be held.
+func (s *keySequencer) flush() []Msg {
+	if len(s.pending) == 0 {
+		return nil
+	}
+	pending := s.pending
+	s.pending = nil
+
+	var seq KeySequenceMsg
+	var rest []Msg
+	for _, msg := range pending {
+		if k, ok := msg.(KeyMsg); ok {
+			seq = append(seq, k)
+		} else {
+			rest = append(rest, msg)
+		}
+	}
+	if s.index(keyStrings(seq)) < 0 {
+		return pending
+	}
+	return append([]Msg{seq}, rest...)
+}
+
+// stopTimer stops waiting for the next key. The mutex must be held.
+func (s *keySequencer) stopTimer() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+}
+
+// wait returns how long the next key of a sequence is waited for.
+func (s *keySequencer) wait() time.Duration {
+	if s.timeout > 0 {
+		return s.timeout
+	}
+	return defaultKeySequenceTimeout
+}
+
+// pendingKeys returns the keys held back so far. The mutex must be held.
+func (s *keySequencer) pendingKeys() []string {
+	var keys []string
+	for _, msg := range s.pending {
+		if k, ok := msg.(KeyMsg); ok {
+			keys = append(keys, k.String())
+		}
+	}
+	return keys
+}
+
+// isPrefix reports whether the keys are the beginning of a sequence, or a
+// whole one. The mutex must be held.
+func (s *keySequencer) isPrefix(keys []string) bool {
+	for _, seq := range s.sequences {
+		if len(seq) >= len(keys) && equalKeys(seq[:len(keys)], keys) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLongest reports whether the keys are a whole sequence which isn't the
+// beginning of a longer one. The mutex must be held.
+func (s *keySequencer) isLongest(keys []string) bool {
+	for _, seq := range s.sequences {
+		if len(seq) > len(keys) && equalKeys(seq[:len(keys)], keys) {
+			return false
+		}
+	}
+	return s.index(keys) >= 0
+}
+
+func keyStrings(keys []KeyMsg) []string {
+	s := make([]string, len(keys))
+	for i, k := range keys {
+		s[i] = k.String()
+	}
+	return s
+}
+
+func equalKeys(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
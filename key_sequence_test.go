@@ -0,0 +1,191 @@
+package tea
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestKeySequencer(t *testing.T) {
+	key := func(s string) KeyMsg {
+		return KeyMsg{Type: KeyRunes, Runes: []rune(s)}
+	}
+	ctrl := func(typ KeyType) KeyMsg {
+		return KeyMsg{Type: typ}
+	}
+	release := KeyReleaseMsg{Type: KeyRunes, Runes: []rune("g")}
+
+	tests := []struct {
+		name     string
+		input    []Msg
+		expected []Msg
+	}{
+		{
+			"sequence",
+			[]Msg{key("g"), release, key("g")},
+			[]Msg{KeySequenceMsg{key("g"), key("g")}, release},
+		},
+		{
+			"two keys",
+			[]Msg{ctrl(KeyCtrlX), ctrl(KeyCtrlS)},
+			[]Msg{KeySequenceMsg{ctrl(KeyCtrlX), ctrl(KeyCtrlS)}},
+		},
+		{
+			"other keys",
+			[]Msg{key("a"), key("b")},
+			[]Msg{key("a"), key("b")},
+		},
+		{
+			"broken",
+			[]Msg{key("g"), key("a")},
+			[]Msg{key("g"), key("a")},
+		},
+		{
+			"broken by another sequence",
+			[]Msg{key("g"), ctrl(KeyCtrlX), ctrl(KeyCtrlS)},
+			[]Msg{key("g"), KeySequenceMsg{ctrl(KeyCtrlX), ctrl(KeyCtrlS)}},
+		},
+		{
+			"broken by the mouse",
+			[]Msg{key("g"), MouseMsg{Type: MouseLeft}},
+			[]Msg{key("g"), MouseMsg{Type: MouseLeft}},
+		},
+		{
+			"raw input",
+			[]Msg{RawInputMsg{Bytes: []byte("g")}, key("g"), RawInputMsg{Bytes: []byte("g")}, key("g")},
+			[]Msg{RawInputMsg{Bytes: []byte("g")}, RawInputMsg{Bytes: []byte("g")}, KeySequenceMsg{key("g"), key("g")}},
+		},
+		{
+			"timeout",
+			[]Msg{key("g")},
+			[]Msg{key("g")},
+		},
+		{
+			"shorter sequence on timeout",
+			[]Msg{key("d"), key("d")},
+			[]Msg{KeySequenceMsg{key("d"), key("d")}},
+		},
+		{
+			"longer sequence",
+			[]Msg{key("d"), key("d"), key("x")},
+			[]Msg{KeySequenceMsg{key("d"), key("d"), key("x")}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var mtx sync.Mutex
+			var got []Msg
+			deliver := func(msg Msg) {
+				mtx.Lock()
+				defer mtx.Unlock()
+				got = append(got, msg)
+			}
+
+			s := &keySequencer{timeout: 20 * time.Millisecond}
+			s.register([]string{"g", "g"})
+			s.register([]string{"ctrl+x", "ctrl+s"})
+			s.register([]string{"d", "d"})
+			s.register([]string{"d", "d", "x"})
+
+			for _, msg := range test.input {
+				s.track(msg, deliver)
+			}
+			time.Sleep(50 * time.Millisecond)
+
+			mtx.Lock()
+			defer mtx.Unlock()
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestKeySequenceMsgString(t *testing.T) {
+	seq := KeySequenceMsg{{Type: KeyCtrlX}, {Type: KeyRunes, Runes: []rune("s"), Alt: true}}
+	if s := seq.String(); s != "ctrl+x alt+s" {
+		t.Errorf(`expected "ctrl+x alt+s", got %q`, s)
+	}
+}
+
+func TestKeySequencerUnregister(t *testing.T) {
+	var got []Msg
+	s := &keySequencer{}
+	s.register([]string{"g", "g"})
+	s.unregister([]string{"g", "g"})
+	s.track(KeyMsg{Type: KeyRunes, Runes: []rune("g")}, func(msg Msg) {
+		got = append(got, msg)
+	})
+	if len(got) != 1 {
+		t.Errorf("expected the key to be delivered right away, got %v", got)
+	}
+}
+
+func TestKeySequencerReset(t *testing.T) {
+	var got []Msg
+	deliver := func(msg Msg) {
+		got = append(got, msg)
+	}
+	g := KeyMsg{Type: KeyRunes, Runes: []rune("g")}
+
+	s := &keySequencer{}
+	s.register([]string{"g", "g"})
+	s.track(g, deliver)
+	s.reset()
+
+	// The key held back is dropped, and the next isn't held back.
+	s.track(g, deliver)
+	if !reflect.DeepEqual(got, []Msg{g}) {
+		t.Errorf("expected only the key after the reset, got %v", got)
+	}
+}
+
+func TestKeySequencerDeliverUnlocked(t *testing.T) {
+	g := KeyMsg{Type: KeyRunes, Runes: []rune("g")}
+	x := KeyMsg{Type: KeyRunes, Runes: []rune("x")}
+
+	tests := []struct {
+		name  string
+		input []Msg
+	}{
+		{"passed through", []Msg{x}},
+		{"sequence broken", []Msg{g, x}},
+		{"sequence complete", []Msg{g, g}},
+		{"timed out", []Msg{g}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &keySequencer{timeout: time.Millisecond}
+			s.register([]string{"g", "g"})
+
+			// The program registers sequences while input is delivered to it,
+			// which must not wait for the delivery.
+			delivered := make(chan struct{}, 3)
+			deliver := func(Msg) {
+				registered := make(chan struct{})
+				go func() {
+					s.register([]string{"x", "y"})
+					s.unregister([]string{"x", "y"})
+					close(registered)
+				}()
+				select {
+				case <-registered:
+				case <-time.After(time.Second):
+					t.Error("registering a sequence waited for the delivery")
+				}
+				delivered <- struct{}{}
+			}
+			for _, msg := range test.input {
+				s.track(msg, deliver)
+			}
+
+			select {
+			case <-delivered:
+			case <-time.After(time.Second):
+				t.Fatal("nothing was delivered")
+			}
+		})
+	}
+}
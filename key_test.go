@@ -2,7 +2,10 @@ package tea
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"reflect"
 	"testing"
 )
 
@@ -25,6 +28,16 @@ func TestKeyString(t *testing.T) {
 		}
 	})
 
+	t.Run("paste", func(t *testing.T) {
+		if got := KeyMsg(Key{
+			Type:  KeyRunes,
+			Runes: []rune{'a'},
+			Paste: true,
+		}).String(); got != "[a]" {
+			t.Fatalf(`expected an "[a]", got %q`, got)
+		}
+	})
+
 	t.Run("invalid", func(t *testing.T) {
 		if got := KeyMsg(Key{
 			Type: KeyType(99999),
@@ -74,7 +87,7 @@ func TestReadInput(t *testing.T) {
 			},
 		},
 		{"ctrl+a",
-			[]byte{byte(keySOH)},
+			[]byte{byte(KeyCtrlA)},
 			[]Msg{
 				KeyMsg{
 					Type: KeyCtrlA,
@@ -154,7 +167,7 @@ func TestReadInput(t *testing.T) {
 			},
 		},
 		{"alt+ctrl+a",
-			[]byte{'\x1b', byte(keySOH)},
+			[]byte{'\x1b', byte(KeyCtrlA)},
 			[]Msg{
 				KeyMsg{
 					Type: KeyCtrlA,
@@ -191,6 +204,22 @@ func TestReadInput(t *testing.T) {
 			[]byte{'\x1b', '\x7f'},
 			[]Msg{KeyMsg{Type: KeyBackspace, Alt: true}},
 		},
+		{"[a b]",
+			[]byte("\x1b[200~a b\x1b[201~"),
+			[]Msg{PasteMsg{Content: "a b"}},
+		},
+		{"paste surrounded by keys",
+			[]byte("a\x1b[200~b\nc\x1b[201~d"),
+			[]Msg{
+				KeyMsg{Type: KeyRunes, Runes: []rune{'a'}},
+				PasteMsg{Content: "b\nc"},
+				KeyMsg{Type: KeyRunes, Runes: []rune{'d'}},
+			},
+		},
+		{"empty paste",
+			[]byte("\x1b[200~\x1b[201~"),
+			[]Msg{},
+		},
 	} {
 		t.Run(fmt.Sprintf("%d: %s", i, td.keyname), func(t *testing.T) {
 			msgs, err := readInputs(bytes.NewReader(td.in))
@@ -212,13 +241,153 @@ func TestReadInput(t *testing.T) {
 					m.String() != td.out[i].(KeyMsg).String() {
 					t.Fatalf(`expected a keymsg %q, got %q`, td.out[i].(KeyMsg), m)
 				}
+				if m, ok := v.(PasteMsg); ok && m != td.out[i] {
+					t.Fatalf(`expected a pastemsg %#v, got %#v`, td.out[i], m)
+				}
 				if m, ok := v.(MouseMsg); ok &&
-					(mouseEventTypes[m.Type] != td.keyname || m.Type != td.out[i].(MouseMsg).Type) {
+					(MouseEvent{Type: m.Type}.String() != td.keyname || m.Type != td.out[i].(MouseMsg).Type) {
 					t.Fatalf(`expected a mousemsg %q, got %q`,
 						td.keyname,
-						mouseEventTypes[td.out[i].(MouseMsg).Type])
+						MouseEvent{Type: td.out[i].(MouseMsg).Type}.String())
 				}
 			}
 		})
 	}
 }
+
+// chunkedReader returns the given chunks of input, one per call to Read.
+type chunkedReader struct {
+	chunks [][]byte
+}
+
+func (r *chunkedReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.chunks[0])
+	r.chunks = r.chunks[1:]
+	return n, nil
+}
+
+func TestReadInputBracketedPasteAcrossReads(t *testing.T) {
+	r := &chunkedReader{chunks: [][]byte{
+		[]byte("\x1b[200~hello "),
+		[]byte("world\x1b[20"),
+		[]byte("1~x"),
+	}}
+
+	msgs, err := readInputs(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(msgs))
+	}
+	if p, ok := msgs[0].(PasteMsg); !ok || p.Content != "hello world" {
+		t.Errorf("expected a paste of %q, got %#v", "hello world", msgs[0])
+	}
+	if k := msgs[1].(KeyMsg); k.String() != "x" {
+		t.Errorf("expected a keymsg %q, got %q", "x", k)
+	}
+}
+
+func TestReadInputStreamedPaste(t *testing.T) {
+	r := &chunkedReader{chunks: [][]byte{[]byte("\x1b[200~hello\x1b[201~")}}
+	msgs, err := readInputsWith(r, inputConfig{pasteChunkSize: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Msg{
+		PasteStartMsg{},
+		PasteChunkMsg{Content: "hel"},
+		PasteChunkMsg{Content: "lo"},
+		PasteEndMsg{},
+	}
+	if !reflect.DeepEqual(msgs, expected) {
+		t.Errorf("expected %#v, got %#v", expected, msgs)
+	}
+}
+
+func TestReadInputBracketedPasteUnterminated(t *testing.T) {
+	r := &chunkedReader{chunks: [][]byte{[]byte("\x1b[200~hello")}}
+	if _, err := readInputs(r); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+}
+
+func TestReadInputUnknownSequences(t *testing.T) {
+	r := bytes.NewReader([]byte("\x1b[99;99z"))
+	msgs, err := readInputsWith(r, inputConfig{reportUnknown: true, reportRaw: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Msg{
+		RawInputMsg{Bytes: []byte("\x1b[99;99z")},
+		UnknownSequenceMsg{Bytes: []byte("\x1b[99;99z")},
+	}
+	if !reflect.DeepEqual(msgs, expected) {
+		t.Errorf("expected %#v, got %#v", expected, msgs)
+	}
+}
+
+func TestReadInputsThroughput(t *testing.T) {
+	tests := []struct {
+		name     string
+		chunks   []string
+		cfg      inputConfig
+		expected []string
+		left     int
+	}{
+		{
+			name:     "low latency split sequence",
+			chunks:   []string{"\x1b[", "A"},
+			expected: []string{"alt+["},
+			left:     1,
+		},
+		{
+			name:     "throughput split sequence",
+			chunks:   []string{"\x1b[", "A"},
+			cfg:      inputConfig{mode: InputThroughput},
+			expected: []string{"up"},
+		},
+		{
+			name:     "throughput full buffer",
+			chunks:   []string{"\x1b[A\x1b", "[B", "\x1b[C"},
+			cfg:      inputConfig{bufferSize: 4, mode: InputThroughput},
+			expected: []string{"up", "down"},
+			left:     1,
+		},
+		{
+			name:     "throughput lone escape",
+			chunks:   []string{"\x1b", "a"},
+			cfg:      inputConfig{mode: InputThroughput},
+			expected: []string{"esc"},
+			left:     1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &chunkedReader{}
+			for _, c := range test.chunks {
+				r.chunks = append(r.chunks, []byte(c))
+			}
+
+			msgs, err := readInputsWith(r, test.cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var keys []string
+			for _, msg := range msgs {
+				keys = append(keys, msg.(KeyMsg).String())
+			}
+			if !reflect.DeepEqual(keys, test.expected) {
+				t.Errorf("expected keys %q, got %q", test.expected, keys)
+			}
+			if len(r.chunks) != test.left {
+				t.Errorf("expected %d chunks left to read, got %d", test.left, len(r.chunks))
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package tea
+
+// translateKey applies the key translator set with WithKeyTranslator to keys
+// and key releases. It returns false if the key is to be dropped.
+func translateKey(translate func(KeyMsg) KeyMsg, msg Msg) (Msg, bool) {
+	switch m := msg.(type) {
+	case KeyMsg:
+		k := translate(m)
+		return k, !isDroppedKey(k)
+	case KeyReleaseMsg:
+		k := translate(KeyMsg(m))
+		k.Action = KeyActionRelease
+		return KeyReleaseMsg(k), !isDroppedKey(k)
+	}
+	return msg, true
+}
+
+// isDroppedKey reports whether a key translator has dropped the key, by
+// returning a KeyRunes key without runes.
+func isDroppedKey(k KeyMsg) bool {
+	return k.Type == KeyRunes && len(k.Runes) == 0
+}
@@ -0,0 +1,68 @@
+package tea
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTranslateKey(t *testing.T) {
+	swap := func(k KeyMsg) KeyMsg {
+		switch k.Type {
+		case KeyCtrlH:
+			return KeyMsg{Type: KeyBackspace, Alt: k.Alt, Action: k.Action}
+		case KeyCtrlZ:
+			return KeyMsg{Type: KeyRunes}
+		}
+		return k
+	}
+
+	tests := []struct {
+		name     string
+		in       Msg
+		expected Msg
+		ok       bool
+	}{
+		{"translated", KeyMsg{Type: KeyCtrlH, Alt: true}, KeyMsg{Type: KeyBackspace, Alt: true}, true},
+		{"unchanged", KeyMsg{Type: KeyEnter}, KeyMsg{Type: KeyEnter}, true},
+		{"dropped", KeyMsg{Type: KeyCtrlZ}, KeyMsg{Type: KeyRunes}, false},
+		{
+			"release",
+			KeyReleaseMsg{Type: KeyCtrlH, Action: KeyActionRelease},
+			KeyReleaseMsg{Type: KeyBackspace, Action: KeyActionRelease},
+			true,
+		},
+		{"dropped release", KeyReleaseMsg{Type: KeyCtrlZ, Action: KeyActionRelease}, nil, false},
+		{"not a key", MouseMsg{Type: MouseLeft}, MouseMsg{Type: MouseLeft}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			msg, ok := translateKey(swap, test.in)
+			if ok != test.ok {
+				t.Fatalf("expected ok %t, got %t", test.ok, ok)
+			}
+			if ok && !reflect.DeepEqual(msg, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, msg)
+			}
+		})
+	}
+}
+
+func TestWithKeyTranslatorChain(t *testing.T) {
+	p := NewProgram(nil,
+		WithKeyTranslator(func(k KeyMsg) KeyMsg {
+			if k.Type == KeyCtrlA {
+				return KeyMsg{Type: KeyRunes}
+			}
+			return KeyMsg{Type: KeyRunes, Runes: append(k.Runes, '1')}
+		}),
+		WithKeyTranslator(func(k KeyMsg) KeyMsg {
+			return KeyMsg{Type: KeyRunes, Runes: append(k.Runes, '2')}
+		}),
+	)
+	if k := p.keyTranslator(KeyMsg{Type: KeyRunes, Runes: []rune("a")}); k.String() != "a12" {
+		t.Errorf(`expected "a12", got %q`, k.String())
+	}
+	if k := p.keyTranslator(KeyMsg{Type: KeyCtrlA}); !isDroppedKey(k) {
+		t.Errorf("expected the key to stay dropped, got %v", k)
+	}
+}
@@ -0,0 +1,71 @@
+package tea
+
+import (
+	"fmt"
+	"time"
+)
+
+// KeyboardEnhancements are the flags of the kitty keyboard protocol, which
+// makes terminals report keys unambiguously and in more detail. Combine them
+// and enable them with WithKeyboardEnhancements.
+//
+// Terminals which don't support the protocol ignore the flags, and report
+// keys as usual.
+type KeyboardEnhancements int
+
+// Available keyboard enhancements.
+const (
+	// KeyboardDisambiguate reports keys which are otherwise ambiguous, such
+	// as escape, alt+key combinations and ctrl+i versus tab, unambiguously.
+	KeyboardDisambiguate KeyboardEnhancements = 1 << iota
+
+	// KeyboardReportEvents reports repeated keys and key releases, in
+	// addition to key presses. Repeats are sent as KeyMsg with Action set to
+	// KeyActionRepeat, and releases as KeyReleaseMsg.
+	KeyboardReportEvents
+
+	// KeyboardAlternateKeys reports the shifted version of keys, so that
+	// shift+a is reported as "A" regardless of the keyboard layout.
+	KeyboardAlternateKeys
+
+	// KeyboardReportAllKeys reports all keys as escape sequences, including
+	// text keys, enter, tab and backspace.
+	KeyboardReportAllKeys
+)
+
+// Sequences to enable and disable keyboard enhancements. Enabling pushes the
+// flags onto the terminal's stack; disabling pops them, restoring whatever
+// was in effect before.
+const disableKeyboardEnhancements = "\x1b[<u"
+
+func enableKeyboardEnhancements(flags KeyboardEnhancements) string {
+	return fmt.Sprintf("\x1b[>%du", flags)
+}
+
+// Windows Terminal and ConPTY don't support the kitty keyboard protocol, but
+// have a mode of their own, win32-input-mode, in which keys are reported as
+// Windows console key events: with all modifiers, and releases. It's enabled
+// in place of keyboard enhancements where supported.
+const (
+	win32InputMode        = 9001
+	enableWin32InputMode  = "\x1b[?9001h"
+	disableWin32InputMode = "\x1b[?9001l"
+)
+
+// KeyReleaseMsg is sent when a key is released. It's only sent when enabled
+// with the KeyboardReportEvents keyboard enhancement, in terminals which
+// support it or win32-input-mode. Its Action is KeyActionRelease. Releases
+// aren't sent as KeyMsg, so that key bindings don't fire twice for each
+// keystroke.
+type KeyReleaseMsg Key
+
+// String returns a string representation of the released key, like
+// KeyMsg.String.
+func (k KeyReleaseMsg) String() string {
+	return Key(k).String()
+}
+
+// Time returns when the key was released, see KeyMsg.Time.
+func (k KeyReleaseMsg) Time() time.Time {
+	return Key(k).Time()
+}
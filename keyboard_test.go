@@ -0,0 +1,65 @@
+package tea
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestReadInputsKittyKeys(t *testing.T) {
+	msgs, err := readInputs(bytes.NewReader([]byte("x\x1b[97;1:2u\x1b[97;1:3u\x1b[57441uy")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Msg{
+		KeyMsg{Type: KeyRunes, Runes: []rune("x")},
+		KeyMsg{Type: KeyRunes, Runes: []rune("a"), Action: KeyActionRepeat},
+		KeyReleaseMsg{Type: KeyRunes, Runes: []rune("a"), Action: KeyActionRelease},
+		KeyMsg{Type: KeyRunes, Runes: []rune("y")},
+	}
+	if !reflect.DeepEqual(msgs, expected) {
+		t.Errorf("expected %#v, got %#v", expected, msgs)
+	}
+}
+
+func TestKeyboardEnhancements(t *testing.T) {
+	tests := []struct {
+		name     string
+		tier     Tier
+		expected string
+	}{
+		{
+			name:     "modern",
+			tier:     TierModern,
+			expected: "\x1b[?25l\x1b[?2004h\x1b[>3usuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[<u\x1b[?2004l",
+		},
+		{
+			name:     "xterm",
+			tier:     TierXterm,
+			expected: "\x1b[?25l\x1b[?2004hsuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[?2004l",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv(tierEnvVar, "")
+
+			var buf bytes.Buffer
+			var in bytes.Buffer
+
+			m := &testModel{}
+			p := NewProgram(m, WithInput(&in), WithOutput(&buf), WithTier(test.tier),
+				WithKeyboardEnhancements(KeyboardDisambiguate|KeyboardReportEvents))
+
+			go p.Send(sequenceMsg{Quit})
+
+			if _, err := p.Run(); err != nil {
+				t.Fatal(err)
+			}
+
+			if buf.String() != test.expected {
+				t.Errorf("expected embedded sequence:\n%q\ngot:\n%q", test.expected, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package tea
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// InputLatencyMsg reports the input-to-photon latency over the past period:
+// the time from reading keyboard or mouse input to writing the first frame
+// which reflects it to the terminal. It's sent periodically when enabled with
+// WithInputLatency, and is meant for performance tuning.
+//
+// The latency covers reading and parsing the input, Update, View, and
+// rendering. It doesn't include the time the terminal takes to send the
+// input and to display the frame, which can't be measured.
+type InputLatencyMsg struct {
+	// Period is the time span covered by the report.
+	Period time.Duration
+
+	// Samples is the number of input events measured.
+	Samples int
+
+	// Percentiles and the maximum of the measured latencies.
+	P50, P90, P99 time.Duration
+	Max           time.Duration
+}
+
+// timedInputMsg wraps input read from the terminal with the time it was
+// read, when input latency is measured. It's unwrapped by the event loop.
+type timedInputMsg struct {
+	msg  Msg
+	time time.Time
+}
+
+// latencyTracker correlates input with the frames which reflect it.
+type latencyTracker struct {
+	mtx sync.Mutex
+
+	// read times of the input whose views have been written to the
+	// renderer, but not flushed yet
+	pending []time.Time
+
+	samples []time.Duration
+}
+
+// written records that the views reflecting the input read at the given
+// times have been written to the renderer.
+func (t *latencyTracker) written(inputs []time.Time) {
+	if len(inputs) == 0 {
+		return
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.pending = append(t.pending, inputs...)
+}
+
+// flushed records that the renderer has brought the terminal up to date with
+// the views written to it.
+func (t *latencyTracker) flushed(now time.Time) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	for _, read := range t.pending {
+		t.samples = append(t.samples, now.Sub(read))
+	}
+	t.pending = t.pending[:0]
+}
+
+// take returns and resets the samples measured so far.
+func (t *latencyTracker) take() []time.Duration {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	samples := t.samples
+	t.samples = nil
+	return samples
+}
+
+// latencyReport summarizes the given samples.
+func latencyReport(samples []time.Duration, period time.Duration) InputLatencyMsg {
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+	// nearest-rank percentile
+	percentile := func(p int) time.Duration {
+		i := (p*len(samples)+99)/100 - 1 //nolint:gomnd
+		if i < 0 {
+			i = 0
+		}
+		return samples[i]
+	}
+
+	return InputLatencyMsg{
+		Period:  period,
+		Samples: len(samples),
+		P50:     percentile(50), //nolint:gomnd
+		P90:     percentile(90), //nolint:gomnd
+		P99:     percentile(99), //nolint:gomnd
+		Max:     samples[len(samples)-1],
+	}
+}
+
+// handleInputLatency periodically reports the input latency to the program.
+// Periods without any input aren't reported.
+func (p *Program) handleInputLatency() chan struct{} {
+	ch := make(chan struct{})
+
+	go func() {
+		defer close(ch)
+
+		ticker := time.NewTicker(p.latencyInterval)
+		defer ticker.Stop()
+
+		last := time.Now()
+		for {
+			select {
+			case <-p.ctx.Done():
+				return
+
+			case now := <-ticker.C:
+				samples := p.latency.take()
+				period := now.Sub(last)
+				last = now
+				if len(samples) == 0 {
+					continue
+				}
+				p.Send(latencyReport(samples, period))
+			}
+		}
+	}()
+
+	return ch
+}
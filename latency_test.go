@@ -0,0 +1,98 @@
+package tea
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestLatencyReport(t *testing.T) {
+	var samples []time.Duration
+	for i := 100; i > 0; i-- {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+
+	msg := latencyReport(samples, time.Second)
+	expected := InputLatencyMsg{
+		Period:  time.Second,
+		Samples: 100,
+		P50:     50 * time.Millisecond,
+		P90:     90 * time.Millisecond,
+		P99:     99 * time.Millisecond,
+		Max:     100 * time.Millisecond,
+	}
+	if msg != expected {
+		t.Errorf("expected %+v, got %+v", expected, msg)
+	}
+
+	single := latencyReport([]time.Duration{time.Millisecond}, time.Second)
+	if single.P50 != time.Millisecond || single.P99 != time.Millisecond {
+		t.Errorf("expected all percentiles to be the only sample, got %+v", single)
+	}
+}
+
+func TestLatencyTracker(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewProgram(nil, WithOutput(&buf), WithInputLatency(time.Second))
+	r := newRenderer(p.output, false).(*standardRenderer)
+	r.latency = p.latency
+
+	read := time.Now().Add(-time.Millisecond)
+	p.drawnInputs = []time.Time{read}
+	p.renderer = r
+	p.draw(viewModel("a"))
+
+	if samples := p.latency.take(); len(samples) != 0 {
+		t.Fatalf("expected no samples before the frame is flushed, got %v", samples)
+	}
+
+	r.flush()
+	samples := p.latency.take()
+	if len(samples) != 1 || samples[0] < time.Millisecond {
+		t.Errorf("expected a sample of at least 1ms, got %v", samples)
+	}
+
+	// Views which don't change anything are on screen right away.
+	p.drawnInputs = []time.Time{time.Now()}
+	p.draw(viewModel("a"))
+	r.flush()
+	if samples := p.latency.take(); len(samples) != 1 {
+		t.Errorf("expected a sample for an unchanged view, got %v", samples)
+	}
+}
+
+type latencyModel struct {
+	report *InputLatencyMsg
+}
+
+func (m latencyModel) Init() Cmd {
+	return nil
+}
+
+func (m latencyModel) Update(msg Msg) (Model, Cmd) {
+	if msg, ok := msg.(InputLatencyMsg); ok {
+		*m.report = msg
+		return m, Quit
+	}
+	return m, nil
+}
+
+func (m latencyModel) View() string {
+	return ""
+}
+
+func TestInputLatency(t *testing.T) {
+	var buf bytes.Buffer
+	in := bytes.NewBufferString("a")
+	var report InputLatencyMsg
+
+	p := NewProgram(latencyModel{report: &report},
+		WithInput(in), WithOutput(&buf), WithInputLatency(50*time.Millisecond))
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Samples != 1 || report.Max <= 0 {
+		t.Errorf("expected one positive sample, got %+v", report)
+	}
+}
@@ -0,0 +1,112 @@
+package tea
+
+import (
+	"math"
+	"strings"
+	"sync"
+)
+
+// LayeredModel is a Model which draws layers, such as dialogs, dropdowns and
+// tooltips, over its view. The program composites them onto the view with
+// Compose, so that models don't need to splice them into their view
+// themselves.
+//
+// Layers are placed relative to the top left corner of the program's output
+// and cover the view, whatever their Z. Mouse events within named layers list
+// their names in MouseMsg.Zones, topmost first and ahead of any zones
+// registered with RegisterZone.
+type LayeredModel interface {
+	Model
+
+	// Layers returns the layers to draw over the view. It's called after
+	// View, every time the view is rendered.
+	Layers() []Layer
+}
+
+// layerState keeps what's needed to composite layers and hit-test them. It's
+// accessed from the event loop and, with pipelined rendering, the render
+// pipeline.
+type layerState struct {
+	mtx sync.Mutex
+
+	// the window size
+	width, height int
+
+	// the named layers of the last view rendered, topmost first
+	hits []Layer
+}
+
+// setSize records the size of the window.
+func (s *layerState) setSize(width, height int) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.width, s.height = width, height
+}
+
+// size returns the size of the window, or zero if it isn't known.
+func (s *layerState) size() (width, height int) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return s.width, s.height
+}
+
+// compose draws the layers over the view. In the altscreen the result fills
+// the window; otherwise it's as tall as the view, or the layers if they reach
+// further down.
+func (s *layerState) compose(view string, layers []Layer, altScreen bool) string {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	// Layers are painted in order of Z, so the reverse order has those on
+	// top first.
+	painted := sortLayers(layers)
+	s.hits = s.hits[:0]
+	for i := len(painted) - 1; i >= 0; i-- {
+		if painted[i].Name != "" {
+			s.hits = append(s.hits, painted[i])
+		}
+	}
+	if len(layers) == 0 {
+		return view
+	}
+
+	lines := strings.Split(view, "\n")
+	width, height := s.width, len(lines)
+	for _, l := range lines {
+		if w := StringWidth(l); s.width <= 0 && w > width {
+			width = w
+		}
+	}
+	for _, l := range layers {
+		if l.Rect.Y+l.Rect.Height > height {
+			height = l.Rect.Y + l.Rect.Height
+		}
+		if s.width <= 0 && l.Rect.X+l.Rect.Width > width {
+			width = l.Rect.X + l.Rect.Width
+		}
+	}
+	if s.height > 0 && (altScreen || height > s.height) {
+		height = s.height
+	}
+
+	// The view goes beneath all layers.
+	base := Layer{Rect: Rect{Width: width, Height: height}, Content: view, Z: math.MinInt}
+	return Compose(width, height, append([]Layer{base}, layers...)...)
+}
+
+// hitTest returns the names of the layers of the last view which contain the
+// cell at x, y, topmost first.
+func (s *layerState) hitTest(x, y int) []string {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var names []string
+	for _, l := range s.hits {
+		if l.Rect.Contains(x, y) {
+			names = append(names, l.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,110 @@
+package tea
+
+import (
+	"reflect"
+	"testing"
+)
+
+type layeredModel struct {
+	view   string
+	layers []Layer
+}
+
+func (m layeredModel) Init() Cmd                   { return nil }
+func (m layeredModel) Update(msg Msg) (Model, Cmd) { return m, nil }
+func (m layeredModel) View() string                { return m.view }
+func (m layeredModel) Layers() []Layer             { return m.layers }
+
+func TestLayerCompose(t *testing.T) {
+	tests := []struct {
+		name      string
+		width     int
+		height    int
+		altScreen bool
+		view      string
+		layers    []Layer
+		expected  string
+	}{
+		{
+			name:     "no layers",
+			width:    4,
+			view:     "ab\ncd",
+			expected: "ab\ncd",
+		},
+		{
+			name:     "overlay",
+			width:    4,
+			height:   10,
+			view:     "abcd\nefgh\nijkl",
+			layers:   []Layer{{Rect: Rect{X: 1, Y: 1, Width: 2, Height: 1}, Content: "XY"}},
+			expected: "abcd\neXYh\nijkl",
+		},
+		{
+			name:   "below the view",
+			width:  3,
+			height: 10,
+			view:   "abc",
+			layers: []Layer{{Rect: Rect{X: 1, Y: 1, Width: 2, Height: 2}, Content: "XY\nZ"}},
+			// The view grows to make room for the layer.
+			expected: "abc\n XY\n Z ",
+		},
+		{
+			name:      "altscreen",
+			width:     3,
+			height:    3,
+			altScreen: true,
+			view:      "abc",
+			layers:    []Layer{{Rect: Rect{Y: 1, Width: 1, Height: 1}, Content: "X"}},
+			expected:  "abc\nX  \n   ",
+		},
+		{
+			name:     "unknown size",
+			view:     "ab\nc",
+			layers:   []Layer{{Rect: Rect{X: 2, Width: 1, Height: 1}, Content: "X", Z: -1}},
+			expected: "abX\nc  ",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var s layerState
+			s.setSize(test.width, test.height)
+			m := layeredModel{view: test.view, layers: test.layers}
+			if got := renderView(m, &s, nil, nil, test.altScreen); got != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestLayerHitTest(t *testing.T) {
+	var s layerState
+	s.setSize(10, 10)
+	s.compose("", []Layer{
+		{Name: "dialog", Rect: Rect{X: 1, Y: 1, Width: 5, Height: 5}, Z: 1},
+		{Name: "tooltip", Rect: Rect{X: 2, Y: 2, Width: 2, Height: 1}, Z: 2},
+		{Rect: Rect{Width: 10, Height: 10}, Z: 3},
+		{Name: "dropdown", Rect: Rect{X: 3, Y: 2, Width: 2, Height: 2}, Z: 1},
+	}, true)
+
+	tests := []struct {
+		x, y     int
+		expected []string
+	}{
+		{0, 0, nil},
+		{1, 1, []string{"dialog"}},
+		{3, 2, []string{"tooltip", "dropdown", "dialog"}},
+		{4, 3, []string{"dropdown", "dialog"}},
+	}
+	for _, test := range tests {
+		if got := s.hitTest(test.x, test.y); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("expected %v at %d,%d, got %v", test.expected, test.x, test.y, got)
+		}
+	}
+
+	// Layers of earlier views don't linger.
+	s.compose("", nil, true)
+	if got := s.hitTest(3, 2); got != nil {
+		t.Errorf("expected no layers to be hit, got %v", got)
+	}
+}
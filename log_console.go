@@ -0,0 +1,156 @@
+package tea
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// LogLevel is the severity of a message logged with Log or Logf.
+type LogLevel int
+
+// Available log levels.
+const (
+	LogDebug LogLevel = iota
+	LogInfo
+	LogWarn
+	LogError
+)
+
+// String returns the name of the log level.
+func (l LogLevel) String() string {
+	switch l {
+	case LogDebug:
+		return "DEBUG"
+	case LogInfo:
+		return "INFO"
+	case LogWarn:
+		return "WARN"
+	case LogError:
+		return "ERROR"
+	}
+	return "UNKNOWN"
+}
+
+// Log logs a message with the given level. When the log console is enabled
+// with WithLogConsole, the message is recorded there along with its level and
+// the current time. Otherwise it behaves like Println.
+func Log(level LogLevel, args ...interface{}) Cmd {
+	return func() Msg {
+		return printLineMessage{
+			messageBody: fmt.Sprint(args...),
+			level:       level,
+			time:        time.Now(),
+		}
+	}
+}
+
+// Logf logs a formatted message with the given level. See Log.
+func Logf(level LogLevel, template string, args ...interface{}) Cmd {
+	return func() Msg {
+		return printLineMessage{
+			messageBody: fmt.Sprintf(template, args...),
+			level:       level,
+			time:        time.Now(),
+		}
+	}
+}
+
+// ToggleLogConsole is a special command that shows or hides the log console
+// enabled with WithLogConsole.
+func ToggleLogConsole() Msg {
+	return toggleLogConsoleMsg{}
+}
+
+// toggleLogConsoleMsg is an internal message that shows or hides the log
+// console. You can send a toggleLogConsoleMsg with ToggleLogConsole.
+type toggleLogConsoleMsg struct{}
+
+// logConsole records the messages printed with Println, Printf, Log and Logf,
+// and shows the most recent ones on top of the program's view.
+type logConsole struct {
+	size    int
+	entries []printLineMessage
+	visible bool
+
+	width, height int
+}
+
+// add records a message, dropping the oldest one if the console is full.
+func (c *logConsole) add(msg printLineMessage) {
+	if msg.time.IsZero() {
+		msg.time = time.Now()
+	}
+	if len(c.entries) >= c.size {
+		copy(c.entries, c.entries[1:])
+		c.entries = c.entries[:len(c.entries)-1]
+	}
+	c.entries = append(c.entries, msg)
+}
+
+// snapshot returns a copy of the console, for rendering on another
+// goroutine.
+func (c *logConsole) snapshot() *logConsole {
+	s := *c
+	s.entries = append([]printLineMessage(nil), c.entries...)
+	return &s
+}
+
+// lines returns the recorded messages formatted for display, one line per
+// line of each message.
+func (c *logConsole) lines() []string {
+	lines := make([]string, 0, len(c.entries))
+	for _, e := range c.entries {
+		prefix := fmt.Sprintf("%s %-5s ", e.time.Format("15:04:05"), e.level)
+		for _, l := range strings.Split(e.messageBody, "\n") {
+			lines = append(lines, prefix+l)
+		}
+	}
+	return lines
+}
+
+// view returns the program's view with the console shown on top of it, if
+// the console is visible. In the altscreen the console covers the bottom of
+// the screen; inline, it's shown below the program's view.
+func (c *logConsole) view(view string, altScreen bool) string {
+	if !c.visible {
+		return view
+	}
+
+	lines := captureLines([]byte(strings.Join(c.lines(), "\n")))
+	v := &OutputView{Title: "log", lines: lines}
+
+	width := c.width
+	if width <= 0 {
+		width = 80 //nolint:gomnd
+	}
+
+	// Leave at least some of the program's view visible.
+	height := len(lines) + 2 //nolint:gomnd
+	if limit := c.height / 3; c.height > 0 && height > limit {
+		height = limit
+	}
+	if height < 3 { //nolint:gomnd
+		height = 3
+	}
+
+	v.SetSize(width, height)
+	v.ScrollDown(len(lines))
+
+	if !altScreen || c.height <= 0 {
+		return view + "\n" + v.View()
+	}
+	return Compose(width, c.height,
+		Layer{Rect: Rect{Width: width, Height: c.height}, Content: view},
+		v.Layer(0, c.height-height),
+	)
+}
+
+// persist writes the recorded messages to w, so that they end up in the
+// terminal's scrollback.
+func (c *logConsole) persist(w io.Writer) {
+	for _, l := range c.lines() {
+		_, _ = io.WriteString(w, l+"\r\n")
+	}
+}
@@ -0,0 +1,77 @@
+package tea
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogConsole(t *testing.T) {
+	at := time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC)
+	c := &logConsole{size: 2}
+	c.add(printLineMessage{messageBody: "first", level: LogInfo, time: at})
+	c.add(printLineMessage{messageBody: "second", level: LogWarn, time: at})
+	c.add(printLineMessage{messageBody: "third\nline", level: LogError, time: at})
+
+	expected := []string{
+		"15:04:05 WARN  second",
+		"15:04:05 ERROR third",
+		"15:04:05 ERROR line",
+	}
+	if got := c.lines(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	if v := c.view("view", false); v != "view" {
+		t.Errorf("expected the console to be hidden, got %q", v)
+	}
+
+	c.visible = true
+	c.width, c.height = 30, 9
+	inline := c.view("view", false)
+	if !strings.HasPrefix(inline, "view\n┌ log ") || !strings.Contains(inline, "│15:04:05 ERROR line") {
+		t.Errorf("expected the console below the view, got %q", inline)
+	}
+
+	alt := strings.Split(c.view("view", true), "\n")
+	if len(alt) != 9 || alt[0] != "view"+strings.Repeat(" ", 26) || !strings.HasPrefix(alt[6], "┌ log ") {
+		t.Errorf("expected the console at the bottom of the screen, got %q", alt)
+	}
+
+	var buf bytes.Buffer
+	c.persist(&buf)
+	if buf.String() != strings.Join(expected, "\r\n")+"\r\n" {
+		t.Errorf("unexpected persisted log %q", buf.String())
+	}
+}
+
+type logConsoleModel struct{}
+
+func (m logConsoleModel) Init() Cmd {
+	return Sequence(Log(LogWarn, "hello"), Quit)
+}
+
+func (m logConsoleModel) Update(msg Msg) (Model, Cmd) {
+	return m, nil
+}
+
+func (m logConsoleModel) View() string {
+	return "view\n"
+}
+
+func TestLogConsoleProgram(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+
+	p := NewProgram(logConsoleModel{}, WithInput(&in), WithOutput(&buf), WithLogConsole(10))
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	if strings.Count(out, "WARN  hello") != 1 {
+		t.Errorf("expected the log to be persisted once, got %q", out)
+	}
+}
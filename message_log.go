@@ -0,0 +1,143 @@
+package tea
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redacted replaces redacted values in the message log.
+const redacted = "[redacted]"
+
+// MessageLogger logs every message a program receives, with its type,
+// estimated size and timing, to an io.Writer. It's meant for audit and
+// debugging logs of programs in production, and is installed as a filter:
+//
+//	f, _ := os.Create("messages.log")
+//	logger := tea.NewMessageLogger(f).
+//		RedactTypes(tea.KeyMsg{}).
+//		RedactFields("Password", "Token")
+//	p := tea.NewProgram(model, tea.WithFilter(logger.Filter))
+//
+// Each line holds the time the message was received, the time elapsed since
+// the previous message, the message's type, its estimated size in bytes and
+// its contents:
+//
+//	15:04:05.000 +1.2ms main.statusMsg 40B {Text:loading}
+//
+// Messages may hold sensitive data, such as passwords typed by the user. The
+// contents of messages of redacted types are left out entirely, while
+// redacted fields are left out of any message which has them.
+type MessageLogger struct {
+	mtx    sync.Mutex
+	w      io.Writer
+	last   time.Time
+	types  map[reflect.Type]struct{}
+	fields map[string]struct{}
+	next   func(Model, Msg) Msg
+}
+
+// NewMessageLogger returns a MessageLogger which writes to w.
+func NewMessageLogger(w io.Writer) *MessageLogger {
+	return &MessageLogger{
+		w:      w,
+		types:  map[reflect.Type]struct{}{},
+		fields: map[string]struct{}{},
+	}
+}
+
+// RedactTypes leaves the contents of messages of the same types as the given
+// messages out of the log. Their type and size are still logged.
+func (l *MessageLogger) RedactTypes(msgs ...Msg) *MessageLogger {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	for _, msg := range msgs {
+		l.types[reflect.TypeOf(msg)] = struct{}{}
+	}
+	return l
+}
+
+// RedactFields leaves the struct fields with the given names out of the log,
+// in messages of any type.
+func (l *MessageLogger) RedactFields(names ...string) *MessageLogger {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	for _, name := range names {
+		l.fields[name] = struct{}{}
+	}
+	return l
+}
+
+// Then passes messages on to the given filter after logging them, for
+// programs which have a filter of their own. Messages are logged as they were
+// received, before the filter sees them.
+func (l *MessageLogger) Then(filter func(Model, Msg) Msg) *MessageLogger {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	l.next = filter
+	return l
+}
+
+// Filter logs the message and returns it. Pass it to WithFilter.
+func (l *MessageLogger) Filter(m Model, msg Msg) Msg {
+	if msg == nil {
+		return nil
+	}
+
+	l.mtx.Lock()
+	now := time.Now()
+	var elapsed time.Duration
+	if !l.last.IsZero() {
+		elapsed = now.Sub(l.last)
+	}
+	l.last = now
+
+	_, _ = fmt.Fprintf(l.w, "%s +%s %T %dB %s\n",
+		now.Format("15:04:05.000"), elapsed, msg, estimateMsgSize(msg), l.format(msg))
+	next := l.next
+	l.mtx.Unlock()
+
+	if next != nil {
+		return next(m, msg)
+	}
+	return msg
+}
+
+// format formats the contents of a message, leaving out what's redacted. It
+// must be called with the mutex held.
+func (l *MessageLogger) format(msg Msg) string {
+	if _, ok := l.types[reflect.TypeOf(msg)]; ok {
+		return redacted
+	}
+
+	v := reflect.ValueOf(msg)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct || len(l.fields) == 0 {
+		return fmt.Sprintf("%+v", msg)
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i < v.NumField(); i++ {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		name := v.Type().Field(i).Name
+		if _, ok := l.fields[name]; ok {
+			fmt.Fprintf(&b, "%s:%s", name, redacted)
+			continue
+		}
+		// fmt can print unexported fields through reflect.Value.
+		fmt.Fprintf(&b, "%s:%+v", name, v.Field(i))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package tea
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+type loginMsg struct {
+	User     string
+	Password string
+}
+
+func TestMessageLogger(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      Msg
+		expected string
+	}{
+		{"plain", loginMsg{User: "joe", Password: "secret"}, `tea.loginMsg \d+B {User:joe Password:\[redacted\]}`},
+		{"redacted type", KeyMsg{Type: KeyRunes, Runes: []rune("x")}, `tea.KeyMsg \d+B \[redacted\]`},
+		{"non-struct", 42, `int \d+B 42`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := NewMessageLogger(&buf).RedactTypes(KeyMsg{}).RedactFields("Password")
+
+			if got := l.Filter(nil, test.msg); got == nil {
+				t.Fatal("expected the message to be passed on")
+			}
+
+			pattern := `^\d\d:\d\d:\d\d\.\d{3} \+0s ` + test.expected + "\n$"
+			if !regexp.MustCompile(pattern).MatchString(buf.String()) {
+				t.Errorf("expected log matching %q, got %q", pattern, buf.String())
+			}
+			if strings.Contains(buf.String(), "secret") {
+				t.Errorf("expected password to be redacted, got %q", buf.String())
+			}
+		})
+	}
+}
+
+func TestMessageLoggerThen(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewMessageLogger(&buf).Then(func(_ Model, msg Msg) Msg {
+		if _, ok := msg.(QuitMsg); ok {
+			return nil
+		}
+		return msg
+	})
+
+	if got := l.Filter(nil, QuitMsg{}); got != nil {
+		t.Errorf("expected the next filter to drop the message, got %v", got)
+	}
+	if got := l.Filter(nil, 1); got != 1 {
+		t.Errorf("expected the message to be passed on, got %v", got)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 2 {
+		t.Errorf("expected both messages to be logged, got %q", buf.String())
+	}
+}
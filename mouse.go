@@ -1,8 +1,10 @@
 package tea
 
 import (
-	"bytes"
-	"errors"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbletea/input"
 )
 
 // MouseMsg contains information about a mouse event and is sent to a program's
@@ -12,138 +14,139 @@ type MouseMsg MouseEvent
 
 // MouseEvent represents a mouse event, which could be a click, a scroll wheel
 // movement, a cursor movement, or a combination.
-type MouseEvent struct {
-	X    int
-	Y    int
-	Type MouseEventType
-	Alt  bool
-	Ctrl bool
+type MouseEvent = input.MouseEvent
+
+// MarshalJSON encodes the message like its MouseEvent, see
+// MouseEvent.MarshalJSON.
+func (m MouseMsg) MarshalJSON() ([]byte, error) {
+	return MouseEvent(m).MarshalJSON()
 }
 
-// String returns a string representation of a mouse event.
-func (m MouseEvent) String() (s string) {
-	if m.Ctrl {
-		s += "ctrl+"
-	}
-	if m.Alt {
-		s += "alt+"
-	}
-	s += mouseEventTypes[m.Type]
-	return s
+// UnmarshalJSON decodes a message encoded with MarshalJSON.
+func (m *MouseMsg) UnmarshalJSON(b []byte) error {
+	return (*MouseEvent)(m).UnmarshalJSON(b)
 }
 
+// Time returns when the event was read from the terminal, see
+// MouseEvent.Time. It's zero for messages which weren't read from the
+// terminal.
+func (m MouseMsg) Time() time.Time {
+	return MouseEvent(m).Time()
+}
+
+// ParseMouseEvent parses a mouse event written as its String, followed by its
+// position, such as "ctrl+left 10,5". See input.ParseMouseEvent.
+func ParseMouseEvent(s string) (MouseEvent, error) {
+	return input.ParseMouseEvent(s)
+}
+
+// Drag describes the motion of the mouse while a button is held. It's set on
+// mouse events reported while dragging, see MouseEvent.Dragging.
+type Drag = input.Drag
+
 // MouseEventType indicates the type of mouse event occurring.
-type MouseEventType int
+type MouseEventType = input.MouseEventType
 
 // Mouse event types.
 const (
-	MouseUnknown MouseEventType = iota
-	MouseLeft
-	MouseRight
-	MouseMiddle
-	MouseRelease
-	MouseWheelUp
-	MouseWheelDown
-	MouseMotion
+	MouseUnknown   = input.MouseUnknown
+	MouseLeft      = input.MouseLeft
+	MouseRight     = input.MouseRight
+	MouseMiddle    = input.MouseMiddle
+	MouseRelease   = input.MouseRelease
+	MouseWheelUp   = input.MouseWheelUp
+	MouseWheelDown = input.MouseWheelDown
+	MouseMotion    = input.MouseMotion
 )
 
-var mouseEventTypes = map[MouseEventType]string{
-	MouseUnknown:   "unknown",
-	MouseLeft:      "left",
-	MouseRight:     "right",
-	MouseMiddle:    "middle",
-	MouseRelease:   "release",
-	MouseWheelUp:   "wheel up",
-	MouseWheelDown: "wheel down",
-	MouseMotion:    "motion",
+// MouseButton is a mouse button, or a direction of the wheel. See
+// MouseEvent.Button.
+type MouseButton = input.MouseButton
+
+// Mouse buttons.
+const (
+	MouseButtonNone      = input.MouseButtonNone
+	MouseButtonLeft      = input.MouseButtonLeft
+	MouseButtonMiddle    = input.MouseButtonMiddle
+	MouseButtonRight     = input.MouseButtonRight
+	MouseButtonWheelUp   = input.MouseButtonWheelUp
+	MouseButtonWheelDown = input.MouseButtonWheelDown
+)
+
+// MouseButtons is a set of mouse buttons. See MouseEvent.HeldButtons.
+type MouseButtons = input.MouseButtons
+
+// MouseAction is what a mouse event reports the mouse did. See
+// MouseEvent.Action.
+type MouseAction = input.MouseAction
+
+// Mouse actions.
+const (
+	MouseActionPress   = input.MouseActionPress
+	MouseActionRelease = input.MouseActionRelease
+	MouseActionMotion  = input.MouseActionMotion
+)
+
+// isMouseMotion reports whether msg is a mouse motion event, with or without
+// a button held.
+func isMouseMotion(msg Msg) bool {
+	m, ok := msg.(MouseMsg)
+	return ok && (m.Type == MouseMotion || m.Dragging)
 }
 
-// Parse X10-encoded mouse events; the simplest kind. The last release of X10
-// was December 1986, by the way.
-//
-// X10 mouse events look like:
-//
-//	ESC [M Cb Cx Cy
-//
-// See: http://www.xfree86.org/current/ctlseqs.html#Mouse%20Tracking
-func parseX10MouseEvents(buf []byte) ([]MouseEvent, error) {
-	var r []MouseEvent
-
-	seq := []byte("\x1b[M")
-	if !bytes.Contains(buf, seq) {
-		return r, errors.New("not an X10 mouse event")
-	}
+// motionCoalescer collapses bursts of mouse motion into the latest event per
+// interval, see WithMouseMotionCoalescing.
+type motionCoalescer struct {
+	interval time.Duration
+	send     func(Msg)
 
-	for _, v := range bytes.Split(buf, seq) {
-		if len(v) == 0 {
-			continue
-		}
-		if len(v) != 3 {
-			return r, errors.New("not an X10 mouse event")
-		}
+	mtx sync.Mutex
 
-		var m MouseEvent
-		const byteOffset = 32
-		e := v[0] - byteOffset
-
-		const (
-			bitShift  = 0b0000_0100
-			bitAlt    = 0b0000_1000
-			bitCtrl   = 0b0001_0000
-			bitMotion = 0b0010_0000
-			bitWheel  = 0b0100_0000
-
-			bitsMask = 0b0000_0011
-
-			bitsLeft    = 0b0000_0000
-			bitsMiddle  = 0b0000_0001
-			bitsRight   = 0b0000_0010
-			bitsRelease = 0b0000_0011
-
-			bitsWheelUp   = 0b0000_0000
-			bitsWheelDown = 0b0000_0001
-		)
-
-		if e&bitWheel != 0 {
-			// Check the low two bits.
-			switch e & bitsMask {
-			case bitsWheelUp:
-				m.Type = MouseWheelUp
-			case bitsWheelDown:
-				m.Type = MouseWheelDown
-			}
-		} else {
-			// Check the low two bits.
-			// We do not separate clicking and dragging.
-			switch e & bitsMask {
-			case bitsLeft:
-				m.Type = MouseLeft
-			case bitsMiddle:
-				m.Type = MouseMiddle
-			case bitsRight:
-				m.Type = MouseRight
-			case bitsRelease:
-				if e&bitMotion != 0 {
-					m.Type = MouseMotion
-				} else {
-					m.Type = MouseRelease
-				}
-			}
-		}
+	// when motion was last delivered
+	last time.Time
 
-		if e&bitAlt != 0 {
-			m.Alt = true
-		}
-		if e&bitCtrl != 0 {
-			m.Ctrl = true
-		}
+	// the latest motion, which is delivered when the timer fires
+	pending Msg
+	timer   *time.Timer
+}
+
+// deliver delivers input read from the terminal, holding back motion which
+// arrives within the interval of the last.
+func (c *motionCoalescer) deliver(msg Msg, motion bool, now time.Time) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
 
-		// (1,1) is the upper left. We subtract 1 to normalize it to (0,0).
-		m.X = int(v[1]) - byteOffset - 1
-		m.Y = int(v[2]) - byteOffset - 1
+	if !motion {
+		c.flush()
+		c.send(msg)
+		return
+	}
 
-		r = append(r, m)
+	if c.pending == nil {
+		if wait := c.interval - now.Sub(c.last); wait > 0 {
+			c.timer = time.AfterFunc(wait, func() {
+				c.mtx.Lock()
+				defer c.mtx.Unlock()
+				c.flush()
+			})
+		} else {
+			c.last = now
+			c.send(msg)
+			return
+		}
 	}
+	c.pending = msg
+}
 
-	return r, nil
+// flush delivers pending motion, if any. The mutex must be held.
+func (c *motionCoalescer) flush() {
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	if c.pending != nil {
+		c.send(c.pending)
+		c.pending = nil
+		c.last = time.Now()
+	}
 }
@@ -0,0 +1,147 @@
+package tea
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MouseBinding describes the mouse events an action is bound to, much like
+// the strings KeyMsg.String is compared against for key bindings. The zero
+// value of each constraint matches any event, except for the modifiers, which
+// must match exactly.
+type MouseBinding struct {
+	// Button is the button the event reports, or MouseButtonNone for any
+	// button.
+	Button MouseButton
+
+	// Action is the action the event reports, or zero for any action.
+	Action MouseAction
+
+	Ctrl bool
+	Alt  bool
+
+	// Zone is the id of a zone the event must hit, see RegisterZone, or
+	// empty for anywhere.
+	Zone string
+}
+
+// ParseMouseBinding parses a mouse binding, so that bindings can be loaded
+// from configuration files. Bindings are written as the modifiers, the button
+// and the action, followed by an optional zone id prefixed with an at sign:
+//
+//	ctrl+left press
+//	wheel up @list
+//	alt+right
+//	release
+//
+// The button and the action can each be left out to match any, but not both;
+// use "any" for a binding matching any mouse event.
+func ParseMouseBinding(s string) (MouseBinding, error) {
+	var b MouseBinding
+	fields := strings.Fields(s)
+
+	if n := len(fields); n > 0 && strings.HasPrefix(fields[n-1], "@") {
+		b.Zone = fields[n-1][1:]
+		fields = fields[:n-1]
+		if b.Zone == "" {
+			return b, fmt.Errorf("mouse binding %q: missing zone id", s)
+		}
+	}
+	if len(fields) == 0 {
+		return b, fmt.Errorf("mouse binding %q: missing button or action", s)
+	}
+
+	// Modifiers are joined to the first word with a plus sign.
+	mods := strings.Split(fields[0], "+")
+	fields[0] = mods[len(mods)-1]
+	for _, mod := range mods[:len(mods)-1] {
+		switch mod {
+		case "ctrl":
+			b.Ctrl = true
+		case "alt":
+			b.Alt = true
+		default:
+			return b, fmt.Errorf("mouse binding %q: unknown modifier %q", s, mod)
+		}
+	}
+
+	// The wheel buttons are named with two words.
+	if fields[0] == "wheel" && len(fields) > 1 {
+		fields = append([]string{"wheel " + fields[1]}, fields[2:]...)
+	}
+
+	if fields[0] == "any" {
+		fields = fields[1:]
+	} else if button, ok := mouseButtonNames[fields[0]]; ok {
+		b.Button = button
+		fields = fields[1:]
+	}
+	if len(fields) > 0 {
+		action, ok := mouseActionNames[fields[0]]
+		if !ok {
+			return b, fmt.Errorf("mouse binding %q: unknown button or action %q", s, fields[0])
+		}
+		b.Action = action
+		fields = fields[1:]
+	}
+	if len(fields) > 0 {
+		return b, fmt.Errorf("mouse binding %q: unexpected %q", s, strings.Join(fields, " "))
+	}
+	return b, nil
+}
+
+var mouseButtonNames = map[string]MouseButton{
+	"left":       MouseButtonLeft,
+	"middle":     MouseButtonMiddle,
+	"right":      MouseButtonRight,
+	"wheel up":   MouseButtonWheelUp,
+	"wheel down": MouseButtonWheelDown,
+}
+
+var mouseActionNames = map[string]MouseAction{
+	"press":   MouseActionPress,
+	"release": MouseActionRelease,
+	"motion":  MouseActionMotion,
+}
+
+// Matches reports whether the mouse event matches the binding.
+func (b MouseBinding) Matches(m MouseMsg) bool {
+	e := MouseEvent(m)
+	switch {
+	case b.Button != MouseButtonNone && e.Button() != b.Button:
+		return false
+	case b.Action != 0 && e.Action() != b.Action:
+		return false
+	case b.Ctrl != m.Ctrl || b.Alt != m.Alt:
+		return false
+	case b.Zone != "" && !m.InZone(b.Zone):
+		return false
+	}
+	return true
+}
+
+// String returns the binding in the form ParseMouseBinding parses.
+func (b MouseBinding) String() string {
+	var s string
+	if b.Ctrl {
+		s += "ctrl+"
+	}
+	if b.Alt {
+		s += "alt+"
+	}
+
+	var words []string
+	if b.Button != MouseButtonNone {
+		words = append(words, b.Button.String())
+	}
+	if b.Action != 0 {
+		words = append(words, b.Action.String())
+	}
+	if len(words) == 0 {
+		words = append(words, "any")
+	}
+	if b.Zone != "" {
+		words = append(words, "@"+b.Zone)
+	}
+	return s + strings.Join(words, " ")
+}
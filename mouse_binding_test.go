@@ -0,0 +1,72 @@
+package tea
+
+import (
+	"testing"
+)
+
+func TestParseMouseBinding(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected MouseBinding
+	}{
+		{"ctrl+left press", MouseBinding{Button: MouseButtonLeft, Action: MouseActionPress, Ctrl: true}},
+		{"wheel up @list", MouseBinding{Button: MouseButtonWheelUp, Zone: "list"}},
+		{"ctrl+alt+right", MouseBinding{Button: MouseButtonRight, Ctrl: true, Alt: true}},
+		{"release", MouseBinding{Action: MouseActionRelease}},
+		{"any @button", MouseBinding{Zone: "button"}},
+	}
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			b, err := ParseMouseBinding(test.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, b)
+			}
+			if b.String() != test.in {
+				t.Errorf("expected the binding to format as %q, got %q", test.in, b.String())
+			}
+		})
+	}
+
+	for _, in := range []string{"", "@list", "shift+left", "left hold", "left press twice", "wheel", "left @"} {
+		if _, err := ParseMouseBinding(in); err == nil {
+			t.Errorf("expected an error parsing %q", in)
+		}
+	}
+}
+
+func TestMouseBindingMatches(t *testing.T) {
+	press := MouseMsg{Type: MouseLeft}.WithZones("button")
+	drag := MouseMsg{Type: MouseLeft, Dragging: true, Drag: Drag{DX: 1}}
+	ctrlWheel := MouseMsg{Type: MouseWheelDown, Ctrl: true}
+
+	tests := []struct {
+		binding  string
+		msg      MouseMsg
+		expected bool
+	}{
+		{"left", press, true},
+		{"left", drag, true},
+		{"left press", drag, false},
+		{"left motion", drag, true},
+		{"right", press, false},
+		{"left @button", press, true},
+		{"left @list", press, false},
+		{"wheel down", ctrlWheel, false},
+		{"ctrl+wheel down", ctrlWheel, true},
+		{"ctrl+any", press, false},
+		{"release", MouseMsg{Type: MouseRelease}, true},
+		{"motion", MouseMsg{Type: MouseMotion}, true},
+	}
+	for _, test := range tests {
+		b, err := ParseMouseBinding(test.binding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := b.Matches(test.msg); got != test.expected {
+			t.Errorf("%q matching %v: expected %v, got %v", test.binding, test.msg, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package tea
+
+import (
+	"context"
+	"time"
+)
+
+// The timing of mouse repeats, unless set with WithMouseRepeat.
+const (
+	defaultMouseRepeatDelay    = 400 * time.Millisecond
+	defaultMouseRepeatInterval = 50 * time.Millisecond
+)
+
+// mouseRepeatMsg is an internal message carrying a repeat of a press in a
+// repeat zone. Repeats of a press which has since been released are dropped.
+type mouseRepeatMsg struct {
+	id  int
+	msg MouseMsg
+}
+
+// mouseRepeater repeats presses in repeat zones while the button is held, see
+// RegisterRepeatZone.
+type mouseRepeater struct {
+	delay    time.Duration
+	interval time.Duration
+
+	// the id of the current repeat, which changes whenever a repeat starts
+	// or stops
+	id int
+
+	// the press being repeated, and a channel closed to stop repeating it
+	press MouseMsg
+	stop  chan struct{}
+}
+
+// track starts or stops repeating presses according to a mouse event, and
+// whether it happened in a repeat zone.
+func (r *mouseRepeater) track(ctx context.Context, m MouseMsg, inZone bool, send func(Msg)) {
+	e := MouseEvent(m)
+	switch {
+	case m.Repeat:
+		// One of ours.
+
+	case r.stop != nil && m.Dragging && m.Type == r.press.Type && inZone:
+		// Still held within the zone.
+
+	case inZone && e.Action() == MouseActionPress && !m.Dragging &&
+		m.Type != MouseWheelUp && m.Type != MouseWheelDown:
+		r.cancel()
+		r.start(ctx, m, send)
+
+	default:
+		r.cancel()
+	}
+}
+
+// start starts repeating a press.
+func (r *mouseRepeater) start(ctx context.Context, m MouseMsg, send func(Msg)) {
+	delay, interval := r.delay, r.interval
+	if delay <= 0 {
+		delay = defaultMouseRepeatDelay
+	}
+	if interval <= 0 {
+		interval = defaultMouseRepeatInterval
+	}
+
+	r.press, r.stop = m, make(chan struct{})
+	stop, msg := r.stop, mouseRepeatMsg{id: r.id, msg: m}
+	msg.msg.Repeat = true
+
+	go func() {
+		t := time.NewTimer(delay)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			case <-t.C:
+				send(msg)
+				t.Reset(interval)
+			}
+		}
+	}()
+}
+
+// cancel stops repeating the current press, if any.
+func (r *mouseRepeater) cancel() {
+	if r.stop == nil {
+		return
+	}
+	close(r.stop)
+	r.stop = nil
+	r.id++
+}
+
+// current reports whether a repeat belongs to the press being repeated.
+func (r *mouseRepeater) current(m mouseRepeatMsg) bool {
+	return r.stop != nil && m.id == r.id
+}
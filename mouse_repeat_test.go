@@ -0,0 +1,65 @@
+package tea
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMouseRepeater(t *testing.T) {
+	var mtx sync.Mutex
+	var repeats []mouseRepeatMsg
+	send := func(msg Msg) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		repeats = append(repeats, msg.(mouseRepeatMsg))
+	}
+	count := func() int {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return len(repeats)
+	}
+
+	var zones zoneRegistry
+	zones.register(zone{id: "arrow", rect: Rect{X: 0, Y: 0, Width: 1, Height: 1}, repeat: true})
+	zones.register(zone{id: "label", rect: Rect{X: 1, Y: 0, Width: 5, Height: 1}})
+
+	r := &mouseRepeater{delay: 20 * time.Millisecond, interval: 10 * time.Millisecond}
+	track := func(m MouseMsg) {
+		r.track(context.Background(), m, zones.repeats(m.X, m.Y), send)
+	}
+
+	// Presses outside repeat zones aren't repeated.
+	track(MouseMsg{X: 2, Type: MouseLeft})
+	time.Sleep(50 * time.Millisecond)
+	if n := count(); n != 0 {
+		t.Fatalf("expected no repeats outside a repeat zone, got %d", n)
+	}
+
+	press := MouseMsg{Type: MouseLeft}
+	track(press)
+	time.Sleep(60 * time.Millisecond)
+	if n := count(); n < 2 {
+		t.Fatalf("expected the press to be repeated, got %d repeats", n)
+	}
+
+	mtx.Lock()
+	first := repeats[0]
+	mtx.Unlock()
+	if !r.current(first) || !first.msg.Repeat || first.msg.X != press.X || first.msg.Type != press.Type {
+		t.Errorf("expected a current repeat of the press, got %+v", first)
+	}
+
+	// Dragging out of the zone stops the repeats, and those still in flight
+	// are no longer current.
+	track(MouseMsg{X: 1, Type: MouseLeft, Dragging: true, Drag: Drag{DX: 1}})
+	if r.current(first) {
+		t.Error("expected repeats to be stale after stopping")
+	}
+	n := count()
+	time.Sleep(50 * time.Millisecond)
+	if count() > n+1 {
+		t.Error("expected repeats to stop after dragging out of the zone")
+	}
+}
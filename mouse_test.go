@@ -1,448 +1,78 @@
 package tea
 
-import "testing"
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
 
-func TestMouseEvent_String(t *testing.T) {
-	tt := []struct {
-		name     string
-		event    MouseEvent
-		expected string
-	}{
-		{
-			name:     "unknown",
-			event:    MouseEvent{Type: MouseUnknown},
-			expected: "unknown",
-		},
-		{
-			name:     "left",
-			event:    MouseEvent{Type: MouseLeft},
-			expected: "left",
-		},
-		{
-			name:     "right",
-			event:    MouseEvent{Type: MouseRight},
-			expected: "right",
-		},
-		{
-			name:     "middle",
-			event:    MouseEvent{Type: MouseMiddle},
-			expected: "middle",
-		},
-		{
-			name:     "release",
-			event:    MouseEvent{Type: MouseRelease},
-			expected: "release",
-		},
-		{
-			name:     "wheel up",
-			event:    MouseEvent{Type: MouseWheelUp},
-			expected: "wheel up",
-		},
-		{
-			name:     "wheel down",
-			event:    MouseEvent{Type: MouseWheelDown},
-			expected: "wheel down",
-		},
-		{
-			name:     "motion",
-			event:    MouseEvent{Type: MouseMotion},
-			expected: "motion",
-		},
-		{
-			name: "alt+left",
-			event: MouseEvent{
-				Type: MouseLeft,
-				Alt:  true,
-			},
-			expected: "alt+left",
-		},
-		{
-			name: "ctrl+left",
-			event: MouseEvent{
-				Type: MouseLeft,
-				Ctrl: true,
-			},
-			expected: "ctrl+left",
-		},
-		{
-			name: "ctrl+alt+left",
-			event: MouseEvent{
-				Type: MouseLeft,
-				Alt:  true,
-				Ctrl: true,
-			},
-			expected: "ctrl+alt+left",
-		},
-		{
-			name: "ignore coordinates",
-			event: MouseEvent{
-				X:    100,
-				Y:    200,
-				Type: MouseLeft,
-			},
-			expected: "left",
-		},
-		{
-			name: "broken type",
-			event: MouseEvent{
-				Type: MouseEventType(-1000),
-			},
-			expected: "",
+func TestMotionCoalescer(t *testing.T) {
+	var mtx sync.Mutex
+	var delivered []Msg
+	c := &motionCoalescer{
+		interval: 50 * time.Millisecond,
+		send: func(msg Msg) {
+			mtx.Lock()
+			defer mtx.Unlock()
+			delivered = append(delivered, msg)
 		},
 	}
-
-	for i := range tt {
-		tc := tt[i]
-
-		t.Run(tc.name, func(t *testing.T) {
-			actual := tc.event.String()
-
-			if tc.expected != actual {
-				t.Fatalf("expected %q but got %q",
-					tc.expected,
-					actual,
-				)
-			}
-		})
+	deliver := func(msg Msg) {
+		c.deliver(msg, isMouseMotion(msg), time.Now())
 	}
-}
-
-func TestParseX10MouseEvent(t *testing.T) {
-	encode := func(b byte, x, y int) []byte {
-		return []byte{
-			'\x1b',
-			'[',
-			'M',
-			byte(32) + b,
-			byte(x + 32 + 1),
-			byte(y + 32 + 1),
-		}
+	get := func() []Msg {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return append([]Msg{}, delivered...)
 	}
 
-	tt := []struct {
-		name     string
-		buf      []byte
-		expected []MouseEvent
-	}{
-		// Position.
-		{
-			name: "zero position",
-			buf:  encode(0b0010_0000, 0, 0),
-			expected: []MouseEvent{
-				{
-					X:    0,
-					Y:    0,
-					Type: MouseLeft,
-				},
-			},
-		},
-		{
-			name: "max position",
-			buf:  encode(0b0010_0000, 222, 222), // Because 255 (max int8) - 32 - 1.
-			expected: []MouseEvent{
-				{
-					X:    222,
-					Y:    222,
-					Type: MouseLeft,
-				},
-			},
-		},
-		// Simple.
-		{
-			name: "left",
-			buf:  encode(0b0000_0000, 32, 16),
-			expected: []MouseEvent{
-				{
-					X:    32,
-					Y:    16,
-					Type: MouseLeft,
-				},
-			},
-		},
-		{
-			name: "left in motion",
-			buf:  encode(0b0010_0000, 32, 16),
-			expected: []MouseEvent{
-				{
-					X:    32,
-					Y:    16,
-					Type: MouseLeft,
-				},
-			},
-		},
-		{
-			name: "middle",
-			buf:  encode(0b0000_0001, 32, 16),
-			expected: []MouseEvent{
-				{
-					X:    32,
-					Y:    16,
-					Type: MouseMiddle,
-				},
-			},
-		},
-		{
-			name: "middle in motion",
-			buf:  encode(0b0010_0001, 32, 16),
-			expected: []MouseEvent{
-				{
-					X:    32,
-					Y:    16,
-					Type: MouseMiddle,
-				},
-			},
-		},
-		{
-			name: "right",
-			buf:  encode(0b0000_0010, 32, 16),
-			expected: []MouseEvent{
-				{
-					X:    32,
-					Y:    16,
-					Type: MouseRight,
-				},
-			},
-		},
-		{
-			name: "right in motion",
-			buf:  encode(0b0010_0010, 32, 16),
-			expected: []MouseEvent{
-				{
-					X:    32,
-					Y:    16,
-					Type: MouseRight,
-				},
-			},
-		},
-		{
-			name: "motion",
-			buf:  encode(0b0010_0011, 32, 16),
-			expected: []MouseEvent{
-				{
-					X:    32,
-					Y:    16,
-					Type: MouseMotion,
-				},
-			},
-		},
-		{
-			name: "wheel up",
-			buf:  encode(0b0100_0000, 32, 16),
-			expected: []MouseEvent{
-				{
-					X:    32,
-					Y:    16,
-					Type: MouseWheelUp,
-				},
-			},
-		},
-		{
-			name: "wheel down",
-			buf:  encode(0b0100_0001, 32, 16),
-			expected: []MouseEvent{
-				{
-					X:    32,
-					Y:    16,
-					Type: MouseWheelDown,
-				},
-			},
-		},
-		{
-			name: "release",
-			buf:  encode(0b0000_0011, 32, 16),
-			expected: []MouseEvent{
-				{
-					X:    32,
-					Y:    16,
-					Type: MouseRelease,
-				},
-			},
-		},
-		// Combinations.
-		{
-			name: "alt+right",
-			buf:  encode(0b0010_1010, 32, 16),
-			expected: []MouseEvent{
-				{
-					X:    32,
-					Y:    16,
-					Type: MouseRight,
-					Alt:  true,
-				},
-			},
-		},
-		{
-			name: "ctrl+right",
-			buf:  encode(0b0011_0010, 32, 16),
-			expected: []MouseEvent{
-				{
-					X:    32,
-					Y:    16,
-					Type: MouseRight,
-					Ctrl: true,
-				},
-			},
-		},
-		{
-			name: "ctrl+alt+right",
-			buf:  encode(0b0011_1010, 32, 16),
-			expected: []MouseEvent{
-				{
-					X:    32,
-					Y:    16,
-					Type: MouseRight,
-					Alt:  true,
-					Ctrl: true,
-				},
-			},
-		},
-		{
-			name: "alt+wheel down",
-			buf:  encode(0b0100_1001, 32, 16),
-			expected: []MouseEvent{
-				{
-					X:    32,
-					Y:    16,
-					Type: MouseWheelDown,
-					Alt:  true,
-				},
-			},
-		},
-		{
-			name: "ctrl+wheel down",
-			buf:  encode(0b0101_0001, 32, 16),
-			expected: []MouseEvent{
-				{
-					X:    32,
-					Y:    16,
-					Type: MouseWheelDown,
-					Ctrl: true,
-				},
-			},
-		},
-		{
-			name: "ctrl+alt+wheel down",
-			buf:  encode(0b0101_1001, 32, 16),
-			expected: []MouseEvent{
-				{
-					X:    32,
-					Y:    16,
-					Type: MouseWheelDown,
-					Alt:  true,
-					Ctrl: true,
-				},
-			},
-		},
-		// Unknown.
-		{
-			name: "wheel with unknown bit",
-			buf:  encode(0b0100_0010, 32, 16),
-			expected: []MouseEvent{
-				{
-					X:    32,
-					Y:    16,
-					Type: MouseUnknown,
-				},
-			},
-		},
-		{
-			name: "unknown with modifier",
-			buf:  encode(0b0100_1010, 32, 16),
-			expected: []MouseEvent{
-				{
-					X:    32,
-					Y:    16,
-					Type: MouseUnknown,
-					Alt:  true,
-				},
-			},
-		},
-		// Overflow position.
-		{
-			name: "overflow position",
-			buf:  encode(0b0010_0000, 250, 223), // Because 255 (max int8) - 32 - 1.
-			expected: []MouseEvent{
-				{
-					X:    -6,
-					Y:    -33,
-					Type: MouseLeft,
-				},
-			},
-		},
-		// Batched events.
-		{
-			name: "batched events",
-			buf:  append(encode(0b0010_0000, 32, 16), encode(0b0000_0011, 64, 32)...),
-			expected: []MouseEvent{
-				{
-					X:    32,
-					Y:    16,
-					Type: MouseLeft,
-				},
-				{
-					X:    64,
-					Y:    32,
-					Type: MouseRelease,
-				},
-			},
-		},
+	motion := func(x int) MouseMsg {
+		return MouseMsg{X: x, Type: MouseMotion}
 	}
 
-	for i := range tt {
-		tc := tt[i]
+	// The first motion is delivered right away, the rest of the burst is
+	// held back.
+	deliver(motion(1))
+	deliver(motion(2))
+	deliver(motion(3))
+	if got := get(); !reflect.DeepEqual(got, []Msg{motion(1)}) {
+		t.Fatalf("expected only the first motion to be delivered, got %v", got)
+	}
 
-		t.Run(tc.name, func(t *testing.T) {
-			actual, err := parseX10MouseEvents(tc.buf)
-			if err != nil {
-				t.Fatalf("unexpected error for test: %v",
-					err,
-				)
-			}
+	// Other input flushes pending motion first.
+	key := KeyMsg{Type: KeyEnter}
+	deliver(key)
+	if got := get(); !reflect.DeepEqual(got, []Msg{motion(1), motion(3), key}) {
+		t.Fatalf("expected pending motion before the key, got %v", got)
+	}
 
-			for i := range tc.expected {
-				if tc.expected[i] != actual[i] {
-					t.Fatalf("expected %#v but got %#v",
-						tc.expected[i],
-						actual[i],
-					)
-				}
-			}
-		})
+	// Pending motion is delivered after the interval.
+	drag := MouseMsg{X: 5, Type: MouseLeft, Dragging: true, Drag: Drag{StartX: 4, DX: 1}}
+	deliver(motion(4))
+	deliver(drag)
+	time.Sleep(100 * time.Millisecond)
+	if got := get(); !reflect.DeepEqual(got, []Msg{motion(1), motion(3), key, drag}) {
+		t.Fatalf("expected the latest motion to be delivered, got %v", got)
 	}
 }
 
-func TestParseX10MouseEvent_error(t *testing.T) {
-	tt := []struct {
-		name string
-		buf  []byte
-	}{
-		{
-			name: "empty buf",
-			buf:  nil,
-		},
-		{
-			name: "wrong high bit",
-			buf:  []byte("\x1a[M@A1"),
-		},
-		{
-			name: "short buf",
-			buf:  []byte("\x1b[M@A"),
-		},
-		{
-			name: "long buf",
-			buf:  []byte("\x1b[M@A11"),
-		},
+func TestMouseMsgJSON(t *testing.T) {
+	msg := MouseMsg{X: 4, Y: 2, Type: MouseRight}.WithZones("menu")
+	b, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"x":4,"y":2,"type":"right","zones":["menu"]}`; string(b) != expected {
+		t.Errorf("expected %s, got %s", expected, b)
 	}
 
-	for i := range tt {
-		tc := tt[i]
-
-		t.Run(tc.name, func(t *testing.T) {
-			_, err := parseX10MouseEvents(tc.buf)
-
-			if err == nil {
-				t.Fatalf("expected error but got nil")
-			}
-		})
+	var got MouseMsg
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, msg) {
+		t.Errorf("expected %+v, got %+v", msg, got)
 	}
 }
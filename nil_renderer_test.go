@@ -15,10 +15,23 @@ func TestNilRenderer(t *testing.T) {
 	}
 	r.exitAltScreen()
 	r.clearScreen()
+	r.resetTerminal()
 	r.showCursor()
 	r.hideCursor()
+	r.setCursorShape(CursorBar)
+	if r.cursor() != (cursorState{}) {
+		t.Errorf("cursor should always return the zero state")
+	}
 	r.enableMouseCellMotion()
 	r.disableMouseCellMotion()
 	r.enableMouseAllMotion()
 	r.disableMouseAllMotion()
+	r.enableBracketedPaste()
+	if r.bracketedPasteActive() {
+		t.Errorf("bracketedPasteActive should always return false")
+	}
+	r.disableBracketedPaste()
+	if err := r.execute("a"); err != nil {
+		t.Errorf("execute should never fail, got %v", err)
+	}
 }
@@ -0,0 +1,87 @@
+package tea
+
+import "strings"
+
+// notificationProtocol is a protocol for showing desktop notifications.
+type notificationProtocol int
+
+// Notification protocols.
+const (
+	// notifyBell rings the bell, which terminals commonly turn into a
+	// notification, or mark the tab with, when it isn't focused.
+	notifyBell notificationProtocol = iota
+
+	// notifyOSC9 is iTerm2's protocol, which only has a body.
+	notifyOSC9
+
+	// notifyOSC777 is rxvt-unicode's protocol, which is used by VTE, foot,
+	// WezTerm and Ghostty, among others.
+	notifyOSC777
+
+	// notifyOSC99 is kitty's protocol.
+	notifyOSC99
+)
+
+// notifyMsg is an internal message used to show a desktop notification. You
+// can send a notifyMsg with Notify.
+type notifyMsg struct {
+	title string
+	body  string
+}
+
+// Notify is a command that shows a desktop notification with the given title
+// and body, for programs that do work in the background and want to let the
+// user know when it's done, say in another tab.
+//
+// Notifications are sent with the terminal's own protocol, where it has one.
+// Other terminals, and those whose tier doesn't support FeatureNotifications,
+// get the bell, which most of them draw attention with when they're not in
+// focus. Whether notifications are shown as such is reported in
+// Capabilities.
+func Notify(title, body string) Cmd {
+	return func() Msg {
+		return notifyMsg{title: title, body: body}
+	}
+}
+
+// notificationSeq returns the sequence that shows a notification with the
+// given protocol.
+func notificationSeq(protocol notificationProtocol, title, body string) string {
+	title, body = sanitizeTitle(title), sanitizeTitle(body)
+
+	switch protocol {
+	case notifyOSC9:
+		msg := body
+		if title != "" && body != "" {
+			msg = title + ": " + body
+		} else if title != "" {
+			msg = title
+		}
+		return "\x1b]9;" + msg + "\a"
+
+	case notifyOSC777:
+		// The title ends at the first semicolon.
+		title = strings.ReplaceAll(title, ";", ",")
+		return "\x1b]777;notify;" + title + ";" + body + "\a"
+
+	case notifyOSC99:
+		// The title and body are sent separately, and shown once the
+		// notification is done (d=1).
+		if body == "" {
+			return "\x1b]99;;" + title + "\x1b\\"
+		}
+		return "\x1b]99;i=1:d=0;" + title + "\x1b\\" +
+			"\x1b]99;i=1:d=1:p=body;" + body + "\x1b\\"
+	}
+
+	return "\a"
+}
+
+// notificationProtocol returns the protocol notifications are shown with in
+// the program's terminal.
+func (p *Program) notificationProtocol() notificationProtocol {
+	if !p.supports(FeatureNotifications) {
+		return notifyBell
+	}
+	return quirksFor(p.terminal).notifications
+}
@@ -0,0 +1,61 @@
+package tea
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNotificationSeq(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol notificationProtocol
+		title    string
+		body     string
+		expected string
+	}{
+		{"bell", notifyBell, "Done", "Build finished", "\a"},
+		{"osc 9", notifyOSC9, "Done", "Build finished", "\x1b]9;Done: Build finished\a"},
+		{"osc 9 title only", notifyOSC9, "Done", "", "\x1b]9;Done\a"},
+		{"osc 9 body only", notifyOSC9, "", "Build finished", "\x1b]9;Build finished\a"},
+		{"osc 777", notifyOSC777, "Done; ok", "Build finished", "\x1b]777;notify;Done, ok;Build finished\a"},
+		{"osc 777 sanitized", notifyOSC777, "Done\a", "Build\x1b finished", "\x1b]777;notify;Done;Build finished\a"},
+		{
+			"osc 99", notifyOSC99, "Done", "Build finished",
+			"\x1b]99;i=1:d=0;Done\x1b\\\x1b]99;i=1:d=1:p=body;Build finished\x1b\\",
+		},
+		{"osc 99 title only", notifyOSC99, "Done", "", "\x1b]99;;Done\x1b\\"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if seq := notificationSeq(test.protocol, test.title, test.body); seq != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, seq)
+			}
+		})
+	}
+}
+
+func TestNotificationProtocol(t *testing.T) {
+	tests := []struct {
+		name     string
+		terminal string
+		tier     Tier
+		expected notificationProtocol
+	}{
+		{"kitty", termKitty, TierModern, notifyOSC99},
+		{"iterm2", termITerm2, TierModern, notifyOSC9},
+		{"foot", termFoot, TierModern, notifyOSC777},
+		{"unknown", termUnknown, TierXterm, notifyBell},
+		{"dumb tier", termKitty, TierDumb, notifyBell},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := NewProgram(nil, WithOutput(&bytes.Buffer{}))
+			p.terminal, p.tier = test.terminal, test.tier
+			if protocol := p.notificationProtocol(); protocol != test.expected {
+				t.Errorf("expected protocol %d, got %d", test.expected, protocol)
+			}
+		})
+	}
+}
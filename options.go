@@ -3,6 +3,8 @@ package tea
 import (
 	"context"
 	"io"
+	"os"
+	"time"
 
 	"github.com/muesli/termenv"
 )
@@ -36,6 +38,20 @@ func WithOutput(output io.Writer) ProgramOption {
 	}
 }
 
+// WithOutputTee copies every byte the program writes to the terminal to w,
+// too, such as to a file for debugging, or to a session recorder. Unlike
+// WithFrameHook, it sees the output exactly as the terminal does, including
+// sequences sent outside of frames.
+//
+// Writes to w are made one at a time, from the renderer, which waits for them
+// to return. Errors writing to w are ignored. Output written by a custom
+// renderer set with WithRenderer isn't copied.
+func WithOutputTee(w io.Writer) ProgramOption {
+	return func(p *Program) {
+		p.outputTee = w
+	}
+}
+
 // WithInput sets the input which, by default, is stdin. In most cases you
 // won't need to use this. To disable input entirely pass nil.
 //
@@ -62,6 +78,33 @@ func WithoutSignalHandler() ProgramOption {
 	}
 }
 
+// WithResizeDebounce has the program wait for resizing to settle, until the
+// window hasn't been resized for the given duration, before sending a
+// WindowSizeMsg, rather than sending one as often as Update keeps up with
+// while the user drags the window's edge. The size the window is left at is
+// always sent.
+func WithResizeDebounce(d time.Duration) ProgramOption {
+	return func(p *Program) {
+		p.resizeDebounce = d
+	}
+}
+
+// WithSignals sends the given OS signals to the program as a SignalMsg, so
+// the model can react to them, such as reloading its configuration on SIGHUP,
+// without a signal handler of its own:
+//
+//	p := tea.NewProgram(model, tea.WithSignals(syscall.SIGHUP, syscall.SIGUSR1))
+//
+// Signals given here are no longer handled by the program, so giving SIGINT
+// or SIGTERM leaves quitting to the model. They're sent even with
+// WithoutSignalHandler, and those received while the terminal is released,
+// as with ExecProcess, are sent once the program is back.
+func WithSignals(sigs ...os.Signal) ProgramOption {
+	return func(p *Program) {
+		p.signals = append(p.signals, sigs...)
+	}
+}
+
 // WithoutCatchPanics disables the panic catching that Bubble Tea does by
 // default. If panic catching is disabled the terminal will be in a fairly
 // unusable state after a panic because Bubble Tea will not perform its usual
@@ -141,6 +184,18 @@ func WithMouseAllMotion() ProgramOption {
 	}
 }
 
+// WithoutBracketedPaste starts the program with bracketed paste disabled.
+// Pasted text will then be delivered as individual keypresses, as if it had
+// been typed, rather than as a PasteMsg.
+//
+// To toggle bracketed paste once the program has already started running use
+// the EnableBracketedPaste and DisableBracketedPaste commands.
+func WithoutBracketedPaste() ProgramOption {
+	return func(p *Program) {
+		p.startupOptions |= withoutBracketedPaste
+	}
+}
+
 // WithoutRenderer disables the renderer. When this is set output and log
 // statements will be plainly sent to stdout (or another output if one is set)
 // without any rendering and redrawing logic. In other words, printing and
@@ -155,6 +210,18 @@ func WithoutRenderer() ProgramOption {
 	}
 }
 
+// WithRenderer sets a custom renderer, which the program hands its views to
+// instead of rendering them itself. See Renderer for what's expected of it.
+//
+// Messages printed with Println and Printf are only shown by Bubble Tea's
+// own renderer, as are the ignored lines and scrolling of high-performance
+// rendering.
+func WithRenderer(r Renderer) ProgramOption {
+	return func(p *Program) {
+		p.renderer = &customRenderer{r: r}
+	}
+}
+
 // WithANSICompressor removes redundant ANSI sequences to produce potentially
 // smaller output, at the cost of some processing overhead.
 //
@@ -166,10 +233,34 @@ func WithANSICompressor() ProgramOption {
 	}
 }
 
+// WithMaxInlineHeight limits the output of programs that don't use the
+// altscreen to the given number of lines. The output takes up as many lines
+// as the view has, and no more than the window has, growing and shrinking
+// with the view; the lines it no longer needs are cleared. With a limit,
+// views taller than it are cut from the top, like those taller than the
+// window, so that the end of the view, where prompts usually are, stays
+// visible.
+func WithMaxInlineHeight(lines int) ProgramOption {
+	return func(p *Program) {
+		p.maxInlineHeight = lines
+	}
+}
+
+// WithRenderOnChange has the renderer draw frames only when the view has
+// changed, rather than checking for changes at a fixed interval, so that a
+// program that's sitting idle doesn't keep waking up the CPU. Frames are still
+// drawn at most at the rate set with WithFPS.
+func WithRenderOnChange() ProgramOption {
+	return func(p *Program) {
+		p.startupOptions |= withRenderOnChange
+	}
+}
+
 // WithFilter supplies an event filter that will be invoked before Bubble Tea
 // processes a tea.Msg. The event filter can return any tea.Msg which will then
 // get handled by Bubble Tea instead of the original event. If the event filter
 // returns nil, the event will be ignored and Bubble Tea will not process it.
+// It can also return several messages in its place, see Msgs.
 //
 // As an example, this could be used to prevent a program from shutting down if
 // there are unsaved changes.
@@ -200,3 +291,442 @@ func WithFilter(filter func(Model, Msg) Msg) ProgramOption {
 		p.filter = filter
 	}
 }
+
+// WithPowerEvents enables power state reporting. The power state of the
+// system is polled at the given interval and a PowerMsg is sent when the
+// program starts and whenever the state changes. A SystemResumeMsg is sent
+// when the program notices that the system has been suspended.
+//
+// This is useful for long-running programs, such as dashboards, which may
+// want to pause polling, dim their display, or warn when the battery is
+// running low.
+//
+// Battery and AC state are currently only available on Linux.
+func WithPowerEvents(interval time.Duration) ProgramOption {
+	return func(p *Program) {
+		p.powerInterval = interval
+	}
+}
+
+// WithFrameHook sets a function which is called with a description of every
+// frame the renderer writes to the terminal: the regions that changed and the
+// bytes written. This allows recorders, remote mirrors and the like to follow
+// along with the program's output without parsing it.
+//
+// The hook is called from the renderer, which waits for it to return, so it
+// should hand the frame off quickly rather than process it in place. The
+// frame's Output is not reused and may be retained.
+//
+// The hook is not called when rendering is disabled with WithoutRenderer.
+func WithFrameHook(hook func(FrameDiff)) ProgramOption {
+	return func(p *Program) {
+		p.frameHook = hook
+	}
+}
+
+// WithFrameFilter adds a function which transforms every view before it's
+// rendered, after layers have been composited and the log console drawn on
+// top. It can be used to restyle the whole view, say dimming it behind a
+// modal, watermarking a demo, or scrubbing secrets from the output of a
+// recorded session. Filters added by several options are applied in the order
+// they were added.
+//
+// Filters are called wherever views are rendered, which with
+// WithPipelinedRendering is a goroutine of its own, so any state they share
+// with the model must be synchronized. They should return quickly, as they're
+// run for every view.
+func WithFrameFilter(filter func(string) string) ProgramOption {
+	return func(p *Program) {
+		p.frameFilters = append(p.frameFilters, filter)
+	}
+}
+
+// WithLogConsole turns messages printed with Println, Printf, Log and Logf
+// into a log console. Rather than being printed above the program, messages
+// are recorded along with their level and time, and the most recent ones can
+// be shown on top of the program's view with the ToggleLogConsole command.
+// At most size messages are kept.
+//
+// When the program exits while running inline, that is, not in the
+// altscreen, the recorded messages are printed, so they remain in the
+// terminal's scrollback.
+func WithLogConsole(size int) ProgramOption {
+	return func(p *Program) {
+		p.logConsole = &logConsole{size: size}
+	}
+}
+
+// WithCrashReports enables crash reports. When the program panics or exits
+// with an error, a report is written to a temporary file, and its path is
+// printed once the terminal has been restored. The report contains the last
+// frame rendered, the given number of most recent messages, the stacks of all
+// goroutines, and the terminal's capabilities, which makes for more
+// actionable bug reports.
+//
+// Keep in mind that messages may contain sensitive data, such as passwords
+// typed by the user, which will end up in the report.
+func WithCrashReports(messages int) ProgramOption {
+	return func(p *Program) {
+		p.crashRecorder = &crashRecorder{size: messages}
+	}
+}
+
+// WithDeterministicCommands makes the order in which the results of batched
+// commands are delivered deterministic. It's intended for tests of programs
+// which make heavy use of Batch, whose results would otherwise arrive in
+// whatever order the commands happen to finish.
+//
+// The commands in a batch still run concurrently, but their results are held
+// back until all of them have finished, and are then delivered in an order
+// determined by the given seed. The same seed always produces the same order,
+// while running a test with several seeds exercises different orders.
+func WithDeterministicCommands(seed int64) ProgramOption {
+	return func(p *Program) {
+		p.scheduler = newCommandScheduler(seed)
+	}
+}
+
+// WithFramePerf enables reports on the renderer's performance. A FramePerfMsg
+// with the render times and the number of dropped frames is sent at the given
+// interval, as long as anything is being rendered.
+func WithFramePerf(interval time.Duration) ProgramOption {
+	return func(p *Program) {
+		p.framePerfInterval = interval
+	}
+}
+
+// WithPipelinedRendering calls View and renders its result on a goroutine of
+// its own, so that Update can keep processing messages in the meantime. This
+// improves input latency for programs with expensive View functions. When
+// Update is faster than View, intermediate views are skipped, as they would
+// be replaced before reaching the screen anyway.
+//
+// View is called on a model returned by Update while Update is already
+// working on the next message. This is only safe if Update doesn't modify
+// models it has returned, which holds for models with value receivers that
+// don't share mutable state between copies. Don't use this option with
+// models which Update modifies in place, such as pointer models.
+func WithPipelinedRendering() ProgramOption {
+	return func(p *Program) {
+		p.pipelined = true
+	}
+}
+
+// WithInputLatency enables measuring the latency from reading keyboard and
+// mouse input to writing the first frame which reflects it. An
+// InputLatencyMsg with the latency percentiles is sent at the given interval,
+// as long as there is input.
+func WithInputLatency(interval time.Duration) ProgramOption {
+	return func(p *Program) {
+		p.latency = &latencyTracker{}
+		p.latencyInterval = interval
+	}
+}
+
+// WithMouseMotionCoalescing collapses bursts of mouse motion, including
+// drags, into the latest event per interval. Terminals report motion as fast
+// as the mouse moves, which can flood programs whose Update or View is
+// expensive with more events than they can handle. Other mouse events and
+// keys are delivered right away, after any pending motion, so that the order
+// of events is preserved.
+func WithMouseMotionCoalescing(interval time.Duration) ProgramOption {
+	return func(p *Program) {
+		p.motionInterval = interval
+	}
+}
+
+// WithGestures enables recognizing swipes and flings in mouse drags, which
+// are sent as SwipeMsg and FlingMsg after the release ending the drag. It's
+// meant for terminals which translate touches into mouse events, and needs
+// mouse tracking to be enabled, see WithMouseCellMotion.
+func WithGestures() ProgramOption {
+	return func(p *Program) {
+		p.gestures = true
+	}
+}
+
+// WithMouseRepeat sets how long a mouse button must be held in a repeat zone
+// before the press is repeated, and the interval of the repeats, see
+// RegisterRepeatZone. By default, presses are repeated every 50ms after
+// 400ms.
+func WithMouseRepeat(delay, interval time.Duration) ProgramOption {
+	return func(p *Program) {
+		p.repeater.delay = delay
+		p.repeater.interval = interval
+	}
+}
+
+// WithFixedWindowSize makes the program use the given window size instead
+// of the terminal's. The size isn't detected and resizes are ignored; a
+// WindowSizeMsg with the given size is sent when the program starts, even if
+// the output isn't a terminal. This is useful when rendering to a pipe or a
+// file, generating screenshots, or serving remote clients which negotiate
+// their size separately.
+func WithFixedWindowSize(width, height int) ProgramOption {
+	return func(p *Program) {
+		p.fixedSize = &WindowSizeMsg{Width: width, Height: height}
+	}
+}
+
+// WithKeyboardEnhancements enables the given flags of the kitty keyboard
+// protocol, which makes terminals report keys unambiguously and in more
+// detail. Programs can pick the flags they need; for example, one which needs
+// key releases, but not every key reported as an escape sequence, might use:
+//
+//	p := tea.NewProgram(model, tea.WithKeyboardEnhancements(
+//		tea.KeyboardDisambiguate|tea.KeyboardReportEvents,
+//	))
+//
+// The flags are only sent to terminals of the modern tier. Terminals which
+// don't support the protocol report keys as usual, except for those which
+// support win32-input-mode, such as Windows Terminal, where it's enabled
+// instead.
+func WithKeyboardEnhancements(flags KeyboardEnhancements) ProgramOption {
+	return func(p *Program) {
+		p.keyboardFlags = flags
+	}
+}
+
+// WithInputBuffer sets the size of the buffer input is read into, and how
+// input is read. By default input is read in chunks of 256 bytes and
+// processed right away, which keeps latency low. Programs which receive a lot
+// of input at once, such as large pastes or floods of mouse motion, can use a
+// bigger buffer and InputThroughput to process it more efficiently. A size of
+// zero keeps the default size.
+func WithInputBuffer(size int, mode InputMode) ProgramOption {
+	return func(p *Program) {
+		p.inputConfig.bufferSize = size
+		p.inputConfig.mode = mode
+	}
+}
+
+// WithDoubleClickInterval sets the longest time between presses of a mouse
+// button at the same position which count as successive clicks. Presses are
+// counted in MouseMsg.ClickCount, so that programs can, for example, select
+// words on double clicks and lines on triple clicks. The default is 500ms.
+func WithDoubleClickInterval(d time.Duration) ProgramOption {
+	return func(p *Program) {
+		p.inputConfig.clickInterval = d
+	}
+}
+
+// WithPasteStreaming delivers bracketed pastes as they're read, as a
+// PasteStartMsg, PasteChunkMsgs of at most chunkSize bytes and a PasteEndMsg,
+// rather than as a single PasteMsg once they end. Large pastes then don't
+// hold up input until they end, and don't need to be held in memory at once.
+func WithPasteStreaming(chunkSize int) ProgramOption {
+	return func(p *Program) {
+		p.inputConfig.pasteChunkSize = chunkSize
+	}
+}
+
+// WithMaxPasteSize limits pastes to the given number of bytes. The rest of a
+// larger paste is dropped, so that pasting a huge amount of text by mistake
+// doesn't exhaust the program's memory.
+func WithMaxPasteSize(size int) ProgramOption {
+	return func(p *Program) {
+		p.inputConfig.maxPasteSize = size
+	}
+}
+
+// WithKeyTable adds escape sequences, and the keys they're translated into, to
+// the ones Bubble Tea knows. They take precedence over the built-in
+// sequences, so they can also fix keys on unusual terminals, such as st
+// variants or serial consoles. For example:
+//
+//	p := tea.NewProgram(model, tea.WithKeyTable(map[string]tea.Key{
+//		"\x1b[4h": {Type: tea.KeyInsert},
+//	}))
+//
+// The sequences of the terminal's terminfo entry can be added with
+// TerminfoKeys:
+//
+//	keys, err := tea.TerminfoKeys(os.Getenv("TERM"))
+//	if err == nil {
+//		opts = append(opts, tea.WithKeyTable(keys))
+//	}
+//
+// The option can be given more than once; later sequences replace earlier
+// ones.
+func WithKeyTable(keys map[string]Key) ProgramOption {
+	return func(p *Program) {
+		if p.inputConfig.keys == nil {
+			p.inputConfig.keys = make(map[string]Key, len(keys))
+		}
+		for seq, k := range keys {
+			p.inputConfig.keys[seq] = k
+		}
+	}
+}
+
+// WithUnknownSequences sends escape sequences read from the terminal which
+// Bubble Tea doesn't recognize as UnknownSequenceMsg, rather than dropping
+// them. This is useful for implementing protocols Bubble Tea doesn't know, or
+// for debugging terminal quirks.
+func WithUnknownSequences() ProgramOption {
+	return func(p *Program) {
+		p.inputConfig.reportUnknown = true
+	}
+}
+
+// WithRawInput sends the bytes of all input read from the terminal as
+// RawInputMsg, ahead of the messages parsed from them, which are sent as
+// usual.
+func WithRawInput() ProgramOption {
+	return func(p *Program) {
+		p.inputConfig.reportRaw = true
+	}
+}
+
+// WithKeyTranslator translates the keys read from the terminal before they
+// reach the program, which allows for remapping keys across a whole
+// application: swapping ctrl and alt, translating between keyboard layouts,
+// and so on. Key releases are translated like the keys pressed. Keys
+// translated into a KeyRunes key without any runes are dropped, for
+// example:
+//
+//	tea.WithKeyTranslator(func(k tea.KeyMsg) tea.KeyMsg {
+//		switch k.Type {
+//		case tea.KeyCtrlH:
+//			// Some terminals send ctrl+h for backspace.
+//			return tea.KeyMsg{Type: tea.KeyBackspace, Alt: k.Alt}
+//		case tea.KeyCtrlZ:
+//			// Disable the key.
+//			return tea.KeyMsg{Type: tea.KeyRunes}
+//		}
+//		return k
+//	})
+//
+// Keys are translated before key sequences are recognized, see
+// RegisterKeySequence. If the option is given more than once, the
+// translators are applied in order.
+func WithKeyTranslator(translate func(KeyMsg) KeyMsg) ProgramOption {
+	return func(p *Program) {
+		if prev := p.keyTranslator; prev != nil {
+			p.keyTranslator = func(k KeyMsg) KeyMsg {
+				if k = prev(k); isDroppedKey(k) {
+					return k
+				}
+				return translate(k)
+			}
+			return
+		}
+		p.keyTranslator = translate
+	}
+}
+
+// WithKeySequenceTimeout sets how long the next key of a sequence registered
+// with RegisterKeySequence is waited for before the keys typed so far are
+// sent on their own. The default is one second.
+func WithKeySequenceTimeout(d time.Duration) ProgramOption {
+	return func(p *Program) {
+		p.keySequences.timeout = d
+	}
+}
+
+// WithTier sets the terminal's tier, instead of detecting it from the
+// environment. Features the tier doesn't support are not used, even when
+// requested by the program. Users can still override the tier with the
+// BUBBLETEA_TIER environment variable.
+func WithTier(t Tier) ProgramOption {
+	return func(p *Program) {
+		p.tier = t
+		p.tierForced = true
+	}
+}
+
+// WithFPS sets the most frames per second the renderer draws, between 1 and
+// 120. The default is 60. It can be changed while the program runs with
+// SetFPS.
+func WithFPS(fps int) ProgramOption {
+	return func(p *Program) {
+		p.fps = fps
+	}
+}
+
+// WithWriteBatching holds back writes to the terminal and writes them with a
+// single call, which over SSH and other network connections means fewer
+// packets and less flicker, as frames arrive in one piece. By default, what's
+// held back, including the sequences written between frames, is written with
+// each frame. With a positive interval, it's written at most once per
+// interval instead, however many frames are drawn in the meantime.
+//
+// Batching only applies to the standard renderer. Writes made while the
+// terminal is released, and when the program exits, aren't held back.
+func WithWriteBatching(interval time.Duration) ProgramOption {
+	return func(p *Program) {
+		p.writeBatching = true
+		p.batchInterval = interval
+	}
+}
+
+// WithFrameBudget limits the bytes the renderer writes to the terminal to
+// about the given number per frame, on average, for slow connections where a
+// burst of large frames would back up. After a frame beyond the budget, the
+// frames that follow are skipped until the excess has been paid off at the
+// rate of the budget per frame; the view that's shown then is the latest one.
+// Frames larger than the budget are still written whole, and the final frame
+// is written whatever the budget.
+//
+// The budget only applies to the standard renderer.
+func WithFrameBudget(bytes int) ProgramOption {
+	return func(p *Program) {
+		p.frameBudget = bytes
+	}
+}
+
+// WithColorProfile sets the colors the terminal supports, instead of
+// detecting them from the environment and by querying the terminal. Colors
+// in the program's output that the profile doesn't support are converted to
+// the closest ones it does, or dropped for termenv.Ascii.
+func WithColorProfile(profile termenv.Profile) ProgramOption {
+	return func(p *Program) {
+		p.colorProfile = profile
+		p.colorProfileForced = true
+	}
+}
+
+// WithSynchronizedOutput sets whether frames are written as synchronized
+// updates (mode 2026), which the terminal shows all at once rather than as
+// they arrive, instead of asking the terminal whether it supports them. By
+// default they're used where the terminal reports support.
+func WithSynchronizedOutput(enabled bool) ProgramOption {
+	return func(p *Program) {
+		p.syncOutput = enabled
+		p.syncOutputForced = true
+	}
+}
+
+// WithQueueLimits limits the number and estimated size of the messages waiting
+// to be processed by Update, which otherwise grow without bounds when messages
+// are sent faster than Update can handle them. See QueueLimits for what
+// happens when the queue is full.
+func WithQueueLimits(limits QueueLimits) ProgramOption {
+	return func(p *Program) {
+		p.queue = newMsgQueue(limits)
+	}
+}
+
+// WithMessageQueue bounds the number of messages waiting to be processed by
+// Update to size, with the given policy deciding what happens when the queue
+// is full. It's a shorthand for WithQueueLimits with only MaxMessages set.
+func WithMessageQueue(size int, policy QueuePolicy) ProgramOption {
+	return WithQueueLimits(QueueLimits{MaxMessages: size, Policy: policy})
+}
+
+// WithInputIdleTimeout sends an InputIdleMsg when the program has received no
+// input for the given duration.
+func WithInputIdleTimeout(d time.Duration) ProgramOption {
+	return func(p *Program) {
+		p.idleTimeout = d
+	}
+}
+
+// WithShutdownTimeout sets the grace period the program waits for the command
+// returned for ShutdownMsg, and the functions registered with OnShutdown, when
+// it exits. The default is five seconds.
+func WithShutdownTimeout(d time.Duration) ProgramOption {
+	return func(p *Program) {
+		p.shutdownTimeout = d
+	}
+}
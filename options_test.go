@@ -2,7 +2,11 @@ package tea
 
 import (
 	"bytes"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestOptions(t *testing.T) {
@@ -49,6 +53,132 @@ func TestOptions(t *testing.T) {
 		}
 	})
 
+	t.Run("frame filters", func(t *testing.T) {
+		upper := func(s string) string { return strings.ToUpper(s) }
+		p := NewProgram(nil, WithFrameFilter(upper), WithFrameFilter(upper))
+		if len(p.frameFilters) != 2 {
+			t.Errorf("expected 2 frame filters, got %d", len(p.frameFilters))
+		}
+	})
+
+	t.Run("output tee", func(t *testing.T) {
+		var buf bytes.Buffer
+		p := NewProgram(nil, WithOutputTee(&buf))
+		if p.outputTee != &buf {
+			t.Errorf("expected the output tee to be set")
+		}
+	})
+
+	t.Run("write batching", func(t *testing.T) {
+		p := NewProgram(nil, WithWriteBatching(time.Millisecond), WithFrameBudget(4096))
+		if !p.writeBatching || p.batchInterval != time.Millisecond || p.frameBudget != 4096 {
+			t.Errorf("expected write batching and a frame budget to be set")
+		}
+	})
+
+	t.Run("power events", func(t *testing.T) {
+		p := NewProgram(nil, WithPowerEvents(time.Minute))
+		if p.powerInterval != time.Minute {
+			t.Errorf("expected power interval to be %v, got %v", time.Minute, p.powerInterval)
+		}
+	})
+
+	t.Run("input idle timeout", func(t *testing.T) {
+		p := NewProgram(nil, WithInputIdleTimeout(time.Minute))
+		if p.idleTimeout != time.Minute {
+			t.Errorf("expected idle timeout to be %v, got %v", time.Minute, p.idleTimeout)
+		}
+	})
+
+	t.Run("double click interval", func(t *testing.T) {
+		p := NewProgram(nil, WithInputBuffer(1024, InputThroughput), WithDoubleClickInterval(time.Second))
+		if p.inputConfig.clickInterval != time.Second || p.inputConfig.bufferSize != 1024 {
+			t.Errorf("expected click interval and buffer size to be set, got %+v", p.inputConfig)
+		}
+	})
+
+	t.Run("paste streaming", func(t *testing.T) {
+		p := NewProgram(nil, WithPasteStreaming(4096), WithMaxPasteSize(1<<20))
+		if p.inputConfig.pasteChunkSize != 4096 || p.inputConfig.maxPasteSize != 1<<20 {
+			t.Errorf("expected paste chunk size and limit to be set, got %+v", p.inputConfig)
+		}
+	})
+
+	t.Run("key table", func(t *testing.T) {
+		p := NewProgram(nil,
+			WithKeyTable(map[string]Key{"\x1b[4h": {Type: KeyInsert}, "\x1bOH": {Type: KeyEnd}}),
+			WithKeyTable(map[string]Key{"\x1bOH": {Type: KeyHome}}),
+		)
+		expected := map[string]Key{"\x1b[4h": {Type: KeyInsert}, "\x1bOH": {Type: KeyHome}}
+		if !reflect.DeepEqual(p.inputConfig.keys, expected) {
+			t.Errorf("expected keys %v, got %v", expected, p.inputConfig.keys)
+		}
+	})
+
+	t.Run("unknown sequences and raw input", func(t *testing.T) {
+		p := NewProgram(nil, WithUnknownSequences(), WithRawInput())
+		if !p.inputConfig.reportUnknown || !p.inputConfig.reportRaw {
+			t.Errorf("expected unknown sequences and raw input to be reported, got %+v", p.inputConfig)
+		}
+	})
+
+	t.Run("key sequence timeout", func(t *testing.T) {
+		p := NewProgram(nil, WithKeySequenceTimeout(time.Minute))
+		if p.keySequences.timeout != time.Minute {
+			t.Errorf("expected a key sequence timeout of %v, got %v", time.Minute, p.keySequences.timeout)
+		}
+	})
+
+	t.Run("message queue", func(t *testing.T) {
+		p := NewProgram(nil, WithMessageQueue(100, QueueCoalesce))
+		if p.queue == nil || p.queue.limits != (QueueLimits{MaxMessages: 100, Policy: QueueCoalesce}) {
+			t.Errorf("expected a message queue to be set")
+		}
+	})
+
+	t.Run("resize debounce", func(t *testing.T) {
+		p := NewProgram(nil, WithResizeDebounce(time.Second))
+		if p.resizeDebounce != time.Second {
+			t.Errorf("expected a resize debounce of %v, got %v", time.Second, p.resizeDebounce)
+		}
+	})
+
+	t.Run("signals", func(t *testing.T) {
+		p := NewProgram(nil, WithSignals(os.Interrupt), WithSignals(os.Kill))
+		if !reflect.DeepEqual(p.signals, []os.Signal{os.Interrupt, os.Kill}) {
+			t.Errorf("expected signals to be set, got %v", p.signals)
+		}
+	})
+
+	t.Run("shutdown timeout", func(t *testing.T) {
+		p := NewProgram(nil, WithShutdownTimeout(time.Minute))
+		if p.shutdownTimeout != time.Minute {
+			t.Errorf("expected a shutdown timeout of %v, got %v", time.Minute, p.shutdownTimeout)
+		}
+	})
+
+	t.Run("mouse motion coalescing", func(t *testing.T) {
+		p := NewProgram(nil, WithMouseMotionCoalescing(time.Second))
+		if p.motionInterval != time.Second {
+			t.Errorf("expected a motion interval of %v, got %v", time.Second, p.motionInterval)
+		}
+	})
+
+	t.Run("gestures", func(t *testing.T) {
+		p := NewProgram(nil, WithGestures())
+		if !p.gestures {
+			t.Error("expected gestures to be enabled")
+		}
+	})
+
+	t.Run("mouse repeat", func(t *testing.T) {
+		p := NewProgram(nil, WithMouseRepeat(time.Second, time.Millisecond))
+		if p.repeater.delay != time.Second || p.repeater.interval != time.Millisecond {
+			t.Errorf("expected a repeat delay of %v and interval of %v, got %v and %v",
+				time.Second, time.Millisecond, p.repeater.delay, p.repeater.interval)
+		}
+	})
+
 	t.Run("input options", func(t *testing.T) {
 		exercise := func(t *testing.T, opt ProgramOption, expect inputType) {
 			p := NewProgram(nil, opt)
@@ -84,6 +214,10 @@ func TestOptions(t *testing.T) {
 			exercise(t, WithANSICompressor(), withANSICompressor)
 		})
 
+		t.Run("render on change", func(t *testing.T) {
+			exercise(t, WithRenderOnChange(), withRenderOnChange)
+		})
+
 		t.Run("without catch panics", func(t *testing.T) {
 			exercise(t, WithoutCatchPanics(), withoutCatchPanics)
 		})
@@ -92,6 +226,10 @@ func TestOptions(t *testing.T) {
 			exercise(t, WithoutSignalHandler(), withoutSignalHandler)
 		})
 
+		t.Run("without bracketed paste", func(t *testing.T) {
+			exercise(t, WithoutBracketedPaste(), withoutBracketedPaste)
+		})
+
 		t.Run("mouse cell motion", func(t *testing.T) {
 			p := NewProgram(nil, WithMouseAllMotion(), WithMouseCellMotion())
 			if !p.startupOptions.has(withMouseCellMotion) {
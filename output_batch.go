@@ -0,0 +1,121 @@
+package tea
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/muesli/termenv"
+)
+
+// batchWriter holds back what's written to the terminal and writes it with a
+// single call, so that a frame, along with the sequences written between
+// frames, goes out in as few packets as possible over a network. See
+// WithWriteBatching.
+type batchWriter struct {
+	mtx sync.Mutex
+	w   io.Writer
+	buf bytes.Buffer
+
+	// how long writes are held back for at most, and the timer that writes
+	// them once that's up
+	delay time.Duration
+	timer *time.Timer
+
+	// whether writes go straight through, as when the renderer is stopped
+	through bool
+}
+
+func newBatchWriter(w io.Writer, delay time.Duration) *batchWriter {
+	return &batchWriter{w: w, delay: delay}
+}
+
+// Write holds back b, to be written once the delay is up, or with the next
+// frame.
+func (b *batchWriter) Write(p []byte) (int, error) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if b.through {
+		if err := b.flushLocked(); err != nil {
+			return 0, err
+		}
+		return b.w.Write(p)
+	}
+	if b.buf.Len() == 0 {
+		b.timer = time.AfterFunc(b.delay, func() { _ = b.flush() })
+	}
+	return b.buf.Write(p)
+}
+
+// flush writes what's been held back.
+func (b *batchWriter) flush() error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	return b.flushLocked()
+}
+
+// flushLocked writes what's been held back. The mutex must be held.
+func (b *batchWriter) flushLocked() error {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if b.buf.Len() == 0 {
+		return nil
+	}
+	_, err := b.w.Write(b.buf.Bytes())
+	b.buf.Reset()
+	return err
+}
+
+// setThrough sets whether writes go straight through, writing what's been
+// held back first.
+func (b *batchWriter) setThrough(through bool) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.through = through
+	if through {
+		_ = b.flushLocked()
+	}
+}
+
+// batchedOutput returns the output the renderer writes to the terminal with,
+// which holds back writes if batching is enabled, along with the writer that
+// does so, if any.
+func (p *Program) batchedOutput() (*termenv.Output, *batchWriter) {
+	out := p.teedOutput()
+	if !p.writeBatching {
+		return out, nil
+	}
+	batch := newBatchWriter(out, p.batchInterval)
+	return termenv.NewOutput(batch, termenv.WithProfile(out.Profile)), batch
+}
+
+// overBudget reports whether frames are skipped to keep to the byte budget,
+// see WithFrameBudget. The output of past frames beyond the budget is paid
+// off at the rate of the budget per frame. The mutex must be held.
+func (r *standardRenderer) overBudget(now time.Time) bool {
+	if r.frameBudget <= 0 || r.budgetDebt <= 0 {
+		return false
+	}
+	paid := int64(r.frameBudget) * int64(now.Sub(r.budgetTime)) / int64(r.framerate)
+	if paid < r.budgetDebt {
+		return true
+	}
+	r.budgetDebt = 0
+	return false
+}
+
+// spend records the bytes written for a frame against the byte budget. The
+// mutex must be held.
+func (r *standardRenderer) spend(n int, now time.Time) {
+	if r.frameBudget <= 0 {
+		return
+	}
+	r.budgetDebt = int64(n - r.frameBudget)
+	r.budgetTime = now
+}
@@ -0,0 +1,117 @@
+package tea
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/muesli/termenv"
+)
+
+// countingWriter counts the writes made to it.
+type countingWriter struct {
+	mtx    sync.Mutex
+	buf    bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(b []byte) (int, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	w.writes++
+	return w.buf.Write(b)
+}
+
+func (w *countingWriter) String() string {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	return w.buf.String()
+}
+
+func TestBatchWriter(t *testing.T) {
+	var w countingWriter
+	b := newBatchWriter(&w, time.Hour)
+
+	_, _ = b.Write([]byte("a"))
+	_, _ = b.Write([]byte("b"))
+	if w.String() != "" {
+		t.Errorf("expected writes to be held back, got %q", w.String())
+	}
+	_ = b.flush()
+	if w.String() != "ab" || w.writes != 1 {
+		t.Errorf("expected a single write, got %d: %q", w.writes, w.String())
+	}
+
+	// Going through, what's held back is written first.
+	_, _ = b.Write([]byte("c"))
+	b.setThrough(true)
+	_, _ = b.Write([]byte("d"))
+	if w.String() != "abcd" {
+		t.Errorf("expected writes to go through, got %q", w.String())
+	}
+
+	// Writes are made once the delay is up, too.
+	b = newBatchWriter(&w, time.Millisecond)
+	_, _ = b.Write([]byte("e"))
+	time.Sleep(50 * time.Millisecond)
+	if w.String() != "abcde" {
+		t.Errorf("expected the write to be made after the delay, got %q", w.String())
+	}
+}
+
+func TestRendererFrameBudget(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRenderer(termenv.NewOutput(&buf), false).(*standardRenderer)
+	r.width, r.height = 80, 10
+	r.running = true
+	r.framerate = time.Second
+	r.frameBudget = 10
+
+	r.write("a frame well beyond the budget")
+	r.flush()
+	if r.lastRender != "a frame well beyond the budget" {
+		t.Fatalf("expected the first frame to be written, got %q", r.lastRender)
+	}
+
+	// Frames are skipped until the excess is paid off...
+	r.write("the next frame")
+	r.flush()
+	if r.lastRender != "a frame well beyond the budget" {
+		t.Errorf("expected the frame to be skipped, got %q", r.lastRender)
+	}
+
+	// ...after which the latest view is drawn.
+	r.budgetTime = r.budgetTime.Add(-10 * time.Second)
+	r.write("the latest frame")
+	r.flush()
+	if r.lastRender != "the latest frame" {
+		t.Errorf("expected the latest frame to be written, got %q", r.lastRender)
+	}
+}
+
+func TestWriteBatching(t *testing.T) {
+	var plain, batched countingWriter
+	for _, test := range []struct {
+		out  *countingWriter
+		opts []ProgramOption
+	}{
+		{&plain, nil},
+		{&batched, []ProgramOption{WithWriteBatching(0)}},
+	} {
+		var in bytes.Buffer
+		opts := append([]ProgramOption{WithInput(&in), WithOutput(test.out), WithAltScreen()}, test.opts...)
+		if _, err := NewProgram(secretModel{}, opts...).Run(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if batched.String() != plain.String() {
+		t.Errorf("expected the same output\nplain:   %q\nbatched: %q", plain.String(), batched.String())
+	}
+	if batched.writes >= plain.writes {
+		t.Errorf("expected fewer writes, got %d rather than %d", batched.writes, plain.writes)
+	}
+}
@@ -0,0 +1,33 @@
+package tea
+
+import (
+	"io"
+
+	"github.com/muesli/termenv"
+)
+
+// teeWriter writes to the terminal, and copies everything written to it to
+// another writer. Errors writing the copy are ignored, so that a recorder
+// failing doesn't take the program down with it.
+type teeWriter struct {
+	w   io.Writer
+	tee io.Writer
+}
+
+func (t teeWriter) Write(b []byte) (int, error) {
+	n, err := t.w.Write(b)
+	if n > 0 {
+		_, _ = t.tee.Write(b[:n])
+	}
+	return n, err
+}
+
+// teedOutput returns the output the program writes to the terminal with,
+// which copies everything written to it to the writer set with
+// WithOutputTee, if any.
+func (p *Program) teedOutput() *termenv.Output {
+	if p.outputTee == nil {
+		return p.output
+	}
+	return termenv.NewOutput(teeWriter{w: p.output, tee: p.outputTee}, termenv.WithProfile(p.output.Profile))
+}
@@ -0,0 +1,36 @@
+package tea
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestOutputTee(t *testing.T) {
+	t.Run("copies the output", func(t *testing.T) {
+		var buf, tee bytes.Buffer
+		var in bytes.Buffer
+
+		p := NewProgram(secretModel{}, WithInput(&in), WithOutput(&buf), WithOutputTee(&tee), WithAltScreen())
+		if _, err := p.Run(); err != nil {
+			t.Fatal(err)
+		}
+		if tee.Len() == 0 || tee.String() != buf.String() {
+			t.Errorf("expected the tee to get a copy of the output\noutput: %q\ntee:    %q", buf.String(), tee.String())
+		}
+	})
+
+	t.Run("ignores errors", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := teeWriter{w: &buf, tee: failingWriter{}}
+		if n, err := w.Write([]byte("hello")); n != 5 || err != nil {
+			t.Errorf("expected the write to succeed, got %d, %v", n, err)
+		}
+	})
+}
@@ -0,0 +1,153 @@
+package tea
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// paletteSize is the number of colors in the terminal's palette.
+const paletteSize = 256
+
+// PaletteColorMsg reports one of the colors of the terminal's palette, in
+// response to QueryPaletteColors. Color is a hex color such as "#f38ba8".
+type PaletteColorMsg struct {
+	Index int
+	Color string
+}
+
+// setPaletteColorMsg is an internal message used to change a color of the
+// terminal's palette. You can send a setPaletteColorMsg with SetPaletteColor.
+type setPaletteColorMsg struct {
+	index int
+	color string
+}
+
+// SetPaletteColor is a command that changes one of the colors of the
+// terminal's palette, such as color 1, which is what red text is shown in.
+// The color is given as a hex color such as "#f38ba8". Colors that can't be
+// parsed, and indices outside of the palette's 256 colors, are ignored.
+//
+// The palette is shared with everything else on the screen, so any text
+// shown in the color changes with it, including text the program didn't
+// print. The colors the program changes are reset when it exits, or when the
+// terminal is released, and changed again once it's restored.
+func SetPaletteColor(index int, color string) Cmd {
+	return func() Msg {
+		return setPaletteColorMsg{index: index, color: color}
+	}
+}
+
+// resetPaletteColorMsg is an internal message used to reset a color of the
+// terminal's palette. You can send a resetPaletteColorMsg with
+// ResetPaletteColor.
+type resetPaletteColorMsg int
+
+// ResetPaletteColor is a command that resets one of the colors of the
+// terminal's palette to its default, undoing SetPaletteColor.
+func ResetPaletteColor(index int) Cmd {
+	return func() Msg {
+		return resetPaletteColorMsg(index)
+	}
+}
+
+// queryPaletteColorsMsg is an internal message used to query colors of the
+// terminal's palette. You can send a queryPaletteColorsMsg with
+// QueryPaletteColors.
+type queryPaletteColorsMsg []int
+
+// QueryPaletteColors is a command that asks the terminal for colors of its
+// palette, each of which is then reported with a PaletteColorMsg. Terminals
+// which don't support the query don't answer it, in which case no messages
+// are sent.
+func QueryPaletteColors(indices ...int) Cmd {
+	return func() Msg {
+		return queryPaletteColorsMsg(indices)
+	}
+}
+
+// setPaletteColorSeq returns the OSC 4 sequence that changes a color of the
+// palette to a hex color. It returns an empty string if the color can't be
+// parsed.
+func setPaletteColorSeq(index int, color string) string {
+	if _, _, _, ok := parseHexColor(color); !ok {
+		return ""
+	}
+	return fmt.Sprintf("\x1b]4;%d;rgb:%s/%s/%s\x1b\\", index, color[1:3], color[3:5], color[5:7])
+}
+
+// resetPaletteColorsSeq returns the OSC 104 sequence that resets the given
+// colors of the palette.
+func resetPaletteColorsSeq(indices []int) string {
+	params := make([]string, len(indices))
+	for i, index := range indices {
+		params[i] = strconv.Itoa(index)
+	}
+	return "\x1b]104;" + strings.Join(params, ";") + "\x1b\\"
+}
+
+// queryPaletteColorsSeq returns the OSC 4 sequence that queries the given
+// colors of the palette.
+func queryPaletteColorsSeq(indices []int) string {
+	var b strings.Builder
+	b.WriteString("\x1b]4")
+	for _, index := range indices {
+		b.WriteString(";" + strconv.Itoa(index) + ";?")
+	}
+	b.WriteString("\x1b\\")
+	return b.String()
+}
+
+// inPalette reports whether index is that of a color of the palette.
+func inPalette(index int) bool {
+	return index >= 0 && index < paletteSize
+}
+
+// setPaletteColor changes a color of the palette, and remembers it so it can
+// be reset when the program exits.
+func (p *Program) setPaletteColor(index int, color string) {
+	seq := setPaletteColorSeq(index, color)
+	if !inPalette(index) || seq == "" {
+		return
+	}
+	if p.palette == nil {
+		p.palette = make(map[int]string)
+	}
+	p.palette[index] = color
+	_ = p.renderer.execute(seq)
+}
+
+// resetPaletteColor resets a color of the palette to its default.
+func (p *Program) resetPaletteColor(index int) {
+	if !inPalette(index) {
+		return
+	}
+	delete(p.palette, index)
+	_ = p.renderer.execute(resetPaletteColorsSeq([]int{index}))
+}
+
+// queryPaletteColors asks the terminal for colors of its palette.
+func (p *Program) queryPaletteColors(indices []int) {
+	valid := make([]int, 0, len(indices))
+	for _, index := range indices {
+		if inPalette(index) {
+			valid = append(valid, index)
+		}
+	}
+	if len(valid) == 0 || !p.canQuery() {
+		return
+	}
+	_ = p.renderer.execute(queryPaletteColorsSeq(valid))
+}
+
+// changedPaletteColors returns the indices of the palette colors the program
+// has changed, in order.
+func (p *Program) changedPaletteColors() []int {
+	indices := make([]int, 0, len(p.palette))
+	for index := range p.palette {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+	return indices
+}
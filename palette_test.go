@@ -0,0 +1,78 @@
+package tea
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPaletteSequences(t *testing.T) {
+	tests := []struct {
+		name     string
+		seq      string
+		expected string
+	}{
+		{"set", setPaletteColorSeq(1, "#f38ba8"), "\x1b]4;1;rgb:f3/8b/a8\x1b\\"},
+		{"set invalid", setPaletteColorSeq(1, "red"), ""},
+		{"reset", resetPaletteColorsSeq([]int{1, 9}), "\x1b]104;1;9\x1b\\"},
+		{"query", queryPaletteColorsSeq([]int{0, 15}), "\x1b]4;0;?;15;?\x1b\\"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.seq != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, test.seq)
+			}
+		})
+	}
+}
+
+type paletteModel struct{}
+
+func (m paletteModel) Init() Cmd {
+	return Sequence(
+		SetPaletteColor(1, "#f38ba8"),
+		SetPaletteColor(2, "#a6e3a1"),
+		SetPaletteColor(3, "yellow"),
+		SetPaletteColor(256, "#f9e2af"),
+		ResetPaletteColor(2),
+		Quit,
+	)
+}
+
+func (m paletteModel) Update(msg Msg) (Model, Cmd) {
+	return m, nil
+}
+
+func (m paletteModel) View() string {
+	return ""
+}
+
+func TestPaletteRestoredOnExit(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+
+	p := NewProgram(paletteModel{}, WithInput(&in), WithOutput(&buf))
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, seq := range []string{
+		"\x1b]4;1;rgb:f3/8b/a8\x1b\\",
+		"\x1b]4;2;rgb:a6/e3/a1\x1b\\",
+		"\x1b]104;2\x1b\\",
+	} {
+		if !strings.Contains(out, seq) {
+			t.Errorf("expected output to contain %q, got %q", seq, out)
+		}
+	}
+	if strings.Contains(out, "yellow") || strings.Contains(out, "\x1b]4;256;") {
+		t.Errorf("expected invalid colors to be ignored, got %q", out)
+	}
+
+	// Only the colors still changed are reset, once the program is done.
+	if strings.LastIndex(out, "\x1b]104;1\x1b\\") < strings.Index(out, "\x1b]104;2\x1b\\") {
+		t.Errorf("expected the changed color to be reset on exit, got %q", out)
+	}
+}
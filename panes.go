@@ -0,0 +1,274 @@
+package tea
+
+import (
+	"reflect"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Split determines how Panes divides its area among its panes.
+type Split int
+
+// Available splits.
+const (
+	// SplitHorizontal places panes side by side.
+	SplitHorizontal Split = iota
+	// SplitVertical stacks panes on top of each other.
+	SplitVertical
+)
+
+// PaneFocusMsg is sent to a model hosted by Panes when it gains or loses
+// focus.
+type PaneFocusMsg struct {
+	Focused bool
+}
+
+// paneMsg is a message destined for a single pane, typically the result of a
+// command returned by the model in that pane.
+type paneMsg struct {
+	pane int
+	msg  Msg
+}
+
+// focusPaneMsg is an internal message that moves the focus to another pane.
+// You can send a focusPaneMsg with FocusPane.
+type focusPaneMsg int
+
+// FocusPane produces a command that moves the focus to the pane with the
+// given index. When panes are nested, the outermost Panes handles it.
+func FocusPane(i int) Cmd {
+	return func() Msg {
+		return focusPaneMsg(i)
+	}
+}
+
+type pane struct {
+	model Model
+	rect  Rect
+}
+
+// Panes is a Model which hosts several models in tiled regions of the screen,
+// like a terminal multiplexer. Each model is sized to its region, and
+// receives WindowSizeMsgs accordingly. Keyboard input goes to the focused
+// pane, and mouse input goes to the pane under the mouse, with coordinates
+// relative to the pane. Clicking a pane focuses it. Any other messages are
+// sent to every pane, except for the results of a pane's commands, which are
+// sent to that pane only.
+//
+// Panes can be nested to build more complex layouts.
+type Panes struct {
+	split Split
+	panes []pane
+	focus int
+
+	width, height int
+}
+
+// NewPanes returns a Panes model which divides its area evenly among the
+// given models. The first model is focused initially.
+func NewPanes(split Split, models ...Model) *Panes {
+	p := &Panes{split: split, panes: make([]pane, len(models))}
+	for i, m := range models {
+		p.panes[i].model = m
+	}
+	return p
+}
+
+// Focused returns the index of the focused pane.
+func (p *Panes) Focused() int {
+	return p.focus
+}
+
+// Model returns the current model in the pane with the given index.
+func (p *Panes) Model(i int) Model {
+	return p.panes[i].model
+}
+
+// Rect returns the region of the pane with the given index, relative to the
+// region of the Panes model itself.
+func (p *Panes) Rect(i int) Rect {
+	return p.panes[i].rect
+}
+
+// Init initializes the models in all panes.
+func (p *Panes) Init() Cmd {
+	cmds := make([]Cmd, 0, len(p.panes)+1)
+	for i, pn := range p.panes {
+		cmds = append(cmds, wrapPaneCmd(i, pn.model.Init()))
+	}
+	if len(p.panes) > 0 {
+		cmds = append(cmds, func() Msg {
+			return paneMsg{pane: p.focus, msg: PaneFocusMsg{Focused: true}}
+		})
+	}
+	return Batch(cmds...)
+}
+
+// Update routes the message to the appropriate panes.
+func (p *Panes) Update(msg Msg) (Model, Cmd) {
+	switch msg := msg.(type) {
+	case paneMsg:
+		if msg.pane < 0 || msg.pane >= len(p.panes) {
+			return p, nil
+		}
+		return p, p.updatePane(msg.pane, msg.msg)
+
+	case focusPaneMsg:
+		return p, p.setFocus(int(msg))
+
+	case WindowSizeMsg:
+		prev := make([]Rect, len(p.panes))
+		for i, pn := range p.panes {
+			prev[i] = pn.rect
+		}
+		p.width, p.height = msg.Width, msg.Height
+		p.layout()
+		cmds := make([]Cmd, len(p.panes))
+		for i, pn := range p.panes {
+			cmds[i] = p.updatePane(i, WindowSizeMsg{
+				Width:      pn.rect.Width,
+				Height:     pn.rect.Height,
+				PrevWidth:  prev[i].Width,
+				PrevHeight: prev[i].Height,
+			})
+		}
+		return p, Batch(cmds...)
+
+	case KeyMsg:
+		if len(p.panes) == 0 {
+			return p, nil
+		}
+		return p, p.updatePane(p.focus, msg)
+
+	case MouseMsg:
+		for i, pn := range p.panes {
+			if !pn.rect.Contains(msg.X, msg.Y) {
+				continue
+			}
+			var cmd Cmd
+			if msg.Type == MouseLeft && i != p.focus {
+				cmd = p.setFocus(i)
+			}
+			msg.X -= pn.rect.X
+			msg.Y -= pn.rect.Y
+			return p, Batch(cmd, p.updatePane(i, msg))
+		}
+		return p, nil
+	}
+
+	cmds := make([]Cmd, len(p.panes))
+	for i := range p.panes {
+		cmds[i] = p.updatePane(i, msg)
+	}
+	return p, Batch(cmds...)
+}
+
+// View composes the views of all panes.
+func (p *Panes) View() string {
+	layers := make([]Layer, len(p.panes))
+	for i, pn := range p.panes {
+		layers[i] = Layer{Rect: pn.rect, Content: pn.model.View()}
+	}
+	return Compose(p.width, p.height, layers...)
+}
+
+// updatePane sends a message to a single pane.
+func (p *Panes) updatePane(i int, msg Msg) Cmd {
+	var cmd Cmd
+	p.panes[i].model, cmd = p.panes[i].model.Update(msg)
+	return wrapPaneCmd(i, cmd)
+}
+
+// setFocus moves the focus to another pane and informs both panes.
+func (p *Panes) setFocus(i int) Cmd {
+	if i < 0 || i >= len(p.panes) || i == p.focus {
+		return nil
+	}
+	old := p.focus
+	p.focus = i
+	return Batch(
+		p.updatePane(old, PaneFocusMsg{Focused: false}),
+		p.updatePane(i, PaneFocusMsg{Focused: true}),
+	)
+}
+
+// layout divides the available area among the panes. Space that can't be
+// divided evenly goes to the last pane.
+func (p *Panes) layout() {
+	n := len(p.panes)
+	if n == 0 {
+		return
+	}
+
+	size := p.width
+	if p.split == SplitVertical {
+		size = p.height
+	}
+
+	var offset int
+	for i := range p.panes {
+		length := size / n
+		if i == n-1 {
+			length = size - offset
+		}
+		if p.split == SplitVertical {
+			p.panes[i].rect = Rect{X: 0, Y: offset, Width: p.width, Height: length}
+		} else {
+			p.panes[i].rect = Rect{X: offset, Y: 0, Width: length, Height: p.height}
+		}
+		offset += length
+	}
+}
+
+// wrapPaneCmd wraps the result of a pane's command so that it's routed back
+// to that pane. Messages the program itself handles, such as QuitMsg, are
+// left alone, and batches and sequences are wrapped command by command.
+func wrapPaneCmd(i int, cmd Cmd) Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() Msg {
+		switch msg := cmd().(type) {
+		case nil:
+			return nil
+
+		case BatchMsg:
+			cmds := make(BatchMsg, len(msg))
+			for j, c := range msg {
+				cmds[j] = wrapPaneCmd(i, c)
+			}
+			return cmds
+
+		case sequenceMsg:
+			cmds := make(sequenceMsg, len(msg))
+			for j, c := range msg {
+				cmds[j] = wrapPaneCmd(i, c)
+			}
+			return cmds
+
+		case paneMsg:
+			return paneMsg{pane: i, msg: msg}
+
+		default:
+			if isProgramMsg(msg) {
+				return msg
+			}
+			return paneMsg{pane: i, msg: msg}
+		}
+	}
+}
+
+// isProgramMsg reports whether msg is handled by the program itself rather
+// than by models: QuitMsg, and the unexported messages produced by commands
+// such as EnterAltScreen.
+func isProgramMsg(msg Msg) bool {
+	if _, ok := msg.(QuitMsg); ok {
+		return true
+	}
+	t := reflect.TypeOf(msg)
+	if t.PkgPath() != reflect.TypeOf(QuitMsg{}).PkgPath() {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(t.Name())
+	return unicode.IsLower(r)
+}
@@ -0,0 +1,114 @@
+package tea
+
+import (
+	"reflect"
+	"testing"
+)
+
+type paneTestModel struct {
+	name string
+	msgs []Msg
+}
+
+func (m *paneTestModel) Init() Cmd {
+	return nil
+}
+
+func (m *paneTestModel) Update(msg Msg) (Model, Cmd) {
+	m.msgs = append(m.msgs, msg)
+	if _, ok := msg.(KeyMsg); ok {
+		return m, func() Msg { return m.name }
+	}
+	return m, nil
+}
+
+func (m *paneTestModel) View() string {
+	return m.name
+}
+
+func TestPanesLayout(t *testing.T) {
+	a, b := &paneTestModel{name: "a"}, &paneTestModel{name: "b"}
+	p := NewPanes(SplitHorizontal, a, b)
+	p.Update(WindowSizeMsg{Width: 5, Height: 2})
+
+	if r := p.Rect(1); r != (Rect{X: 2, Y: 0, Width: 3, Height: 2}) {
+		t.Errorf("unexpected rect %+v", r)
+	}
+	if !reflect.DeepEqual(b.msgs, []Msg{WindowSizeMsg{Width: 3, Height: 2}}) {
+		t.Errorf("unexpected messages %#v", b.msgs)
+	}
+	if v := p.View(); v != "a b  \n     " {
+		t.Errorf("unexpected view %q", v)
+	}
+
+	// Panes are told the size they had before.
+	b.msgs = nil
+	p.Update(WindowSizeMsg{Width: 9, Height: 4})
+	if !reflect.DeepEqual(b.msgs, []Msg{WindowSizeMsg{Width: 5, Height: 4, PrevWidth: 3, PrevHeight: 2}}) {
+		t.Errorf("unexpected messages %#v", b.msgs)
+	}
+
+	p = NewPanes(SplitVertical, a, b)
+	p.Update(WindowSizeMsg{Width: 5, Height: 3})
+	if r := p.Rect(1); r != (Rect{X: 0, Y: 1, Width: 5, Height: 2}) {
+		t.Errorf("unexpected rect %+v", r)
+	}
+}
+
+func TestPanesRouting(t *testing.T) {
+	a, b := &paneTestModel{name: "a"}, &paneTestModel{name: "b"}
+	p := NewPanes(SplitHorizontal, a, b)
+	p.Update(WindowSizeMsg{Width: 10, Height: 2})
+	a.msgs, b.msgs = nil, nil
+
+	// Keys go to the focused pane, and command results are routed back.
+	_, cmd := p.Update(KeyMsg{Type: KeyEnter})
+	if len(a.msgs) != 1 || len(b.msgs) != 0 {
+		t.Fatalf("expected the key to go to the first pane only")
+	}
+	if msg := cmd(); msg != (paneMsg{pane: 0, msg: "a"}) {
+		t.Errorf("expected the command result to be wrapped, got %#v", msg)
+	}
+
+	// Clicking the second pane focuses it and translates coordinates.
+	a.msgs = nil
+	p.Update(MouseMsg{X: 6, Y: 1, Type: MouseLeft})
+	if p.Focused() != 1 {
+		t.Errorf("expected the second pane to be focused")
+	}
+	if !reflect.DeepEqual(a.msgs, []Msg{PaneFocusMsg{Focused: false}}) {
+		t.Errorf("unexpected messages %#v", a.msgs)
+	}
+	expected := []Msg{PaneFocusMsg{Focused: true}, MouseMsg{X: 1, Y: 1, Type: MouseLeft}}
+	if !reflect.DeepEqual(b.msgs, expected) {
+		t.Errorf("unexpected messages %#v", b.msgs)
+	}
+
+	// Wrapped messages go to their pane only, others go to everyone.
+	a.msgs, b.msgs = nil, nil
+	p.Update(paneMsg{pane: 0, msg: "x"})
+	p.Update("y")
+	if !reflect.DeepEqual(a.msgs, []Msg{"x", "y"}) || !reflect.DeepEqual(b.msgs, []Msg{"y"}) {
+		t.Errorf("unexpected messages %#v, %#v", a.msgs, b.msgs)
+	}
+}
+
+func TestWrapPaneCmd(t *testing.T) {
+	if msg := wrapPaneCmd(1, Quit)(); msg != (QuitMsg{}) {
+		t.Errorf("expected QuitMsg to be left alone, got %#v", msg)
+	}
+	if msg := wrapPaneCmd(1, func() Msg { return ClearScreen() })(); msg != (clearScreenMsg{}) {
+		t.Errorf("expected internal messages to be left alone, got %#v", msg)
+	}
+	if msg := wrapPaneCmd(1, func() Msg { return paneMsg{pane: 2, msg: "x"} })(); msg != (paneMsg{pane: 1, msg: paneMsg{pane: 2, msg: "x"}}) {
+		t.Errorf("expected nested pane messages to be wrapped, got %#v", msg)
+	}
+
+	batch, ok := wrapPaneCmd(1, Batch(func() Msg { return "x" }))().(BatchMsg)
+	if !ok || len(batch) != 1 {
+		t.Fatalf("expected a batch")
+	}
+	if msg := batch[0](); msg != (paneMsg{pane: 1, msg: "x"}) {
+		t.Errorf("expected batched commands to be wrapped, got %#v", msg)
+	}
+}
@@ -0,0 +1,23 @@
+package tea
+
+// PasteMsg is sent when text is pasted into the terminal while bracketed
+// paste is enabled, which it is by default. The text is delivered whole,
+// rather than as keys, so that pasted characters don't trigger key bindings
+// and models can handle the paste in one go.
+type PasteMsg struct {
+	Content string
+}
+
+// PasteStartMsg is sent when text starts being pasted into the terminal, if
+// pastes are streamed with WithPasteStreaming. It's followed by the
+// PasteChunkMsgs of the paste and a PasteEndMsg.
+type PasteStartMsg struct{}
+
+// PasteChunkMsg is a piece of text pasted into the terminal, if pastes are
+// streamed.
+type PasteChunkMsg struct {
+	Content string
+}
+
+// PasteEndMsg is sent when a paste ends, if pastes are streamed.
+type PasteEndMsg struct{}
@@ -0,0 +1,122 @@
+package tea
+
+import (
+	"sync"
+	"time"
+)
+
+// frame is a snapshot of everything needed to render a view, taken on the
+// event loop's goroutine.
+type frame struct {
+	seq     uint64
+	model   Model
+	console *logConsole
+
+	// read times of the input the frame reflects, see WithInputLatency
+	inputs []time.Time
+}
+
+// renderPipeline renders views on a goroutine of its own, so that the event
+// loop can keep processing messages while View runs. It's enabled with
+// WithPipelinedRendering.
+//
+// Frames are rendered in the order they were submitted. If the event loop
+// submits frames faster than they can be rendered, the ones that haven't been
+// started yet are replaced by newer ones, as the renderer would only drop
+// them anyway.
+type renderPipeline struct {
+	frames chan frame
+	done   chan struct{}
+	once   sync.Once
+
+	// sequence number of the last frame submitted; only accessed from the
+	// event loop's goroutine
+	seq uint64
+
+	// sequence number of the last frame rendered; only accessed from the
+	// pipeline's goroutine
+	rendered uint64
+}
+
+func newRenderPipeline() *renderPipeline {
+	return &renderPipeline{
+		frames: make(chan frame, 1),
+		done:   make(chan struct{}),
+	}
+}
+
+// start starts rendering frames submitted to the pipeline.
+func (rp *renderPipeline) start(p *Program) {
+	go func() {
+		defer close(rp.done)
+
+		for f := range rp.frames {
+			if f.seq <= rp.rendered {
+				continue
+			}
+			rp.rendered = f.seq
+			p.writeView(renderView(f.model, &p.layers, &p.cells, f.console, p.renderer.altScreen()), cursorOf(f.model))
+			if p.latency != nil {
+				p.latency.written(f.inputs)
+			}
+		}
+	}()
+}
+
+// submit submits a frame, replacing the pending one, if any. It must only be
+// called from the event loop's goroutine.
+func (rp *renderPipeline) submit(f frame) {
+	rp.seq++
+	f.seq = rp.seq
+
+	select {
+	case pending := <-rp.frames:
+		// The replacement reflects the input of the pending frame, too.
+		f.inputs = append(pending.inputs, f.inputs...)
+	default:
+	}
+	rp.frames <- f
+}
+
+// stop renders the pending frame, if any, and stops the pipeline. It's safe
+// to call more than once.
+func (rp *renderPipeline) stop() {
+	rp.once.Do(func() {
+		close(rp.frames)
+		<-rp.done
+	})
+}
+
+// draw renders the model's view, on the render pipeline if it's enabled.
+func (p *Program) draw(model Model) {
+	inputs := p.drawnInputs
+	p.drawnInputs = nil
+
+	if p.pipeline == nil {
+		p.writeView(p.view(model), cursorOf(model))
+		if p.latency != nil {
+			p.latency.written(inputs)
+		}
+		return
+	}
+
+	f := frame{model: model, inputs: inputs}
+	if p.logConsole != nil {
+		f.console = p.logConsole.snapshot()
+	}
+	p.pipeline.submit(f)
+}
+
+// writeView writes a view to the renderer, along with where the model wants
+// the cursor, if the renderer places it. The view is run through the frame
+// filters first.
+func (p *Program) writeView(view string, cursor cursorPlacement) {
+	for _, filter := range p.frameFilters {
+		view = filter(view)
+	}
+	if r, ok := p.renderer.(*standardRenderer); ok {
+		r.writeWithCursor(view, cursor)
+		return
+	}
+	p.renderer.write(view)
+}
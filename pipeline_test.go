@@ -0,0 +1,139 @@
+package tea
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type viewModel string
+
+func (m viewModel) Init() Cmd {
+	return nil
+}
+
+func (m viewModel) Update(msg Msg) (Model, Cmd) {
+	return m, nil
+}
+
+func (m viewModel) View() string {
+	return string(m)
+}
+
+func TestRenderPipelineReplacesPendingFrames(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewProgram(nil, WithOutput(&buf))
+	r := newRenderer(p.output, false).(*standardRenderer)
+	p.renderer = r
+
+	rp := newRenderPipeline()
+	for _, v := range []string{"first", "second", "third"} {
+		rp.submit(frame{model: viewModel(v)})
+	}
+	rp.start(p)
+	rp.stop()
+
+	if got := r.buf.String(); got != "third" {
+		t.Errorf("expected only the last frame to be rendered, got %q", got)
+	}
+	if r.stats.dropped != 0 {
+		t.Errorf("expected replaced frames not to reach the renderer, got %d dropped", r.stats.dropped)
+	}
+}
+
+type slowViewModel struct {
+	n int
+}
+
+func (m slowViewModel) Init() Cmd {
+	return nil
+}
+
+func (m slowViewModel) Update(msg Msg) (Model, Cmd) {
+	if _, ok := msg.(incrementMsg); ok {
+		m.n++
+		if m.n == 20 {
+			return m, Quit
+		}
+	}
+	return m, nil
+}
+
+func (m slowViewModel) View() string {
+	time.Sleep(10 * time.Millisecond)
+	return "count " + strconv.Itoa(m.n)
+}
+
+func TestPipelinedRendering(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+
+	p := NewProgram(slowViewModel{}, WithInput(&in), WithOutput(&buf), WithPipelinedRendering())
+	go func() {
+		for i := 0; i < 20; i++ {
+			p.Send(incrementMsg{})
+		}
+	}()
+
+	start := time.Now()
+	model, err := p.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := model.(slowViewModel).n; n != 20 {
+		t.Errorf("expected 20 updates, got %d", n)
+	}
+	if !strings.Contains(buf.String(), "count 20") {
+		t.Errorf("expected the final view to be rendered, got %q", buf.String())
+	}
+	// Rendering every view one after the other would take over 200ms.
+	if d := time.Since(start); d >= 200*time.Millisecond {
+		t.Errorf("expected views to be skipped, took %s", d)
+	}
+}
+
+type secretModel struct{}
+
+func (m secretModel) Init() Cmd {
+	return Quit
+}
+
+func (m secretModel) Update(msg Msg) (Model, Cmd) {
+	return m, nil
+}
+
+func (m secretModel) View() string {
+	return "token: hunter2"
+}
+
+func TestFrameFilters(t *testing.T) {
+	scrub := func(s string) string { return strings.ReplaceAll(s, "hunter2", "*******") }
+	upper := func(s string) string { return strings.ToUpper(s) }
+
+	for _, pipelined := range []bool{false, true} {
+		t.Run("pipelined "+strconv.FormatBool(pipelined), func(t *testing.T) {
+			var buf bytes.Buffer
+			var in bytes.Buffer
+
+			opts := []ProgramOption{WithInput(&in), WithOutput(&buf), WithFrameFilter(scrub), WithFrameFilter(upper)}
+			if pipelined {
+				opts = append(opts, WithPipelinedRendering())
+			}
+			p := NewProgram(secretModel{}, opts...)
+			if _, err := p.Run(); err != nil {
+				t.Fatal(err)
+			}
+
+			out := buf.String()
+			if strings.Contains(strings.ToLower(out), "hunter2") {
+				t.Errorf("expected the secret to be scrubbed, got %q", out)
+			}
+			if !strings.Contains(out, "TOKEN: *******") {
+				t.Errorf("expected the filters to be applied in order, got %q", out)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package tea
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// PowerMsg reports the power state of the system. It's sent once when power
+// events are enabled with WithPowerEvents and again whenever the state
+// changes.
+type PowerMsg struct {
+	// BatteryLevel is the remaining battery charge as a percentage between 0
+	// and 100. If the system has no battery, or the level can't be
+	// determined, BatteryLevel is -1.
+	BatteryLevel int
+
+	// OnAC reports whether the system is running on external power.
+	OnAC bool
+
+	// Charging reports whether the battery is currently charging.
+	Charging bool
+}
+
+// HasBattery reports whether a battery level is available.
+func (p PowerMsg) HasBattery() bool {
+	return p.BatteryLevel >= 0
+}
+
+// LowBattery reports whether the system is running on battery power with a
+// charge at or below the given percentage.
+func (p PowerMsg) LowBattery(threshold int) bool {
+	return p.HasBattery() && !p.OnAC && p.BatteryLevel <= threshold
+}
+
+// SystemResumeMsg is sent when the program detects that the system has
+// resumed after being suspended. Slept is the approximate amount of time the
+// system was asleep. Long-running programs can use this to refresh stale data
+// right away rather than waiting for their next poll.
+type SystemResumeMsg struct {
+	Slept time.Duration
+}
+
+// InputIdleMsg is sent once when the program has received no input for the
+// duration set with WithInputIdleTimeout. It won't be sent again until further
+// input has been received and the program becomes idle once more.
+//
+// Only the program's own input counts: the user may well be busy in another
+// window or program meanwhile.
+type InputIdleMsg struct {
+	// Since is the time at which the last input was received.
+	Since time.Time
+}
+
+// resumeThreshold is the minimum difference between wall clock time and
+// monotonic time for which we'll consider the system to have been suspended.
+// Wall clock adjustments (NTP and friends) are usually much smaller than this.
+const resumeThreshold = 5 * time.Second
+
+// detectResume compares the wall clock and monotonic time elapsed between two
+// readings of time.Now. The monotonic clock doesn't advance while the system
+// is suspended, but the wall clock does, so a large discrepancy indicates
+// that the system has been asleep.
+func detectResume(wall, mono time.Duration) (time.Duration, bool) {
+	if slept := wall - mono; slept >= resumeThreshold {
+		return slept, true
+	}
+	return 0, false
+}
+
+// handlePower polls the power state of the system at the configured interval
+// and informs the program of changes, as well as of system resumes.
+func (p *Program) handlePower() chan struct{} {
+	ch := make(chan struct{})
+
+	go func() {
+		defer close(ch)
+
+		ticker := time.NewTicker(p.powerInterval)
+		defer ticker.Stop()
+
+		last, err := readPowerState()
+		if err == nil {
+			p.Send(last)
+		}
+
+		prev := time.Now()
+		for {
+			select {
+			case <-p.ctx.Done():
+				return
+
+			case <-ticker.C:
+				now := time.Now()
+				// Round(0) strips the monotonic clock reading.
+				wall := now.Round(0).Sub(prev.Round(0))
+				if slept, ok := detectResume(wall, now.Sub(prev)); ok {
+					p.Send(SystemResumeMsg{Slept: slept})
+				}
+				prev = now
+
+				state, err := readPowerState()
+				if err != nil || state == last {
+					continue
+				}
+				last = state
+				p.Send(state)
+			}
+		}
+	}()
+
+	return ch
+}
+
+// minIdleCheckInterval is the shortest interval at which we check whether
+// the program has become idle.
+const minIdleCheckInterval = time.Millisecond
+
+// idleCheckInterval returns the interval at which to check whether the
+// program has become idle: often enough that InputIdleMsg arrives reasonably
+// close to the timeout, without waking up too frequently.
+func idleCheckInterval(timeout time.Duration) time.Duration {
+	if d := timeout / 10; d > minIdleCheckInterval { //nolint:gomnd
+		return d
+	}
+	return minIdleCheckInterval
+}
+
+// handleIdle informs the program when it has received no input for the
+// configured idle timeout.
+func (p *Program) handleIdle() chan struct{} {
+	ch := make(chan struct{})
+	atomic.StoreInt64(&p.lastInput, time.Now().UnixNano())
+
+	go func() {
+		defer close(ch)
+
+		ticker := time.NewTicker(idleCheckInterval(p.idleTimeout))
+		defer ticker.Stop()
+
+		var reported int64
+		for {
+			select {
+			case <-p.ctx.Done():
+				return
+
+			case <-ticker.C:
+				last := atomic.LoadInt64(&p.lastInput)
+				if last == reported {
+					continue
+				}
+				since := time.Unix(0, last)
+				if time.Since(since) >= p.idleTimeout {
+					reported = last
+					p.Send(InputIdleMsg{Since: since})
+				}
+			}
+		}
+	}()
+
+	return ch
+}
@@ -0,0 +1,70 @@
+//go:build linux
+// +build linux
+
+package tea
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const powerSupplyPath = "/sys/class/power_supply"
+
+// readPowerState reads the current power state from sysfs.
+func readPowerState() (PowerMsg, error) {
+	return readPowerSupply(powerSupplyPath)
+}
+
+// readPowerSupply reads the power state from the given sysfs power_supply
+// directory. If several batteries are present, their average level is
+// reported.
+func readPowerSupply(dir string) (PowerMsg, error) {
+	state := PowerMsg{BatteryLevel: -1}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return state, err
+	}
+
+	var total, batteries int
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+
+		switch readSysfsValue(path, "type") {
+		case "Mains", "USB":
+			if readSysfsValue(path, "online") == "1" {
+				state.OnAC = true
+			}
+
+		case "Battery":
+			if readSysfsValue(path, "present") == "0" {
+				continue
+			}
+			level, err := strconv.Atoi(readSysfsValue(path, "capacity"))
+			if err != nil {
+				continue
+			}
+			total += level
+			batteries++
+
+			if readSysfsValue(path, "status") == "Charging" {
+				state.Charging = true
+			}
+		}
+	}
+
+	if batteries > 0 {
+		state.BatteryLevel = total / batteries
+	}
+	return state, nil
+}
+
+func readSysfsValue(dir, name string) string {
+	b, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
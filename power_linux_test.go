@@ -0,0 +1,56 @@
+//go:build linux
+// +build linux
+
+package tea
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadPowerSupply(t *testing.T) {
+	write := func(t *testing.T, dir string, files map[string]string) {
+		t.Helper()
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content+"\n"), 0o644); err != nil { //nolint:gosec
+				t.Fatal(err)
+			}
+		}
+	}
+
+	t.Run("no supplies", func(t *testing.T) {
+		state, err := readPowerSupply(t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if state.HasBattery() || state.OnAC {
+			t.Errorf("expected no battery and no AC, got %+v", state)
+		}
+	})
+
+	t.Run("battery and mains", func(t *testing.T) {
+		root := t.TempDir()
+		write(t, filepath.Join(root, "AC"), map[string]string{"type": "Mains", "online": "1"})
+		write(t, filepath.Join(root, "BAT0"), map[string]string{"type": "Battery", "capacity": "40", "status": "Charging"})
+		write(t, filepath.Join(root, "BAT1"), map[string]string{"type": "Battery", "capacity": "60", "status": "Full"})
+
+		state, err := readPowerSupply(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := PowerMsg{BatteryLevel: 50, OnAC: true, Charging: true}
+		if state != expected {
+			t.Errorf("expected %+v, got %+v", expected, state)
+		}
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		if _, err := readPowerSupply(filepath.Join(t.TempDir(), "nope")); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+// +build !linux
+
+package tea
+
+import "errors"
+
+// readPowerState is not yet implemented on this platform. Resume and idle
+// detection still work, but no PowerMsg will be sent.
+func readPowerState() (PowerMsg, error) {
+	return PowerMsg{BatteryLevel: -1}, errors.New("power state is not supported on this platform")
+}
@@ -0,0 +1,99 @@
+package tea
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestDetectResume(t *testing.T) {
+	tests := []struct {
+		name  string
+		wall  time.Duration
+		mono  time.Duration
+		slept time.Duration
+		ok    bool
+	}{
+		{"awake", time.Second, time.Second, 0, false},
+		{"clock adjustment", 2 * time.Second, time.Second, 0, false},
+		{"clock set back", 0, time.Hour, 0, false},
+		{"slept", time.Hour + time.Second, time.Second, time.Hour, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			slept, ok := detectResume(test.wall, test.mono)
+			if ok != test.ok {
+				t.Fatalf("expected resume detection to be %t, got %t", test.ok, ok)
+			}
+			if slept != test.slept {
+				t.Errorf("expected to have slept for %v, got %v", test.slept, slept)
+			}
+		})
+	}
+}
+
+func TestPowerMsg(t *testing.T) {
+	tests := []struct {
+		name    string
+		msg     PowerMsg
+		battery bool
+		low     bool
+	}{
+		{"no battery", PowerMsg{BatteryLevel: -1}, false, false},
+		{"full", PowerMsg{BatteryLevel: 100}, true, false},
+		{"low", PowerMsg{BatteryLevel: 5}, true, true},
+		{"low on ac", PowerMsg{BatteryLevel: 5, OnAC: true, Charging: true}, true, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.msg.HasBattery(); got != test.battery {
+				t.Errorf("expected HasBattery to be %t, got %t", test.battery, got)
+			}
+			if got := test.msg.LowBattery(10); got != test.low {
+				t.Errorf("expected LowBattery to be %t, got %t", test.low, got)
+			}
+		})
+	}
+}
+
+func TestIdleCheckInterval(t *testing.T) {
+	tests := []struct {
+		timeout  time.Duration
+		expected time.Duration
+	}{
+		{time.Minute, 6 * time.Second},
+		{10 * time.Millisecond, time.Millisecond},
+		{5 * time.Nanosecond, time.Millisecond},
+	}
+	for _, test := range tests {
+		if got := idleCheckInterval(test.timeout); got != test.expected {
+			t.Errorf("%v: expected an interval of %v, got %v", test.timeout, test.expected, got)
+		}
+	}
+}
+
+type idleModel struct{}
+
+func (m idleModel) Init() Cmd { return nil }
+
+func (m idleModel) Update(msg Msg) (Model, Cmd) {
+	if _, ok := msg.(InputIdleMsg); ok {
+		return m, Quit
+	}
+	return m, nil
+}
+
+func (m idleModel) View() string { return "" }
+
+func TestInputIdleTimeout(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+
+	// Timeouts too short to check at a tenth of them still work.
+	p := NewProgram(idleModel{}, WithInput(&in), WithOutput(&buf), WithInputIdleTimeout(time.Nanosecond))
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,72 @@
+package tea
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// Responses to terminal queries. These are internal messages produced by the
+// input reader when the terminal answers a query we've sent.
+
+// primaryDeviceAttributesMsg is the terminal's response to a primary device
+// attributes (DA1) query. As every terminal answers DA1, we send it after any
+// other queries: once the response arrives, all other responses have been
+// received, too.
+type primaryDeviceAttributesMsg []int
+
+// deviceAttrSixel is the device attribute of terminals which support sixel
+// graphics.
+const deviceAttrSixel = 4
+
+// deviceAttrRectangularEditing is the device attribute of terminals which
+// implement the VT420 rectangular area operations, such as DECCRA (copy) and
+// DECFRA (fill).
+const deviceAttrRectangularEditing = 28
+
+// termcapMsg is the terminal's response to an XTGETTCAP query for a single
+// terminfo capability.
+type termcapMsg struct {
+	name  string
+	value string
+	ok    bool
+}
+
+// statusStringMsg is the terminal's response to a DECRQSS query.
+type statusStringMsg struct {
+	value string
+	ok    bool
+}
+
+// modeReportMsg is the terminal's response to a DECRQM query for a private
+// mode.
+type modeReportMsg struct {
+	mode  int
+	value int
+}
+
+// supported reports whether the terminal knows the mode.
+func (m modeReportMsg) supported() bool {
+	return m.value != 0
+}
+
+// Query sequences.
+const (
+	queryPrimaryDeviceAttributes = "\x1b[c"
+	querySGR                     = "\x1bP$qm\x1b\\"
+)
+
+// queryMode returns a DECRQM query for the given private mode.
+func queryMode(mode int) string {
+	return "\x1b[?" + strconv.Itoa(mode) + "$p"
+}
+
+// queryTermcap returns an XTGETTCAP query for the given terminfo
+// capabilities.
+func queryTermcap(names ...string) string {
+	encoded := make([]string, len(names))
+	for i, name := range names {
+		encoded[i] = strings.ToUpper(hex.EncodeToString([]byte(name)))
+	}
+	return "\x1bP+q" + strings.Join(encoded, ";") + "\x1b\\"
+}
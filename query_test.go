@@ -0,0 +1,72 @@
+package tea
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestQueryTermcap(t *testing.T) {
+	if got := queryTermcap("RGB", "Tc"); got != "\x1bP+q524742;5463\x1b\\" {
+		t.Errorf("unexpected query %q", got)
+	}
+}
+
+func TestReadInputResponses(t *testing.T) {
+	t.Run("between keys", func(t *testing.T) {
+		msgs, err := readInputs(bytes.NewReader([]byte("a\x1bP1$r0m\x1b\\\x1b[?62cb")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []Msg{
+			KeyMsg{Type: KeyRunes, Runes: []rune{'a'}},
+			statusStringMsg{value: "0m", ok: true},
+			primaryDeviceAttributesMsg{62},
+			KeyMsg{Type: KeyRunes, Runes: []rune{'b'}},
+		}
+		if !reflect.DeepEqual(msgs, expected) {
+			t.Errorf("expected messages %#v, got %#v", expected, msgs)
+		}
+	})
+
+	t.Run("across reads", func(t *testing.T) {
+		r := &chunkedReader{chunks: [][]byte{
+			[]byte("\x1bP1+r5"),
+			[]byte("463\x1b"),
+			[]byte("\\"),
+		}}
+		d := newInputDecoder(r, inputConfig{})
+		d.ExpectDCS(true)
+		msgs, err := readMsgs(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []Msg{termcapMsg{name: "Tc", ok: true}}
+		if !reflect.DeepEqual(msgs, expected) {
+			t.Errorf("expected messages %#v, got %#v", expected, msgs)
+		}
+	})
+
+	t.Run("alt+P and a digit", func(t *testing.T) {
+		// Unless responses are expected, they're keys, which aren't held
+		// back waiting for the rest of a response.
+		r := &chunkedReader{chunks: [][]byte{[]byte("\x1bP1")}}
+		msgs, err := readInputs(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(msgs) == 0 || msgs[0].(KeyMsg).String() != "alt+P" {
+			t.Errorf("expected alt+P, got %#v", msgs)
+		}
+	})
+
+	t.Run("alt+P", func(t *testing.T) {
+		msgs, err := readInputs(bytes.NewReader([]byte("\x1bP")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(msgs) != 1 || msgs[0].(KeyMsg).String() != "alt+P" {
+			t.Errorf("expected alt+P, got %#v", msgs)
+		}
+	})
+}
@@ -0,0 +1,328 @@
+package tea
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// QueuePolicy determines what happens when a message is sent to a program
+// whose message queue is full. See QueueLimits.
+type QueuePolicy int
+
+// Available queue policies.
+const (
+	// QueueBlock makes senders wait until there's room in the queue. This is
+	// how programs without queue limits behave.
+	QueueBlock QueuePolicy = iota
+
+	// QueueDropOldest drops the oldest message in the queue to make room for
+	// the new one. Use it when only the latest state matters, such as for
+	// progress updates.
+	QueueDropOldest
+
+	// QueueDropNewest drops the message being sent, keeping the queue as it
+	// is.
+	QueueDropNewest
+
+	// QueueCoalesce replaces the latest queued message of the same type as
+	// the one being sent, which takes its place in the queue. Use it when
+	// only the latest message of each type matters, such as for ticks and
+	// the lines of a log tail. If no message of that type is queued, the
+	// oldest message is dropped, as with QueueDropOldest.
+	QueueCoalesce
+)
+
+// QueueLimits limits the growth of a program's message queue, which can grow
+// without bounds when messages are sent faster than Update processes them.
+// Use them with WithQueueLimits.
+//
+// The limits apply to messages sent with Program.Send and to the results of
+// commands. When the queue is full, the policy decides which message is shed.
+// Some messages are never shed, so that the program keeps working: keyboard
+// and mouse input, window size changes, QuitMsg and other messages handled by
+// the program itself, and batches of commands. These messages are always
+// queued, even if that exceeds the limits.
+type QueueLimits struct {
+	// MaxMessages is the maximum number of queued messages. Zero means no
+	// limit.
+	MaxMessages int
+
+	// MaxBytes is the maximum estimated size of the queued messages. The
+	// size of a message is estimated from the size of its value and the
+	// contents of any strings and slices it holds directly. Zero means no
+	// limit.
+	MaxBytes int
+
+	// Policy decides what to do when the queue is full.
+	Policy QueuePolicy
+}
+
+// QueuePressureMsg is sent when a program's message queue fills up to 80% of
+// one of its limits, as an advisory to slow down producers or to coalesce
+// messages. It's sent ahead of any queued messages, and isn't sent again
+// until the queue has drained to below half of its limits.
+type QueuePressureMsg struct {
+	// Messages and Bytes are the current length and estimated size of the
+	// queue.
+	Messages int
+	Bytes    int
+
+	// Dropped is the total number of messages shed so far.
+	Dropped int
+}
+
+// Fractions of the limits at which pressure is reported and released.
+const (
+	queuePressureHigh = 0.8
+	queuePressureLow  = 0.5
+)
+
+type queuedMsg struct {
+	msg  Msg
+	size int
+}
+
+// msgQueue is a bounded queue of messages in front of the event loop.
+type msgQueue struct {
+	limits QueueLimits
+
+	mtx       sync.Mutex
+	msgs      []queuedMsg
+	bytes     int
+	dropped   int
+	pressured bool
+
+	// reported ahead of the queued messages, without counting against the
+	// limits
+	pressure *QueuePressureMsg
+
+	// signalled when messages are added or removed, respectively
+	notEmpty chan struct{}
+	notFull  chan struct{}
+}
+
+func newMsgQueue(limits QueueLimits) *msgQueue {
+	return &msgQueue{
+		limits:   limits,
+		notEmpty: make(chan struct{}, 1),
+		notFull:  make(chan struct{}, 1),
+	}
+}
+
+// push adds a message to the queue, shedding messages or waiting for room
+// as the policy dictates.
+func (q *msgQueue) push(ctx context.Context, msg Msg) {
+	if msg == nil {
+		return
+	}
+	size := estimateMsgSize(msg)
+	exempt := isExemptFromShedding(msg)
+
+	q.mtx.Lock()
+	for !exempt && q.full(size) {
+		switch q.limits.Policy {
+		case QueueDropNewest:
+			q.dropped++
+			q.mtx.Unlock()
+			return
+
+		case QueueCoalesce:
+			if q.coalesce(msg, size) {
+				q.mtx.Unlock()
+				return
+			}
+			if !q.dropOldest() {
+				exempt = true
+			}
+
+		case QueueDropOldest:
+			if !q.dropOldest() {
+				// Nothing can be shed, so go over the limits.
+				exempt = true
+			}
+
+		default:
+			q.mtx.Unlock()
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.notFull:
+			}
+			q.mtx.Lock()
+		}
+	}
+
+	q.msgs = append(q.msgs, queuedMsg{msg: msg, size: size})
+	q.bytes += size
+
+	if !q.pressured && q.above(queuePressureHigh) {
+		q.pressured = true
+		q.pressure = &QueuePressureMsg{Messages: len(q.msgs), Bytes: q.bytes, Dropped: q.dropped}
+	}
+	q.mtx.Unlock()
+
+	notify(q.notEmpty)
+}
+
+// pop removes the oldest message from the queue, waiting for one if the queue
+// is empty. It returns false if the context is done.
+func (q *msgQueue) pop(ctx context.Context) (Msg, bool) {
+	q.mtx.Lock()
+	for len(q.msgs) == 0 && q.pressure == nil {
+		q.mtx.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-q.notEmpty:
+		}
+		q.mtx.Lock()
+	}
+
+	if q.pressure != nil {
+		msg := *q.pressure
+		q.pressure = nil
+		q.mtx.Unlock()
+		return msg, true
+	}
+
+	m := q.msgs[0]
+	q.msgs[0] = queuedMsg{}
+	q.msgs = q.msgs[1:]
+	q.bytes -= m.size
+	if q.pressured && !q.above(queuePressureLow) {
+		q.pressured = false
+	}
+	more := len(q.msgs) > 0
+	q.mtx.Unlock()
+
+	notify(q.notFull)
+	if more {
+		notify(q.notEmpty)
+	}
+	return m.msg, true
+}
+
+// len returns the number of queued messages.
+func (q *msgQueue) len() int {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	return len(q.msgs)
+}
+
+// full reports whether adding a message of the given size would exceed the
+// limits. It must be called with the mutex held.
+func (q *msgQueue) full(size int) bool {
+	if len(q.msgs) == 0 {
+		return false
+	}
+	return (q.limits.MaxMessages > 0 && len(q.msgs)+1 > q.limits.MaxMessages) ||
+		(q.limits.MaxBytes > 0 && q.bytes+size > q.limits.MaxBytes)
+}
+
+// above reports whether the queue is filled beyond the given fraction of
+// either limit. It must be called with the mutex held.
+func (q *msgQueue) above(fraction float64) bool {
+	return (q.limits.MaxMessages > 0 && float64(len(q.msgs)) >= fraction*float64(q.limits.MaxMessages)) ||
+		(q.limits.MaxBytes > 0 && float64(q.bytes) >= fraction*float64(q.limits.MaxBytes))
+}
+
+// dropOldest drops the oldest message which may be shed. It reports whether
+// there was such a message. It must be called with the mutex held.
+func (q *msgQueue) dropOldest() bool {
+	for i, m := range q.msgs {
+		if isExemptFromShedding(m.msg) {
+			continue
+		}
+		q.bytes -= m.size
+		q.msgs = append(q.msgs[:i], q.msgs[i+1:]...)
+		q.dropped++
+		return true
+	}
+	return false
+}
+
+// coalesce replaces the latest queued message of the same type as msg with
+// msg. It reports whether there was such a message. It must be called with
+// the mutex held.
+func (q *msgQueue) coalesce(msg Msg, size int) bool {
+	t := reflect.TypeOf(msg)
+	for i := len(q.msgs) - 1; i >= 0; i-- {
+		m := q.msgs[i]
+		if reflect.TypeOf(m.msg) != t || isExemptFromShedding(m.msg) {
+			continue
+		}
+		q.bytes += size - m.size
+		q.msgs[i] = queuedMsg{msg: msg, size: size}
+		q.dropped++
+		return true
+	}
+	return false
+}
+
+// notify notifies a waiter on ch, if there is one, without blocking.
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// isExemptFromShedding reports whether a message must never be shed.
+func isExemptFromShedding(msg Msg) bool {
+	switch msg.(type) {
+	case KeyMsg, MouseMsg, WindowSizeMsg, BatchMsg:
+		return true
+	}
+	return isProgramMsg(msg)
+}
+
+// estimateMsgSize estimates the memory used by a message: the size of its
+// value, plus the contents of strings and slices it holds directly, or in
+// its fields.
+func estimateMsgSize(msg Msg) int {
+	v := reflect.ValueOf(msg)
+	size := int(v.Type().Size())
+
+	contents := func(v reflect.Value) int {
+		switch v.Kind() { //nolint:exhaustive
+		case reflect.String:
+			return v.Len()
+		case reflect.Slice:
+			return v.Len() * int(v.Type().Elem().Size())
+		}
+		return 0
+	}
+
+	if v.Kind() == reflect.Struct {
+		for i := 0; i < v.NumField(); i++ {
+			size += contents(v.Field(i))
+		}
+		return size
+	}
+	return size + contents(v)
+}
+
+// pumpQueue forwards messages from the queue to the event loop.
+func (p *Program) pumpQueue() chan struct{} {
+	ch := make(chan struct{})
+
+	go func() {
+		defer close(ch)
+
+		for {
+			msg, ok := p.queue.pop(p.ctx)
+			if !ok {
+				return
+			}
+			select {
+			case <-p.ctx.Done():
+				return
+			case p.msgs <- msg:
+			}
+		}
+	}()
+
+	return ch
+}
@@ -0,0 +1,252 @@
+package tea
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// drainQueue pops all messages from the queue, except for pressure messages.
+func drainQueue(q *msgQueue) []Msg {
+	var msgs []Msg
+	for len(q.msgs) > 0 || q.pressure != nil {
+		msg, _ := q.pop(context.Background())
+		if _, ok := msg.(QueuePressureMsg); !ok {
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
+
+func TestMsgQueuePolicies(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   QueuePolicy
+		sent     []Msg
+		expected []Msg
+	}{
+		{
+			name:     "drop oldest",
+			policy:   QueueDropOldest,
+			sent:     []Msg{1, 2, 3, 4, 5},
+			expected: []Msg{3, 4, 5},
+		},
+		{
+			name:     "drop newest",
+			policy:   QueueDropNewest,
+			sent:     []Msg{1, 2, 3, 4, 5},
+			expected: []Msg{1, 2, 3},
+		},
+		{
+			name:     "coalesce",
+			policy:   QueueCoalesce,
+			sent:     []Msg{1, "a", 2.0, 3, "b"},
+			expected: []Msg{3, "b", 2.0},
+		},
+		{
+			name:     "coalesce without a message of the same type",
+			policy:   QueueCoalesce,
+			sent:     []Msg{1, 2, 3, "a"},
+			expected: []Msg{2, 3, "a"},
+		},
+		{
+			name:     "exempt messages are kept",
+			policy:   QueueDropOldest,
+			sent:     []Msg{KeyMsg{Type: KeyEnter}, 1, QuitMsg{}, 2, 3},
+			expected: []Msg{KeyMsg{Type: KeyEnter}, QuitMsg{}, 3},
+		},
+		{
+			name:     "exempt messages exceed the limits",
+			policy:   QueueDropNewest,
+			sent:     []Msg{1, 2, 3, KeyMsg{Type: KeyEnter}},
+			expected: []Msg{1, 2, 3, KeyMsg{Type: KeyEnter}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q := newMsgQueue(QueueLimits{MaxMessages: 3, Policy: test.policy})
+			for _, msg := range test.sent {
+				q.push(context.Background(), msg)
+			}
+
+			msgs := drainQueue(q)
+			if !reflect.DeepEqual(msgs, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, msgs)
+			}
+			if dropped := len(test.sent) - len(test.expected); q.dropped != dropped {
+				t.Errorf("expected %d dropped messages, got %d", dropped, q.dropped)
+			}
+		})
+	}
+}
+
+func TestMsgQueueMaxBytes(t *testing.T) {
+	q := newMsgQueue(QueueLimits{MaxBytes: 100, Policy: QueueDropOldest})
+	for _, s := range []string{"first", "second", "third"} {
+		q.push(context.Background(), s+string(make([]byte, 30)))
+	}
+
+	msgs := drainQueue(q)
+	if len(msgs) != 1 || msgs[0].(string)[:5] != "third" {
+		t.Errorf("expected only the last message to be kept, got %q", msgs)
+	}
+}
+
+func TestMsgQueueBlock(t *testing.T) {
+	q := newMsgQueue(QueueLimits{MaxMessages: 1})
+	q.push(context.Background(), 1)
+
+	done := make(chan struct{})
+	go func() {
+		q.push(context.Background(), 2)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("expected push to block while the queue is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	msg, _ := q.pop(context.Background())
+	if _, ok := msg.(QueuePressureMsg); ok {
+		msg, _ = q.pop(context.Background())
+	}
+	if msg != 1 {
+		t.Errorf("expected 1, got %v", msg)
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected push to finish once the queue has room")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	q.push(ctx, 3)
+	if msgs := drainQueue(q); !reflect.DeepEqual(msgs, []Msg{2}) {
+		t.Errorf("expected a cancelled push to be discarded, got %v", msgs)
+	}
+}
+
+func TestMsgQueuePressure(t *testing.T) {
+	q := newMsgQueue(QueueLimits{MaxMessages: 10, Policy: QueueDropNewest})
+	for i := 0; i < 8; i++ {
+		q.push(context.Background(), i)
+	}
+
+	msg, _ := q.pop(context.Background())
+	pressure, ok := msg.(QueuePressureMsg)
+	if !ok {
+		t.Fatalf("expected a QueuePressureMsg first, got %#v", msg)
+	}
+	if pressure.Messages != 8 {
+		t.Errorf("expected 8 queued messages, got %d", pressure.Messages)
+	}
+
+	// Pressure isn't reported again until the queue has drained.
+	q.push(context.Background(), 8)
+	for i := 0; i < 4; i++ {
+		q.pop(context.Background()) //nolint:errcheck
+	}
+	if !q.pressured {
+		t.Error("expected pressure to persist above the low mark")
+	}
+	q.pop(context.Background()) //nolint:errcheck
+	if q.pressured {
+		t.Error("expected pressure to be released below the low mark")
+	}
+}
+
+type queueModel struct {
+	received *int
+}
+
+func (m queueModel) Init() Cmd {
+	return nil
+}
+
+func (m queueModel) Update(msg Msg) (Model, Cmd) {
+	if _, ok := msg.(int); ok {
+		*m.received++
+		if *m.received == 3 {
+			return m, Quit
+		}
+	}
+	return m, nil
+}
+
+func (m queueModel) View() string {
+	return ""
+}
+
+func TestQueueLimits(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+	var received int
+
+	p := NewProgram(queueModel{received: &received},
+		WithInput(&in), WithOutput(&buf),
+		WithQueueLimits(QueueLimits{MaxMessages: 10}))
+
+	// Messages sent before the program starts are queued.
+	for i := 0; i < 3; i++ {
+		p.Send(i)
+	}
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if received != 3 {
+		t.Errorf("expected 3 messages, got %d", received)
+	}
+}
+
+type priorityModel struct {
+	received *[]Msg
+}
+
+func (m priorityModel) Init() Cmd {
+	return nil
+}
+
+func (m priorityModel) Update(msg Msg) (Model, Cmd) {
+	switch msg.(type) {
+	case int, string:
+		*m.received = append(*m.received, msg)
+		if len(*m.received) == 4 {
+			return m, Quit
+		}
+	}
+	return m, nil
+}
+
+func (m priorityModel) View() string {
+	return ""
+}
+
+func TestSendPriority(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+	var received []Msg
+
+	p := NewProgram(priorityModel{received: &received},
+		WithInput(&in), WithOutput(&buf),
+		WithQueueLimits(QueueLimits{MaxMessages: 10}))
+
+	// The priority message is handled ahead of those already queued.
+	for i := 0; i < 3; i++ {
+		p.Send(i)
+	}
+	p.SendPriority("urgent")
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Msg{"urgent", 0, 1, 2}
+	if !reflect.DeepEqual(received, expected) {
+		t.Errorf("expected %v, got %v", expected, received)
+	}
+}
@@ -0,0 +1,139 @@
+package tea
+
+import "strings"
+
+// Terminal names, as detected by detectTerminal.
+const (
+	termUnknown         = ""
+	termAlacritty       = "alacritty"
+	termAppleTerminal   = "apple_terminal"
+	termFoot            = "foot"
+	termGhostty         = "ghostty"
+	termITerm2          = "iterm2"
+	termKitty           = "kitty"
+	termKonsole         = "konsole"
+	termLinuxConsole    = "linux"
+	termScreen          = "screen"
+	termTmux            = "tmux"
+	termVSCode          = "vscode"
+	termVTE             = "vte"
+	termWezTerm         = "wezterm"
+	termWindowsTerminal = "windows_terminal"
+	termXterm           = "xterm"
+)
+
+// detectTerminal makes a best guess at the terminal emulator we're running in
+// based on the environment. Multiplexers are reported in favor of the
+// terminal they're running in, since it's the multiplexer that interprets our
+// output.
+func detectTerminal(getenv func(string) string) string {
+	term := getenv("TERM")
+
+	switch {
+	case getenv("TMUX") != "" || strings.HasPrefix(term, "tmux"):
+		return termTmux
+	case getenv("STY") != "" || strings.HasPrefix(term, "screen"):
+		return termScreen
+	}
+
+	switch getenv("TERM_PROGRAM") {
+	case "iTerm.app":
+		return termITerm2
+	case "WezTerm":
+		return termWezTerm
+	case "Apple_Terminal":
+		return termAppleTerminal
+	case "vscode":
+		return termVSCode
+	case "ghostty":
+		return termGhostty
+	}
+
+	switch {
+	case getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty":
+		return termKitty
+	case getenv("WT_SESSION") != "":
+		return termWindowsTerminal
+	case getenv("ALACRITTY_WINDOW_ID") != "" || term == "alacritty":
+		return termAlacritty
+	case term == "foot" || strings.HasPrefix(term, "foot-"):
+		return termFoot
+	case getenv("KONSOLE_VERSION") != "":
+		return termKonsole
+	case getenv("VTE_VERSION") != "":
+		return termVTE
+	case term == "linux":
+		return termLinuxConsole
+	case strings.HasPrefix(term, "xterm"):
+		return termXterm
+	}
+
+	return termUnknown
+}
+
+// terminalQuirks describes known limitations and extensions of a terminal
+// that can't be queried for at runtime.
+type terminalQuirks struct {
+	// clipboardLimit is the largest OSC 52 payload, in base64-encoded bytes,
+	// the terminal accepts in a single sequence. Zero means there's no known
+	// limit and -1 means the terminal doesn't support OSC 52 at all.
+	clipboardLimit int
+
+	// clipboardChunks reports whether the terminal appends consecutive OSC 52
+	// writes, which allows payloads larger than clipboardLimit to be split
+	// into several sequences.
+	clipboardChunks bool
+
+	// textAttrs are the text attributes the terminal supports out of the
+	// box. Support for some of them can also be queried at runtime.
+	textAttrs textAttrs
+
+	// reflows reports whether the terminal rewraps lines when the window
+	// is resized, so that narrowing it makes long lines take up more rows.
+	reflows bool
+
+	// imageProtocol is the protocol images are drawn with. Terminals which
+	// support several get the one which works best in them. Sixel support
+	// can also be queried at runtime.
+	imageProtocol ImageProtocol
+
+	// notifications is the protocol desktop notifications are shown with.
+	// Terminals which don't support any ring the bell instead.
+	notifications notificationProtocol
+}
+
+// defaultQuirks are used for terminals we don't know anything about. The
+// clipboard limit is on the conservative side; it matches what hterm (and
+// thus ChromeOS) accepts.
+var defaultQuirks = terminalQuirks{
+	clipboardLimit: 100_000, //nolint:gomnd
+	textAttrs:      attrsBasic,
+}
+
+// quirks is a database of per-terminal quirks. Terminals not listed here use
+// defaultQuirks.
+var quirks = map[string]terminalQuirks{
+	termAlacritty:       {clipboardLimit: 0, textAttrs: attrsAll, reflows: true},
+	termAppleTerminal:   {clipboardLimit: -1, textAttrs: attrsBasic, reflows: true},
+	termFoot:            {clipboardLimit: 0, textAttrs: attrsAll, imageProtocol: ImageProtocolSixel, reflows: true, notifications: notifyOSC777},
+	termGhostty:         {clipboardLimit: 0, textAttrs: attrsAll, imageProtocol: ImageProtocolKitty, reflows: true, notifications: notifyOSC777},
+	termITerm2:          {clipboardLimit: 0, textAttrs: attrsAll, imageProtocol: ImageProtocolITerm2, reflows: true, notifications: notifyOSC9},
+	termKitty:           {clipboardLimit: 4096, clipboardChunks: true, textAttrs: attrsAll, imageProtocol: ImageProtocolKitty, reflows: true, notifications: notifyOSC99}, //nolint:gomnd
+	termKonsole:         {clipboardLimit: -1, textAttrs: attrsBasic, reflows: true},
+	termLinuxConsole:    {clipboardLimit: -1},
+	termScreen:          {clipboardLimit: 768},                                           //nolint:gomnd
+	termTmux:            {clipboardLimit: 1 << 20, textAttrs: attrsBasic, reflows: true}, //nolint:gomnd
+	termVSCode:          {clipboardLimit: 0, textAttrs: attrsAll, imageProtocol: ImageProtocolITerm2, reflows: true},
+	termVTE:             {clipboardLimit: -1, textAttrs: attrsAll, reflows: true, notifications: notifyOSC777},
+	termWezTerm:         {clipboardLimit: 0, textAttrs: attrsAll, imageProtocol: ImageProtocolITerm2, reflows: true, notifications: notifyOSC777},
+	termWindowsTerminal: {clipboardLimit: 1 << 20, textAttrs: attrsBasic, reflows: true}, //nolint:gomnd
+	termXterm:           {clipboardLimit: 100_000, textAttrs: attrsBasic},                //nolint:gomnd
+}
+
+// quirksFor returns the known quirks for the given terminal.
+func quirksFor(term string) terminalQuirks {
+	if q, ok := quirks[term]; ok {
+		return q
+	}
+	return defaultQuirks
+}
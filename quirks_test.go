@@ -0,0 +1,39 @@
+package tea
+
+import "testing"
+
+func TestDetectTerminal(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{"empty", map[string]string{}, termUnknown},
+		{"xterm", map[string]string{"TERM": "xterm-256color"}, termXterm},
+		{"kitty", map[string]string{"TERM": "xterm-kitty"}, termKitty},
+		{"iterm2", map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "iTerm.app"}, termITerm2},
+		{"wezterm", map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "WezTerm"}, termWezTerm},
+		{"windows terminal", map[string]string{"WT_SESSION": "1"}, termWindowsTerminal},
+		{"linux console", map[string]string{"TERM": "linux"}, termLinuxConsole},
+		{"tmux in iterm2", map[string]string{"TERM": "tmux-256color", "TMUX": "/tmp/tmux", "TERM_PROGRAM": "iTerm.app"}, termTmux},
+		{"screen", map[string]string{"TERM": "screen", "STY": "1.pts"}, termScreen},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			getenv := func(k string) string { return test.env[k] }
+			if got := detectTerminal(getenv); got != test.expected {
+				t.Errorf("expected terminal %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestQuirksFor(t *testing.T) {
+	if q := quirksFor(termUnknown); q != defaultQuirks {
+		t.Errorf("expected default quirks for unknown terminal, got %+v", q)
+	}
+	if q := quirksFor(termKitty); !q.clipboardChunks {
+		t.Errorf("expected kitty to support clipboard chunking")
+	}
+}
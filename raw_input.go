@@ -0,0 +1,15 @@
+package tea
+
+// UnknownSequenceMsg is sent for escape sequences read from the terminal which
+// Bubble Tea doesn't recognize, if enabled with WithUnknownSequences.
+// Otherwise they're dropped.
+type UnknownSequenceMsg struct {
+	Bytes []byte
+}
+
+// RawInputMsg holds the bytes of input as they're read from the terminal, if
+// enabled with WithRawInput. It's sent ahead of the messages parsed from
+// them.
+type RawInputMsg struct {
+	Bytes []byte
+}
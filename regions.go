@@ -0,0 +1,175 @@
+package tea
+
+import (
+	"strings"
+	"time"
+)
+
+// Region is a region of the program's output which is repainted at a frame
+// rate of its own, such as a large pane that changes rarely next to a pane
+// with an animation. Between its frames, the renderer keeps what it last
+// painted in the region, whatever the view has there, so the region is
+// neither compared with the last frame nor repainted when the rest of the
+// view is.
+type Region struct {
+	// ID identifies the region. Creating a region with an id that's already
+	// in use replaces the region.
+	ID string
+
+	// Rect is the region of cells to repaint at the region's frame rate.
+	// Coordinates are relative to the top of the program's output, which
+	// in the altscreen is the top of the screen.
+	Rect Rect
+
+	// FPS is how many times a second the region is repainted, which is
+	// limited to between 1 and 120. It may be lower or higher than the
+	// program's frame rate, but the region isn't repainted more often than
+	// the program's frames are drawn.
+	FPS int
+}
+
+// createRegionMsg is an internal message that creates a region. You can send
+// a createRegionMsg with CreateRegion.
+type createRegionMsg Region
+
+// destroyRegionMsg is an internal message that destroys a region. You can
+// send a destroyRegionMsg with DestroyRegion.
+type destroyRegionMsg string
+
+// CreateRegion is a command that creates a region with a frame rate of its
+// own, or replaces the region with the same id. Regions are only supported by
+// the standard renderer.
+func CreateRegion(region Region) Cmd {
+	return func() Msg {
+		return createRegionMsg(region)
+	}
+}
+
+// DestroyRegion is a command that destroys the region with the given id. The
+// region is painted at the program's frame rate again.
+func DestroyRegion(id string) Cmd {
+	return func() Msg {
+		return destroyRegionMsg(id)
+	}
+}
+
+// refreshRegion is a region the renderer knows about.
+type refreshRegion struct {
+	Region
+
+	// when the region is next repainted, and what was painted in each of
+	// its rows, by row of the region
+	next time.Time
+	held map[int]string
+}
+
+// createRegion adds a region, replacing any region with the same id.
+func (r *standardRenderer) createRegion(region Region) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	rg := &refreshRegion{Region: region}
+	for i, old := range r.regions {
+		if old.ID == region.ID {
+			r.regions[i] = rg
+			return
+		}
+	}
+	r.regions = append(r.regions, rg)
+}
+
+// destroyRegion removes the region with the given id, and has the view
+// painted in it again.
+func (r *standardRenderer) destroyRegion(id string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	for i, rg := range r.regions {
+		if rg.ID == id {
+			r.regions = append(r.regions[:i], r.regions[i+1:]...)
+			r.rehold(time.Now())
+			return
+		}
+	}
+}
+
+// regionsDue reports whether any region is due to be repainted.
+func (r *standardRenderer) regionsDue(now time.Time) bool {
+	for _, rg := range r.regions {
+		if !now.Before(rg.next) {
+			return true
+		}
+	}
+	return false
+}
+
+// rehold replaces the view waiting to be rendered with the last view written,
+// with the regions held anew. The mutex must be held.
+func (r *standardRenderer) rehold(now time.Time) {
+	if r.view == "" {
+		return
+	}
+	r.buf.Reset()
+	_, _ = r.buf.WriteString(r.holdRegions(r.view, now))
+}
+
+// holdRegions returns the view with what was last painted in each region
+// that isn't due to be repainted. Regions which are due take what the view
+// has in them. The mutex must be held.
+func (r *standardRenderer) holdRegions(view string, now time.Time) string {
+	if len(r.regions) == 0 {
+		return view
+	}
+
+	lines := strings.Split(view, "\n")
+	next := time.Time{}
+	for _, rg := range r.regions {
+		due := !now.Before(rg.next)
+		if due {
+			rg.held = make(map[int]string, rg.Rect.Height)
+			rg.next = now.Add(time.Second / time.Duration(clampFPS(rg.FPS)))
+		}
+		if next.IsZero() || rg.next.Before(next) {
+			next = rg.next
+		}
+
+		// Regions as wide as the window hold whole lines.
+		x0, x1 := rg.Rect.X, rg.Rect.X+rg.Rect.Width
+		if x0 < 0 {
+			x0 = 0
+		}
+		whole := x0 == 0 && r.width > 0 && x1 >= r.width
+		if !whole && x0 >= x1 {
+			continue
+		}
+
+		for row := 0; row < rg.Rect.Height; row++ {
+			y := rg.Rect.Y + row
+			if y < 0 || y >= len(lines) {
+				continue
+			}
+			if due {
+				if whole {
+					rg.held[row] = lines[y]
+				} else {
+					rg.held[row] = PadRight(cutCells(lines[y], x0, x1), x1-x0)
+				}
+				continue
+			}
+			held, ok := rg.held[row]
+			switch {
+			case !ok:
+			case whole:
+				lines[y] = held
+			default:
+				lines[y] = splice(PadRight(lines[y], x1), x0, x1, held)
+			}
+		}
+	}
+
+	// Drawing frames on change, the renderer is woken up when the next
+	// region is due.
+	r.wakeAfter(next.Sub(now))
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,63 @@
+package tea
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muesli/termenv"
+)
+
+func TestRendererRegions(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRenderer(termenv.NewOutput(&buf), false).(*standardRenderer)
+	r.altScreenActive = true
+	r.width, r.height = 10, 5
+
+	r.handleMessages(createRegionMsg{ID: "log", Rect: Rect{Y: 1, Width: 10, Height: 1}, FPS: 1})
+	r.write("spinner 1\nlog 1")
+	r.flush()
+	if out := buf.String(); !strings.Contains(out, "log 1") {
+		t.Errorf("expected the region to be painted at first, got %q", out)
+	}
+
+	// Between the region's frames, what was painted there is kept.
+	r.write("spinner 2\nlog 2")
+	r.flush()
+	if r.lastRender != "spinner 2\nlog 1" {
+		t.Errorf("expected only the rest of the view to be painted, got %q", r.lastRender)
+	}
+
+	// Once it's due, it's repainted with the last view.
+	r.regions[0].next = time.Now().Add(-time.Second)
+	r.flush()
+	if r.lastRender != "spinner 2\nlog 2" {
+		t.Errorf("expected the region to be repainted, got %q", r.lastRender)
+	}
+
+	// Destroyed, it's painted along with the rest of the view again.
+	r.write("spinner 3\nlog 3")
+	r.handleMessages(destroyRegionMsg("log"))
+	r.flush()
+	if r.lastRender != "spinner 3\nlog 3" {
+		t.Errorf("expected the region to be painted again, got %q", r.lastRender)
+	}
+}
+
+func TestHoldRegions(t *testing.T) {
+	r := &standardRenderer{width: 10}
+	r.regions = []*refreshRegion{{Region: Region{ID: "pane", Rect: Rect{X: 2, Y: 0, Width: 3, Height: 2}, FPS: 1}}}
+
+	now := time.Now()
+	if got := r.holdRegions("aaaaaa\nb", now); got != "aaaaaa\nb" {
+		t.Errorf("expected the view to be left alone, got %q", got)
+	}
+
+	// Only the cells within the region are held, including those the view
+	// didn't reach.
+	expected := "xxaaax\nyy   "
+	if got := r.holdRegions("xxxxxx\nyyyyy", now.Add(time.Millisecond)); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
@@ -1,6 +1,7 @@
 package tea
 
-// renderer is the interface for Bubble Tea renderers.
+// renderer is the interface for Bubble Tea renderers. Custom renderers
+// implement Renderer instead, and are adapted by customRenderer.
 type renderer interface {
 	// Start the renderer.
 	start()
@@ -24,6 +25,9 @@ type renderer interface {
 	// Clears the terminal.
 	clearScreen()
 
+	// Reset the terminal, re-apply the renderer's modes and repaint.
+	resetTerminal()
+
 	// Whether or not the alternate screen buffer is enabled.
 	altScreen() bool
 	// Enable the alternate screen buffer.
@@ -35,6 +39,10 @@ type renderer interface {
 	showCursor()
 	// Hide the cursor.
 	hideCursor()
+	// Set the shape of the cursor.
+	setCursorShape(CursorShape)
+	// The current visibility and shape of the cursor.
+	cursor() cursorState
 
 	// enableMouseCellMotion enables mouse click, release, wheel and motion
 	// events if a mouse button is pressed (i.e., drag events).
@@ -50,7 +58,35 @@ type renderer interface {
 
 	// DisableMouseAllMotion disables All Motion mouse tracking.
 	disableMouseAllMotion()
+
+	// enableBracketedPaste enables bracketed paste, where characters inside
+	// the input are not interpreted when pasted as a whole.
+	enableBracketedPaste()
+
+	// disableBracketedPaste disables bracketed paste.
+	disableBracketedPaste()
+
+	// setAlternateScroll enables or disables alternate scroll mode, which is
+	// only active while in the altscreen.
+	setAlternateScroll(bool)
+
+	// bracketedPasteActive reports whether bracketed paste mode is currently
+	// enabled.
+	bracketedPasteActive() bool
+
+	// Write a sequence to the terminal immediately, bypassing the frame
+	// buffer. This is used for sequences that don't affect the layout of
+	// the screen, such as setting the clipboard.
+	execute(string) error
+}
+
+// Repaint is a special command that makes the renderer repaint the entire
+// view on the next frame, rather than only the lines that have changed. Use
+// it when something other than the renderer has drawn over the program's
+// output, such as a component writing to the terminal directly.
+func Repaint() Msg {
+	return repaintMsg{}
 }
 
-// repaintMsg forces a full repaint.
+// repaintMsg forces a full repaint. You can send a repaintMsg with Repaint.
 type repaintMsg struct{}
@@ -0,0 +1,65 @@
+package tea
+
+// restartMsg is an internal message used to restart the program. You can send
+// a restartMsg with Restart.
+type restartMsg struct{}
+
+// Restart is a special command that restarts the program without exiting: the
+// terminal is restored, then set up again as when the program started, as its
+// options have it, and the model the program was created with is started over
+// from Init. It's for programs that reload their configuration, or recover
+// from the terminal getting into a bad state.
+//
+// Whatever the model changed about the terminal, such as the mouse mode, the
+// cursor, the window title or the palette, is reset, as are the regions it
+// created and the zones and key sequences it registered; keys held back as
+// the start of a sequence are dropped. Commands still running keep going,
+// and their messages are sent to the restarted model. A model that's a
+// pointer keeps its state, so it's up to Init to reset it.
+func Restart() Msg {
+	return restartMsg{}
+}
+
+// Restart restarts the program, as the Restart command does.
+func (p *Program) Restart() {
+	p.Send(Restart())
+}
+
+// restart restores the terminal, sets it up again as when the program
+// started, and returns the model the program was created with.
+func (p *Program) restart() (Model, error) {
+	if err := p.ReleaseTerminal(); err != nil {
+		return nil, err
+	}
+
+	// RestoreTerminal sets up what's saved here as the startup options have
+	// it, rather than as the model left it.
+	p.altScreenWasActive = p.startupOptions.has(withAltScreen) && p.supports(FeatureAltScreen)
+	p.bpWasActive = !p.startupOptions.has(withoutBracketedPaste) && p.supports(FeatureBracketedPaste)
+	p.savedCursor = cursorState{hidden: true}
+	p.mouse = mouseModeNone
+	if p.supports(FeatureMouse) {
+		if p.startupOptions.has(withMouseCellMotion) {
+			p.mouse = mouseModeCellMotion
+		} else if p.startupOptions.has(withMouseAllMotion) {
+			p.mouse = mouseModeAllMotion
+		}
+	}
+	p.highlight = highlightTracking{}
+	p.appKeypad = false
+	p.palette = nil
+	p.titles, p.titleStack = titles{}, nil
+	p.zones = nil
+	p.repeater.cancel()
+	p.keySequences.reset()
+	if r, ok := p.renderer.(*standardRenderer); ok {
+		r.mtx.Lock()
+		r.regions = nil
+		r.mtx.Unlock()
+	}
+
+	if err := p.RestoreTerminal(); err != nil {
+		return nil, err
+	}
+	return p.initialModel, nil
+}
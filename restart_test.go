@@ -0,0 +1,65 @@
+package tea
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type restartModel struct {
+	inits *int
+}
+
+func (m restartModel) Init() Cmd {
+	*m.inits++
+	if *m.inits == 1 {
+		return Sequence(
+			EnableMouseAllMotion,
+			RegisterZone("button", 0, 0, 10, 1),
+			RegisterKeySequence("g", "g"),
+			Restart,
+		)
+	}
+	return Quit
+}
+
+func (m restartModel) Update(msg Msg) (Model, Cmd) {
+	return m, nil
+}
+
+func (m restartModel) View() string {
+	return "view"
+}
+
+func TestRestart(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+	var inits int
+
+	p := NewProgram(restartModel{inits: &inits}, WithInput(&in), WithOutput(&buf), WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if inits != 2 {
+		t.Errorf("expected the model to be started twice, got %d", inits)
+	}
+
+	// The terminal is set up again as the options have it: the mouse mode
+	// the model enabled is left off.
+	out := buf.String()
+	if n := strings.Count(out, "\x1b[?1049h"); n != 2 {
+		t.Errorf("expected the altscreen to be entered twice, got %d: %q", n, out)
+	}
+	if n := strings.Count(out, "\x1b[?1003h"); n != 1 {
+		t.Errorf("expected the mouse to be enabled once, got %d: %q", n, out)
+	}
+
+	// The zones and key sequences the model registered are gone.
+	if len(p.zones) != 0 {
+		t.Errorf("expected no zones, got %v", p.zones)
+	}
+	if len(p.keySequences.sequences) != 0 {
+		t.Errorf("expected no key sequences, got %v", p.keySequences.sequences)
+	}
+}
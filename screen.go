@@ -7,6 +7,19 @@ package tea
 type WindowSizeMsg struct {
 	Width  int
 	Height int
+
+	// WidthPixels and HeightPixels are the size of the window in pixels,
+	// which programs that draw images need to scale them to cells. They're
+	// zero if neither the system nor the terminal reports them. See also
+	// QueryCellSize.
+	WidthPixels  int
+	HeightPixels int
+
+	// PrevWidth and PrevHeight are the size of the window before it was
+	// resized, so that models can resize their parts in proportion. They're
+	// zero for the initial size.
+	PrevWidth  int
+	PrevHeight int
 }
 
 // ClearScreen is a special command that tells the program to clear the screen
@@ -23,6 +36,19 @@ func ClearScreen() Msg {
 // You can send a clearScreenMsg with ClearScreen.
 type clearScreenMsg struct{}
 
+// ResetTerminal is a special command that resets the terminal to its initial
+// state, re-applies the modes the program has set, such as the altscreen and
+// mouse tracking, and repaints the screen. Use it to recover from external
+// corruption of the terminal state, for example when a subprocess has written
+// binary garbage to the terminal.
+func ResetTerminal() Msg {
+	return resetTerminalMsg{}
+}
+
+// resetTerminalMsg is an internal message that signals to reset the terminal.
+// You can send a resetTerminalMsg with ResetTerminal.
+type resetTerminalMsg struct{}
+
 // EnterAltScreen is a special command that tells the Bubble Tea program to
 // enter the alternate screen buffer.
 //
@@ -42,6 +68,11 @@ type enterAltScreenMsg struct{}
 // the alternate screen buffer. This command should be used to exit the
 // alternate screen buffer while the program is running.
 //
+// The output the program rendered before entering the alternate screen buffer
+// is where it was left, and the view is drawn over it again, so programs
+// running inline can switch to the alternate screen buffer for a while, say
+// for a picker, and return to inline afterwards.
+//
 // Note that the alternate screen buffer will be automatically exited when the
 // program quits.
 func ExitAltScreen() Msg {
@@ -56,6 +87,11 @@ type exitAltScreenMsg struct{}
 // release, and wheel events. Mouse movement events are also captured if
 // a mouse button is pressed (i.e., drag events).
 //
+// Mouse modes can be switched at any time by returning this command,
+// EnableMouseAllMotion or DisableMouse from Update. For example, a program
+// might only enable all motion while the user drags something, and switch
+// back to cell motion afterwards to receive fewer events.
+//
 // Because commands run asynchronously, this command should not be used in your
 // model's Init function. Use the WithMouseCellMotion ProgramOption instead.
 func EnableMouseCellMotion() Msg {
@@ -74,6 +110,9 @@ type enableMouseCellMotionMsg struct{}
 // Many modern terminals support this, but not all. If in doubt, use
 // EnableMouseCellMotion instead.
 //
+// Like the other mouse modes, it can be enabled at any time. See
+// EnableMouseCellMotion.
+//
 // Because commands run asynchronously, this command should not be used in your
 // model's Init function. Use the WithMouseAllMotion ProgramOption instead.
 func EnableMouseAllMotion() Msg {
@@ -86,6 +125,7 @@ func EnableMouseAllMotion() Msg {
 type enableMouseAllMotionMsg struct{}
 
 // DisableMouse is a special command that stops listening for mouse events.
+// It can be used at any time, like the commands which enable them.
 func DisableMouse() Msg {
 	return disableMouseMsg{}
 }
@@ -94,6 +134,37 @@ func DisableMouse() Msg {
 // for mouse events. To send a disableMouseMsg, use the DisableMouse command.
 type disableMouseMsg struct{}
 
+// mouseMode is the mouse tracking mode enabled by the program.
+type mouseMode int
+
+const (
+	mouseModeNone mouseMode = iota
+	mouseModeCellMotion
+	mouseModeAllMotion
+)
+
+// setMouseMode switches to the given mouse tracking mode. Terminals don't
+// agree on whether enabling one mode disables the other, so it's disabled
+// explicitly.
+func (p *Program) setMouseMode(m mouseMode) {
+	switch m {
+	case mouseModeNone:
+		p.renderer.disableMouseCellMotion()
+		p.renderer.disableMouseAllMotion()
+	case mouseModeCellMotion:
+		if p.mouse == mouseModeAllMotion {
+			p.renderer.disableMouseAllMotion()
+		}
+		p.renderer.enableMouseCellMotion()
+	case mouseModeAllMotion:
+		if p.mouse == mouseModeCellMotion {
+			p.renderer.disableMouseCellMotion()
+		}
+		p.renderer.enableMouseAllMotion()
+	}
+	p.mouse = m
+}
+
 // HideCursor is a special command for manually instructing Bubble Tea to hide
 // the cursor. In some rare cases, certain operations will cause the terminal
 // to show the cursor, which is normally hidden for the duration of a Bubble
@@ -116,6 +187,102 @@ func ShowCursor() Msg {
 // this message with ShowCursor.
 type showCursorMsg struct{}
 
+// EnableBracketedPaste is a special command that tells the Bubble Tea program
+// to accept bracketed paste input. With bracketed paste, pasted text is
+// delivered as a single KeyMsg with Paste set to true, rather than as
+// individual keypresses.
+//
+// Note that bracketed paste is enabled by default. Use this command to
+// re-enable it after disabling it with DisableBracketedPaste.
+func EnableBracketedPaste() Msg {
+	return enableBracketedPasteMsg{}
+}
+
+// enableBracketedPasteMsg in an internal message signals that bracketed paste
+// should be enabled. You can send an enableBracketedPasteMsg with
+// EnableBracketedPaste.
+type enableBracketedPasteMsg struct{}
+
+// DisableBracketedPaste is a special command that tells the Bubble Tea program
+// to stop accepting bracketed paste input. Pasted text will then be delivered
+// as individual keypresses, as if it had been typed. This is useful for
+// screens that want raw per-key input, such as a keybinding trainer.
+func DisableBracketedPaste() Msg {
+	return disableBracketedPasteMsg{}
+}
+
+// disableBracketedPasteMsg in an internal message signals that bracketed paste
+// should be disabled. You can send a disableBracketedPasteMsg with
+// DisableBracketedPaste.
+type disableBracketedPasteMsg struct{}
+
+// EnableAlternateScroll is a special command that enables alternate scroll
+// mode in the altscreen. In this mode, the terminal translates mouse wheel
+// motion into up and down arrow keys, so that programs can be scrolled with
+// the wheel without enabling mouse tracking. Mouse tracking, when enabled,
+// takes precedence.
+//
+// Alternate scroll mode only applies to the altscreen, and is left disabled
+// while the program is inline, so that the wheel keeps scrolling the
+// terminal's scrollback there. The mode is kept when switching between
+// screens.
+func EnableAlternateScroll() Msg {
+	return enableAlternateScrollMsg{}
+}
+
+// enableAlternateScrollMsg is an internal message that signals to enable
+// alternate scroll mode. You can send an enableAlternateScrollMsg with
+// EnableAlternateScroll.
+type enableAlternateScrollMsg struct{}
+
+// DisableAlternateScroll is a special command that disables alternate scroll
+// mode. See EnableAlternateScroll.
+func DisableAlternateScroll() Msg {
+	return disableAlternateScrollMsg{}
+}
+
+// disableAlternateScrollMsg is an internal message that signals to disable
+// alternate scroll mode. You can send a disableAlternateScrollMsg with
+// DisableAlternateScroll.
+type disableAlternateScrollMsg struct{}
+
+// Alternate scroll mode sequences.
+const (
+	enableAltScroll  = "\x1b[?1007h"
+	disableAltScroll = "\x1b[?1007l"
+)
+
+// EnableApplicationKeypad is a special command that enables application
+// keypad mode (DECKPAM), in which the terminal reports the keys of the numeric
+// keypad as such, like KeyKPEnter and KeyKP5, rather than like the
+// corresponding keys of the main keyboard. Some terminals only do so while num
+// lock is off.
+func EnableApplicationKeypad() Msg {
+	return enableApplicationKeypadMsg{}
+}
+
+// enableApplicationKeypadMsg is an internal message that signals to enable
+// application keypad mode. You can send an enableApplicationKeypadMsg with
+// EnableApplicationKeypad.
+type enableApplicationKeypadMsg struct{}
+
+// DisableApplicationKeypad is a special command that disables application
+// keypad mode. See EnableApplicationKeypad.
+func DisableApplicationKeypad() Msg {
+	return disableApplicationKeypadMsg{}
+}
+
+// disableApplicationKeypadMsg is an internal message that signals to disable
+// application keypad mode. You can send a disableApplicationKeypadMsg with
+// DisableApplicationKeypad.
+type disableApplicationKeypadMsg struct{}
+
+// Application keypad mode sequences, DECKPAM and DECKPNM.
+const (
+	enableAppKeypad  = "\x1b="
+	disableAppKeypad = "\x1b>"
+)
+
 // EnterAltScreen enters the alternate screen buffer, which consumes the entire
 // terminal window. ExitAltScreen will return the terminal to its former state.
 //
@@ -2,6 +2,7 @@ package tea
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 )
 
@@ -14,42 +15,92 @@ func TestClearMsg(t *testing.T) {
 		{
 			name:     "clear_screen",
 			cmds:     []Cmd{ClearScreen},
-			expected: "\x1b[?25l\x1b[2J\x1b[1;1H\x1b[1;1Hsuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l",
+			expected: "\x1b[?25l\x1b[?2004h\x1b[2J\x1b[1;1H\x1b[1;1Hsuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[?2004l",
+		},
+		{
+			name:     "reset_terminal",
+			cmds:     []Cmd{EnableMouseCellMotion, ResetTerminal},
+			expected: "\x1b[?25l\x1b[?2004h\x1b[?1002h\x1b[!p\x1bc\x1b[2J\x1b[1;1H\x1b[1;1H\x1b[?25l\x1b[?1002h\x1b[?2004hsuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[?2004l",
 		},
 		{
 			name:     "altscreen",
 			cmds:     []Cmd{EnterAltScreen, ExitAltScreen},
-			expected: "\x1b[?25l\x1b[?1049h\x1b[2J\x1b[1;1H\x1b[1;1H\x1b[?25l\x1b[?1049l\x1b[?25lsuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l",
+			expected: "\x1b[?25l\x1b[?2004h\x1b[?1049h\x1b[2J\x1b[1;1H\x1b[1;1H\x1b[?25l\x1b[?1049l\x1b[?25lsuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[?2004l",
 		},
 		{
 			name:     "altscreen_autoexit",
 			cmds:     []Cmd{EnterAltScreen},
-			expected: "\x1b[?25l\x1b[?1049h\x1b[2J\x1b[1;1H\x1b[1;1H\x1b[?25lsuccess\r\n\x1b[2;0H\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[?1049l\x1b[?25h",
+			expected: "\x1b[?25l\x1b[?2004h\x1b[?1049h\x1b[2J\x1b[1;1H\x1b[1;1H\x1b[?25lsuccess\r\n\x1b[2;0H\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[?2004l\x1b[?1049l\x1b[?25h",
+		},
+		{
+			name:     "alternate_scroll",
+			cmds:     []Cmd{EnableAlternateScroll, EnterAltScreen, ExitAltScreen},
+			expected: "\x1b[?25l\x1b[?2004h\x1b[?1049h\x1b[?1007h\x1b[2J\x1b[1;1H\x1b[1;1H\x1b[?25l\x1b[?1007l\x1b[?1049l\x1b[?25lsuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[?2004l",
+		},
+		{
+			name:     "alternate_scroll_inline",
+			cmds:     []Cmd{EnableAlternateScroll},
+			expected: "\x1b[?25l\x1b[?2004hsuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[?2004l",
+		},
+		{
+			name:     "application_keypad",
+			cmds:     []Cmd{EnableApplicationKeypad},
+			expected: "\x1b[?25l\x1b[?2004h\x1b=success\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b>\x1b[?2004l",
+		},
+		{
+			name:     "application_keypad_disable",
+			cmds:     []Cmd{EnableApplicationKeypad, DisableApplicationKeypad},
+			expected: "\x1b[?25l\x1b[?2004h\x1b=\x1b>success\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[?2004l",
 		},
 		{
 			name:     "mouse_cellmotion",
 			cmds:     []Cmd{EnableMouseCellMotion},
-			expected: "\x1b[?25l\x1b[?1002hsuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l",
+			expected: "\x1b[?25l\x1b[?2004h\x1b[?1002hsuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[?2004l",
 		},
 		{
 			name:     "mouse_allmotion",
 			cmds:     []Cmd{EnableMouseAllMotion},
-			expected: "\x1b[?25l\x1b[?1003hsuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l",
+			expected: "\x1b[?25l\x1b[?2004h\x1b[?1003hsuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[?2004l",
 		},
 		{
 			name:     "mouse_disable",
 			cmds:     []Cmd{EnableMouseAllMotion, DisableMouse},
-			expected: "\x1b[?25l\x1b[?1003h\x1b[?1002l\x1b[?1003lsuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l",
+			expected: "\x1b[?25l\x1b[?2004h\x1b[?1003h\x1b[?1002l\x1b[?1003lsuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[?2004l",
+		},
+		{
+			name:     "mouse_switch",
+			cmds:     []Cmd{EnableMouseCellMotion, EnableMouseAllMotion, EnableMouseCellMotion},
+			expected: "\x1b[?25l\x1b[?2004h\x1b[?1002h\x1b[?1002l\x1b[?1003h\x1b[?1003l\x1b[?1002hsuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[?2004l",
+		},
+		{
+			name:     "mouse_highlight",
+			cmds:     []Cmd{EnableMouseHighlightTracking(0, 10)},
+			expected: "\x1b[?25l\x1b[?2004h\x1b[?1001hsuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[?1001l\x1b[?2004l",
 		},
 		{
 			name:     "cursor_hide",
 			cmds:     []Cmd{HideCursor},
-			expected: "\x1b[?25l\x1b[?25lsuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l",
+			expected: "\x1b[?25l\x1b[?2004h\x1b[?25lsuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[?2004l",
 		},
 		{
 			name:     "cursor_hideshow",
 			cmds:     []Cmd{HideCursor, ShowCursor},
-			expected: "\x1b[?25l\x1b[?25l\x1b[?25hsuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l",
+			expected: "\x1b[?25l\x1b[?2004h\x1b[?25l\x1b[?25hsuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[?2004l",
+		},
+		{
+			name:     "cursor_shape",
+			cmds:     []Cmd{SetCursorShape(CursorBar)},
+			expected: "\x1b[?25l\x1b[?2004h\x1b[6 qsuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[0 q\x1b[?1002l\x1b[?1003l\x1b[?2004l",
+		},
+		{
+			name:     "set_window_title",
+			cmds:     []Cmd{SetWindowTitle("foo")},
+			expected: "\x1b[?25l\x1b[?2004h\x1b[22;0t\x1b]2;foo\asuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[23;0t\x1b[?2004l",
+		},
+		{
+			name:     "bp_stop_start",
+			cmds:     []Cmd{DisableBracketedPaste, EnableBracketedPaste},
+			expected: "\x1b[?25l\x1b[?2004h\x1b[?2004l\x1b[?2004hsuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[?2004l",
 		},
 	}
 
@@ -74,3 +125,27 @@ func TestClearMsg(t *testing.T) {
 		})
 	}
 }
+
+func TestRestoreTerminalMouseMode(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewProgram(nil, WithInput(&bytes.Buffer{}), WithOutput(&buf))
+	p.renderer = newRenderer(p.output, false)
+	p.renderer.start()
+	if err := p.initCancelReader(); err != nil {
+		t.Fatal(err)
+	}
+	p.setMouseMode(mouseModeAllMotion)
+
+	if err := p.ReleaseTerminal(); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := p.RestoreTerminal(); err != nil {
+		t.Fatal(err)
+	}
+	p.renderer.stop()
+
+	if !strings.Contains(buf.String(), "\x1b[?1003h") {
+		t.Errorf("expected all motion to be enabled again, got %q", buf.String())
+	}
+}
@@ -0,0 +1,103 @@
+package tea
+
+import "time"
+
+// defaultShutdownTimeout is how long the program waits for what's run when it
+// exits, unless set with WithShutdownTimeout.
+const defaultShutdownTimeout = 5 * time.Second
+
+// ShutdownMsg is sent to the model when the program quits, so it can save its
+// state, close files and cancel network calls. The program waits for the
+// command returned to finish before it exits, until the deadline at most, and
+// discards its message. The view is rendered once more afterwards.
+//
+// It isn't sent when the program is killed, or when its context is canceled,
+// as Update isn't called again then. Use OnShutdown for what must run on any
+// exit.
+type ShutdownMsg struct {
+	// Deadline is when the program exits, whether or not the command
+	// returned has finished.
+	Deadline time.Time
+}
+
+// OnShutdown registers a function to be called when the program exits, whether
+// it quits, is killed or stops with an error, before the terminal is restored.
+// Functions are called one after another, in the order they were registered,
+// and are waited for until the grace period set with WithShutdownTimeout is
+// up, which covers the command returned for ShutdownMsg too. Any still running
+// then are left behind. They aren't called after a panic, as the program's
+// state can't be relied upon then.
+//
+// It's safe to call OnShutdown from commands, and before the program is run.
+func (p *Program) OnShutdown(fn func()) {
+	p.shutdownMtx.Lock()
+	defer p.shutdownMtx.Unlock()
+
+	p.shutdownHooks = append(p.shutdownHooks, fn)
+}
+
+// startShutdown starts the grace period for what's run when the program
+// exits, if it hasn't started yet, and returns when it ends.
+func (p *Program) startShutdown() time.Time {
+	if p.shutdownDeadline.IsZero() {
+		d := p.shutdownTimeout
+		if d <= 0 {
+			d = defaultShutdownTimeout
+		}
+		p.shutdownDeadline = time.Now().Add(d)
+	}
+	return p.shutdownDeadline
+}
+
+// shutdownModel sends ShutdownMsg to the model, and waits for the command it
+// returns to finish, until the deadline at most.
+func (p *Program) shutdownModel(model Model) (Model, error) {
+	deadline := p.startShutdown()
+	model, cmd, err := update(model, ShutdownMsg{Deadline: deadline})
+	if err != nil {
+		return model, err
+	}
+	if cmd != nil {
+		waitUntil(deadline, func() { _ = cmd() })
+	}
+	return model, nil
+}
+
+// runShutdownHooks calls the functions registered with OnShutdown, until the
+// deadline at most.
+func (p *Program) runShutdownHooks() {
+	p.shutdownMtx.Lock()
+	hooks := p.shutdownHooks
+	p.shutdownHooks = nil
+	p.shutdownMtx.Unlock()
+
+	if len(hooks) == 0 {
+		return
+	}
+	waitUntil(p.startShutdown(), func() {
+		for _, fn := range hooks {
+			fn()
+		}
+	})
+}
+
+// waitUntil calls fn on a goroutine of its own, and waits for it to return,
+// until the deadline at most. If fn panics, the panic is raised again on the
+// caller's goroutine, where the program recovers from it.
+func waitUntil(deadline time.Time, fn func()) {
+	done := make(chan interface{}, 1)
+	go func() {
+		defer func() { done <- recover() }()
+		fn()
+	}()
+
+	t := time.NewTimer(time.Until(deadline))
+	defer t.Stop()
+	select {
+	case r := <-done:
+		if r != nil {
+			panic(r)
+		}
+	case <-t.C:
+	}
+}
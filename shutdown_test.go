@@ -0,0 +1,82 @@
+package tea
+
+import (
+	"bytes"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type shutdownModel struct {
+	mtx    *sync.Mutex
+	events *[]string
+}
+
+func (m shutdownModel) record(event string) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	*m.events = append(*m.events, event)
+}
+
+func (m shutdownModel) Init() Cmd {
+	return Quit
+}
+
+func (m shutdownModel) Update(msg Msg) (Model, Cmd) {
+	if msg, ok := msg.(ShutdownMsg); ok {
+		if msg.Deadline.IsZero() {
+			m.record("no deadline")
+		}
+		return m, func() Msg {
+			m.record("command")
+			return nil
+		}
+	}
+	return m, nil
+}
+
+func (m shutdownModel) View() string {
+	return "view"
+}
+
+func TestShutdown(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+	var mtx sync.Mutex
+	var events []string
+
+	m := shutdownModel{mtx: &mtx, events: &events}
+	p := NewProgram(m, WithInput(&in), WithOutput(&buf))
+	p.OnShutdown(func() { m.record("first hook") })
+	p.OnShutdown(func() { m.record("second hook") })
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"command", "first hook", "second hook"}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected %q, got %q", expected, events)
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+
+	p := NewProgram(&testModel{}, WithInput(&in), WithOutput(&buf), WithShutdownTimeout(50*time.Millisecond))
+	block := make(chan struct{})
+	defer close(block)
+	p.OnShutdown(func() { <-block })
+
+	// Hooks are called when the program is killed too, and are left behind
+	// once the grace period is up.
+	go p.Kill()
+	start := time.Now()
+	if _, err := p.Run(); err != ErrProgramKilled {
+		t.Fatalf("expected ErrProgramKilled, got %v", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("expected the hook to be waited for until the timeout, waited %v", d)
+	}
+}
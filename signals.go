@@ -0,0 +1,19 @@
+package tea
+
+import "os"
+
+// SignalMsg is sent to the model when the program receives one of the signals
+// set with WithSignals.
+type SignalMsg struct {
+	Signal os.Signal
+}
+
+// isMsgSignal reports whether s is sent to the program as a SignalMsg.
+func (p *Program) isMsgSignal(s os.Signal) bool {
+	for _, sig := range p.signals {
+		if sig == s {
+			return true
+		}
+	}
+	return false
+}
@@ -7,8 +7,27 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
+// suspendSupported reports whether programs can be suspended, see Suspend.
+const suspendSupported = true
+
+// suspendSignals are the signals which suspend the program.
+var suspendSignals = []os.Signal{syscall.SIGTSTP}
+
+// suspendProcess stops the process, as the shell does for ctrl+z, and waits
+// for it to be continued. SIGTSTP is caught while the program runs, so the
+// process is stopped with SIGSTOP, which can't be.
+var suspendProcess = func() {
+	cont := make(chan os.Signal, 1)
+	signal.Notify(cont, syscall.SIGCONT)
+	defer signal.Stop(cont)
+
+	_ = syscall.Kill(0, syscall.SIGSTOP)
+	<-cont
+}
+
 // listenForResize sends messages (or errors) when the terminal resizes.
 // Argument output should be the file descriptor for the terminal; usually
 // os.Stdout.
@@ -21,13 +40,37 @@ func (p *Program) listenForResize(done chan struct{}) {
 		close(done)
 	}()
 
+	// Resizes are coalesced: signals received while the program is busy with
+	// the last size are merged into one, and when debouncing, the size is
+	// only checked once resizing has settled. Either way, the size is
+	// checked after the last signal, so the program ends up with the size
+	// the window was left at.
+	var settled <-chan time.Time
+	var timer *time.Timer
 	for {
 		select {
 		case <-p.ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
 			return
 		case <-sig:
+			if p.resizeDebounce > 0 {
+				if timer == nil {
+					timer = time.NewTimer(p.resizeDebounce)
+				} else {
+					if !timer.Stop() && settled != nil {
+						<-timer.C
+					}
+					timer.Reset(p.resizeDebounce)
+				}
+				settled = timer.C
+				continue
+			}
+		case <-settled:
+			settled = nil
 		}
 
-		p.checkResize()
+		p.checkResizeChanged()
 	}
 }
@@ -0,0 +1,105 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris || aix
+// +build darwin dragonfly freebsd linux netbsd openbsd solaris aix
+
+package tea
+
+import (
+	"bytes"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type signalModel struct {
+	signal os.Signal
+}
+
+func (m signalModel) Init() Cmd {
+	return nil
+}
+
+func (m signalModel) Update(msg Msg) (Model, Cmd) {
+	if msg, ok := msg.(SignalMsg); ok {
+		m.signal = msg.Signal
+		return m, Quit
+	}
+	return m, nil
+}
+
+func (m signalModel) View() string {
+	return "view"
+}
+
+func TestSignals(t *testing.T) {
+	// Keep the signal from stopping the test before the program listens for
+	// it.
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGUSR1)
+	defer signal.Stop(c)
+
+	var buf bytes.Buffer
+	var in bytes.Buffer
+	p := NewProgram(signalModel{}, WithInput(&in), WithOutput(&buf), WithoutSignalHandler(), WithSignals(syscall.SIGUSR1))
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(10 * time.Millisecond):
+				_ = syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+			}
+		}
+	}()
+
+	m, err := p.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := m.(signalModel).signal; s != syscall.SIGUSR1 {
+		t.Errorf("expected SIGUSR1, got %v", s)
+	}
+}
+
+func TestResizeDebounce(t *testing.T) {
+	p := NewProgram(nil, WithFixedWindowSize(80, 24), WithResizeDebounce(20*time.Millisecond))
+	done := make(chan struct{})
+	go p.listenForResize(done)
+	defer func() {
+		p.cancel()
+		<-done
+	}()
+
+	// A burst of resizes...
+	for i := 0; i < 10; i++ {
+		_ = syscall.Kill(os.Getpid(), syscall.SIGWINCH)
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// ...is sent as a single size, once it has settled.
+	select {
+	case msg := <-p.msgs:
+		if msg != (WindowSizeMsg{Width: 80, Height: 24}) {
+			t.Errorf("expected the window size, got %#v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the window size to be sent")
+	}
+	select {
+	case msg := <-p.msgs:
+		t.Errorf("expected a single message, got %#v", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// The same size isn't sent again.
+	_ = syscall.Kill(os.Getpid(), syscall.SIGWINCH)
+	select {
+	case msg := <-p.msgs:
+		t.Errorf("expected the size not to be sent again, got %#v", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
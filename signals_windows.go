@@ -3,6 +3,18 @@
 
 package tea
 
+import "os"
+
+// suspendSupported reports whether programs can be suspended, see Suspend.
+// Windows has no job control.
+const suspendSupported = false
+
+// suspendSignals are the signals which suspend the program.
+var suspendSignals []os.Signal
+
+// suspendProcess does nothing, as programs can't be suspended.
+var suspendProcess = func() {}
+
 // listenForResize is not available on windows because windows does not
 // implement syscall.SIGWINCH.
 func (p *Program) listenForResize(done chan struct{}) {
@@ -9,7 +9,6 @@ import (
 	"time"
 
 	"github.com/muesli/ansi/compressor"
-	"github.com/muesli/reflow/truncate"
 	"github.com/muesli/termenv"
 )
 
@@ -19,6 +18,14 @@ const (
 	defaultFramerate = time.Second / 60
 )
 
+// Synchronized output, which has the terminal hold off on updating the screen
+// between the beginning and end of an update.
+const (
+	synchronizedOutputMode  = 2026
+	beginSynchronizedUpdate = "\x1b[?2026h"
+	endSynchronizedUpdate   = "\x1b[?2026l"
+)
+
 // standardRenderer is a framerate-based terminal renderer, updating the view
 // at a given framerate to avoid overloading the terminal emulator.
 //
@@ -38,18 +45,129 @@ type standardRenderer struct {
 	useANSICompressor  bool
 	once               sync.Once
 
-	// cursor visibility state
+	// whether the renderer is drawing frames, between start and stop
+	running bool
+
+	// cursor visibility and shape state
 	cursorHidden bool
+	cursorShape  CursorShape
+
+	// where the model wants the cursor, see CursorModel, and whether that
+	// has changed since the last frame
+	cursorWanted cursorPlacement
+	cursorMoved  bool
+
+	// whether the cursor is placed, how many lines above the last line, and
+	// how many lines of the view were cut from the top of the frame
+	cursorPlaced bool
+	cursorUp     int
+	cursorOffset int
 
 	// essentially whether or not we're using the full size of the terminal
 	altScreenActive bool
 
+	// whether or not we're currently using bracketed paste
+	bpActive bool
+
+	// mouse tracking state
+	mouseCellMotion bool
+	mouseAllMotion  bool
+
+	// whether alternate scroll mode is wanted in the altscreen
+	altScroll bool
+
 	// renderer dimensions; usually the size of the window
 	width  int
 	height int
 
 	// lines explicitly set not to render
 	ignoreLines map[int]struct{}
+
+	// lines to repaint on the next flush, even if they haven't changed
+	invalidLines map[int]struct{}
+
+	// whether the terminal supports rectangular area operations, which we
+	// use to move and clear blocks of lines in the altscreen. Only set once
+	// the terminal has reported them in its device attributes.
+	rectOps bool
+
+	// text attributes the terminal doesn't support, which we remove from
+	// the output
+	unsupportedAttrs textAttrs
+
+	// images drawn over the view, see PlaceImage, and the protocol they're
+	// drawn with
+	images        []*placedImage
+	imageProtocol ImageProtocol
+
+	// the last id given to a kitty image, and the ids of those to delete on
+	// the next frame
+	nextKittyID  int
+	kittyDeletes []int
+
+	// the colors the terminal supports; colors beyond them are downgraded
+	colorProfile termenv.Profile
+
+	// the lines of the view painted in the last frame
+	renderedLines []string
+
+	// whether the terminal rewraps lines when the window is resized, and
+	// whether the next frame needs to erase the rows rewrapped lines spilled
+	// onto
+	reflows    bool
+	clearBelow bool
+
+	// the state of the output outside the altscreen while it's active, so
+	// that the frames drawn on return pick up where they left off: the
+	// lines rendered, the lines of the view painted and the width they
+	// were painted at
+	inlineLinesRendered int
+	inlineLines         []string
+	inlineWidth         int
+
+	// regions repainted at frame rates of their own, see CreateRegion, and
+	// the last view written, which they're held over
+	regions []*refreshRegion
+	view    string
+
+	// the most lines of output outside the altscreen, if set
+	maxInlineHeight int
+
+	// whether to draw frames only when the view changes rather than on
+	// every tick, and the channel that signals a change
+	onChange bool
+	changed  chan struct{}
+
+	// the timer that wakes the renderer drawing frames on change when a
+	// frame is due that no change would bring about, and when
+	wakeTimer *time.Timer
+	wakeAt    time.Time
+
+	// holds back writes to the terminal, if set, see WithWriteBatching, and
+	// whether what it holds is written with each frame
+	batch       *batchWriter
+	batchFrames bool
+
+	// the most bytes to write per frame on average, if set, see
+	// WithFrameBudget, and the bytes written beyond it as of when
+	frameBudget int
+	budgetDebt  int64
+	budgetTime  time.Time
+
+	// whether to wrap frames in synchronized updates, so that the terminal
+	// shows them all at once
+	syncOutput bool
+
+	// called with the diff of every frame we write, if set
+	frameHook func(FrameDiff)
+
+	// measures input latency, if set
+	latency *latencyTracker
+
+	// performance statistics since they were last taken, and since the
+	// renderer was created
+	stats  frameStats
+	totals frameStats
 }
 
 // newRenderer creates a new renderer. Normally you'll want to initialize it
@@ -71,7 +189,12 @@ func newRenderer(out *termenv.Output, useANSICompressor bool) renderer {
 
 // start starts the renderer.
 func (r *standardRenderer) start() {
-	if r.ticker == nil {
+	if r.onChange {
+		// Frames are drawn when the view changes, see write.
+		if r.changed == nil {
+			r.changed = make(chan struct{}, 1)
+		}
+	} else if r.ticker == nil {
 		r.ticker = time.NewTicker(r.framerate)
 	} else {
 		// If the ticker already exists, it has been stopped and we need to
@@ -81,22 +204,38 @@ func (r *standardRenderer) start() {
 
 	// Since the renderer can be restarted after a stop, we need to reset
 	// the done channel and its corresponding sync.Once.
+	r.done = make(chan struct{})
 	r.once = sync.Once{}
+	r.running = true
+	if r.batch != nil {
+		r.batch.setThrough(false)
+	}
 
-	go r.listen()
+	go r.listen(r.done)
 }
 
 // stop permanently halts the renderer, rendering the final frame.
 func (r *standardRenderer) stop() {
+	// The final frame is drawn whatever the byte budget.
+	r.mtx.Lock()
+	r.running = false
+	r.mtx.Unlock()
+
 	// flush locks the mutex
 	r.flush()
 
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
+	r.unplaceCursor(r.out)
 	r.out.ClearLine()
+	if r.batch != nil {
+		r.batch.setThrough(true)
+	}
 	r.once.Do(func() {
-		r.done <- struct{}{}
+		// Closed rather than sent on while the mutex is held, as listen
+		// may be waiting on it in flush.
+		close(r.done)
 	})
 
 	if r.useANSICompressor {
@@ -106,46 +245,161 @@ func (r *standardRenderer) stop() {
 	}
 }
 
+// eraseInline erases the output rendered outside the altscreen, when the
+// terminal is released, so that whatever runs in the meantime starts where it
+// was, and the next frame is rendered from there, below anything printed. The
+// cursor must be at the start of the last line rendered.
+func (r *standardRenderer) eraseInline() {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.altScreenActive || r.linesRendered == 0 {
+		return
+	}
+	if r.linesRendered > 1 {
+		r.out.CursorUp(r.linesRendered - 1)
+	}
+	_, _ = io.WriteString(r.out, "\r\x1b[J")
+	r.linesRendered, r.renderedLines = 0, nil
+	r.lastRender = ""
+}
+
 // kill halts the renderer. The final frame will not be rendered.
 func (r *standardRenderer) kill() {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
+	r.unplaceCursor(r.out)
 	r.out.ClearLine()
+	r.running = false
+	if r.batch != nil {
+		r.batch.setThrough(true)
+	}
 	r.once.Do(func() {
-		r.done <- struct{}{}
+		close(r.done)
 	})
 }
 
-// listen waits for ticks on the ticker, or a signal to stop the renderer.
-func (r *standardRenderer) listen() {
+// setFPS sets the most frames per second the renderer draws.
+func (r *standardRenderer) setFPS(fps int) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.framerate = time.Second / time.Duration(fps)
+	if r.ticker != nil {
+		r.ticker.Reset(r.framerate)
+	}
+}
+
+// listen waits for ticks on the ticker, or for changes to the view when
+// drawing frames on change, until done is closed as the renderer stops.
+func (r *standardRenderer) listen(done chan struct{}) {
+	var tick <-chan time.Time
+	if r.ticker != nil {
+		tick = r.ticker.C
+	}
 	for {
 		select {
-		case <-r.done:
-			r.ticker.Stop()
+		case <-done:
+			if r.ticker != nil {
+				r.ticker.Stop()
+			}
 			return
 
-		case <-r.ticker.C:
+		case <-tick:
 			r.flush()
+
+		case <-r.changed:
+			r.flush()
+
+			// Hold off on the next frame to keep to the framerate.
+			r.mtx.Lock()
+			framerate := r.framerate
+			r.mtx.Unlock()
+			select {
+			case <-done:
+				return
+			case <-time.After(framerate):
+			}
 		}
 	}
 }
 
+// wakeAfter has the renderer, when drawing frames on change, draw a frame
+// after d even if the view doesn't change, as when a region is due. An
+// earlier wake up that's pending is kept. The mutex must be held.
+func (r *standardRenderer) wakeAfter(d time.Duration) {
+	if !r.onChange || r.changed == nil {
+		return
+	}
+	at := time.Now().Add(d)
+	if r.wakeTimer != nil && time.Now().Before(r.wakeAt) && r.wakeAt.Before(at) {
+		return
+	}
+	if r.wakeTimer != nil {
+		r.wakeTimer.Stop()
+	}
+	changed := r.changed
+	r.wakeAt = at
+	r.wakeTimer = time.AfterFunc(d, func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+}
+
 // flush renders the buffer.
 func (r *standardRenderer) flush() {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
-	if r.buf.Len() == 0 || r.buf.String() == r.lastRender {
+	// Once we're done, the terminal reflects everything written so far,
+	// whether or not there was anything to do.
+	if r.latency != nil {
+		defer func() { r.latency.flushed(time.Now()) }()
+	}
+	if r.batch != nil && r.batchFrames {
+		defer func() { _ = r.batch.flush() }()
+	}
+
+	// Regions due to be repainted take what the last view has in them.
+	if now := time.Now(); r.regionsDue(now) {
+		r.rehold(now)
+	}
+
+	if r.buf.Len() == 0 || (r.buf.String() == r.lastRender && len(r.invalidLines) == 0 && !r.cursorMoved) {
 		// Nothing to do
 		return
 	}
 
+	start := time.Now()
+
+	// Frames are skipped until the output of past ones has been paid off.
+	// The view is kept, to be drawn once it has.
+	if r.running && r.overBudget(start) {
+		r.wakeAfter(r.framerate)
+		return
+	}
+
 	// Output buffer
 	buf := &bytes.Buffer{}
 	out := termenv.NewOutput(buf)
 
-	newLines := strings.Split(r.buf.String(), "\n")
+	r.unplaceCursor(out)
+	r.cursorMoved = false
+	if r.buf.String() == r.lastRender && len(r.invalidLines) == 0 {
+		// Only the cursor has moved.
+		r.placeCursor(out)
+		_, _ = r.out.Write(buf.Bytes())
+		r.buf.Reset()
+		return
+	}
+
+	// Hyperlinks are closed at the end of each line, so that lines can be
+	// painted on their own.
+	newLines := closeHyperlinks(strings.Split(r.buf.String(), "\n"))
+	viewLines := len(newLines)
 
 	// If we know the output's height, we can use it to determine how many
 	// lines we can render. We drop lines from the top of the render buffer if
@@ -155,33 +409,101 @@ func (r *standardRenderer) flush() {
 		newLines = newLines[len(newLines)-r.height:]
 	}
 
+	// Inline, the output is also kept within its own limit.
+	if !r.altScreenActive && r.maxInlineHeight > 0 && len(newLines) > r.maxInlineHeight {
+		newLines = newLines[len(newLines)-r.maxInlineHeight:]
+	}
+
 	numLinesThisFlush := len(newLines)
-	oldLines := strings.Split(r.lastRender, "\n")
+	r.cursorOffset = viewLines - numLinesThisFlush
+	renderedLines := newLines
+	oldLines := closeHyperlinks(strings.Split(r.lastRender, "\n"))
 	skipLines := make(map[int]struct{})
 	flushQueuedMessages := len(r.queuedMessageLines) > 0 && !r.altScreenActive
 
+	// Where possible, update blocks of lines with rectangular area
+	// operations rather than rewriting them.
+	var rectLines map[int]struct{}
+	if r.rectOps && r.altScreenActive && r.width > 0 && len(r.ignoreLines) == 0 && len(r.invalidLines) == 0 && len(r.images) == 0 {
+		if r.height > 0 && len(oldLines) > r.height {
+			oldLines = oldLines[len(oldLines)-r.height:]
+		}
+		rectLines = r.rectUpdate(out, oldLines, newLines)
+	}
+
+	// Leave the regions covered by images alone. Lines with images on them
+	// are compared without them, and aren't cleared before being painted
+	// over, unless printed messages push them around.
+	for _, img := range r.images {
+		for y := img.Rect.Y; y < img.Rect.Y+img.Rect.Height; y++ {
+			if _, invalid := r.invalidLines[y]; invalid || flushQueuedMessages {
+				img.drawn = false
+			}
+		}
+	}
+	newLines, imageLines := r.maskImages(newLines)
+	oldLines, _ = r.maskImages(oldLines)
+	if flushQueuedMessages {
+		imageLines = nil
+	}
+
 	// Add any queued messages to this render
 	if flushQueuedMessages {
 		newLines = append(r.queuedMessageLines, newLines...)
 		r.queuedMessageLines = []string{}
 	}
 
+	// Lines which have changed in part are repainted in part.
+	var damaged map[int]damagedLine
+	if !flushQueuedMessages && r.width > 0 && len(newLines) <= len(oldLines) {
+		for i := range newLines {
+			_, invalid := r.invalidLines[i]
+			_, ignored := r.ignoreLines[i]
+			_, image := imageLines[i]
+			_, rect := rectLines[i]
+			if invalid || ignored || image || rect || newLines[i] == oldLines[i] {
+				continue
+			}
+			if d, ok := lineDamage(r.paintLine(oldLines[i]), r.paintLine(newLines[i])); ok {
+				if damaged == nil {
+					damaged = make(map[int]damagedLine)
+				}
+				damaged[i] = d
+			}
+		}
+	}
+
 	// Clear any lines we painted in the last render.
 	if r.linesRendered > 0 {
 		for i := r.linesRendered - 1; i > 0; i-- {
 			// If the number of lines we want to render hasn't increased and
 			// new line is the same as the old line we can skip rendering for
 			// this line as a performance optimization.
-			if (len(newLines) <= len(oldLines)) && (len(newLines) > i && len(oldLines) > i) && (newLines[i] == oldLines[i]) {
+			if _, ok := rectLines[i]; ok {
 				skipLines[i] = struct{}{}
-			} else if _, exists := r.ignoreLines[i]; !exists {
-				out.ClearLine()
+			} else if _, invalid := r.invalidLines[i]; !invalid && (len(newLines) <= len(oldLines)) && (len(newLines) > i && len(oldLines) > i) && (newLines[i] == oldLines[i]) {
+				skipLines[i] = struct{}{}
+			} else if _, exists := r.ignoreLines[i]; !exists || i >= len(newLines) {
+				// Lines past the end of the frame are cleared, ignored or
+				// not, so none are left behind when the view shrinks.
+				_, image := imageLines[i]
+				_, partial := damaged[i]
+				if !image && !partial {
+					out.ClearLine()
+				}
 			}
 
 			out.CursorUp(1)
 		}
 
-		if _, exists := r.ignoreLines[0]; !exists {
+		_, handled := rectLines[0]
+		if _, image := imageLines[0]; image {
+			handled = true
+		}
+		if _, partial := damaged[0]; partial {
+			handled = true
+		}
+		if _, exists := r.ignoreLines[0]; !exists && !handled {
 			// We need to return to the start of the line here to properly
 			// erase it. Going back the entire width of the terminal will
 			// usually be farther than we need to go, but terminal emulators
@@ -194,7 +516,14 @@ func (r *standardRenderer) flush() {
 			out.CursorBack(r.width)
 			out.ClearLine()
 		}
+
+		// Erase the rows that lines rewrapped by the terminal spilled
+		// onto, see resize.
+		if r.clearBelow {
+			_, _ = out.WriteString("\x1b[J")
+		}
 	}
+	r.clearBelow = false
 
 	// Merge the set of lines we're skipping as a rendering optimization with
 	// the set of lines we've explicitly asked the renderer to ignore.
@@ -203,6 +532,20 @@ func (r *standardRenderer) flush() {
 			skipLines[k] = v
 		}
 	}
+	if _, ok := rectLines[0]; ok {
+		skipLines[0] = struct{}{}
+	}
+
+	// Keep track of the lines we paint for the frame hook.
+	var painted []bool
+	if r.frameHook != nil {
+		painted = make([]bool, len(newLines))
+		for i := range rectLines {
+			if i < len(painted) {
+				painted[i] = true
+			}
+		}
+	}
 
 	// Paint new lines
 	for i := 0; i < len(newLines); i++ {
@@ -211,21 +554,23 @@ func (r *standardRenderer) flush() {
 			if i < len(newLines)-1 {
 				out.CursorDown(1)
 			}
-		} else {
-			line := newLines[i]
-
-			// Truncate lines wider than the width of the window to avoid
-			// wrapping, which will mess up rendering. If we don't have the
-			// width of the window this will be ignored.
-			//
-			// Note that on Windows we only get the width of the window on
-			// program initialization, so after a resize this won't perform
-			// correctly (signal SIGWINCH is not supported on Windows).
-			if r.width > 0 {
-				line = truncate.String(line, uint(r.width))
+		} else if d, partial := damaged[i]; partial {
+			if d.col > 0 {
+				out.CursorForward(d.col)
+			}
+			_, _ = out.WriteString(d.span)
+			if painted != nil {
+				painted[i] = true
 			}
 
-			_, _ = out.WriteString(line)
+			if i < len(newLines)-1 {
+				_, _ = out.WriteString("\r\n")
+			}
+		} else {
+			_, _ = out.WriteString(r.paintLine(newLines[i]))
+			if painted != nil {
+				painted[i] = true
+			}
 
 			if i < len(newLines)-1 {
 				_, _ = out.WriteString("\r\n")
@@ -233,6 +578,7 @@ func (r *standardRenderer) flush() {
 		}
 	}
 	r.linesRendered = numLinesThisFlush
+	r.renderedLines = renderedLines
 
 	// Make sure the cursor is at the start of the last line to keep rendering
 	// behavior consistent.
@@ -245,9 +591,138 @@ func (r *standardRenderer) flush() {
 		out.CursorBack(r.width)
 	}
 
+	r.drawImages(out)
+	r.placeCursor(out)
+
+	// Have the terminal hold off on showing the frame until it's complete,
+	// so large repaints don't tear.
+	if r.syncOutput {
+		frame := append([]byte(beginSynchronizedUpdate), buf.Bytes()...)
+		buf.Reset()
+		_, _ = buf.Write(frame)
+		_, _ = buf.WriteString(endSynchronizedUpdate)
+	}
+
 	_, _ = r.out.Write(buf.Bytes())
+	r.spend(buf.Len(), start)
 	r.lastRender = r.buf.String()
 	r.buf.Reset()
+	r.invalidLines = nil
+	elapsed := time.Since(start)
+	r.stats.add(elapsed, buf.Len())
+	r.totals.add(elapsed, buf.Len())
+
+	if r.frameHook != nil {
+		r.frameHook(FrameDiff{
+			Time:    time.Now(),
+			Changed: changedRegions(painted, newLines, r.width),
+			Lines:   len(newLines),
+			Output:  buf.Bytes(),
+		})
+	}
+}
+
+// paintLine prepares a line for painting.
+func (r *standardRenderer) paintLine(line string) string {
+	// Truncate lines wider than the width of the window to avoid wrapping,
+	// which will mess up rendering. If we don't have the width of the window
+	// this will be ignored.
+	//
+	// Note that on Windows we only get the width of the window on program
+	// initialization, so after a resize this won't perform correctly (signal
+	// SIGWINCH is not supported on Windows).
+	if r.width > 0 {
+		line = Truncate(line, r.width, "")
+	}
+	return downgradeColors(filterAttrs(line, r.unsupportedAttrs), r.colorProfile)
+}
+
+// minRectLines is the minimum number of lines a rectangular area operation
+// must cover to be worth using over rewriting the lines.
+const minRectLines = 3
+
+// rectUpdate updates lines that have moved or have been blanked using the
+// rectangular area operations DECCRA and DECFRA. It returns the set of lines
+// that are up to date as a result and don't need to be painted.
+//
+// This must be called before any of the old lines have been cleared, as
+// DECCRA copies from what's currently on the screen.
+func (r *standardRenderer) rectUpdate(out *termenv.Output, oldLines, newLines []string) map[int]struct{} {
+	handled := make(map[int]struct{})
+
+	// Copy a moved block of lines to its new position. Note that screen
+	// coordinates are 1-based.
+	if dst, src, n, ok := findMovedLines(oldLines, newLines); ok {
+		fmt.Fprintf(out, "\x1b[%d;1;%d;%d;1;%d;1;1$v", src+1, src+n, r.width, dst+1)
+		for i := dst; i < dst+n; i++ {
+			handled[i] = struct{}{}
+		}
+	}
+
+	// Fill runs of lines which have become blank with spaces.
+	for _, run := range findBlankedLines(oldLines, newLines, handled) {
+		fmt.Fprintf(out, "\x1b[32;%d;1;%d;%d$x", run[0]+1, run[1], r.width)
+		for i := run[0]; i < run[1]; i++ {
+			handled[i] = struct{}{}
+		}
+	}
+
+	return handled
+}
+
+// findMovedLines looks for the longest block of lines in newLines that
+// appears at a different position in oldLines. It returns the position of the
+// block in newLines, its position in oldLines and its length. Blocks shorter
+// than minRectLines, or that contain only blank lines, are ignored.
+func findMovedLines(oldLines, newLines []string) (dst, src, n int, ok bool) {
+	for d := 1 - len(oldLines); d < len(newLines); d++ {
+		if d == 0 {
+			continue
+		}
+
+		// Walk the diagonal where newLines[i] is compared to
+		// oldLines[i-d], tracking runs of equal lines.
+		start, blank := -1, true
+		for i := 0; i <= len(newLines); i++ {
+			j := i - d
+			if i < len(newLines) && j >= 0 && j < len(oldLines) && newLines[i] == oldLines[j] {
+				if start < 0 {
+					start, blank = i, true
+				}
+				if newLines[i] != "" {
+					blank = false
+				}
+				continue
+			}
+			if start >= 0 && !blank && i-start >= minRectLines && i-start > n {
+				dst, src, n, ok = start, start-d, i-start, true
+			}
+			start = -1
+		}
+	}
+	return dst, src, n, ok
+}
+
+// findBlankedLines returns runs of lines, as [start, end) pairs, which are
+// blank in newLines but weren't in oldLines. Lines in the skip set are not
+// considered. Runs shorter than minRectLines are ignored.
+func findBlankedLines(oldLines, newLines []string, skip map[int]struct{}) [][2]int {
+	var runs [][2]int
+	start := -1
+	for i := 0; i <= len(newLines); i++ {
+		_, skipped := skip[i]
+		if i < len(newLines) && i < len(oldLines) && !skipped && newLines[i] == "" && oldLines[i] != "" {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 && i-start >= minRectLines {
+			runs = append(runs, [2]int{start, i})
+		}
+		start = -1
+	}
+	return runs
 }
 
 // write writes to the internal buffer. The buffer will be outputted via the
@@ -255,8 +730,25 @@ func (r *standardRenderer) flush() {
 func (r *standardRenderer) write(s string) {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
-	r.buf.Reset()
 
+	r.writeLocked(s)
+}
+
+// writeWithCursor writes a view like write, along with where the model wants
+// the cursor in it.
+func (r *standardRenderer) writeWithCursor(s string, c cursorPlacement) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if c != r.cursorWanted {
+		r.cursorWanted = c
+		r.cursorMoved = true
+	}
+	r.writeLocked(s)
+}
+
+// writeLocked writes a view to the buffer. The mutex must be held.
+func (r *standardRenderer) writeLocked(s string) {
 	// If an empty string was passed we should clear existing output and
 	// rendering nothing. Rather than introduce additional state to manage
 	// this, we render a single space as a simple (albeit less correct)
@@ -265,11 +757,115 @@ func (r *standardRenderer) write(s string) {
 		s = " "
 	}
 
+	// A view that's still waiting to be rendered is being replaced.
+	if r.buf.Len() > 0 && r.buf.String() != r.lastRender && r.buf.String() != s {
+		r.stats.dropped++
+		r.totals.dropped++
+	}
+	r.buf.Reset()
+
+	r.view = s
+	s = r.holdRegions(s, time.Now())
 	_, _ = r.buf.WriteString(s)
+
+	// Wake up the renderer if there's anything to draw.
+	if r.onChange && (s != r.lastRender || len(r.invalidLines) > 0 || r.cursorMoved) {
+		select {
+		case r.changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// resize sets the renderer's dimensions and repaints.
+func (r *standardRenderer) resize(width, height int) {
+	if !r.altScreenActive {
+		r.rewrap(r.width, width, height)
+	}
+
+	r.width = width
+	r.height = height
+	r.repaint()
+}
+
+// rewrap accounts for the terminal rewrapping the lines rendered inline when
+// the window narrows from oldWidth to width. Terminals which reflow their
+// contents rewrap them so that they take up more rows than we rendered, and
+// the cursor ends up on the first of the rows the last line takes up. Count
+// the rows above it, so that the next frame goes back up to the top of the
+// output, and have it erase everything below from there, rewrapped rows
+// included.
+func (r *standardRenderer) rewrap(oldWidth, width, height int) {
+	if !r.reflows || len(r.renderedLines) == 0 || width <= 0 || (oldWidth > 0 && width >= oldWidth) {
+		return
+	}
+
+	rows := 1
+	for _, line := range r.renderedLines[:len(r.renderedLines)-1] {
+		if oldWidth > 0 {
+			line = Truncate(line, oldWidth, "")
+		}
+		if w := StringWidth(line); w > width {
+			rows += (w + width - 1) / width
+		} else {
+			rows++
+		}
+	}
+	if height > 0 && rows > height {
+		rows = height
+	}
+	r.linesRendered = rows
+	r.clearBelow = true
 }
 
 func (r *standardRenderer) repaint() {
 	r.lastRender = ""
+	for _, img := range r.images {
+		img.drawn = false
+	}
+	for _, rg := range r.regions {
+		rg.next = time.Time{}
+	}
+}
+
+// resetTerminal resets the terminal to its initial state, re-applies the modes
+// the renderer has set, and repaints.
+func (r *standardRenderer) resetTerminal() {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	// DECSTR (soft reset) followed by RIS (hard reset). Terminals which
+	// don't implement RIS fully will at least have performed the former.
+	_, _ = io.WriteString(r.out, "\x1b[!p\x1bc")
+
+	if r.altScreenActive {
+		r.out.AltScreen()
+		if r.altScroll {
+			_, _ = io.WriteString(r.out, enableAltScroll)
+		}
+	}
+	r.out.ClearScreen()
+	r.out.MoveCursor(1, 1)
+
+	if r.cursorHidden {
+		r.out.HideCursor()
+	}
+	if r.cursorShape != CursorDefault {
+		_, _ = io.WriteString(r.out, cursorShapeSeq(r.cursorShape))
+	}
+	if r.mouseCellMotion {
+		r.out.EnableMouseCellMotion()
+	}
+	if r.mouseAllMotion {
+		r.out.EnableMouseAllMotion()
+	}
+	if r.bpActive {
+		r.out.EnableBracketedPaste()
+	}
+
+	r.linesRendered = 0
+	r.repaint()
+	r.cursorPlaced = false
 }
 
 func (r *standardRenderer) clearScreen() {
@@ -280,6 +876,7 @@ func (r *standardRenderer) clearScreen() {
 	r.out.MoveCursor(1, 1)
 
 	r.repaint()
+	r.cursorPlaced = false
 }
 
 func (r *standardRenderer) altScreen() bool {
@@ -297,9 +894,18 @@ func (r *standardRenderer) enterAltScreen() {
 		return
 	}
 
+	r.unplaceCursor(r.out)
 	r.altScreenActive = true
 	r.out.AltScreen()
 
+	// The output is left as it is outside the altscreen, to be picked up
+	// again on return. The altscreen starts out empty.
+	r.inlineLinesRendered, r.inlineLines, r.inlineWidth = r.linesRendered, r.renderedLines, r.width
+	r.linesRendered, r.renderedLines = 0, nil
+	if r.altScroll {
+		_, _ = io.WriteString(r.out, enableAltScroll)
+	}
+
 	// Ensure that the terminal is cleared, even when it doesn't support
 	// alt screen (or alt screen support is disabled, like GNU screen by
 	// default).
@@ -330,7 +936,33 @@ func (r *standardRenderer) exitAltScreen() {
 	}
 
 	r.altScreenActive = false
+	if r.altScroll {
+		_, _ = io.WriteString(r.out, disableAltScroll)
+	}
 	r.out.ExitAltScreen()
+	r.cursorPlaced = false
+
+	// The cursor is back where it was before the altscreen was entered, at
+	// the start of the last line rendered then, so the next frame paints
+	// over that output rather than what was rendered in the altscreen. The
+	// window may have narrowed in the meantime.
+	r.linesRendered, r.renderedLines = r.inlineLinesRendered, r.inlineLines
+	r.inlineLinesRendered, r.inlineLines = 0, nil
+	r.rewrap(r.inlineWidth, r.width, r.height)
+
+	// Lines printed in the altscreen were held back, and are printed above
+	// the output with the next frame. If there won't be one, as when the
+	// program exits, they're printed below the output right away.
+	if !r.running && len(r.queuedMessageLines) > 0 {
+		if r.linesRendered > 0 {
+			_, _ = io.WriteString(r.out, "\r\n")
+		}
+		for _, l := range r.queuedMessageLines {
+			_, _ = io.WriteString(r.out, l+"\r\n")
+		}
+		r.queuedMessageLines = []string{}
+		r.linesRendered, r.renderedLines = 0, nil
+	}
 
 	// cmd.exe and other terminals keep separate cursor states for the AltScreen
 	// and the main buffer. We have to explicitly reset the cursor visibility
@@ -360,11 +992,42 @@ func (r *standardRenderer) hideCursor() {
 	r.out.HideCursor()
 }
 
+func (r *standardRenderer) setCursorShape(shape CursorShape) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.cursorShape = shape
+	_, _ = io.WriteString(r.out, cursorShapeSeq(shape))
+}
+
+func (r *standardRenderer) cursor() cursorState {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	return cursorState{hidden: r.cursorHidden, shape: r.cursorShape, placement: r.cursorWanted}
+}
+
+// redraw draws a frame right away, with the cursor placed in it, whatever
+// was rendered before. It's used to draw the last frame again when the
+// terminal is restored, rather than leave the cursor out of place until the
+// model's view is rendered.
+func (r *standardRenderer) redraw(frame string, c cursorPlacement) {
+	r.mtx.Lock()
+	r.repaint()
+	r.mtx.Unlock()
+
+	r.writeWithCursor(frame, c)
+
+	// flush locks the mutex
+	r.flush()
+}
+
 func (r *standardRenderer) enableMouseCellMotion() {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
 	r.out.EnableMouseCellMotion()
+	r.mouseCellMotion = true
 }
 
 func (r *standardRenderer) disableMouseCellMotion() {
@@ -372,6 +1035,7 @@ func (r *standardRenderer) disableMouseCellMotion() {
 	defer r.mtx.Unlock()
 
 	r.out.DisableMouseCellMotion()
+	r.mouseCellMotion = false
 }
 
 func (r *standardRenderer) enableMouseAllMotion() {
@@ -379,6 +1043,7 @@ func (r *standardRenderer) enableMouseAllMotion() {
 	defer r.mtx.Unlock()
 
 	r.out.EnableMouseAllMotion()
+	r.mouseAllMotion = true
 }
 
 func (r *standardRenderer) disableMouseAllMotion() {
@@ -386,6 +1051,106 @@ func (r *standardRenderer) disableMouseAllMotion() {
 	defer r.mtx.Unlock()
 
 	r.out.DisableMouseAllMotion()
+	r.mouseAllMotion = false
+}
+
+func (r *standardRenderer) enableBracketedPaste() {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.out.EnableBracketedPaste()
+	r.bpActive = true
+}
+
+func (r *standardRenderer) disableBracketedPaste() {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.out.DisableBracketedPaste()
+	r.bpActive = false
+}
+
+func (r *standardRenderer) setAlternateScroll(on bool) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.altScroll == on {
+		return
+	}
+	r.altScroll = on
+	if !r.altScreenActive {
+		return
+	}
+	if on {
+		_, _ = io.WriteString(r.out, enableAltScroll)
+	} else {
+		_, _ = io.WriteString(r.out, disableAltScroll)
+	}
+}
+
+func (r *standardRenderer) bracketedPasteActive() bool {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	return r.bpActive
+}
+
+// execute writes a sequence to the terminal immediately, bypassing the frame
+// buffer.
+func (r *standardRenderer) execute(seq string) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	_, err := io.WriteString(r.out, seq)
+	return err
+}
+
+// takeFrameStats returns the performance statistics gathered since they were
+// last taken, and starts over.
+func (r *standardRenderer) takeFrameStats() frameStats {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	s := r.stats
+	r.stats = frameStats{}
+	return s
+}
+
+// setUnsupportedAttrs sets the text attributes to remove from the output.
+func (r *standardRenderer) setUnsupportedAttrs(attrs textAttrs) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.unsupportedAttrs = attrs
+}
+
+// setRectangularOps sets whether the terminal supports rectangular area
+// operations.
+func (r *standardRenderer) setRectangularOps(enabled bool) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.rectOps = enabled
+}
+
+// setColorProfile sets the colors the terminal supports.
+func (r *standardRenderer) setColorProfile(profile termenv.Profile) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if profile != r.colorProfile {
+		r.colorProfile = profile
+		r.repaint()
+	}
+}
+
+// setSynchronizedOutput sets whether frames are wrapped in synchronized
+// updates.
+func (r *standardRenderer) setSynchronizedOutput(enabled bool) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.syncOutput = enabled
 }
 
 // setIgnoredLines specifies lines not to be touched by the standard Bubble Tea
@@ -405,18 +1170,24 @@ func (r *standardRenderer) setIgnoredLines(from int, to int) {
 		r.ignoreLines[i] = struct{}{}
 	}
 
-	// Erase ignored lines
+	// Erase ignored lines, moving the cursor relative to where it is, at the
+	// start of the last line rendered, so that this works inline too.
 	if r.linesRendered > 0 {
 		buf := &bytes.Buffer{}
 		out := termenv.NewOutput(buf)
+		r.parkCursor(out)
 
 		for i := r.linesRendered - 1; i >= 0; i-- {
 			if _, exists := r.ignoreLines[i]; exists {
 				out.ClearLine()
 			}
-			out.CursorUp(1)
+			if i > 0 {
+				out.CursorUp(1)
+			}
+		}
+		if r.linesRendered > 1 {
+			out.CursorDown(r.linesRendered - 1) // put cursor back
 		}
-		out.MoveCursor(r.linesRendered, 0) // put cursor back
 		_, _ = r.out.Write(buf.Bytes())
 	}
 }
@@ -437,10 +1208,6 @@ func (r *standardRenderer) clearIgnoredLines() {
 // For this to work renderer.ignoreLines must be set to ignore the scrollable
 // region since we are bypassing the normal Bubble Tea renderer here.
 //
-// Because this method relies on the terminal dimensions, it's only valid for
-// full-window applications (generally those that use the alternate screen
-// buffer).
-//
 // This method bypasses the normal rendering buffer and is philosophically
 // different than the normal way we approach rendering in Bubble Tea. It's for
 // use in high-performance rendering, such as a pager that could potentially
@@ -450,19 +1217,25 @@ func (r *standardRenderer) insertTop(lines []string, topBoundary, bottomBoundary
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
+	top, bottom, ok := r.scrollArea(topBoundary, bottomBoundary)
+	if !ok || len(lines) == 0 {
+		return
+	}
+	// Lines which would be pushed out of the region right away are left out.
+	if len(lines) > bottom-top {
+		lines = lines[:bottom-top]
+	}
+
 	buf := &bytes.Buffer{}
 	out := termenv.NewOutput(buf)
+	r.parkCursor(out)
 
-	out.ChangeScrollingRegion(topBoundary, bottomBoundary)
-	out.MoveCursor(topBoundary, 0)
+	// Note that screen coordinates are 1-based.
+	out.ChangeScrollingRegion(top+1, bottom)
+	out.MoveCursor(top+1, 1)
 	out.InsertLines(len(lines))
-	_, _ = out.WriteString(strings.Join(lines, "\r\n"))
-	out.ChangeScrollingRegion(0, r.height)
-
-	// Move cursor back to where the main rendering routine expects it to be
-	out.MoveCursor(r.linesRendered, 0)
-
-	_, _ = r.out.Write(buf.Bytes())
+	_, _ = out.WriteString(r.paintLines(lines))
+	r.writeScroll(buf, out)
 }
 
 // insertBottom effectively scrolls down. It inserts lines at the bottom of
@@ -471,25 +1244,71 @@ func (r *standardRenderer) insertTop(lines []string, topBoundary, bottomBoundary
 //
 // To call this function use the command ScrollDown().
 //
-// See note in insertTop() for caveats, how this function only makes sense for
-// full-window applications, and how it differs from the normal way we do
-// rendering in Bubble Tea.
+// See note in insertTop() for caveats and how it differs from the normal way
+// we do rendering in Bubble Tea.
 func (r *standardRenderer) insertBottom(lines []string, topBoundary, bottomBoundary int) {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
+	top, bottom, ok := r.scrollArea(topBoundary, bottomBoundary)
+	if !ok || len(lines) == 0 {
+		return
+	}
+	if len(lines) > bottom-top {
+		lines = lines[len(lines)-(bottom-top):]
+	}
+
 	buf := &bytes.Buffer{}
 	out := termenv.NewOutput(buf)
+	r.parkCursor(out)
 
-	out.ChangeScrollingRegion(topBoundary, bottomBoundary)
-	out.MoveCursor(bottomBoundary, 0)
-	_, _ = out.WriteString("\r\n" + strings.Join(lines, "\r\n"))
-	out.ChangeScrollingRegion(0, r.height)
+	out.ChangeScrollingRegion(top+1, bottom)
+	out.MoveCursor(bottom, 1)
+	_, _ = out.WriteString("\r\n" + r.paintLines(lines))
+	r.writeScroll(buf, out)
+}
+
+// scrollArea clamps a scrollable region, given as zero-based lines from top
+// up to, but not including, bottom, to the window. It reports whether the
+// region can be scrolled, which it can't if it's empty or if we don't know
+// where the top of the output is on the screen: scrolling regions are set in
+// screen coordinates, which match the output's only in the altscreen or when
+// the output fills the window.
+func (r *standardRenderer) scrollArea(top, bottom int) (int, int, bool) {
+	if !r.altScreenActive && (r.height <= 0 || r.linesRendered < r.height) {
+		return 0, 0, false
+	}
+	if top < 0 {
+		top = 0
+	}
+	if r.height > 0 && bottom > r.height {
+		bottom = r.height
+	}
+	return top, bottom, top < bottom
+}
 
-	// Move cursor back to where the main rendering routine expects it to be
+// paintLines prepares lines written to a scrollable region for painting, just
+// like the lines of the view, and joins them.
+func (r *standardRenderer) paintLines(lines []string) string {
+	painted := make([]string, len(lines))
+	for i, line := range closeHyperlinks(lines) {
+		painted[i] = r.paintLine(line)
+	}
+	return strings.Join(painted, "\r\n")
+}
+
+// writeScroll resets the scrolling region, moves the cursor back to where the
+// main rendering routine expects it to be and writes out a scroll, as one
+// synchronized update if the terminal supports it.
+func (r *standardRenderer) writeScroll(buf *bytes.Buffer, out *termenv.Output) {
+	out.ChangeScrollingRegion(0, r.height)
 	out.MoveCursor(r.linesRendered, 0)
 
-	_, _ = r.out.Write(buf.Bytes())
+	b := buf.Bytes()
+	if r.syncOutput {
+		b = append(append([]byte(beginSynchronizedUpdate), b...), endSynchronizedUpdate...)
+	}
+	_, _ = r.out.Write(b)
 }
 
 // handleMessages handles internal messages for the renderer.
@@ -504,11 +1323,37 @@ func (r *standardRenderer) handleMessages(msg Msg) {
 
 	case WindowSizeMsg:
 		r.mtx.Lock()
-		r.width = msg.Width
-		r.height = msg.Height
-		r.repaint()
+		r.resize(msg.Width, msg.Height)
 		r.mtx.Unlock()
 
+	case invalidateLinesMsg:
+		r.mtx.Lock()
+		if r.invalidLines == nil {
+			r.invalidLines = make(map[int]struct{})
+		}
+		for i := msg.from; i < msg.to; i++ {
+			r.invalidLines[i] = struct{}{}
+		}
+		r.mtx.Unlock()
+
+	case ignoreLinesMsg:
+		r.setIgnoredLines(msg.from, msg.to)
+
+	case placeImageMsg:
+		r.placeImage(Image(msg))
+
+	case removeImageMsg:
+		r.removeImages(func(img *placedImage) bool { return img.ID == string(msg) })
+
+	case clearImagesMsg:
+		r.removeImages(func(*placedImage) bool { return true })
+
+	case createRegionMsg:
+		r.createRegion(Region(msg))
+
+	case destroyRegionMsg:
+		r.destroyRegion(string(msg))
+
 	case clearScrollAreaMsg:
 		r.clearIgnoredLines()
 
@@ -536,18 +1381,61 @@ func (r *standardRenderer) handleMessages(msg Msg) {
 		r.insertBottom(msg.lines, msg.topBoundary, msg.bottomBoundary)
 
 	case printLineMessage:
+		// In the altscreen, the lines are held back until it's exited.
+		lines := strings.Split(msg.messageBody, "\n")
+		r.mtx.Lock()
+		r.queuedMessageLines = append(r.queuedMessageLines, lines...)
 		if !r.altScreenActive {
-			lines := strings.Split(msg.messageBody, "\n")
-			r.mtx.Lock()
-			r.queuedMessageLines = append(r.queuedMessageLines, lines...)
 			r.repaint()
-			r.mtx.Unlock()
 		}
+		r.mtx.Unlock()
 	}
 }
 
 // HIGH-PERFORMANCE RENDERING STUFF
 
+type invalidateLinesMsg struct {
+	from, to int
+}
+
+// InvalidateLines makes the renderer repaint the lines of the view from
+// line from up to, but not including, line to on the next frame, even if
+// they haven't changed. Line numbers are zero-based, counted from the top of
+// the view.
+//
+// This is cheaper than Repaint for components which know which part of the
+// screen they've disturbed, such as embedded terminals or plotting widgets
+// which draw to the terminal directly.
+func InvalidateLines(from, to int) Cmd {
+	return func() Msg {
+		return invalidateLinesMsg{from: from, to: to}
+	}
+}
+
+type ignoreLinesMsg struct {
+	from, to int
+}
+
+// IgnoreLines hands the lines of the view from line from up to, but not
+// including, line to over to the program: the renderer erases them once and
+// then leaves them alone, so that a component can draw them itself. Line
+// numbers are zero-based, counted from the top of the view. Return the lines
+// to the renderer with ClearIgnoredLines.
+//
+// Like the scrollable region of SyncScrollArea, which is built on the same
+// mechanism, this is meant for full-window programs.
+func IgnoreLines(from, to int) Cmd {
+	return func() Msg {
+		return ignoreLinesMsg{from: from, to: to}
+	}
+}
+
+// ClearIgnoredLines returns the lines set aside with IgnoreLines or
+// SyncScrollArea to the renderer, which repaints them on the next frame.
+func ClearIgnoredLines() Msg {
+	return clearScrollAreaMsg{}
+}
+
 type syncScrollAreaMsg struct {
 	lines          []string
 	topBoundary    int
@@ -558,6 +1446,22 @@ type syncScrollAreaMsg struct {
 // scrollable area. This is required to initialize the scrollable region and
 // should also be called on resize (WindowSizeMsg).
 //
+// The region covers the lines of the view from line topBoundary up to, but
+// not including, line bottomBoundary. Line numbers are zero-based, counted
+// from the top of the view, as for IgnoreLines. The renderer leaves these
+// lines of the view alone until the region is cleared with ClearScrollArea,
+// so the view should leave them blank, and keeps painting the rest of the
+// view around them as usual. Scrolling the region with ScrollUp and
+// ScrollDown then has the terminal move its lines, rather than repainting
+// them, which is much cheaper for the likes of chat logs and tail-style
+// panes.
+//
+// Lines written to the region are prepared like those of the view: they're
+// cut to the width of the window and their colors and attributes are reduced
+// to those the terminal supports. Regions only scroll in the altscreen or
+// when the view fills the window, as otherwise we don't know where the view
+// is on the screen; elsewhere the region is left blank.
+//
 // For high-performance, scroll-based rendering only.
 func SyncScrollArea(lines []string, topBoundary int, bottomBoundary int) Cmd {
 	return func() Msg {
@@ -572,7 +1476,8 @@ func SyncScrollArea(lines []string, topBoundary int, bottomBoundary int) Cmd {
 type clearScrollAreaMsg struct{}
 
 // ClearScrollArea deallocates the scrollable region and returns the control of
-// those lines to the main rendering routine.
+// those lines to the main rendering routine, which repaints them on the next
+// frame.
 //
 // For high-performance, scroll-based rendering only.
 func ClearScrollArea() Msg {
@@ -587,7 +1492,7 @@ type scrollUpMsg struct {
 
 // ScrollUp adds lines to the top of the scrollable region, pushing existing
 // lines below down. Lines that are pushed out the scrollable region disappear
-// from view.
+// from view. The region is given as to SyncScrollArea.
 //
 // For high-performance, scroll-based rendering only.
 func ScrollUp(newLines []string, topBoundary, bottomBoundary int) Cmd {
@@ -608,7 +1513,7 @@ type scrollDownMsg struct {
 
 // ScrollDown adds lines to the bottom of the scrollable region, pushing
 // existing lines above up. Lines that are pushed out of the scrollable region
-// disappear from view.
+// disappear from view. The region is given as to SyncScrollArea.
 //
 // For high-performance, scroll-based rendering only.
 func ScrollDown(newLines []string, topBoundary, bottomBoundary int) Cmd {
@@ -623,6 +1528,10 @@ func ScrollDown(newLines []string, topBoundary, bottomBoundary int) Cmd {
 
 type printLineMessage struct {
 	messageBody string
+
+	// used by the log console, see WithLogConsole
+	level LogLevel
+	time  time.Time
 }
 
 // Println prints above the Program. This output is unmanaged by the program and
@@ -631,11 +1540,14 @@ type printLineMessage struct {
 // Unlike fmt.Println (but similar to log.Println) the message will be print on
 // its own line.
 //
-// If the altscreen is active no output will be printed.
+// If the altscreen is active, the output is held back and printed once the
+// altscreen is exited, or when the program exits.
 func Println(args ...interface{}) Cmd {
 	return func() Msg {
 		return printLineMessage{
 			messageBody: fmt.Sprint(args...),
+			level:       LogInfo,
+			time:        time.Now(),
 		}
 	}
 }
@@ -647,11 +1559,14 @@ func Println(args ...interface{}) Cmd {
 // Unlike fmt.Printf (but similar to log.Printf) the message will be print on
 // its own line.
 //
-// If the altscreen is active no output will be printed.
+// If the altscreen is active, the output is held back and printed once the
+// altscreen is exited, or when the program exits.
 func Printf(template string, args ...interface{}) Cmd {
 	return func() Msg {
 		return printLineMessage{
 			messageBody: fmt.Sprintf(template, args...),
+			level:       LogInfo,
+			time:        time.Now(),
 		}
 	}
 }
@@ -0,0 +1,484 @@
+package tea
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muesli/termenv"
+)
+
+func TestFindMovedLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new []string
+		dst, src int
+		n        int
+		ok       bool
+	}{
+		{
+			name: "unchanged",
+			old:  []string{"a", "b", "c", "d"},
+			new:  []string{"a", "b", "c", "d"},
+		},
+		{
+			name: "scrolled up",
+			old:  []string{"a", "b", "c", "d", "e"},
+			new:  []string{"b", "c", "d", "e", "f"},
+			dst:  0, src: 1, n: 4, ok: true,
+		},
+		{
+			name: "pane moved down",
+			old:  []string{"x", "a", "b", "c", "", ""},
+			new:  []string{"x", "", "", "a", "b", "c"},
+			dst:  3, src: 1, n: 3, ok: true,
+		},
+		{
+			name: "block too small",
+			old:  []string{"a", "b", "c"},
+			new:  []string{"b", "c", "d"},
+		},
+		{
+			name: "blank block",
+			old:  []string{"", "", "", "a"},
+			new:  []string{"a", "", "", ""},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dst, src, n, ok := findMovedLines(test.old, test.new)
+			if ok != test.ok {
+				t.Fatalf("expected ok to be %t, got %t", test.ok, ok)
+			}
+			if dst != test.dst || src != test.src || n != test.n {
+				t.Errorf("expected %d lines from %d to %d, got %d lines from %d to %d",
+					test.n, test.src, test.dst, n, src, dst)
+			}
+		})
+	}
+}
+
+func TestFindBlankedLines(t *testing.T) {
+	old := []string{"a", "b", "c", "d", "e", "f", "g"}
+	new := []string{"a", "", "", "", "e", "", ""}
+
+	runs := findBlankedLines(old, new, nil)
+	if len(runs) != 1 || runs[0] != [2]int{1, 4} {
+		t.Errorf("expected a single run of [1, 4), got %v", runs)
+	}
+
+	runs = findBlankedLines(old, new, map[int]struct{}{2: {}})
+	if len(runs) != 0 {
+		t.Errorf("expected no runs, got %v", runs)
+	}
+}
+
+func TestRendererRectangularOps(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRenderer(termenv.NewOutput(&buf), false).(*standardRenderer)
+	r.rectOps = true
+	r.altScreenActive = true
+	r.width, r.height = 10, 10
+
+	r.write("a\nb\nc\nd\ne")
+	r.flush()
+	buf.Reset()
+
+	r.write("b\nc\nd\ne\nf")
+	r.flush()
+
+	out := buf.String()
+	if !strings.Contains(out, "\x1b[2;1;5;10;1;1;1;1$v") {
+		t.Errorf("expected a rectangular copy, got %q", out)
+	}
+	if strings.Count(out, "\x1b[2K") != 1 {
+		t.Errorf("expected only a single line to be cleared, got %q", out)
+	}
+	if strings.Contains(out, "b") || !strings.Contains(out, "f") {
+		t.Errorf("expected only the new line to be painted, got %q", out)
+	}
+}
+
+func TestRendererSynchronizedOutput(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRenderer(termenv.NewOutput(&buf), false).(*standardRenderer)
+	r.setSynchronizedOutput(true)
+
+	r.write("a\nb")
+	r.flush()
+	out := buf.String()
+	if !strings.HasPrefix(out, beginSynchronizedUpdate) || !strings.HasSuffix(out, endSynchronizedUpdate) ||
+		!strings.Contains(out, "a\r\nb") {
+		t.Errorf("expected the frame to be a synchronized update, got %q", out)
+	}
+
+	buf.Reset()
+	r.setSynchronizedOutput(false)
+	r.write("c\nd")
+	r.flush()
+	if out := buf.String(); strings.Contains(out, beginSynchronizedUpdate) || strings.Contains(out, endSynchronizedUpdate) {
+		t.Errorf("expected an unsynchronized frame, got %q", out)
+	}
+}
+
+func TestRendererFrameHook(t *testing.T) {
+	var buf bytes.Buffer
+	var frames []FrameDiff
+	r := newRenderer(termenv.NewOutput(&buf), false).(*standardRenderer)
+	r.width = 10
+	r.frameHook = func(f FrameDiff) {
+		frames = append(frames, f)
+	}
+
+	r.write("a\nb\nc\nd")
+	r.flush()
+	r.write("a\nx\ny\nd")
+	r.flush()
+	r.write("a\nx\ny\nd")
+	r.flush()
+
+	if len(frames) != 2 {
+		t.Fatalf("expected 2 frames, got %d", len(frames))
+	}
+	if got := frames[0].Changed; !reflect.DeepEqual(got, []Rect{{Width: 10, Height: 4}}) {
+		t.Errorf("expected the whole first frame to change, got %+v", got)
+	}
+	// The first line is always repainted.
+	if got := frames[1].Changed; !reflect.DeepEqual(got, []Rect{{Y: 0, Width: 10, Height: 3}}) {
+		t.Errorf("expected all but the last line to change, got %+v", got)
+	}
+	if frames[1].Lines != 4 || !bytes.HasSuffix(buf.Bytes(), frames[1].Output) {
+		t.Errorf("unexpected frame %+v", frames[1])
+	}
+}
+
+func TestRendererLineControls(t *testing.T) {
+	var buf bytes.Buffer
+	var frames []FrameDiff
+	r := newRenderer(termenv.NewOutput(&buf), false).(*standardRenderer)
+	r.width = 10
+	r.frameHook = func(f FrameDiff) {
+		frames = append(frames, f)
+	}
+
+	r.write("a\nb\nc\nd")
+	r.flush()
+
+	// Invalidated lines are repainted even though nothing has changed.
+	r.handleMessages(InvalidateLines(2, 3)())
+	r.write("a\nb\nc\nd")
+	r.flush()
+
+	// Ignored lines aren't painted even though they have changed.
+	r.handleMessages(IgnoreLines(1, 2)())
+	r.write("a\nx\nc\nd")
+	r.flush()
+
+	if len(frames) != 3 {
+		t.Fatalf("expected 3 frames, got %d", len(frames))
+	}
+	if got := frames[1].Changed; !reflect.DeepEqual(got, []Rect{{Y: 0, Width: 10, Height: 1}, {Y: 2, Width: 10, Height: 1}}) {
+		t.Errorf("expected the invalidated line to be repainted, got %+v", got)
+	}
+	if got := frames[2].Changed; !reflect.DeepEqual(got, []Rect{{Y: 0, Width: 10, Height: 1}}) {
+		t.Errorf("expected the ignored line to be left alone, got %+v", got)
+	}
+}
+
+func TestRendererScrollArea(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRenderer(termenv.NewOutput(&buf), false).(*standardRenderer)
+	r.width, r.height = 4, 5
+	r.write("a\nb\nc")
+	r.flush()
+
+	// Inline output that doesn't fill the window isn't scrolled, as we don't
+	// know where it is on the screen.
+	buf.Reset()
+	r.handleMessages(ScrollDown([]string{"x"}, 1, 3)())
+	if buf.Len() != 0 {
+		t.Errorf("expected no output, got %q", buf.String())
+	}
+
+	r.altScreenActive = true
+	r.syncOutput = true
+	r.handleMessages(SyncScrollArea([]string{"one", "two"}, 1, 3)())
+	buf.Reset()
+	r.handleMessages(ScrollDown([]string{"lost", "\x1b[1mtoo wide", "last"}, 1, 3)())
+
+	// Lines are cut to the window, and the region, which is zero-based and
+	// excludes its bottom boundary, is set in 1-based screen coordinates.
+	expected := beginSynchronizedUpdate + "\x1b[2;3r\x1b[3;1H\r\n\x1b[1mtoo \r\nlast\x1b[0;5r\x1b[3;0H" + endSynchronizedUpdate
+	if got := buf.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	// The region is clamped to the window.
+	buf.Reset()
+	r.handleMessages(ScrollUp([]string{"x"}, -1, 10)())
+	if got := buf.String(); !strings.Contains(got, "\x1b[1;5r") {
+		t.Errorf("expected the region to be clamped to the window, got %q", got)
+	}
+}
+
+func TestChangedRegions(t *testing.T) {
+	lines := []string{"ab", "abcd", "a", "abc"}
+	painted := []bool{true, true, false, true}
+	expected := []Rect{{Y: 0, Width: 4, Height: 2}, {Y: 3, Width: 3, Height: 1}}
+	if got := changedRegions(painted, lines, 0); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestRendererFrameStats(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRenderer(termenv.NewOutput(&buf), false).(*standardRenderer)
+
+	r.write("a")
+	r.write("a") // unchanged, not dropped
+	r.write("b") // replaces "a" before it's rendered
+	r.flush()
+	r.write("b") // already rendered, not dropped
+	r.write("c")
+	r.flush()
+
+	s := r.takeFrameStats()
+	if s.frames != 2 || s.dropped != 1 || s.bytes != int64(buf.Len()) {
+		t.Errorf("expected 2 frames, 1 dropped and %d bytes, got %+v", buf.Len(), s)
+	}
+	if s := r.takeFrameStats(); s != (frameStats{}) {
+		t.Errorf("expected the stats to be reset, got %+v", s)
+	}
+
+	// The totals aren't reset.
+	p := &Program{renderer: r, queue: newMsgQueue(QueueLimits{})}
+	p.queue.msgs = []queuedMsg{{msg: incrementMsg{}}}
+	stats := p.Stats()
+	if stats.Frames != 2 || stats.Skipped != 1 || stats.Bytes != int64(buf.Len()) || stats.QueueDepth != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if stats.MaxRenderTime < stats.LastRenderTime || stats.MaxRenderTime < stats.AvgRenderTime {
+		t.Errorf("expected consistent render times, got %+v", stats)
+	}
+
+	msg := frameStats{frames: 2, dropped: 3, bytes: 7, total: 6, max: 5}.msg(time.Second)
+	expected := FramePerfMsg{Period: time.Second, Frames: 2, Dropped: 3, AvgRenderTime: 3, MaxRenderTime: 5, Bytes: 7}
+	if msg != expected {
+		t.Errorf("expected %+v, got %+v", expected, msg)
+	}
+}
+
+func TestRendererOnChange(t *testing.T) {
+	frames := make(chan FrameDiff, 10)
+	r := newRenderer(termenv.NewOutput(io.Discard), false).(*standardRenderer)
+	r.onChange = true
+	r.framerate = time.Millisecond
+	r.frameHook = func(f FrameDiff) { frames <- f }
+	r.start()
+	defer r.kill()
+
+	if r.ticker != nil {
+		t.Fatal("expected no ticker when drawing on change")
+	}
+
+	waitFrame := func() bool {
+		select {
+		case <-frames:
+			return true
+		case <-time.After(50 * time.Millisecond):
+			return false
+		}
+	}
+
+	r.write("a")
+	if !waitFrame() {
+		t.Fatal("expected a frame for the first view")
+	}
+	r.write("a")
+	if waitFrame() {
+		t.Error("expected no frame for an unchanged view")
+	}
+	r.write("b")
+	if !waitFrame() {
+		t.Error("expected a frame for a changed view")
+	}
+}
+
+func TestRendererInlineHeight(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRenderer(termenv.NewOutput(&buf), false).(*standardRenderer)
+	r.width, r.height = 10, 10
+	r.maxInlineHeight = 3
+
+	// Views taller than the limit are cut from the top.
+	r.write("1\n2\n3\n4\n5")
+	r.flush()
+	if r.linesRendered != 3 || !strings.HasSuffix(buf.String(), "3\r\n4\r\n5\x1b[10D") {
+		t.Errorf("expected the last 3 lines to be rendered, got %d lines in %q", r.linesRendered, buf.String())
+	}
+
+	// Lines left over when the view shrinks are cleared, even ignored ones.
+	r.handleMessages(IgnoreLines(2, 3)())
+	buf.Reset()
+	r.write("3")
+	r.flush()
+	if n := strings.Count(buf.String(), "\x1b[2K"); n != 3 || r.linesRendered != 1 {
+		t.Errorf("expected all 3 lines to be cleared, got %d lines in %q", r.linesRendered, buf.String())
+	}
+
+	// The altscreen isn't limited.
+	r.altScreenActive = true
+	r.write("1\n2\n3\n4\n5")
+	r.flush()
+	if r.linesRendered != 5 {
+		t.Errorf("expected 5 lines in the altscreen, got %d", r.linesRendered)
+	}
+}
+
+func TestRendererReflow(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRenderer(termenv.NewOutput(&buf), false).(*standardRenderer)
+	r.width, r.height = 10, 10
+	r.reflows = true
+
+	r.write("aaaaaaaaaa\nbb\ncc")
+	r.flush()
+
+	// Narrowing the window rewraps the first line onto two rows, so the
+	// next frame has to go up one more row, and erase what's below.
+	r.handleMessages(WindowSizeMsg{Width: 5, Height: 10})
+	if r.linesRendered != 4 {
+		t.Errorf("expected 4 rows above the cursor, got %d", r.linesRendered)
+	}
+	buf.Reset()
+	r.write("aaaaa\nbb\ncc")
+	r.flush()
+	out := buf.String()
+	if n := strings.Count(out, "\x1b[1A"); n != 3 || !strings.Contains(out, "\x1b[J") {
+		t.Errorf("expected to go up 3 rows and erase below, got %q", out)
+	}
+
+	// Terminals which don't reflow just cut lines off.
+	r.reflows = false
+	r.handleMessages(WindowSizeMsg{Width: 2, Height: 10})
+	if r.linesRendered != 3 {
+		t.Errorf("expected 3 rows above the cursor, got %d", r.linesRendered)
+	}
+}
+
+func TestRendererAltScreenSwitch(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRenderer(termenv.NewOutput(&buf), false).(*standardRenderer)
+	r.width, r.height = 10, 10
+	r.reflows = true
+
+	r.write("aaaaaaaaaa\nbb\ncc")
+	r.flush()
+
+	r.enterAltScreen()
+	r.write(strings.Repeat("picker\n", 9) + "picker")
+	r.flush()
+
+	// On return, the next frame paints over the output it left behind, and
+	// nothing above it.
+	r.exitAltScreen()
+	buf.Reset()
+	r.write("aaaaaaaaaa\nbb\ndd")
+	r.flush()
+	out := buf.String()
+	if n := strings.Count(out, "\x1b[1A"); n != 2 {
+		t.Errorf("expected to go up 2 rows, got %q", out)
+	}
+	if !strings.Contains(out, "aaaaaaaaaa") {
+		t.Errorf("expected the output to be repainted, got %q", out)
+	}
+
+	// Narrowing the window in the altscreen rewraps the output outside it.
+	r.enterAltScreen()
+	r.write("picker")
+	r.flush()
+	r.handleMessages(WindowSizeMsg{Width: 5, Height: 10})
+	r.exitAltScreen()
+	if r.linesRendered != 4 || !r.clearBelow {
+		t.Errorf("expected 4 rewrapped rows above the cursor, got %d", r.linesRendered)
+	}
+}
+
+func TestRendererPrintInAltScreen(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRenderer(termenv.NewOutput(&buf), false).(*standardRenderer)
+	r.width, r.height = 10, 10
+	r.running = true
+
+	// Lines printed in the altscreen are held back...
+	r.enterAltScreen()
+	r.write("dashboard")
+	r.flush()
+	buf.Reset()
+	r.handleMessages(printLineMessage{messageBody: "log 1\nlog 2"})
+	r.write("dashboard!")
+	r.flush()
+	if out := buf.String(); strings.Contains(out, "log") {
+		t.Errorf("expected no lines to be printed in the altscreen, got %q", out)
+	}
+
+	// ...until it's exited, when they're printed above the output.
+	r.exitAltScreen()
+	buf.Reset()
+	r.write("prompt")
+	r.flush()
+	if out := buf.String(); !strings.Contains(out, "log 1") || strings.Index(out, "log 2") > strings.Index(out, "prompt") {
+		t.Errorf("expected the lines to be printed above the output, got %q", out)
+	}
+
+	// Once the renderer has stopped, they're printed on exit right away.
+	r.enterAltScreen()
+	r.handleMessages(printLineMessage{messageBody: "log 3"})
+	r.running = false
+	buf.Reset()
+	r.exitAltScreen()
+	if out := buf.String(); !strings.Contains(out, "\r\nlog 3\r\n") {
+		t.Errorf("expected the line to be printed on exit, got %q", out)
+	}
+	if len(r.queuedMessageLines) != 0 || r.linesRendered != 0 {
+		t.Errorf("expected the printed lines to be written out, got %q", r.queuedMessageLines)
+	}
+}
+
+func TestRendererEraseInline(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRenderer(termenv.NewOutput(&buf), false).(*standardRenderer)
+	r.width, r.height = 10, 10
+
+	r.write("one\ntwo\nthree")
+	r.flush()
+	buf.Reset()
+
+	// The output is erased from its first line...
+	r.eraseInline()
+	if out := buf.String(); out != "\x1b[2A\r\x1b[J" {
+		t.Errorf("expected the output to be erased, got %q", out)
+	}
+
+	// ...and the next frame is rendered from there, in full.
+	buf.Reset()
+	r.write("one\ntwo\nthree")
+	r.flush()
+	if out := buf.String(); !strings.Contains(out, "one") || strings.Contains(out, "\x1b[2A") {
+		t.Errorf("expected the frame to be rendered in full, got %q", out)
+	}
+
+	// Nothing is erased in the altscreen.
+	r.enterAltScreen()
+	r.write("dashboard")
+	r.flush()
+	buf.Reset()
+	r.eraseInline()
+	if out := buf.String(); out != "" {
+		t.Errorf("expected nothing to be erased in the altscreen, got %q", out)
+	}
+}
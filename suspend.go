@@ -0,0 +1,60 @@
+package tea
+
+// SuspendMsg is sent to the model when the program is suspended, with
+// Suspend, or by the SIGTSTP signal, such as from kill. By the time it's
+// received, the terminal has been restored; once the model has handled it,
+// the program is stopped until it's continued, say with the shell's fg.
+type SuspendMsg struct{}
+
+// ResumeMsg is sent to the model when the program is continued after being
+// suspended. By the time it's received, the terminal has been set up again
+// and the view is being repainted.
+type ResumeMsg struct{}
+
+// suspendMsg is an internal message used to suspend the program. You can send
+// a suspendMsg with Suspend.
+type suspendMsg struct{}
+
+// Suspend is a special command that suspends the program, as ctrl+z does in
+// other terminal programs: the terminal is restored and the program is
+// stopped, leaving the user at the shell, until it's continued, when the
+// terminal is set up again and the view repainted. The model is sent a
+// SuspendMsg and a ResumeMsg along the way.
+//
+// The terminal sends ctrl+z to the program as a key, like ctrl+c, so it's up
+// to the model to suspend the program in response:
+//
+//	case tea.KeyMsg:
+//	    if msg.Type == tea.KeyCtrlZ {
+//	        return m, tea.Suspend
+//	    }
+//
+// Programs can't be suspended on Windows, where the command does nothing.
+func Suspend() Msg {
+	return suspendMsg{}
+}
+
+// suspend suspends the program until it's continued, and returns the model
+// as updated with the SuspendMsg. If the update fails, the program isn't
+// suspended, and the error is returned.
+func (p *Program) suspend(model Model, cmds chan Cmd) (Model, error) {
+	if !suspendSupported {
+		return model, nil
+	}
+	if err := p.ReleaseTerminal(); err != nil {
+		return model, nil //nolint:nilerr // the program goes on unsuspended
+	}
+
+	model, cmd, err := update(model, SuspendMsg{})
+	if err != nil {
+		return model, err
+	}
+	p.queueCmd(cmds, cmd)
+
+	// This blocks until the program is continued.
+	suspendProcess()
+
+	_ = p.RestoreTerminal()
+	go p.Send(ResumeMsg{})
+	return model, nil
+}
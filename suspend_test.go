@@ -0,0 +1,56 @@
+package tea
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+type suspendModel struct {
+	msgs []Msg
+}
+
+func (m suspendModel) Init() Cmd {
+	return Suspend
+}
+
+func (m suspendModel) Update(msg Msg) (Model, Cmd) {
+	switch msg.(type) {
+	case SuspendMsg:
+		m.msgs = append(m.msgs, msg)
+	case ResumeMsg:
+		m.msgs = append(m.msgs, msg)
+		return m, Quit
+	}
+	return m, nil
+}
+
+func (m suspendModel) View() string {
+	return "view"
+}
+
+func TestSuspend(t *testing.T) {
+	if !suspendSupported {
+		t.Skip("programs can't be suspended on this platform")
+	}
+
+	var suspended bool
+	defer func(f func()) { suspendProcess = f }(suspendProcess)
+	suspendProcess = func() { suspended = true }
+
+	var buf bytes.Buffer
+	var in bytes.Buffer
+	p := NewProgram(suspendModel{}, WithInput(&in), WithOutput(&buf))
+	m, err := p.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !suspended {
+		t.Errorf("expected the process to be suspended")
+	}
+	expected := []Msg{SuspendMsg{}, ResumeMsg{}}
+	if msgs := m.(suspendModel).msgs; !reflect.DeepEqual(msgs, expected) {
+		t.Errorf("expected %v, got %v", expected, msgs)
+	}
+}
@@ -19,7 +19,9 @@ import (
 	"runtime/debug"
 	"sync"
 	"syscall"
+	"time"
 
+	"github.com/charmbracelet/bubbletea/input"
 	"github.com/containerd/console"
 	isatty "github.com/mattn/go-isatty"
 	"github.com/muesli/cancelreader"
@@ -82,7 +84,7 @@ func (i inputType) String() string {
 // generally set with ProgramOptions.
 //
 // The options here are treated as bits.
-type startupOptions byte
+type startupOptions uint16
 
 func (s startupOptions) has(option startupOptions) bool {
 	return s&option != 0
@@ -94,6 +96,8 @@ const (
 	withMouseAllMotion
 	withANSICompressor
 	withoutSignalHandler
+	withoutBracketedPaste
+	withRenderOnChange
 
 	// Catching panics is incredibly useful for restoring the terminal to a
 	// usable state after a panic occurs. When this is set, Bubble Tea will
@@ -119,6 +123,15 @@ type Program struct {
 	errs     chan error
 	finished chan struct{}
 
+	// messages sent with SendPriority, which are handled ahead of msgs, and
+	// a signal that one was sent
+	priority      chan Msg
+	priorityReady chan struct{}
+
+	// messages a filter expanded a message into, which are handled ahead of
+	// all others, see Msgs
+	expanded chan Msg
+
 	// where to send output, this will usually be os.Stdout.
 	output        *termenv.Output
 	restoreOutput func() error
@@ -130,9 +143,41 @@ type Program struct {
 	readLoopDone chan struct{}
 	console      console.Console
 
+	// decodes the input read, and whether it's to expect responses to
+	// DECRQSS and XTGETTCAP queries, see expectDCS
+	decoder     *input.Decoder
+	dcsExpected bool
+
 	// was the altscreen active before releasing the terminal?
 	altScreenWasActive bool
-	ignoreSignals      bool
+	// was bracketed paste active before releasing the terminal?
+	bpWasActive bool
+	// the state of the cursor before releasing the terminal
+	savedCursor cursorState
+	// the last frame rendered before releasing the terminal
+	savedFrame string
+	// have we paused timers while the terminal is released?
+	timersPaused bool
+
+	// zones mouse events are tested against, see RegisterZone
+	zones zoneRegistry
+
+	// repeats presses in repeat zones, see RegisterRepeatZone
+	repeater mouseRepeater
+
+	// key sequences recognized in the input, see RegisterKeySequence
+	keySequences keySequencer
+
+	// mouse tracking mode enabled by the program
+	mouse mouseMode
+
+	// mouse highlight tracking state
+	highlight highlightTracking
+
+	// whether application keypad mode is enabled
+	appKeypad bool
+
+	ignoreSignals bool
 
 	// Stores the original reference to stdin for cases where input is not a
 	// TTY on windows and we've automatically opened CONIN$ to receive input.
@@ -144,6 +189,167 @@ type Program struct {
 	windowsStdin *os.File //nolint:golint,structcheck,unused
 
 	filter func(Model, Msg) Msg
+
+	// translates keys read from the terminal, see WithKeyTranslator.
+	keyTranslator func(KeyMsg) KeyMsg
+
+	// the terminal we're running in, as detected from the environment. See
+	// detectTerminal.
+	terminal string
+
+	// the terminal's tier, see detectTier and WithTier.
+	tier       Tier
+	tierForced bool
+
+	// the most lines of output outside the altscreen, see
+	// WithMaxInlineHeight; zero for no limit
+	maxInlineHeight int
+
+	// the most frames per second to draw, see WithFPS; zero for the
+	// default
+	fps int
+
+	// the color profile to use regardless of what's detected, see
+	// WithColorProfile.
+	colorProfile       termenv.Profile
+	colorProfileForced bool
+
+	// whether to use synchronized output regardless of what the terminal
+	// reports, see WithSynchronizedOutput.
+	syncOutput       bool
+	syncOutputForced bool
+
+	// whether to batch writes to the terminal, and how often to write them
+	// if not with each frame, see WithWriteBatching.
+	writeBatching bool
+	batchInterval time.Duration
+
+	// the most bytes to write per frame on average, see WithFrameBudget.
+	frameBudget int
+
+	// the state of capability detection, see queryCapabilities.
+	capQuery *capabilityQuery
+
+	// the state of the latest query for the terminal's colors, see
+	// queryTerminalColors.
+	colorQuery *colorQuery
+
+	// the colors of the terminal's palette changed by the program, by index,
+	// see SetPaletteColor.
+	palette map[int]string
+
+	// the number of reads of the clipboard, and whether the latest is
+	// waiting on the terminal, see ReadClipboard.
+	clipboardReads   int
+	clipboardWaiting bool
+
+	// the titles set by the program, and those saved on the terminal's title
+	// stack, starting with the terminal's own, see PushWindowTitle.
+	titles     titles
+	titleStack []titles
+
+	// power and idle events, disabled when zero.
+	powerInterval time.Duration
+	idleTimeout   time.Duration
+	lastInput     int64 // unix nanoseconds, accessed atomically
+
+	// receives a copy of everything written to the terminal, see
+	// WithOutputTee.
+	outputTee io.Writer
+
+	// called with the diff of every frame, see WithFrameHook.
+	frameHook func(FrameDiff)
+
+	// applied to every view before it's rendered, see WithFrameFilter.
+	frameFilters []func(string) string
+
+	// how often to report on rendering performance, disabled when zero. See
+	// WithFramePerf.
+	framePerfInterval time.Duration
+
+	// the grid a CellModel draws its view into, kept from one frame to the
+	// next.
+	cells CellGrid
+
+	// records printed messages when enabled, see WithLogConsole.
+	logConsole *logConsole
+
+	// records recent messages for crash reports, see WithCrashReports.
+	crashRecorder *crashRecorder
+
+	// orders the results of batched commands when set, see
+	// WithDeterministicCommands.
+	scheduler *commandScheduler
+
+	// bounds the messages waiting for the event loop when set, see
+	// WithQueueLimits.
+	queue *msgQueue
+
+	// renders views on a goroutine of their own when set, see
+	// WithPipelinedRendering.
+	pipelined bool
+	pipeline  *renderPipeline
+
+	// composites the layers of a LayeredModel and hit-tests them.
+	layers layerState
+
+	// the window size to report instead of the terminal's, see
+	// WithFixedWindowSize.
+	fixedSize *WindowSizeMsg
+
+	// the last window size reported to the model
+	size WindowSizeMsg
+
+	// how input is read, see WithInputBuffer.
+	inputConfig inputConfig
+
+	// kitty keyboard protocol flags to enable, see WithKeyboardEnhancements.
+	// keyboardRequested keeps the flags asked for, even if the terminal's
+	// tier doesn't allow them.
+	keyboardFlags     KeyboardEnhancements
+	keyboardRequested KeyboardEnhancements
+
+	// whether win32-input-mode is enabled in place of keyboard
+	// enhancements, see finishCapabilityQuery.
+	win32Input bool
+
+	// measures input latency when set, see WithInputLatency.
+	latency         *latencyTracker
+	latencyInterval time.Duration
+
+	// read times of the input processed by Update since the last view was
+	// drawn; only accessed from the event loop's goroutine
+	drawnInputs []time.Time
+
+	// the interval mouse motion is coalesced over, see
+	// WithMouseMotionCoalescing.
+	motionInterval time.Duration
+
+	// whether swipes and flings are recognized, see WithGestures
+	gestures bool
+
+	// the size last sent to the program, and how long resizing has to
+	// settle before it's sent, see WithResizeDebounce
+	sizeMtx        sync.Mutex
+	sentSize       WindowSizeMsg
+	resizeDebounce time.Duration
+
+	// the size of the terminal's cells in pixels, as reported by the
+	// terminal, guarded by sizeMtx, and the state of the queries for it, see
+	// QueryCellSize
+	cellWidth, cellHeight int
+	cellQuery             *cellSizeQuery
+	windowPixelsQueried   bool
+
+	// signals sent to the program as messages, see WithSignals
+	signals []os.Signal
+
+	// what's called when the program exits, see OnShutdown, and the grace
+	// period it's waited for, see WithShutdownTimeout
+	shutdownMtx      sync.Mutex
+	shutdownHooks    []func()
+	shutdownTimeout  time.Duration
+	shutdownDeadline time.Time
 }
 
 // Quit is a special command that tells the Bubble Tea program to exit.
@@ -158,8 +364,10 @@ type QuitMsg struct{}
 // NewProgram creates a new Program.
 func NewProgram(model Model, opts ...ProgramOption) *Program {
 	p := &Program{
-		initialModel: model,
-		msgs:         make(chan Msg),
+		initialModel:  model,
+		msgs:          make(chan Msg),
+		priority:      make(chan Msg, maxPriorityMsgs),
+		priorityReady: make(chan struct{}, 1),
 	}
 
 	// Apply all options to the program.
@@ -184,6 +392,21 @@ func NewProgram(model Model, opts ...ProgramOption) *Program {
 	}
 
 	p.restoreOutput, _ = termenv.EnableVirtualTerminalProcessing(p.output)
+	p.terminal = detectTerminal(os.Getenv)
+
+	// Settle on a color profile. The renderer downgrades colors the profile
+	// doesn't support.
+	if p.colorProfileForced {
+		p.output.Profile = p.colorProfile
+	} else if p.colorsAllowed() {
+		p.output.Profile = terminfoProfile(p.output.Profile, os.Getenv("TERM"))
+	}
+
+	// The environment variable takes precedence over WithTier, so that users
+	// can work around misdetection.
+	if _, ok := parseTier(os.Getenv(tierEnvVar)); ok || !p.tierForced {
+		p.tier = detectTier(p.terminal, os.Getenv)
+	}
 
 	return p
 }
@@ -199,9 +422,20 @@ func (p *Program) handleSignals() chan struct{} {
 	// caught here.
 	//
 	// SIGTERM is sent by unix utilities (like kill) to terminate a process.
+	//
+	// SIGTSTP suspends the program. Like ^C, ^Z is a keystroke in raw mode.
+	//
+	// Signals set with WithSignals are sent to the program as messages
+	// instead, even without the signal handler.
+	var sigs []os.Signal
+	if !p.startupOptions.has(withoutSignalHandler) {
+		sigs = append([]os.Signal{syscall.SIGINT, syscall.SIGTERM}, suspendSignals...)
+	}
+	sigs = append(sigs, p.signals...)
+
 	go func() {
 		sig := make(chan os.Signal, 1)
-		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+		signal.Notify(sig, sigs...)
 		defer func() {
 			signal.Stop(sig)
 			close(ch)
@@ -212,11 +446,20 @@ func (p *Program) handleSignals() chan struct{} {
 			case <-p.ctx.Done():
 				return
 
-			case <-sig:
-				if !p.ignoreSignals {
-					p.msgs <- QuitMsg{}
-					return
+			case s := <-sig:
+				if p.isMsgSignal(s) {
+					p.Send(SignalMsg{Signal: s})
+					continue
+				}
+				if p.ignoreSignals {
+					continue
+				}
+				if s != syscall.SIGINT && s != syscall.SIGTERM {
+					p.Send(suspendMsg{})
+					continue
 				}
+				p.msgs <- QuitMsg{}
+				return
 			}
 		}
 	}()
@@ -228,6 +471,13 @@ func (p *Program) handleSignals() chan struct{} {
 func (p *Program) handleResize() chan struct{} {
 	ch := make(chan struct{})
 
+	if p.fixedSize != nil {
+		// Report the fixed size and ignore the terminal's.
+		go p.checkResize()
+		close(ch)
+		return ch
+	}
+
 	if f, ok := p.output.TTY().(*os.File); ok && isatty.IsTerminal(f.Fd()) {
 		// Get the initial terminal size and send it to the program.
 		go p.checkResize()
@@ -265,6 +515,8 @@ func (p *Program) handleCommands(cmds chan Cmd) chan struct{} {
 				// possible to cancel them so we'll have to leak the goroutine
 				// until Cmd returns.
 				go func() {
+					defer p.recoverGoPanic()
+
 					msg := cmd() // this can be long.
 					p.Send(msg)
 				}()
@@ -279,6 +531,15 @@ func (p *Program) handleCommands(cmds chan Cmd) chan struct{} {
 // Bubble Tea messages, update the model and triggers redraws.
 func (p *Program) eventLoop(model Model, cmds chan Cmd) (Model, error) {
 	for {
+		// Messages sent with SendPriority are handled ahead of the others.
+		msgs := p.msgs
+		if len(p.priority) > 0 {
+			msgs = p.priority
+		}
+		if len(p.expanded) > 0 {
+			msgs = p.expanded
+		}
+
 		select {
 		case <-p.ctx.Done():
 			return model, nil
@@ -286,23 +547,100 @@ func (p *Program) eventLoop(model Model, cmds chan Cmd) (Model, error) {
 		case err := <-p.errs:
 			return model, err
 
-		case msg := <-p.msgs:
+		case <-p.priorityReady:
+			continue
+
+		case msg := <-msgs:
+			var inputTime time.Time
+			if m, ok := msg.(timedInputMsg); ok {
+				msg, inputTime = m.msg, m.time
+			}
+			f, filtered := msg.(filteredMsg)
+			if filtered {
+				msg = f.msg
+			}
+			if m, ok := msg.(WindowSizeMsg); ok {
+				m.PrevWidth, m.PrevHeight = p.size.Width, p.size.Height
+				p.size = WindowSizeMsg{Width: m.Width, Height: m.Height, WidthPixels: m.WidthPixels, HeightPixels: m.HeightPixels}
+				msg = m
+			}
+			if m, ok := msg.(mouseRepeatMsg); ok {
+				if !p.repeater.current(m) {
+					continue
+				}
+				msg = m.msg
+			}
+			if m, ok := msg.(MouseMsg); ok {
+				if zones := append(p.layers.hitTest(m.X, m.Y), p.zones.hits(m.X, m.Y)...); len(zones) > 0 {
+					m = m.WithZones(zones...)
+					msg = m
+				}
+				p.repeater.track(p.ctx, m, p.zones.repeats(m.X, m.Y), p.Send)
+			}
+
 			// Filter messages.
-			if p.filter != nil {
+			if p.filter != nil && !filtered {
 				msg = p.filter(model, msg)
+				if msgs, ok := msg.(Msgs); ok {
+					p.expandMsgs(msgs)
+					continue
+				}
 			}
 			if msg == nil {
 				continue
 			}
 
+			if p.crashRecorder != nil {
+				p.crashRecorder.record(msg)
+			}
+
+			// Drop requests for features the terminal's tier doesn't
+			// support.
+			if f, ok := featureFor(msg); ok && !p.supports(f) {
+				continue
+			}
+
 			// Handle special internal messages.
 			switch msg := msg.(type) {
 			case QuitMsg:
-				return model, nil
+				return p.shutdownModel(model)
 
 			case clearScreenMsg:
 				p.renderer.clearScreen()
 
+			case printLineMessage:
+				if p.logConsole != nil {
+					p.logConsole.add(msg)
+					p.draw(model)
+					continue
+				}
+
+			case toggleLogConsoleMsg:
+				if p.logConsole != nil {
+					p.logConsole.visible = !p.logConsole.visible
+					p.draw(model)
+				}
+				continue
+
+			case WindowSizeMsg:
+				p.queryWindowPixels(msg)
+				p.layers.setSize(msg.Width, msg.Height)
+				if p.logConsole != nil {
+					p.logConsole.width, p.logConsole.height = msg.Width, msg.Height
+				}
+
+			case resetTerminalMsg:
+				p.renderer.resetTerminal()
+				if p.highlight.enabled {
+					_ = p.renderer.execute(enableHighlightTracking)
+				}
+				if p.keyboardFlags != 0 {
+					_ = p.renderer.execute(enableKeyboardEnhancements(p.keyboardFlags))
+				}
+				if p.appKeypad {
+					_ = p.renderer.execute(enableAppKeypad)
+				}
+
 			case enterAltScreenMsg:
 				p.renderer.enterAltScreen()
 
@@ -310,14 +648,54 @@ func (p *Program) eventLoop(model Model, cmds chan Cmd) (Model, error) {
 				p.renderer.exitAltScreen()
 
 			case enableMouseCellMotionMsg:
-				p.renderer.enableMouseCellMotion()
+				p.highlight.enabled = false
+				p.setMouseMode(mouseModeCellMotion)
 
 			case enableMouseAllMotionMsg:
-				p.renderer.enableMouseAllMotion()
+				p.highlight.enabled = false
+				p.setMouseMode(mouseModeAllMotion)
+
+			case enableMouseHighlightTrackingMsg:
+				p.highlight = highlightTracking{
+					enabled:  true,
+					firstRow: msg.firstRow,
+					lastRow:  msg.lastRow,
+				}
+				_ = p.renderer.execute(enableHighlightTracking)
 
 			case disableMouseMsg:
-				p.renderer.disableMouseCellMotion()
-				p.renderer.disableMouseAllMotion()
+				p.setMouseMode(mouseModeNone)
+				if p.highlight.enabled {
+					p.highlight.enabled = false
+					_ = p.renderer.execute(disableHighlightTracking)
+				}
+
+			case registerZoneMsg:
+				p.zones.register(zone(msg))
+				continue
+
+			case unregisterZoneMsg:
+				p.zones.unregister(string(msg))
+				continue
+
+			case clearZonesMsg:
+				p.zones = nil
+				continue
+
+			case registerKeySequenceMsg:
+				p.keySequences.register(msg)
+				continue
+
+			case unregisterKeySequenceMsg:
+				p.keySequences.unregister(msg)
+				continue
+
+			case MouseMsg:
+				// The terminal waits for our answer before doing anything
+				// else, so don't leave it to the model.
+				if p.highlight.enabled && msg.Type == MouseLeft {
+					_ = p.renderer.execute(p.highlight.response(MouseEvent(msg)))
+				}
 
 			case showCursorMsg:
 				p.renderer.showCursor()
@@ -325,18 +703,164 @@ func (p *Program) eventLoop(model Model, cmds chan Cmd) (Model, error) {
 			case hideCursorMsg:
 				p.renderer.hideCursor()
 
+			case setCursorShapeMsg:
+				p.renderer.setCursorShape(CursorShape(msg))
+
+			case enableBracketedPasteMsg:
+				p.renderer.enableBracketedPaste()
+
+			case disableBracketedPasteMsg:
+				p.renderer.disableBracketedPaste()
+
+			case enableApplicationKeypadMsg:
+				_ = p.renderer.execute(enableAppKeypad)
+				p.appKeypad = true
+
+			case disableApplicationKeypadMsg:
+				_ = p.renderer.execute(disableAppKeypad)
+				p.appKeypad = false
+
+			case enableAlternateScrollMsg:
+				p.renderer.setAlternateScroll(true)
+
+			case disableAlternateScrollMsg:
+				p.renderer.setAlternateScroll(false)
+
+			case termcapMsg, statusStringMsg, modeReportMsg, primaryDeviceAttributesMsg, queryCapabilitiesTimeoutMsg:
+				p.handleCapabilityResponse(msg)
+				p.handleColorResponse(msg)
+				p.handleCellSizeResponse(msg)
+
+			case queryCellSizeMsg:
+				p.queryCellSize()
+				continue
+
+			case windowPixelsMsg, cellPixelsMsg, queryCellSizeTimeoutMsg:
+				p.handleCellSizeResponse(msg)
+				continue
+
+			case queryTerminalColorsMsg:
+				p.queryTerminalColors()
+				continue
+
+			case queryColorsTimeoutMsg:
+				p.handleColorResponse(msg)
+				continue
+
+			case setPaletteColorMsg:
+				p.setPaletteColor(msg.index, msg.color)
+				continue
+
+			case resetPaletteColorMsg:
+				p.resetPaletteColor(int(msg))
+				continue
+
+			case queryPaletteColorsMsg:
+				p.queryPaletteColors(msg)
+				continue
+
+			case ForegroundColorMsg, BackgroundColorMsg:
+				p.handleColorResponse(msg)
+
+			case setWindowTitleMsg:
+				p.saveTitles()
+				_ = p.renderer.execute(windowTitleSeq(string(msg)))
+				p.titles.window = string(msg)
+
+			case setPaneTitleMsg:
+				if seq := paneTitleSeq(p.terminal, string(msg)); seq != "" {
+					_ = p.renderer.execute(seq)
+				}
+
+			case setTabTitleMsg:
+				p.saveTitles()
+				_ = p.renderer.execute(tabTitleSeq(p.terminal, string(msg)))
+				p.titles.tab = string(msg)
+
+			case pushWindowTitleMsg:
+				p.pushTitles()
+
+			case popWindowTitleMsg:
+				p.popTitles()
+
+			case setFPSMsg:
+				var fps int
+				if r, ok := p.renderer.(*standardRenderer); ok {
+					fps = clampFPS(int(msg))
+					r.setFPS(fps)
+				}
+				go p.Send(FPSMsg{FPS: fps})
+
+			case notifyMsg:
+				_ = p.renderer.execute(notificationSeq(p.notificationProtocol(), msg.title, msg.body))
+				continue
+
+			case setClipboardMsg:
+				res := p.setClipboard(string(msg))
+				go p.Send(res)
+
+			case readClipboardMsg:
+				p.readClipboard()
+				continue
+
+			case clipboardContentMsg, clipboardReadTimeoutMsg:
+				p.handleClipboardResponse(msg)
+				continue
+
+			case restartMsg:
+				m, err := p.restart()
+				if err != nil {
+					return model, err
+				}
+				model = m
+				if !p.queueCmd(cmds, model.Init()) {
+					return model, nil
+				}
+				p.draw(model)
+				continue
+
+			case runChildMsg:
+				// As with exec, the release of a held button won't be
+				// read while the child runs.
+				p.repeater.cancel()
+
+				// NB: this blocks.
+				p.runChild(msg)
+				continue
+
+			case suspendMsg:
+				var err error
+				if model, err = p.suspend(model, cmds); err != nil {
+					return model, err
+				}
+				continue
+
 			case execMsg:
+				// The release of a held button won't be read while the
+				// terminal is released.
+				p.repeater.cancel()
+
 				// NB: this blocks.
 				p.exec(msg.cmd, msg.fn)
 
 			case BatchMsg:
+				if p.scheduler != nil {
+					if !p.queueCmd(cmds, p.deterministicBatch(msg)) {
+						return model, nil
+					}
+					continue
+				}
 				for _, cmd := range msg {
-					cmds <- cmd
+					if !p.queueCmd(cmds, cmd) {
+						return model, nil
+					}
 				}
 				continue
 
 			case sequenceMsg:
 				go func() {
+					defer p.recoverGoPanic()
+
 					// Execute commands one at a time, in order.
 					for _, cmd := range msg {
 						if cmd == nil {
@@ -345,10 +869,16 @@ func (p *Program) eventLoop(model Model, cmds chan Cmd) (Model, error) {
 
 						msg := cmd()
 						if batchMsg, ok := msg.(BatchMsg); ok {
+							if p.scheduler != nil {
+								p.deterministicBatch(batchMsg)()
+								continue
+							}
+
 							g, _ := errgroup.WithContext(p.ctx)
 							for _, cmd := range batchMsg {
 								cmd := cmd
 								g.Go(func() error {
+									defer p.recoverGoPanic()
 									p.Send(cmd())
 									return nil
 								})
@@ -365,22 +895,76 @@ func (p *Program) eventLoop(model Model, cmds chan Cmd) (Model, error) {
 			}
 
 			// Process internal messages for the renderer.
-			if r, ok := p.renderer.(*standardRenderer); ok {
+			switch r := p.renderer.(type) {
+			case *standardRenderer:
 				r.handleMessages(msg)
+			case *customRenderer:
+				r.r.HandleMessage(msg)
 			}
 
 			var cmd Cmd
-			model, cmd = model.Update(msg) // run update
-			cmds <- cmd                    // process command (if any)
-			p.renderer.write(model.View()) // send view to renderer
+			var err error
+			model, cmd, err = update(model, msg) // run update
+			if err != nil {
+				return model, err
+			}
+
+			// process command (if any)
+			if !p.queueCmd(cmds, cmd) {
+				return model, nil
+			}
+			if !inputTime.IsZero() {
+				p.drawnInputs = append(p.drawnInputs, inputTime)
+			}
+			p.draw(model) // send view to renderer
 		}
 	}
 }
 
+// queueCmd hands a command to the command handler. It reports false if the
+// program was killed in the meantime, as the handler has stopped taking
+// commands then.
+func (p *Program) queueCmd(cmds chan Cmd, cmd Cmd) bool {
+	select {
+	case cmds <- cmd:
+		return true
+	case <-p.ctx.Done():
+		return false
+	}
+}
+
+// view returns the model's view, with its layers and the log console on top
+// of it if it's visible.
+func (p *Program) view(model Model) string {
+	return renderView(model, &p.layers, &p.cells, p.logConsole, p.renderer.altScreen())
+}
+
+// renderView returns the model's view, drawn into the given grid if it's a
+// CellModel, with its layers composited by the given layer state and the
+// given log console on top of it if it's visible.
+func renderView(model Model, layers *layerState, cells *CellGrid, console *logConsole, altScreen bool) string {
+	width, height := layers.size()
+	view, ok := cellView(model, cells, width, height)
+	if !ok {
+		view = model.View()
+	}
+	if m, ok := optionalModel(model).(interface{ Layers() []Layer }); ok {
+		view = layers.compose(view, m.Layers(), altScreen)
+	}
+	if console == nil {
+		return view
+	}
+	return console.view(view, altScreen)
+}
+
 // Run initializes the program and runs its event loops, blocking until it gets
 // terminated by either [Program.Quit], [Program.Kill], or its signal handler.
 // Returns the final model.
-func (p *Program) Run() (Model, error) {
+//
+// Panics in Update, View and commands are recovered, unless disabled with
+// WithoutCatchPanics: the terminal is restored, the panic and its stack are
+// printed, and Run returns ErrProgramPanic.
+func (p *Program) Run() (_ Model, returnErr error) {
 	handlers := handlers{}
 	cmds := make(chan Cmd)
 	p.errs = make(chan error)
@@ -423,7 +1007,7 @@ func (p *Program) Run() (Model, error) {
 	}
 
 	// Handle signals.
-	if !p.startupOptions.has(withoutSignalHandler) {
+	if !p.startupOptions.has(withoutSignalHandler) || len(p.signals) > 0 {
 		handlers.add(p.handleSignals())
 	}
 
@@ -431,9 +1015,16 @@ func (p *Program) Run() (Model, error) {
 	if !p.startupOptions.has(withoutCatchPanics) {
 		defer func() {
 			if r := recover(); r != nil {
+				returnErr = ErrProgramPanic
+
+				// Write the crash report before restoring the terminal, which
+				// may change what the goroutines are up to.
+				var report string
+				if p.crashRecorder != nil {
+					report = p.saveCrashReport(fmt.Sprintf("panic: %v", r))
+				}
 				p.shutdown(true)
-				fmt.Printf("Caught panic:\n\n%s\n\nRestoring terminal...\n\n", r)
-				debug.PrintStack()
+				printPanic(r, debug.Stack(), report)
 				return
 			}
 		}()
@@ -441,7 +1032,33 @@ func (p *Program) Run() (Model, error) {
 
 	// If no renderer is set use the standard one.
 	if p.renderer == nil {
-		p.renderer = newRenderer(p.output, p.startupOptions.has(withANSICompressor))
+		out, batch := p.batchedOutput()
+		p.renderer = newRenderer(out, p.startupOptions.has(withANSICompressor))
+		if r, ok := p.renderer.(*standardRenderer); ok {
+			q := quirksFor(p.terminal)
+			r.unsupportedAttrs = attrsAll &^ q.textAttrs
+			if p.tier == TierDumb {
+				r.unsupportedAttrs = attrsAll
+			}
+			r.frameHook = p.frameHook
+			r.latency = p.latency
+			r.syncOutput = p.syncOutputForced && p.syncOutput
+			r.colorProfile = p.output.Profile
+			r.onChange = p.startupOptions.has(withRenderOnChange)
+			r.maxInlineHeight = p.maxInlineHeight
+			r.reflows = q.reflows
+			if p.fps > 0 {
+				r.framerate = time.Second / time.Duration(clampFPS(p.fps))
+			}
+			r.frameBudget = p.frameBudget
+			if batch != nil {
+				r.batch = batch
+				r.batchFrames = p.batchInterval <= 0
+				if r.batchFrames {
+					batch.delay = r.framerate
+				}
+			}
+		}
 	}
 
 	// Check if output is a TTY before entering raw mode, hiding the cursor and
@@ -450,14 +1067,25 @@ func (p *Program) Run() (Model, error) {
 		return p.initialModel, err
 	}
 
-	// Honor program startup options.
-	if p.startupOptions&withAltScreen != 0 {
+	// Honor program startup options, as far as the terminal's tier allows.
+	if p.startupOptions&withAltScreen != 0 && p.supports(FeatureAltScreen) {
 		p.renderer.enterAltScreen()
 	}
-	if p.startupOptions&withMouseCellMotion != 0 {
-		p.renderer.enableMouseCellMotion()
-	} else if p.startupOptions&withMouseAllMotion != 0 {
-		p.renderer.enableMouseAllMotion()
+	if p.supports(FeatureMouse) {
+		if p.startupOptions&withMouseCellMotion != 0 {
+			p.setMouseMode(mouseModeCellMotion)
+		} else if p.startupOptions&withMouseAllMotion != 0 {
+			p.setMouseMode(mouseModeAllMotion)
+		}
+	}
+	if !p.startupOptions.has(withoutBracketedPaste) && p.supports(FeatureBracketedPaste) {
+		p.renderer.enableBracketedPaste()
+	}
+	p.keyboardRequested = p.keyboardFlags
+	if p.keyboardFlags != 0 && p.supports(FeatureKeyboardEnhancements) {
+		_ = p.renderer.execute(enableKeyboardEnhancements(p.keyboardFlags))
+	} else {
+		p.keyboardFlags = 0
 	}
 
 	// Initialize the program.
@@ -478,9 +1106,14 @@ func (p *Program) Run() (Model, error) {
 
 	// Start the renderer.
 	p.renderer.start()
+	if p.pipelined {
+		p.pipeline = newRenderPipeline()
+		p.pipeline.start(p)
+		defer p.pipeline.stop()
+	}
 
 	// Render the initial view.
-	p.renderer.write(model.View())
+	p.draw(model)
 
 	// Subscribe to user input.
 	if p.input != nil {
@@ -489,20 +1122,50 @@ func (p *Program) Run() (Model, error) {
 		}
 	}
 
+	// Detect the terminal's capabilities.
+	p.queryCapabilities()
+
 	// Handle resize events.
 	handlers.add(p.handleResize())
 
 	// Process commands.
 	handlers.add(p.handleCommands(cmds))
 
+	// Report power and idle events, if requested.
+	if p.powerInterval > 0 {
+		handlers.add(p.handlePower())
+	}
+	if p.idleTimeout > 0 {
+		handlers.add(p.handleIdle())
+	}
+
+	// Forward queued messages to the event loop.
+	if p.queue != nil {
+		handlers.add(p.pumpQueue())
+	}
+
+	// Report input latency, if requested.
+	if p.latency != nil && p.latencyInterval > 0 {
+		handlers.add(p.handleInputLatency())
+	}
+
+	// Report rendering performance, if requested.
+	if r, ok := p.renderer.(*standardRenderer); ok && p.framePerfInterval > 0 {
+		handlers.add(p.handleFramePerf(r))
+	}
+
 	// Run event loop, handle updates and draw.
 	model, err := p.eventLoop(model, cmds)
+	if p.pipeline != nil {
+		p.pipeline.stop()
+	}
+	gp, panicked := err.(goPanic)
 	killed := p.ctx.Err() != nil
 	if killed {
 		err = ErrProgramKilled
-	} else {
+	} else if !panicked {
 		// Ensure we rendered the final state of the model.
-		p.renderer.write(model.View())
+		p.writeView(p.view(model), cursorOf(model))
 	}
 
 	// Tear down.
@@ -520,12 +1183,56 @@ func (p *Program) Run() (Model, error) {
 	// Wait for all handlers to finish.
 	handlers.shutdown()
 
+	var report string
+	if err != nil && !killed && p.crashRecorder != nil {
+		reason := fmt.Sprintf("error: %v", err)
+		if panicked {
+			reason = fmt.Sprintf("panic: %v\n\n%s", gp.value, gp.stack)
+		}
+		report = p.saveCrashReport(reason)
+	}
+
+	if !panicked {
+		p.runShutdownHooks()
+	}
+
 	// Restore terminal state.
-	p.shutdown(killed)
+	p.shutdown(killed || panicked)
+
+	if panicked {
+		printPanic(gp.value, gp.stack, report)
+		return model, ErrProgramPanic
+	}
+	if report != "" {
+		fmt.Fprint(os.Stderr, report)
+	}
 
 	return model, err
 }
 
+// RunContext runs the program like Run, until it quits or ctx is canceled,
+// whichever comes first. It's for services which embed programs and need to
+// stop them along with whatever else is going on, such as an SSH session.
+//
+// When ctx is canceled, the program is stopped as with Kill: the model's
+// Update isn't called again and the final render is skipped, but the
+// terminal is restored, and the model as of the last update is returned with
+// ErrProgramKilled. A frame that's being drawn is finished first, as is a
+// command run with Exec.
+func (p *Program) RunContext(ctx context.Context) (Model, error) {
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			p.Kill()
+		case <-done:
+		}
+	}()
+	return p.Run()
+}
+
 // StartReturningModel initializes the program and runs its event loops,
 // blocking until it gets terminated by either [Program.Quit], [Program.Kill],
 // or its signal handler. Returns the final model.
@@ -549,16 +1256,46 @@ func (p *Program) Start() error {
 // messages to be injected from outside the program for interoperability
 // purposes.
 //
-// If the program hasn't started yet this will be a blocking operation.
-// If the program has already been terminated this will be a no-op, so it's safe
-// to send messages after the program has exited.
+// If the program hasn't started yet this will be a blocking operation, unless
+// queue limits are set with WithQueueLimits. If the program has already been
+// terminated this will be a no-op, so it's safe to send messages after the
+// program has exited.
 func (p *Program) Send(msg Msg) {
+	if p.queue != nil {
+		p.queue.push(p.ctx, msg)
+		return
+	}
 	select {
 	case <-p.ctx.Done():
 	case p.msgs <- msg:
 	}
 }
 
+// maxPriorityMsgs is the number of messages sent with SendPriority that can
+// wait to be handled before SendPriority blocks.
+const maxPriorityMsgs = 64
+
+// SendPriority sends a message to the main update function like Send, but
+// ahead of the messages waiting to be handled, such as those queued with
+// WithQueueLimits, or sent by goroutines blocked in Send. It's for urgent
+// messages, like canceling an operation, that mustn't wait behind a backlog
+// of updates from a chat stream or a log tail. Messages sent with
+// SendPriority are handled in the order they were sent.
+//
+// Up to 64 messages wait to be handled without blocking, even before the
+// program has started. If the program has already been terminated this
+// will be a no-op.
+func (p *Program) SendPriority(msg Msg) {
+	if p.ctx.Err() != nil {
+		return
+	}
+	select {
+	case <-p.ctx.Done():
+	case p.priority <- msg:
+		notify(p.priorityReady)
+	}
+}
+
 // Quit is a convenience function for quitting Bubble Tea programs. Use it
 // when you need to shut down a Bubble Tea program from the outside.
 //
@@ -591,18 +1328,38 @@ func (p *Program) shutdown(kill bool) {
 		} else {
 			p.renderer.stop()
 		}
+
+		// Leave the log in the scrollback when running inline.
+		if p.logConsole != nil && !p.renderer.altScreen() {
+			p.logConsole.persist(p.teedOutput())
+		}
 	}
 
 	_ = p.restoreTerminalState()
 	if p.restoreOutput != nil {
 		_ = p.restoreOutput()
 	}
+	p.resumeTimers()
 	p.finished <- struct{}{}
 }
 
+// resumeTimers resumes the timers paused when the terminal was released.
+func (p *Program) resumeTimers() {
+	if p.timersPaused {
+		timers.resume()
+		p.timersPaused = false
+	}
+}
+
 // ReleaseTerminal restores the original terminal state and cancels the input
 // reader. You can return control to the Program with RestoreTerminal.
 func (p *Program) ReleaseTerminal() error {
+	return p.releaseTerminal(true)
+}
+
+// releaseTerminal restores the original terminal state and cancels the input
+// reader, pausing the timers of Tick and Every if asked to.
+func (p *Program) releaseTerminal(pauseTimers bool) error {
 	p.ignoreSignals = true
 	p.cancelReader.Cancel()
 	p.waitForReadLoop()
@@ -610,8 +1367,21 @@ func (p *Program) ReleaseTerminal() error {
 	if p.renderer != nil {
 		p.renderer.stop()
 	}
+	if r, ok := p.renderer.(*standardRenderer); ok {
+		r.mtx.Lock()
+		p.savedFrame = r.lastRender
+		r.mtx.Unlock()
+		r.eraseInline()
+	}
 
 	p.altScreenWasActive = p.renderer.altScreen()
+	p.bpWasActive = p.renderer.bracketedPasteActive()
+	p.savedCursor = p.renderer.cursor()
+
+	if pauseTimers && !p.timersPaused {
+		timers.pause()
+		p.timersPaused = true
+	}
 	return p.restoreTerminalState()
 }
 
@@ -628,15 +1398,54 @@ func (p *Program) RestoreTerminal() error {
 		return err
 	}
 
+	if p.bpWasActive {
+		p.renderer.enableBracketedPaste()
+	}
+	// initTerminal hides the cursor, which may not be what the program
+	// wants.
+	if !p.savedCursor.hidden {
+		p.renderer.showCursor()
+	}
+	if p.savedCursor.shape != CursorDefault {
+		p.renderer.setCursorShape(p.savedCursor.shape)
+	}
+	switch p.mouse {
+	case mouseModeCellMotion:
+		p.renderer.enableMouseCellMotion()
+	case mouseModeAllMotion:
+		p.renderer.enableMouseAllMotion()
+	}
+	if p.highlight.enabled {
+		_ = p.renderer.execute(enableHighlightTracking)
+	}
+	if p.keyboardFlags != 0 {
+		_ = p.renderer.execute(enableKeyboardEnhancements(p.keyboardFlags))
+	}
+	if p.win32Input {
+		_ = p.renderer.execute(enableWin32InputMode)
+	}
+	if p.appKeypad {
+		_ = p.renderer.execute(enableAppKeypad)
+	}
+	for _, index := range p.changedPaletteColors() {
+		_ = p.renderer.execute(setPaletteColorSeq(index, p.palette[index]))
+	}
+	p.reclaimTitles()
 	if p.altScreenWasActive {
 		p.renderer.enterAltScreen()
-	} else {
-		// entering alt screen already causes a repaint.
-		go p.Send(repaintMsg{})
 	}
 	if p.renderer != nil {
 		p.renderer.start()
 	}
+	if r, ok := p.renderer.(*standardRenderer); ok && p.savedFrame != "" {
+		// The last frame is drawn again right away, so that the cursor is
+		// back where the model had it.
+		r.redraw(p.savedFrame, p.savedCursor.placement)
+	} else if !p.altScreenWasActive {
+		// entering alt screen already causes a repaint.
+		go p.Send(repaintMsg{})
+	}
+	p.resumeTimers()
 
 	// If the output is a terminal, it may have been resized while another
 	// process was at the foreground, in which case we may not have received
@@ -650,10 +1459,13 @@ func (p *Program) RestoreTerminal() error {
 // Println prints above the Program. This output is unmanaged by the program
 // and will persist across renders by the Program.
 //
-// If the altscreen is active no output will be printed.
+// If the altscreen is active, the output is held back and printed once the
+// altscreen is exited, or when the program exits.
 func (p *Program) Println(args ...interface{}) {
 	p.msgs <- printLineMessage{
 		messageBody: fmt.Sprint(args...),
+		level:       LogInfo,
+		time:        time.Now(),
 	}
 }
 
@@ -664,10 +1476,13 @@ func (p *Program) Println(args ...interface{}) {
 // Unlike fmt.Printf (but similar to log.Printf) the message will be print on
 // its own line.
 //
-// If the altscreen is active no output will be printed.
+// If the altscreen is active, the output is held back and printed once the
+// altscreen is exited, or when the program exits.
 func (p *Program) Printf(template string, args ...interface{}) {
 	p.msgs <- printLineMessage{
 		messageBody: fmt.Sprintf(template, args...),
+		level:       LogInfo,
+		time:        time.Now(),
 	}
 }
 
@@ -3,6 +3,8 @@ package tea
 import (
 	"bytes"
 	"context"
+	"reflect"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -118,6 +120,71 @@ func testTeaWithFilter(t *testing.T, preventCount uint32) {
 	}
 }
 
+func TestTeaFixedWindowSize(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+	var size WindowSizeMsg
+
+	m := &testModel{}
+	var p *Program
+	p = NewProgram(m,
+		WithInput(&in),
+		WithOutput(&buf),
+		WithFixedWindowSize(4, 10),
+		WithFilter(func(_ Model, msg Msg) Msg {
+			if msg, ok := msg.(WindowSizeMsg); ok {
+				size = msg
+				go p.Quit()
+			}
+			return msg
+		}))
+
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if size != (WindowSizeMsg{Width: 4, Height: 10}) {
+		t.Errorf("expected the fixed window size, got %+v", size)
+	}
+	if !strings.Contains(buf.String(), "succ\r") {
+		t.Errorf("expected the view to be truncated to the fixed width, got %q", buf.String())
+	}
+}
+
+func TestTeaPrevWindowSize(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+	var sizes []WindowSizeMsg
+
+	m := &testModel{}
+	var p *Program
+	p = NewProgram(m,
+		WithInput(&in),
+		WithOutput(&buf),
+		WithFixedWindowSize(4, 10),
+		WithFilter(func(_ Model, msg Msg) Msg {
+			if msg, ok := msg.(WindowSizeMsg); ok {
+				sizes = append(sizes, msg)
+				if len(sizes) == 1 {
+					go p.Send(WindowSizeMsg{Width: 8, Height: 5})
+				} else {
+					go p.Quit()
+				}
+			}
+			return msg
+		}))
+
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []WindowSizeMsg{
+		{Width: 4, Height: 10},
+		{Width: 8, Height: 5, PrevWidth: 4, PrevHeight: 10},
+	}
+	if !reflect.DeepEqual(sizes, expected) {
+		t.Errorf("expected sizes %+v, got %+v", expected, sizes)
+	}
+}
+
 func TestTeaKill(t *testing.T) {
 	var buf bytes.Buffer
 	var in bytes.Buffer
@@ -161,6 +228,41 @@ func TestTeaContext(t *testing.T) {
 	}
 }
 
+func TestTeaRunContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var buf bytes.Buffer
+	var in bytes.Buffer
+
+	m := &testModel{}
+	p := NewProgram(m, WithInput(&in), WithOutput(&buf))
+	go func() {
+		for {
+			time.Sleep(time.Millisecond)
+			if m.executed.Load() != nil {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	model, err := p.RunContext(ctx)
+	if err != ErrProgramKilled {
+		t.Fatalf("Expected %v, got %v", ErrProgramKilled, err)
+	}
+	if model != m {
+		t.Errorf("expected the final model to be returned, got %v", model)
+	}
+	if out := buf.String(); !strings.Contains(out, "\x1b[?25h") || !strings.HasSuffix(out, "\x1b[?2004l") {
+		t.Errorf("expected the terminal to be restored, got %q", buf.String())
+	}
+
+	// A program whose context is canceled before it runs stops right away.
+	p = NewProgram(&testModel{}, WithInput(&in), WithOutput(&buf))
+	if _, err := p.RunContext(ctx); err != ErrProgramKilled {
+		t.Fatalf("Expected %v, got %v", ErrProgramKilled, err)
+	}
+}
+
 func TestTeaBatchMsg(t *testing.T) {
 	var buf bytes.Buffer
 	var in bytes.Buffer
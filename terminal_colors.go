@@ -0,0 +1,175 @@
+package tea
+
+import (
+	"strconv"
+	"time"
+)
+
+// ForegroundColorMsg reports the terminal's default foreground color. It's
+// sent when the program starts, and in response to QueryTerminalColors.
+//
+// Color is a hex color such as "#cdd6f4". It's empty if the terminal didn't
+// report its color, as many don't.
+type ForegroundColorMsg struct {
+	Color string
+}
+
+// BackgroundColorMsg reports the terminal's default background color. It's
+// sent when the program starts, and in response to QueryTerminalColors. It's
+// useful for picking a light or dark theme to match the terminal:
+//
+//	case tea.BackgroundColorMsg:
+//	    m.dark = msg.Color == "" || msg.IsDark()
+//
+// Color is a hex color such as "#1e1e2e". It's empty if the terminal didn't
+// report its color, as many don't.
+type BackgroundColorMsg struct {
+	Color string
+}
+
+// IsDark reports whether the background color is a dark one. It reports false
+// if the color is unknown.
+func (m BackgroundColorMsg) IsDark() bool {
+	r, g, b, ok := parseHexColor(m.Color)
+	if !ok {
+		return false
+	}
+	// Relative luminance, as perceived.
+	return 0.2126*r+0.7152*g+0.0722*b < 0.5 //nolint:gomnd
+}
+
+// parseHexColor parses a color such as "#1e1e2e" into its components, each
+// between 0 and 1.
+func parseHexColor(s string) (r, g, b float64, ok bool) {
+	if len(s) != 7 || s[0] != '#' { //nolint:gomnd
+		return 0, 0, 0, false
+	}
+	n, err := strconv.ParseUint(s[1:], 16, 32) //nolint:gomnd
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	r = float64(n>>16&0xff) / 255 //nolint:gomnd
+	g = float64(n>>8&0xff) / 255  //nolint:gomnd
+	b = float64(n&0xff) / 255     //nolint:gomnd
+	return r, g, b, true
+}
+
+// queryTerminalColorsMsg is an internal message used to query the terminal's
+// colors. You can send a queryTerminalColorsMsg with QueryTerminalColors.
+type queryTerminalColorsMsg struct{}
+
+// QueryTerminalColors is a command that asks the terminal for its default
+// foreground and background colors, which are then reported with a
+// ForegroundColorMsg and a BackgroundColorMsg. The colors are queried when
+// the program starts, too, but they may have changed since, say if the user
+// switched the terminal's theme.
+//
+// Both messages are sent whether or not the terminal answers. Where it
+// doesn't, or where the program's input and output aren't terminals, their
+// colors are empty.
+func QueryTerminalColors() Msg {
+	return queryTerminalColorsMsg{}
+}
+
+// Query sequences for the terminal's default colors.
+const (
+	queryForegroundColor = "\x1b]10;?\x1b\\"
+	queryBackgroundColor = "\x1b]11;?\x1b\\"
+)
+
+// colorQuery tracks the progress of a query for the terminal's colors.
+type colorQuery struct {
+	gotFg, gotBg bool
+	done         bool
+}
+
+// queryColorsTimeoutMsg is sent when the terminal hasn't answered a query for
+// its colors in time.
+type queryColorsTimeoutMsg struct {
+	query *colorQuery
+}
+
+// startColorQuery starts tracking a query for the terminal's colors, whose
+// sequences are up to the caller to send, followed by a DA1 query. A query
+// that's already in progress is left to answer for both.
+func (p *Program) startColorQuery() bool {
+	if p.colorQuery != nil && !p.colorQuery.done {
+		return false
+	}
+	q := &colorQuery{}
+	p.colorQuery = q
+
+	go func() {
+		select {
+		case <-p.ctx.Done():
+		case <-time.After(queryTimeout):
+			p.Send(queryColorsTimeoutMsg{query: q})
+		}
+	}()
+	return true
+}
+
+// queryTerminalColors asks the terminal for its colors. If it can't be
+// queried, the colors are reported as unknown right away.
+func (p *Program) queryTerminalColors() {
+	if !p.canQuery() {
+		go func() {
+			p.Send(ForegroundColorMsg{})
+			p.Send(BackgroundColorMsg{})
+		}()
+		return
+	}
+	if !p.startColorQuery() {
+		return
+	}
+	seq := queryForegroundColor + queryBackgroundColor + queryPrimaryDeviceAttributes
+	if err := p.renderer.execute(seq); err != nil {
+		p.finishColorQuery()
+	}
+}
+
+// handleColorResponse records the terminal's answers to a query for its
+// colors. The colors it reports are passed on to the model as they arrive;
+// once the query is over, the ones it didn't are reported as unknown.
+func (p *Program) handleColorResponse(msg Msg) {
+	q := p.colorQuery
+	if q == nil || q.done {
+		return
+	}
+
+	switch msg := msg.(type) {
+	case ForegroundColorMsg:
+		q.gotFg = true
+	case BackgroundColorMsg:
+		q.gotBg = true
+	case primaryDeviceAttributesMsg:
+		p.finishColorQuery()
+	case queryColorsTimeoutMsg:
+		if msg.query == q {
+			p.finishColorQuery()
+		}
+	}
+}
+
+// finishColorQuery ends the query for the terminal's colors, and reports the
+// colors the terminal didn't answer with as unknown.
+func (p *Program) finishColorQuery() {
+	q := p.colorQuery
+	q.done = true
+
+	var msgs []Msg
+	if !q.gotFg {
+		msgs = append(msgs, ForegroundColorMsg{})
+	}
+	if !q.gotBg {
+		msgs = append(msgs, BackgroundColorMsg{})
+	}
+	if len(msgs) == 0 {
+		return
+	}
+	go func() {
+		for _, msg := range msgs {
+			p.Send(msg)
+		}
+	}()
+}
@@ -0,0 +1,151 @@
+package tea
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBackgroundColorIsDark(t *testing.T) {
+	tests := []struct {
+		color string
+		dark  bool
+	}{
+		{"#1e1e2e", true},
+		{"#000000", true},
+		{"#eff1f5", false},
+		{"#ffffff", false},
+		{"#0000ff", true},
+		{"#ffff00", false},
+		{"", false},
+		{"#fff", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.color, func(t *testing.T) {
+			if dark := (BackgroundColorMsg{Color: test.color}).IsDark(); dark != test.dark {
+				t.Errorf("expected %v, got %v", test.dark, dark)
+			}
+		})
+	}
+}
+
+func TestColorQuery(t *testing.T) {
+	stale := &colorQuery{}
+
+	tests := []struct {
+		name      string
+		responses func(q *colorQuery) []Msg
+		expected  []Msg
+	}{
+		{
+			name: "both answered",
+			responses: func(*colorQuery) []Msg {
+				return []Msg{
+					ForegroundColorMsg{Color: "#cdd6f4"},
+					BackgroundColorMsg{Color: "#1e1e2e"},
+					primaryDeviceAttributesMsg{62},
+				}
+			},
+		},
+		{
+			name: "background only",
+			responses: func(*colorQuery) []Msg {
+				return []Msg{BackgroundColorMsg{Color: "#1e1e2e"}, primaryDeviceAttributesMsg{62}}
+			},
+			expected: []Msg{ForegroundColorMsg{}},
+		},
+		{
+			name: "no answers",
+			responses: func(*colorQuery) []Msg {
+				return []Msg{primaryDeviceAttributesMsg{62}}
+			},
+			expected: []Msg{ForegroundColorMsg{}, BackgroundColorMsg{}},
+		},
+		{
+			name: "timeout",
+			responses: func(q *colorQuery) []Msg {
+				return []Msg{queryColorsTimeoutMsg{query: q}}
+			},
+			expected: []Msg{ForegroundColorMsg{}, BackgroundColorMsg{}},
+		},
+		{
+			name: "timeout of an earlier query",
+			responses: func(*colorQuery) []Msg {
+				return []Msg{queryColorsTimeoutMsg{query: stale}}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := NewProgram(nil, WithOutput(&bytes.Buffer{}))
+			p.colorQuery = &colorQuery{}
+			for _, msg := range test.responses(p.colorQuery) {
+				p.handleColorResponse(msg)
+			}
+
+			var msgs []Msg
+			for len(msgs) < len(test.expected) {
+				select {
+				case msg := <-p.msgs:
+					msgs = append(msgs, msg)
+				case <-time.After(time.Second):
+					t.Fatalf("expected %#v, got %#v", test.expected, msgs)
+				}
+			}
+			if !reflect.DeepEqual(msgs, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, msgs)
+			}
+			select {
+			case msg := <-p.msgs:
+				t.Errorf("unexpected message %#v", msg)
+			case <-time.After(10 * time.Millisecond):
+			}
+		})
+	}
+}
+
+type terminalColorsModel struct {
+	msgs *[]Msg
+}
+
+func (m terminalColorsModel) Init() Cmd {
+	return QueryTerminalColors
+}
+
+func (m terminalColorsModel) Update(msg Msg) (Model, Cmd) {
+	switch msg.(type) {
+	case ForegroundColorMsg:
+		*m.msgs = append(*m.msgs, msg)
+	case BackgroundColorMsg:
+		*m.msgs = append(*m.msgs, msg)
+		return m, Quit
+	}
+	return m, nil
+}
+
+func (m terminalColorsModel) View() string {
+	return ""
+}
+
+func TestQueryTerminalColors(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+
+	var msgs []Msg
+	p := NewProgram(terminalColorsModel{msgs: &msgs}, WithInput(&in), WithOutput(&buf))
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A terminal that can't be queried has its colors reported as unknown.
+	expected := []Msg{ForegroundColorMsg{}, BackgroundColorMsg{}}
+	if !reflect.DeepEqual(msgs, expected) {
+		t.Errorf("expected %#v, got %#v", expected, msgs)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("\x1b]11;?")) {
+		t.Errorf("expected no queries to be sent to a non-terminal, got %q", buf.String())
+	}
+}
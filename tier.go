@@ -0,0 +1,145 @@
+package tea
+
+import "strings"
+
+// Tier is a coarse measure of what a terminal supports. Rather than deciding
+// about every feature on its own, Bubble Tea degrades them together based on
+// the tier, so that programs look and behave consistently on less capable
+// terminals.
+//
+// The tier is detected from the environment when the program starts. It can
+// be overridden with WithTier or, by users, with the BUBBLETEA_TIER
+// environment variable, which takes precedence. The variable accepts the
+// names returned by Tier.String.
+type Tier int
+
+// Available tiers, from least to most capable.
+const (
+	// TierDumb terminals only display text. Bubble Tea doesn't enable any
+	// terminal modes and drops all text attributes.
+	TierDumb Tier = iota
+
+	// TierBasic terminals understand basic ANSI sequences, such as cursor
+	// movement, colors and the altscreen, but none of xterm's extensions.
+	TierBasic
+
+	// TierXterm terminals support xterm's extensions, such as mouse
+	// tracking, bracketed paste, window titles, cursor shapes and setting the
+	// clipboard. This is assumed for terminals we don't know.
+	TierXterm
+
+	// TierModern terminals additionally support recent extensions, such as
+	// the kitty keyboard protocol, hyperlinks, synchronized output and inline
+	// graphics.
+	TierModern
+)
+
+// tierEnvVar is the environment variable which overrides the detected tier.
+const tierEnvVar = "BUBBLETEA_TIER"
+
+// String returns the name of the tier.
+func (t Tier) String() string {
+	switch t {
+	case TierDumb:
+		return "dumb"
+	case TierBasic:
+		return "basic"
+	case TierXterm:
+		return "xterm"
+	case TierModern:
+		return "modern"
+	}
+	return "unknown"
+}
+
+// parseTier returns the tier with the given name.
+func parseTier(s string) (Tier, bool) {
+	for t := TierDumb; t <= TierModern; t++ {
+		if strings.EqualFold(s, t.String()) {
+			return t, true
+		}
+	}
+	return TierDumb, false
+}
+
+// Feature is a terminal feature whose use depends on the tier. Components
+// which use a feature directly, for example by printing hyperlinks, can check
+// for it with Capabilities.Supports.
+type Feature int
+
+// Features which depend on the tier.
+const (
+	FeatureAltScreen Feature = iota
+	FeatureMouse
+	FeatureBracketedPaste
+	FeatureWindowTitle
+	FeatureCursorShape
+	FeatureClipboard
+	FeatureKeyboardEnhancements
+	FeatureHyperlinks
+	FeatureSyncOutput
+	FeatureGraphics
+	FeatureNotifications
+)
+
+// minTier returns the least capable tier which supports the feature.
+func (f Feature) minTier() Tier {
+	switch f {
+	case FeatureAltScreen:
+		return TierBasic
+	case FeatureKeyboardEnhancements, FeatureHyperlinks, FeatureSyncOutput, FeatureGraphics:
+		return TierModern
+	}
+	return TierXterm
+}
+
+// Supports reports whether the terminal's tier supports the given feature.
+func (c Capabilities) Supports(f Feature) bool {
+	return c.Tier >= f.minTier()
+}
+
+// detectTier determines the tier of the given terminal from the environment.
+// Terminals we don't know are assumed to be xterm compatible, which is what
+// Bubble Tea has always assumed.
+func detectTier(term string, getenv func(string) string) Tier {
+	if t, ok := parseTier(getenv(tierEnvVar)); ok {
+		return t
+	}
+	if getenv("TERM") == "dumb" {
+		return TierDumb
+	}
+
+	switch term {
+	case termLinuxConsole:
+		return TierBasic
+	case termAlacritty, termFoot, termGhostty, termITerm2, termKitty,
+		termVSCode, termVTE, termWezTerm:
+		return TierModern
+	}
+	return TierXterm
+}
+
+// featureFor returns the feature an internal message relies on, if any.
+func featureFor(msg Msg) (Feature, bool) {
+	switch msg.(type) {
+	case enterAltScreenMsg:
+		return FeatureAltScreen, true
+	case enableMouseCellMotionMsg, enableMouseAllMotionMsg,
+		enableMouseHighlightTrackingMsg, enableAlternateScrollMsg:
+		return FeatureMouse, true
+	case enableBracketedPasteMsg:
+		return FeatureBracketedPaste, true
+	case setWindowTitleMsg, setPaneTitleMsg, setTabTitleMsg, pushWindowTitleMsg, popWindowTitleMsg:
+		return FeatureWindowTitle, true
+	case setCursorShapeMsg:
+		return FeatureCursorShape, true
+	case placeImageMsg:
+		return FeatureGraphics, true
+	}
+	return 0, false
+}
+
+// supports reports whether the program's tier supports the given feature.
+func (p *Program) supports(f Feature) bool {
+	return p.tier >= f.minTier()
+}
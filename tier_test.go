@@ -0,0 +1,107 @@
+package tea
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDetectTier(t *testing.T) {
+	tests := []struct {
+		name     string
+		term     string
+		env      map[string]string
+		expected Tier
+	}{
+		{"unknown", termUnknown, map[string]string{}, TierXterm},
+		{"dumb", termUnknown, map[string]string{"TERM": "dumb"}, TierDumb},
+		{"linux console", termLinuxConsole, map[string]string{"TERM": "linux"}, TierBasic},
+		{"xterm", termXterm, map[string]string{"TERM": "xterm-256color"}, TierXterm},
+		{"tmux", termTmux, map[string]string{"TERM": "tmux-256color"}, TierXterm},
+		{"kitty", termKitty, map[string]string{"TERM": "xterm-kitty"}, TierModern},
+		{"override", termKitty, map[string]string{"BUBBLETEA_TIER": "Basic"}, TierBasic},
+		{"invalid override", termKitty, map[string]string{"BUBBLETEA_TIER": "fancy"}, TierModern},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			getenv := func(k string) string { return test.env[k] }
+			if got := detectTier(test.term, getenv); got != test.expected {
+				t.Errorf("expected tier %s, got %s", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestCapabilitiesSupports(t *testing.T) {
+	tests := []struct {
+		tier        Tier
+		supported   []Feature
+		unsupported []Feature
+	}{
+		{TierDumb, nil, []Feature{FeatureAltScreen, FeatureMouse}},
+		{TierBasic, []Feature{FeatureAltScreen}, []Feature{FeatureMouse, FeatureClipboard, FeatureNotifications}},
+		{TierXterm, []Feature{FeatureMouse, FeatureWindowTitle}, []Feature{FeatureHyperlinks, FeatureSyncOutput}},
+		{TierModern, []Feature{FeatureMouse, FeatureHyperlinks, FeatureGraphics, FeatureNotifications}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.tier.String(), func(t *testing.T) {
+			caps := Capabilities{Tier: test.tier}
+			for _, f := range test.supported {
+				if !caps.Supports(f) {
+					t.Errorf("expected feature %d to be supported", f)
+				}
+			}
+			for _, f := range test.unsupported {
+				if caps.Supports(f) {
+					t.Errorf("expected feature %d not to be supported", f)
+				}
+			}
+		})
+	}
+}
+
+func TestTierDegradation(t *testing.T) {
+	tests := []struct {
+		name     string
+		tier     Tier
+		cmds     sequenceMsg
+		expected string
+	}{
+		{
+			name:     "basic",
+			tier:     TierBasic,
+			cmds:     []Cmd{EnableMouseCellMotion, SetWindowTitle("foo"), SetCursorShape(CursorBar)},
+			expected: "\x1b[?25lsuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[?2004l",
+		},
+		{
+			name:     "xterm",
+			tier:     TierXterm,
+			cmds:     []Cmd{EnableMouseCellMotion},
+			expected: "\x1b[?25l\x1b[?2004h\x1b[?1002hsuccess\r\n\x1b[0D\x1b[2K\x1b[?25h\x1b[?1002l\x1b[?1003l\x1b[?2004l",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv(tierEnvVar, "")
+
+			var buf bytes.Buffer
+			var in bytes.Buffer
+
+			m := &testModel{}
+			p := NewProgram(m, WithInput(&in), WithOutput(&buf), WithTier(test.tier))
+
+			test.cmds = append(test.cmds, Quit)
+			go p.Send(test.cmds)
+
+			if _, err := p.Run(); err != nil {
+				t.Fatal(err)
+			}
+
+			if buf.String() != test.expected {
+				t.Errorf("expected embedded sequence:\n%q\ngot:\n%q", test.expected, buf.String())
+			}
+		})
+	}
+}
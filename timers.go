@@ -0,0 +1,93 @@
+package tea
+
+import (
+	"sync"
+	"time"
+)
+
+// timerClock pauses the timers of Tick and Every while a program isn't on
+// screen, that is while it has released the terminal to run another process
+// or to be suspended. Otherwise all ticks which came due in the meantime
+// would arrive in a burst as soon as the program is back, and animations
+// would jump ahead.
+//
+// Commands don't know which program runs them, so the clock is shared by all
+// programs in the process. Timers pause while any of them is released.
+type timerClock struct {
+	mtx    sync.Mutex
+	pauses int
+
+	// closed and replaced whenever the clock pauses or resumes
+	change chan struct{}
+}
+
+// timers is the clock of Tick and Every.
+var timers = &timerClock{change: make(chan struct{})}
+
+// pause pauses timers until a matching call to resume.
+func (c *timerClock) pause() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.pauses++
+	if c.pauses == 1 {
+		c.notify()
+	}
+}
+
+// resume resumes timers paused by pause.
+func (c *timerClock) resume() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.pauses == 0 {
+		return
+	}
+	c.pauses--
+	if c.pauses == 0 {
+		c.notify()
+	}
+}
+
+func (c *timerClock) notify() {
+	close(c.change)
+	c.change = make(chan struct{})
+}
+
+// state reports whether timers are paused, and returns a channel which is
+// closed when that changes.
+func (c *timerClock) state() (bool, <-chan struct{}) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	return c.pauses > 0, c.change
+}
+
+// sleep waits until d has passed while timers weren't paused, and returns
+// the time it woke up. If realign is given, it determines how long to wait
+// after timers were resumed, rather than waiting for the rest of d.
+func (c *timerClock) sleep(d time.Duration, realign func() time.Duration) time.Time {
+	for {
+		paused, change := c.state()
+		if paused {
+			<-change
+			if realign != nil {
+				d = realign()
+			}
+			continue
+		}
+
+		start := time.Now()
+		t := time.NewTimer(d)
+		select {
+		case now := <-t.C:
+			return now
+
+		case <-change:
+			t.Stop()
+			if d -= time.Since(start); d < 0 {
+				d = 0
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package tea
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestTimerClockPause(t *testing.T) {
+	c := &timerClock{change: make(chan struct{})}
+
+	start := time.Now()
+	done := make(chan time.Time)
+	go func() {
+		done <- c.sleep(50*time.Millisecond, nil)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	c.pause()
+	c.pause()
+	c.resume()
+
+	select {
+	case <-done:
+		t.Fatal("expected the timer not to fire while paused")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	resumed := time.Now()
+	c.resume()
+	woke := <-done
+
+	if woke.Sub(start) < 150*time.Millisecond {
+		t.Errorf("expected the timer to be delayed by the pause, woke after %v", woke.Sub(start))
+	}
+	if rest := woke.Sub(resumed); rest > 45*time.Millisecond {
+		t.Errorf("expected the timer to wait for the rest of its duration, waited %v", rest)
+	}
+}
+
+func TestTimerClockRealign(t *testing.T) {
+	c := &timerClock{change: make(chan struct{})}
+	c.pause()
+
+	done := make(chan time.Time)
+	go func() {
+		done <- c.sleep(time.Hour, func() time.Duration { return 0 })
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	c.resume()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the timer to be realigned after resuming")
+	}
+}
+
+func TestReleaseTerminalPausesTimers(t *testing.T) {
+	p := NewProgram(nil, WithInput(&bytes.Buffer{}))
+	p.renderer = &nilRenderer{}
+	if err := p.initCancelReader(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.ReleaseTerminal(); err != nil {
+		t.Fatal(err)
+	}
+	if paused, _ := timers.state(); !paused {
+		t.Error("expected timers to be paused while the terminal is released")
+	}
+
+	p.resumeTimers()
+	if paused, _ := timers.state(); paused {
+		t.Error("expected timers to be resumed")
+	}
+}
@@ -0,0 +1,189 @@
+package tea
+
+import "strings"
+
+// setWindowTitleMsg is an internal message used to set the window title. You
+// can send a setWindowTitleMsg with SetWindowTitle.
+type setWindowTitleMsg string
+
+// SetWindowTitle produces a command that sets the terminal window title.
+//
+// For example:
+//
+//	func (m model) Init() Cmd {
+//	    // Set title.
+//	    return tea.SetWindowTitle("My App")
+//	}
+func SetWindowTitle(title string) Cmd {
+	return func() Msg {
+		return setWindowTitleMsg(title)
+	}
+}
+
+// setPaneTitleMsg is an internal message used to set the title of the
+// multiplexer pane the program is running in. You can send a setPaneTitleMsg
+// with SetPaneTitle.
+type setPaneTitleMsg string
+
+// SetPaneTitle produces a command that sets the title of the terminal
+// multiplexer pane the program is running in. In tmux this is the pane title
+// (#{pane_title}); in GNU screen it's the window title.
+//
+// Outside of a multiplexer this command does nothing, so it's safe to use
+// alongside SetWindowTitle.
+func SetPaneTitle(title string) Cmd {
+	return func() Msg {
+		return setPaneTitleMsg(title)
+	}
+}
+
+// setTabTitleMsg is an internal message used to set the tab title. You can
+// send a setTabTitleMsg with SetTabTitle.
+type setTabTitleMsg string
+
+// SetTabTitle produces a command that sets the title of the terminal tab the
+// program is running in, where it differs from the window title. In tmux and
+// GNU screen this renames the current window, which is what's shown in the
+// status line. In terminals such as iTerm2 and WezTerm this sets the tab
+// title independently of the window title.
+func SetTabTitle(title string) Cmd {
+	return func() Msg {
+		return setTabTitleMsg(title)
+	}
+}
+
+// pushWindowTitleMsg is an internal message used to save the window title.
+// You can send a pushWindowTitleMsg with PushWindowTitle.
+type pushWindowTitleMsg struct{}
+
+// PushWindowTitle is a command that saves the window and tab titles on the
+// terminal's title stack, so that they can be restored with PopWindowTitle
+// after changing them.
+//
+// There's no need to save the titles the terminal had before the program
+// started: they're saved the first time the program changes them, and
+// restored when it exits, and while the terminal is released.
+func PushWindowTitle() Msg {
+	return pushWindowTitleMsg{}
+}
+
+// popWindowTitleMsg is an internal message used to restore the window title.
+// You can send a popWindowTitleMsg with PopWindowTitle.
+type popWindowTitleMsg struct{}
+
+// PopWindowTitle is a command that restores the window and tab titles last
+// saved with PushWindowTitle. If there are none, it restores the titles the
+// terminal had before the program changed them.
+func PopWindowTitle() Msg {
+	return popWindowTitleMsg{}
+}
+
+// Sequences that save and restore the window and tab titles.
+const (
+	pushTitleSeq = "\x1b[22;0t"
+	popTitleSeq  = "\x1b[23;0t"
+)
+
+// titles are the window and tab titles the program has set. They're empty if
+// the program hasn't set them.
+type titles struct {
+	window string
+	tab    string
+}
+
+// apply sets the titles the program has set.
+func (t titles) apply(p *Program) {
+	if t.window != "" {
+		_ = p.renderer.execute(windowTitleSeq(t.window))
+	}
+	if t.tab != "" {
+		_ = p.renderer.execute(tabTitleSeq(p.terminal, t.tab))
+	}
+}
+
+// saveTitles saves the terminal's titles before the program first changes
+// them.
+func (p *Program) saveTitles() {
+	if len(p.titleStack) == 0 {
+		p.pushTitles()
+	}
+}
+
+// pushTitles saves the current titles on the terminal's title stack.
+func (p *Program) pushTitles() {
+	_ = p.renderer.execute(pushTitleSeq)
+	p.titleStack = append(p.titleStack, p.titles)
+}
+
+// popTitles restores the titles last saved. Titles saved by anything other
+// than the program are left alone.
+func (p *Program) popTitles() {
+	if len(p.titleStack) == 0 {
+		return
+	}
+	_ = p.renderer.execute(popTitleSeq)
+	p.titles = p.titleStack[len(p.titleStack)-1]
+	p.titleStack = p.titleStack[:len(p.titleStack)-1]
+}
+
+// releaseTitles restores the titles the terminal had before the program
+// changed them, emptying its title stack.
+func (p *Program) releaseTitles() {
+	_ = p.renderer.execute(strings.Repeat(popTitleSeq, len(p.titleStack)))
+}
+
+// reclaimTitles rebuilds the terminal's title stack as it was before the
+// terminal was released, and sets the program's titles again.
+func (p *Program) reclaimTitles() {
+	for i, t := range p.titleStack {
+		if i > 0 {
+			t.apply(p)
+		}
+		_ = p.renderer.execute(pushTitleSeq)
+	}
+	p.titles.apply(p)
+}
+
+// sanitizeTitle removes control characters from a title so it can't terminate
+// the escape sequence it's embedded in early.
+func sanitizeTitle(title string) string {
+	return strings.Map(func(r rune) rune {
+		if r < ' ' || r == 0x7f || (r >= 0x80 && r < 0xa0) {
+			return -1
+		}
+		return r
+	}, title)
+}
+
+// windowTitleSeq returns the sequence that sets the window title.
+func windowTitleSeq(title string) string {
+	return "\x1b]2;" + sanitizeTitle(title) + "\a"
+}
+
+// paneTitleSeq returns the sequence that sets the pane title in the given
+// terminal, if any.
+func paneTitleSeq(term, title string) string {
+	switch term {
+	case termTmux:
+		return "\x1b]2;" + sanitizeTitle(title) + "\x1b\\"
+	case termScreen:
+		return "\x1bk" + sanitizeTitle(title) + "\x1b\\"
+	default:
+		return ""
+	}
+}
+
+// tabTitleSeq returns the sequence that sets the tab title in the given
+// terminal.
+func tabTitleSeq(term, title string) string {
+	switch term {
+	case termTmux, termScreen:
+		// Renames the multiplexer window. In tmux this requires the
+		// allow-rename option to be enabled.
+		return "\x1bk" + sanitizeTitle(title) + "\x1b\\"
+	default:
+		// iTerm2, WezTerm and most others use the icon name as the tab
+		// title.
+		return "\x1b]1;" + sanitizeTitle(title) + "\a"
+	}
+}
@@ -0,0 +1,100 @@
+package tea
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestTitleSequences(t *testing.T) {
+	tests := []struct {
+		name     string
+		seq      string
+		expected string
+	}{
+		{"window", windowTitleSeq("foo"), "\x1b]2;foo\a"},
+		{"window sanitized", windowTitleSeq("foo\abar\x1b\\"), "\x1b]2;foobar\\\a"},
+		{"pane tmux", paneTitleSeq(termTmux, "foo"), "\x1b]2;foo\x1b\\"},
+		{"pane screen", paneTitleSeq(termScreen, "foo"), "\x1bkfoo\x1b\\"},
+		{"pane without multiplexer", paneTitleSeq(termITerm2, "foo"), ""},
+		{"tab tmux", tabTitleSeq(termTmux, "foo"), "\x1bkfoo\x1b\\"},
+		{"tab iterm2", tabTitleSeq(termITerm2, "foo"), "\x1b]1;foo\a"},
+		{"tab wezterm", tabTitleSeq(termWezTerm, "foo"), "\x1b]1;foo\a"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.seq != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, test.seq)
+			}
+		})
+	}
+}
+
+// titleSeqs matches the sequences that set, save and restore titles.
+var titleSeqs = regexp.MustCompile("\x1b\\[2[23];0t|\x1b\\]2;[^\a]*\a")
+
+type titleModel struct{}
+
+func (m titleModel) Init() Cmd {
+	return Sequence(
+		PopWindowTitle,
+		SetWindowTitle("a"),
+		PushWindowTitle,
+		SetWindowTitle("b"),
+		PopWindowTitle,
+		PushWindowTitle,
+		Quit,
+	)
+}
+
+func (m titleModel) Update(msg Msg) (Model, Cmd) {
+	return m, nil
+}
+
+func (m titleModel) View() string {
+	return ""
+}
+
+func TestTitleStack(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+
+	p := NewProgram(titleModel{}, WithInput(&in), WithOutput(&buf))
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The terminal's own title is saved before it's first changed, and all
+	// that's been saved is restored on exit. Popping titles the program
+	// didn't save does nothing.
+	expected := []string{
+		pushTitleSeq, "\x1b]2;a\a",
+		pushTitleSeq, "\x1b]2;b\a",
+		popTitleSeq,
+		pushTitleSeq,
+		popTitleSeq, popTitleSeq,
+	}
+	if seqs := titleSeqs.FindAllString(buf.String(), -1); !reflect.DeepEqual(seqs, expected) {
+		t.Errorf("expected %q, got %q", expected, seqs)
+	}
+	if p.titles.window != "a" {
+		t.Errorf("expected title %q, got %q", "a", p.titles.window)
+	}
+}
+
+func TestReclaimTitles(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewProgram(nil, WithOutput(&buf))
+	p.renderer = newRenderer(p.output, false)
+	p.titleStack = []titles{{}, {window: "a"}}
+	p.titles = titles{window: "b"}
+
+	p.reclaimTitles()
+
+	expected := []string{pushTitleSeq, "\x1b]2;a\a", pushTitleSeq, "\x1b]2;b\a"}
+	if seqs := titleSeqs.FindAllString(buf.String(), -1); !reflect.DeepEqual(seqs, expected) {
+		t.Errorf("expected %q, got %q", expected, seqs)
+	}
+}
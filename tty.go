@@ -4,8 +4,10 @@ import (
 	"errors"
 	"io"
 	"os"
+	"sync/atomic"
 	"time"
 
+	"github.com/charmbracelet/bubbletea/input"
 	isatty "github.com/mattn/go-isatty"
 	"github.com/muesli/cancelreader"
 	"golang.org/x/term"
@@ -33,8 +35,30 @@ func (p *Program) initTerminal() error {
 func (p *Program) restoreTerminalState() error {
 	if p.renderer != nil {
 		p.renderer.showCursor()
+		if p.renderer.cursor().shape != CursorDefault {
+			p.renderer.setCursorShape(CursorDefault)
+		}
 		p.renderer.disableMouseCellMotion()
 		p.renderer.disableMouseAllMotion()
+		if p.highlight.enabled {
+			_ = p.renderer.execute(disableHighlightTracking)
+		}
+		if p.keyboardFlags != 0 {
+			_ = p.renderer.execute(disableKeyboardEnhancements)
+		}
+		if p.win32Input {
+			_ = p.renderer.execute(disableWin32InputMode)
+		}
+		if p.appKeypad {
+			_ = p.renderer.execute(disableAppKeypad)
+		}
+		if len(p.palette) > 0 {
+			_ = p.renderer.execute(resetPaletteColorsSeq(p.changedPaletteColors()))
+		}
+		if len(p.titleStack) > 0 {
+			p.releaseTitles()
+		}
+		p.renderer.disableBracketedPaste()
 
 		if p.renderer.altScreen() {
 			p.renderer.exitAltScreen()
@@ -62,21 +86,50 @@ func (p *Program) initCancelReader() error {
 		return err
 	}
 
+	cfg := p.inputConfig
+	cfg.timestamps = true
+	p.decoder = newInputDecoder(p.cancelReader, cfg)
+	p.decoder.ExpectDCS(p.dcsExpected)
+
 	p.readLoopDone = make(chan struct{})
-	go p.readLoop()
+	go p.readLoop(p.decoder)
 
 	return nil
 }
 
-func (p *Program) readLoop() {
+// expectDCS sets whether the input is to contain responses to DECRQSS and
+// XTGETTCAP queries, which the decoder would otherwise take for keys.
+func (p *Program) expectDCS(expect bool) {
+	p.dcsExpected = expect
+	if p.decoder != nil {
+		p.decoder.ExpectDCS(expect)
+	}
+}
+
+func (p *Program) readLoop(dec *input.Decoder) {
 	defer close(p.readLoopDone)
 
+	send := func(msg Msg) {
+		select {
+		case <-p.ctx.Done():
+		case p.msgs <- msg:
+		}
+	}
+	var motion *motionCoalescer
+	if p.motionInterval > 0 {
+		motion = &motionCoalescer{interval: p.motionInterval, send: send}
+	}
+	var gestures *gestureRecognizer
+	if p.gestures {
+		gestures = &gestureRecognizer{}
+	}
+
 	for {
 		if p.ctx.Err() != nil {
 			return
 		}
 
-		msgs, err := readInputs(p.cancelReader)
+		msgs, err := readMsgs(dec)
 		if err != nil {
 			if !errors.Is(err, io.EOF) && !errors.Is(err, cancelreader.ErrCanceled) {
 				select {
@@ -88,8 +141,42 @@ func (p *Program) readLoop() {
 			return
 		}
 
+		now := time.Now()
+		atomic.StoreInt64(&p.lastInput, now.UnixNano())
+
+		deliver := func(msg Msg) {
+			isMotion := isMouseMotion(msg)
+			if p.latency != nil {
+				switch msg.(type) {
+				case KeyMsg, MouseMsg:
+					msg = timedInputMsg{msg: msg, time: now}
+				}
+			}
+			if motion != nil {
+				motion.deliver(msg, isMotion, now)
+				return
+			}
+			send(msg)
+		}
 		for _, msg := range msgs {
-			p.msgs <- msg
+			if _, ok := msg.(KeyReleaseMsg); ok && p.keyboardRequested&KeyboardReportEvents == 0 {
+				// Reported in win32-input-mode, but not asked for.
+				continue
+			}
+			if p.keyTranslator != nil {
+				var ok bool
+				if msg, ok = translateKey(p.keyTranslator, msg); !ok {
+					continue
+				}
+			}
+			var gesture Msg
+			if gestures != nil {
+				gesture = gestures.track(msg, now)
+			}
+			p.keySequences.track(msg, deliver)
+			if gesture != nil {
+				p.keySequences.track(gesture, deliver)
+			}
 		}
 	}
 }
@@ -105,13 +192,50 @@ func (p *Program) waitForReadLoop() {
 	}
 }
 
-// checkResize detects the current size of the output and informs the program
-// via a WindowSizeMsg.
+// checkResize detects the current size of the output, unless a fixed size has
+// been set with WithFixedWindowSize, and informs the program via a
+// WindowSizeMsg.
 func (p *Program) checkResize() {
+	if msg, ok := p.windowSize(); ok {
+		p.sendSize(msg)
+	}
+}
+
+// checkResizeChanged is like checkResize, but only informs the program if the
+// size differs from the one it was last sent, as terminals may signal a resize
+// more than once for the same size.
+func (p *Program) checkResizeChanged() {
+	msg, ok := p.windowSize()
+	if !ok {
+		return
+	}
+	p.sizeMtx.Lock()
+	same := p.sentSize == msg
+	p.sizeMtx.Unlock()
+	if !same {
+		p.sendSize(msg)
+	}
+}
+
+// sendSize sends the program a WindowSizeMsg, and remembers the size sent.
+func (p *Program) sendSize(msg WindowSizeMsg) {
+	p.sizeMtx.Lock()
+	p.sentSize = msg
+	p.sizeMtx.Unlock()
+	p.Send(msg)
+}
+
+// windowSize returns the current size of the output, or the fixed size set
+// with WithFixedWindowSize. It reports false if the size can't be detected.
+func (p *Program) windowSize() (WindowSizeMsg, bool) {
+	if p.fixedSize != nil {
+		return *p.fixedSize, true
+	}
+
 	f, ok := p.output.TTY().(*os.File)
 	if !ok || !isatty.IsTerminal(f.Fd()) {
 		// can't query window size
-		return
+		return WindowSizeMsg{}, false
 	}
 
 	w, h, err := term.GetSize(int(f.Fd()))
@@ -121,11 +245,21 @@ func (p *Program) checkResize() {
 		case p.errs <- err:
 		}
 
-		return
+		return WindowSizeMsg{}, false
+	}
+
+	// Where the system doesn't know the size in pixels, it's worked out
+	// from the size of the cells, if the terminal has reported it.
+	wp, hp := windowPixels(f)
+	if wp <= 0 || hp <= 0 {
+		cw, ch := p.cellSize()
+		wp, hp = w*cw, h*ch
 	}
 
-	p.Send(WindowSizeMsg{
-		Width:  w,
-		Height: h,
-	})
+	return WindowSizeMsg{
+		Width:        w,
+		Height:       h,
+		WidthPixels:  wp,
+		HeightPixels: hp,
+	}, true
 }
@@ -7,6 +7,7 @@ import (
 	"os"
 
 	"github.com/containerd/console"
+	"golang.org/x/sys/unix"
 )
 
 func (p *Program) initInput() error {
@@ -33,6 +34,16 @@ func (p *Program) restoreInput() error {
 	return nil
 }
 
+// windowPixels returns the size in pixels of the terminal f is, or zeros if
+// the system doesn't know it.
+func windowPixels(f *os.File) (int, int) {
+	ws, err := unix.IoctlGetWinsize(int(f.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0, 0
+	}
+	return int(ws.Xpixel), int(ws.Ypixel)
+}
+
 func openInputTTY() (*os.File, error) {
 	f, err := os.Open("/dev/tty")
 	if err != nil {
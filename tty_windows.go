@@ -37,6 +37,12 @@ func (p *Program) restoreInput() error {
 	return nil
 }
 
+// windowPixels returns zeros, as Windows doesn't report the size of the
+// console in pixels.
+func windowPixels(f *os.File) (int, int) {
+	return 0, 0
+}
+
 // Open the Windows equivalent of a TTY.
 func openInputTTY() (*os.File, error) {
 	f, err := os.OpenFile("CONIN$", os.O_RDWR, 0644)
@@ -0,0 +1,120 @@
+package tea
+
+import (
+	"context"
+)
+
+// TypedModel is a model whose Update returns a model of its own type M,
+// rather than a Model. It's run with a Program2, which hands the final model
+// back as an M, too, so that neither Update nor the caller of Run need to
+// assert models back to their own type:
+//
+//	func (m model) Update(msg tea.Msg) (model, tea.Cmd) {
+//	    if _, ok := msg.(tea.KeyMsg); ok {
+//	        return m, tea.Quit
+//	    }
+//	    return m, nil
+//	}
+//
+// A TypedModel can implement the optional interfaces of models with the
+// same methods, except that UpdateErr of ErrorModel returns an M as well:
+//
+//	func (m model) UpdateErr(msg tea.Msg) (model, tea.Cmd, error)
+type TypedModel[M any] interface {
+	// Init is the first function that will be called. It returns an optional
+	// initial command. To not perform an initial command return nil.
+	Init() Cmd
+
+	// Update is called when a message is received. Use it to inspect messages
+	// and, in response, update the model and/or send a command.
+	Update(Msg) (M, Cmd)
+
+	// View renders the program's UI, which is just a string. The view is
+	// rendered after every Update.
+	View() string
+}
+
+// Program2 is a Program whose model is a TypedModel of type M, so that Run
+// returns the final model as an M:
+//
+//	p := tea.NewProgram2(model{}, tea.WithAltScreen())
+//	m, err := p.Run()
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Println(m.choice)
+//
+// Otherwise, a Program2 is used just like the Program it embeds.
+type Program2[M TypedModel[M]] struct {
+	*Program
+}
+
+// NewProgram2 creates a new Program2.
+func NewProgram2[M TypedModel[M]](model M, opts ...ProgramOption) *Program2[M] {
+	return &Program2[M]{Program: NewProgram(typedModel[M]{model: model}, opts...)}
+}
+
+// Run runs the program like Program.Run, and returns the final model as an M.
+func (p *Program2[M]) Run() (M, error) {
+	return finalTypedModel[M](p.Program.Run())
+}
+
+// RunContext runs the program like Program.RunContext, and returns the final
+// model as an M.
+func (p *Program2[M]) RunContext(ctx context.Context) (M, error) {
+	return finalTypedModel[M](p.Program.RunContext(ctx))
+}
+
+// finalTypedModel returns the final model of a Program2 as an M, along with
+// the error it was returned with. Nil models, which are returned with some
+// errors, are returned as the zero M.
+func finalTypedModel[M TypedModel[M]](model Model, err error) (M, error) {
+	m, _ := model.(typedModel[M])
+	return m.model, err
+}
+
+// typedModel runs a TypedModel as a Model.
+type typedModel[M TypedModel[M]] struct {
+	model M
+}
+
+func (m typedModel[M]) Init() Cmd {
+	return m.model.Init()
+}
+
+func (m typedModel[M]) Update(msg Msg) (Model, Cmd) {
+	model, cmd := m.model.Update(msg)
+	return typedModel[M]{model: model}, cmd
+}
+
+// UpdateErr calls the model's UpdateErr if it has one, and Update otherwise,
+// so that a TypedModel can be an ErrorModel, too.
+func (m typedModel[M]) UpdateErr(msg Msg) (Model, Cmd, error) {
+	if u, ok := any(m.model).(interface {
+		UpdateErr(Msg) (M, Cmd, error)
+	}); ok {
+		model, cmd, err := u.UpdateErr(msg)
+		return typedModel[M]{model: model}, cmd, err
+	}
+	model, cmd := m.Update(msg)
+	return model, cmd, nil
+}
+
+func (m typedModel[M]) View() string {
+	return m.model.View()
+}
+
+// unwrap returns the TypedModel, which implements the optional interfaces of
+// models, if any.
+func (m typedModel[M]) unwrap() any {
+	return m.model
+}
+
+// optionalModel returns the value to check for the optional interfaces of
+// models, such as CursorModel: the model itself, or the TypedModel it runs.
+func optionalModel(model Model) any {
+	if m, ok := model.(interface{ unwrap() any }); ok {
+		return m.unwrap()
+	}
+	return model
+}
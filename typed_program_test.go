@@ -0,0 +1,76 @@
+package tea
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+type typedModel1 struct {
+	msgs int
+}
+
+func (m typedModel1) Init() Cmd {
+	return func() Msg { return "update" }
+}
+
+func (m typedModel1) Update(msg Msg) (typedModel1, Cmd) {
+	m.msgs++
+	return m, Quit
+}
+
+func (m typedModel1) View() string {
+	return "view"
+}
+
+func (m typedModel1) Cursor() (x, y int, visible bool) {
+	return 2, 0, true
+}
+
+type typedErrorModel struct {
+	typedModel1
+}
+
+func (m typedErrorModel) Update(msg Msg) (typedErrorModel, Cmd) {
+	return m, nil
+}
+
+func (m typedErrorModel) UpdateErr(msg Msg) (typedErrorModel, Cmd, error) {
+	m.msgs++
+	return m, nil, errors.New("failed")
+}
+
+func TestProgram2(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+
+	p := NewProgram2(typedModel1{}, WithInput(&in), WithOutput(&buf))
+	m, err := p.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.msgs == 0 {
+		t.Errorf("expected the final model, got %+v", m)
+	}
+}
+
+func TestProgram2ErrorModel(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+
+	p := NewProgram2(typedErrorModel{}, WithInput(&in), WithOutput(&buf))
+	m, err := p.Run()
+	if err == nil || err.Error() != "failed" {
+		t.Errorf("expected the error of UpdateErr, got %v", err)
+	}
+	if m.msgs == 0 {
+		t.Errorf("expected the final model, got %+v", m)
+	}
+}
+
+func TestTypedModelOptionalInterfaces(t *testing.T) {
+	c := cursorOf(typedModel[typedModel1]{})
+	if c != (cursorPlacement{x: 2, visible: true}) {
+		t.Errorf("expected the typed model's cursor, got %+v", c)
+	}
+}
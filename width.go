@@ -0,0 +1,229 @@
+package tea
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/uniseg"
+)
+
+// These helpers measure, truncate and pad strings the same way the renderer
+// does. Components that need to line things up with the renderer's output,
+// such as tables and status bars, should use them rather than other width
+// implementations, which may subtly disagree on emoji and combining marks.
+//
+// All of them skip over ANSI escape sequences, which don't occupy any cells,
+// and operate on grapheme clusters rather than runes, so that combining marks
+// and emoji sequences joined with ZWJ are never split.
+
+// AmbiguousWidth is how many cells characters of ambiguous East Asian width
+// occupy. These are characters such as "±", "§" and the box-drawing
+// characters, which terminals and fonts for East Asian languages usually show
+// two cells wide, and others one cell wide. See SetAmbiguousWidth.
+type AmbiguousWidth int32
+
+// Ways to measure characters of ambiguous width.
+const (
+	// AmbiguousWidthAuto measures them as the locale suggests: they're
+	// wide in Chinese, Japanese and Korean locales, as detected from the
+	// LC_ALL, LC_CTYPE and LANG environment variables, unless the
+	// RUNEWIDTH_EASTASIAN environment variable says otherwise.
+	AmbiguousWidthAuto AmbiguousWidth = iota
+
+	// AmbiguousWidthNarrow measures them as one cell wide.
+	AmbiguousWidthNarrow
+
+	// AmbiguousWidthWide measures them as two cells wide.
+	AmbiguousWidthWide
+)
+
+// ambiguousWidth is how characters of ambiguous width are measured, see
+// SetAmbiguousWidth.
+var ambiguousWidth int32
+
+var (
+	narrowCondition = &runewidth.Condition{StrictEmojiNeutral: true}
+	wideCondition   = &runewidth.Condition{EastAsianWidth: true, StrictEmojiNeutral: true}
+)
+
+// SetAmbiguousWidth sets how many cells characters of ambiguous East Asian
+// width, such as "±" and the box-drawing characters, occupy, for terminals
+// whose width for them doesn't match the locale. Getting it wrong leaves
+// stray characters behind and lines out of alignment. By default, it's
+// detected from the locale.
+//
+// The setting is global rather than per program: it applies to the renderers
+// of all programs, and to StringWidth, Truncate and the other width helpers,
+// so that components measure text the way the renderer does. Set it before
+// starting a program.
+func SetAmbiguousWidth(w AmbiguousWidth) {
+	atomic.StoreInt32(&ambiguousWidth, int32(w))
+}
+
+// widthCondition returns the condition characters are measured with.
+func widthCondition() *runewidth.Condition {
+	switch AmbiguousWidth(atomic.LoadInt32(&ambiguousWidth)) {
+	case AmbiguousWidthNarrow:
+		return narrowCondition
+	case AmbiguousWidthWide:
+		return wideCondition
+	}
+	return runewidth.DefaultCondition
+}
+
+// StringWidth returns the number of cells s occupies in the terminal.
+func StringWidth(s string) int {
+	var width int
+	forEachCluster(s, func(seq string, w int) bool {
+		width += w
+		return true
+	})
+	return width
+}
+
+// Truncate shortens s so that it occupies at most width cells. If s had to be
+// shortened, tail is appended, within the given width. Escape sequences are
+// preserved, so styles are reset and hyperlinks are closed as they would be
+// in the original string.
+func Truncate(s string, width int, tail string) string {
+	if StringWidth(s) <= width {
+		return s
+	}
+
+	tailWidth := StringWidth(tail)
+	if tailWidth > width {
+		tail, tailWidth = "", 0
+	}
+
+	var b strings.Builder
+	var cur int
+	var truncated bool
+	forEachCluster(s, func(seq string, w int) bool {
+		switch {
+		case seq[0] == '\x1b':
+			// Escape sequences are kept, even past the truncation point.
+			b.WriteString(seq)
+		case !truncated && cur+w <= width-tailWidth:
+			b.WriteString(seq)
+			cur += w
+		case !truncated:
+			truncated = true
+			b.WriteString(tail)
+		}
+		return true
+	})
+	return b.String()
+}
+
+// PadRight appends spaces to s until it occupies width cells. Strings that
+// are already wider are returned unchanged.
+func PadRight(s string, width int) string {
+	if n := width - StringWidth(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
+
+// PadLeft prepends spaces to s until it occupies width cells. Strings that
+// are already wider are returned unchanged.
+func PadLeft(s string, width int) string {
+	if n := width - StringWidth(s); n > 0 {
+		return strings.Repeat(" ", n) + s
+	}
+	return s
+}
+
+// clusterWidth returns the number of cells a grapheme cluster occupies.
+func clusterWidth(cluster []rune) int {
+	var width int
+	cond := widthCondition()
+	for _, r := range cluster {
+		// Our best guess is the width of the first rune that occupies any
+		// space; the rest are usually combining marks and joiners.
+		if width = cond.RuneWidth(r); width > 0 {
+			break
+		}
+	}
+	if width == 1 && len(cluster) > 1 {
+		for _, r := range cluster {
+			// An emoji presentation selector turns text-style symbols,
+			// such as the heart in "❤️", into wide emoji. Pairs of regional
+			// indicators form flags, which are wide, too.
+			if r == '\uFE0F' || r >= '\U0001F1E6' && r <= '\U0001F1FF' {
+				return 2 //nolint:gomnd
+			}
+		}
+	}
+	return width
+}
+
+// forEachCluster calls fn for each escape sequence and grapheme cluster in s,
+// along with the number of cells it occupies. Escape sequences occupy zero
+// cells. Iteration stops when fn returns false.
+func forEachCluster(s string, fn func(seq string, width int) bool) {
+	for len(s) > 0 {
+		if n := escapeSequenceLen(s); n > 0 {
+			if !fn(s[:n], 0) {
+				return
+			}
+			s = s[n:]
+			continue
+		}
+
+		// Find the next escape sequence, if any, and segment the text
+		// before it into grapheme clusters.
+		end := strings.IndexByte(s, '\x1b')
+		if end < 0 {
+			end = len(s)
+		} else if end == 0 {
+			// A lone escape character that doesn't start a sequence.
+			end = 1
+		}
+
+		g := uniseg.NewGraphemes(s[:end])
+		for g.Next() {
+			if !fn(g.Str(), clusterWidth(g.Runes())) {
+				return
+			}
+		}
+		s = s[end:]
+	}
+}
+
+// escapeSequenceLen returns the length of the escape sequence at the start of
+// s, or zero if s doesn't start with one. CSI, OSC and other string
+// sequences, and two-character escapes are recognized. Unterminated
+// sequences extend to the end of s.
+func escapeSequenceLen(s string) int {
+	if len(s) < 2 || s[0] != '\x1b' { //nolint:gomnd
+		return 0
+	}
+
+	switch s[1] {
+	case '[':
+		// CSI: parameters and intermediates followed by a final byte.
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return i + 1
+			}
+		}
+		return len(s)
+
+	case ']', 'P', '_', '^', 'X':
+		// OSC, DCS, APC, PM and SOS are terminated by ST. OSC may also be
+		// terminated by BEL.
+		for i := 2; i < len(s); i++ {
+			if s[i] == '\a' && s[1] == ']' {
+				return i + 1
+			}
+			if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2 //nolint:gomnd
+			}
+		}
+		return len(s)
+
+	default:
+		return 2 //nolint:gomnd
+	}
+}
@@ -0,0 +1,146 @@
+package tea
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/muesli/termenv"
+)
+
+func TestStringWidth(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  int
+	}{
+		{"empty", "", 0},
+		{"ascii", "hello", 5},
+		{"wide", "你好", 4},
+		{"combining", "éé", 2},
+		{"zwj emoji", "👩‍💻", 2},
+		{"emoji presentation", "❤️", 2},
+		{"flag", "🇩🇪", 2},
+		{"sgr", "\x1b[1;31mred\x1b[0m", 3},
+		{"hyperlink", "\x1b]8;;https://charm.sh\x1b\\charm\x1b]8;;\x1b\\", 5},
+		{"osc bel", "\x1b]2;title\aok", 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := StringWidth(test.input); got != test.want {
+				t.Errorf("expected width %d, got %d", test.want, got)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		width int
+		tail  string
+		want  string
+	}{
+		{"fits", "hello", 5, "…", "hello"},
+		{"ascii", "hello world", 5, "", "hello"},
+		{"tail", "hello world", 5, "…", "hell…"},
+		{"tail too wide", "hello", 2, "...", "he"},
+		{"wide", "你好世界", 5, "", "你好"},
+		{"wide tail", "你好世界", 5, "…", "你好…"},
+		{"combining", "ééé", 2, "", "éé"},
+		{"zwj emoji", "a👩‍💻b", 2, "", "a"},
+		{"keeps escapes", "\x1b[31mhello\x1b[0m", 3, "", "\x1b[31mhel\x1b[0m"},
+		{"zero", "hello", 0, "", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Truncate(test.input, test.width, test.tail); got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestPad(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		width int
+		left  string
+		right string
+	}{
+		{"ascii", "ab", 4, "  ab", "ab  "},
+		{"wide", "你", 3, " 你", "你 "},
+		{"escapes", "\x1b[1mab\x1b[0m", 3, " \x1b[1mab\x1b[0m", "\x1b[1mab\x1b[0m "},
+		{"too wide", "abc", 2, "abc", "abc"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := PadLeft(test.input, test.width); got != test.left {
+				t.Errorf("PadLeft: expected %q, got %q", test.left, got)
+			}
+			if got := PadRight(test.input, test.width); got != test.right {
+				t.Errorf("PadRight: expected %q, got %q", test.right, got)
+			}
+		})
+	}
+}
+
+func TestAmbiguousWidth(t *testing.T) {
+	defer SetAmbiguousWidth(AmbiguousWidthAuto)
+
+	tests := []struct {
+		width AmbiguousWidth
+		want  int
+	}{
+		{AmbiguousWidthNarrow, 3},
+		{AmbiguousWidthWide, 6},
+	}
+	for _, test := range tests {
+		SetAmbiguousWidth(test.width)
+		if got := StringWidth("±─§"); got != test.want {
+			t.Errorf("expected width %d for ambiguous width %d, got %d", test.want, test.width, got)
+		}
+		// Characters of unambiguous width aren't affected.
+		if got := StringWidth("a你"); got != 3 {
+			t.Errorf("expected width 3 for ambiguous width %d, got %d", test.width, got)
+		}
+	}
+}
+
+func TestRendererWideCharacters(t *testing.T) {
+	var buf bytes.Buffer
+	r := newRenderer(termenv.NewOutput(&buf), false).(*standardRenderer)
+	r.width, r.height = 5, 5
+
+	// A wide character which doesn't fit at the edge of the window is left
+	// out rather than wrapped.
+	r.write("x\nabcd你")
+	r.flush()
+	if out := buf.String(); !strings.HasSuffix(out, "\r\nabcd\x1b[5D") {
+		t.Errorf("expected the wide character to be cut, got %q", out)
+	}
+
+	// A line that shrinks is erased past its new end.
+	buf.Reset()
+	r.write("x\n你")
+	r.flush()
+	if out := buf.String(); !strings.HasPrefix(out, "\x1b[2K") || !strings.Contains(out, "\r\n你") {
+		t.Errorf("expected the line to be cleared and repainted, got %q", out)
+	}
+
+	// Changing one wide character repaints it at its column.
+	buf.Reset()
+	r.write("x\n你好吗")
+	r.flush()
+	buf.Reset()
+	r.write("x\n你们吗")
+	r.flush()
+	if out := buf.String(); !strings.Contains(out, "\x1b[2C们") {
+		t.Errorf("expected only the changed character to be painted, got %q", out)
+	}
+}
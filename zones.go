@@ -0,0 +1,128 @@
+package tea
+
+// zone is a region of the screen registered with RegisterZone.
+type zone struct {
+	id   string
+	rect Rect
+
+	// whether holding a button in the zone repeats the press, see
+	// RegisterRepeatZone
+	repeat bool
+}
+
+// registerZoneMsg is an internal message that registers a zone. You can send
+// a registerZoneMsg with RegisterZone.
+type registerZoneMsg zone
+
+// unregisterZoneMsg is an internal message that removes a zone. You can send
+// an unregisterZoneMsg with UnregisterZone.
+type unregisterZoneMsg string
+
+// clearZonesMsg is an internal message that removes all zones. You can send a
+// clearZonesMsg with ClearZones.
+type clearZonesMsg struct{}
+
+// RegisterZone is a command that registers a zone: a region of the screen,
+// identified by id, which mouse events are tested against. Mouse events
+// within the region list the zone's id in MouseMsg.Zones, so that clickable
+// components don't need to know where they ended up on the screen, as long
+// as whoever lays them out registers their zones.
+//
+// Coordinates are zero-based, like those of MouseMsg. Registering an id again
+// moves its zone. Zones overlap in the reverse order they were registered:
+// zones registered last, which are usually drawn on top, are listed first.
+func RegisterZone(id string, x, y, width, height int) Cmd {
+	return func() Msg {
+		return registerZoneMsg{id: id, rect: Rect{X: x, Y: y, Width: width, Height: height}}
+	}
+}
+
+// RegisterRepeatZone is a command that registers a zone like RegisterZone,
+// in which holding a mouse button down repeats the press, like the arrow
+// buttons of a scroll bar. After a delay, copies of the press with
+// MouseMsg.Repeat set are sent at an interval until the button is released or
+// dragged out of the zone. Wheel events aren't repeated. The delay and the
+// interval can be set with WithMouseRepeat.
+func RegisterRepeatZone(id string, x, y, width, height int) Cmd {
+	return func() Msg {
+		return registerZoneMsg{id: id, rect: Rect{X: x, Y: y, Width: width, Height: height}, repeat: true}
+	}
+}
+
+// UnregisterZone is a command that removes the zone with the given id.
+func UnregisterZone(id string) Cmd {
+	return func() Msg {
+		return unregisterZoneMsg(id)
+	}
+}
+
+// ClearZones is a special command that removes all zones, for example before
+// registering those of a new layout.
+func ClearZones() Msg {
+	return clearZonesMsg{}
+}
+
+// Zones returns the ids of the zones the mouse event hit, see
+// MouseEvent.Zones.
+func (m MouseMsg) Zones() []string {
+	return MouseEvent(m).Zones()
+}
+
+// WithZones returns a copy of the mouse event listing the given zone ids,
+// which is handy to test how models handle clicks in zones.
+func (m MouseMsg) WithZones(ids ...string) MouseMsg {
+	return MouseMsg(MouseEvent(m).WithZones(ids...))
+}
+
+// InZone reports whether the mouse event hit the zone with the given id.
+func (m MouseMsg) InZone(id string) bool {
+	for _, z := range m.Zones() {
+		if z == id {
+			return true
+		}
+	}
+	return false
+}
+
+// zoneRegistry holds the registered zones, in the order they were
+// registered.
+type zoneRegistry []zone
+
+// register adds a zone, replacing any zone with the same id.
+func (r *zoneRegistry) register(z zone) {
+	r.unregister(z.id)
+	*r = append(*r, z)
+}
+
+// unregister removes the zone with the given id.
+func (r *zoneRegistry) unregister(id string) {
+	for i, z := range *r {
+		if z.id == id {
+			*r = append((*r)[:i], (*r)[i+1:]...)
+			return
+		}
+	}
+}
+
+// hits returns the ids of the zones containing the cell at x, y, the last
+// registered first.
+func (r zoneRegistry) hits(x, y int) []string {
+	var ids []string
+	for i := len(r) - 1; i >= 0; i-- {
+		if r[i].rect.Contains(x, y) {
+			ids = append(ids, r[i].id)
+		}
+	}
+	return ids
+}
+
+// repeats reports whether any of the zones containing the cell at x, y is a
+// repeat zone.
+func (r zoneRegistry) repeats(x, y int) bool {
+	for _, z := range r {
+		if z.repeat && z.rect.Contains(x, y) {
+			return true
+		}
+	}
+	return false
+}
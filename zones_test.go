@@ -0,0 +1,89 @@
+package tea
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestZoneRegistry(t *testing.T) {
+	var r zoneRegistry
+	r.register(zone{id: "pane", rect: Rect{X: 0, Y: 0, Width: 10, Height: 5}})
+	r.register(zone{id: "button", rect: Rect{X: 2, Y: 1, Width: 4, Height: 1}})
+
+	tests := []struct {
+		name     string
+		x, y     int
+		expected []string
+	}{
+		{"nested", 3, 1, []string{"button", "pane"}},
+		{"outer", 8, 3, []string{"pane"}},
+		{"outside", 10, 0, nil},
+	}
+	for _, test := range tests {
+		if got := r.hits(test.x, test.y); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s: expected zones %v, got %v", test.name, test.expected, got)
+		}
+	}
+
+	// Registering a zone again moves it.
+	r.register(zone{id: "pane", rect: Rect{X: 20, Y: 0, Width: 10, Height: 5}})
+	if got := r.hits(8, 3); got != nil {
+		t.Errorf("expected the zone to have moved, got %v", got)
+	}
+
+	r.unregister("button")
+	if got := r.hits(3, 1); got != nil {
+		t.Errorf("expected the zone to be removed, got %v", got)
+	}
+}
+
+func TestMouseZones(t *testing.T) {
+	var buf bytes.Buffer
+	var in bytes.Buffer
+	var mouse []MouseMsg
+
+	m := &testModel{}
+	p := NewProgram(m,
+		WithInput(&in),
+		WithOutput(&buf),
+		WithFilter(func(_ Model, msg Msg) Msg {
+			if msg, ok := msg.(MouseMsg); ok {
+				mouse = append(mouse, msg)
+			}
+			return msg
+		}))
+
+	click := func(x, y int) Cmd {
+		return func() Msg {
+			return MouseMsg{X: x, Y: y, Type: MouseLeft}
+		}
+	}
+	go p.Send(sequenceMsg{
+		RegisterZone("list", 0, 0, 20, 10),
+		RegisterZone("item", 0, 2, 20, 1),
+		click(5, 2),
+		UnregisterZone("item"),
+		click(5, 2),
+		ClearZones,
+		click(5, 2),
+		Quit,
+	})
+
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(mouse) != 3 {
+		t.Fatalf("expected 3 mouse messages, got %d", len(mouse))
+	}
+	if !mouse[0].InZone("item") || !mouse[0].InZone("list") {
+		t.Errorf("expected the first click to hit both zones, got %v", mouse[0].Zones())
+	}
+	if mouse[1].InZone("item") || !mouse[1].InZone("list") {
+		t.Errorf("expected the second click to hit the list only, got %v", mouse[1].Zones())
+	}
+	if len(mouse[2].Zones()) != 0 {
+		t.Errorf("expected the third click to hit no zones, got %v", mouse[2].Zones())
+	}
+}